@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+)
+
+// adoptMatching reports whether create can adopt the existing blob of config instead of writing
+// it, as after an apply that is retried because an earlier attempt crashed: the blob has exactly
+// the content that would be uploaded and is either not leased or leased with config.LeaseID
+func (r *BlobLeaseResource) adoptMatching(ctx context.Context, config blobclient.BlobLeaseConfig) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	// The content of a copy or a page blob is not known up front
+	if config.CopySource != "" || config.BlobType == blobclient.BlobTypePage {
+		return false, diags
+	}
+
+	exists, err := r.client.BlobExists(ctx, config.StorageAccount, config.ContainerName, config.BlobName)
+	if err != nil {
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence, got error: %s", err))
+		return false, diags
+	}
+	if !exists {
+		return false, diags
+	}
+
+	existing, err := r.client.GetBlobLeaseState(ctx, config.StorageAccount, config.ContainerName, config.BlobName)
+	if err != nil {
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read blob properties, got error: %s", err))
+		return false, diags
+	}
+	if existing.BlobType != config.BlobType || existing.ContentMD5 == "" {
+		return false, diags
+	}
+
+	expected := blobclient.ContentMD5(config.Content)
+	if config.SourcePath != "" {
+		expected, err = blobclient.SourceFileMD5(config.SourcePath)
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("source"),
+				"Invalid Source File",
+				fmt.Sprintf("Unable to read source file %s, got error: %s", config.SourcePath, err),
+			)
+			return false, diags
+		}
+	}
+	if existing.ContentMD5 != expected {
+		return false, diags
+	}
+
+	// A lease is only continued when it is ours, which requires a configured lease_id since
+	// a generated one is lost with the run that crashed
+	if existing.LeaseState != "leased" {
+		return true, diags
+	}
+	held, err := r.client.ProbeBlobLease(ctx, config)
+	if err != nil {
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob lease, got error: %s", err))
+		return false, diags
+	}
+	return held, diags
+}
+
+// restoreSoftDeleted undeletes a soft-deleted blob of config when no live blob exists, and
+// reports whether one was restored
+func (r *BlobLeaseResource) restoreSoftDeleted(ctx context.Context, config blobclient.BlobLeaseConfig) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	exists, err := r.client.BlobExists(ctx, config.StorageAccount, config.ContainerName, config.BlobName)
+	if err != nil {
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence, got error: %s", err))
+		return false, diags
+	}
+	if exists {
+		return false, diags
+	}
+
+	restored, err := r.client.RestoreDeletedBlob(ctx, config.StorageAccount, config.ContainerName, config.BlobName)
+	if err != nil {
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to restore soft-deleted blob, got error: %s", err))
+		return false, diags
+	}
+	return restored, diags
+}
+
+// restoredBlobWarning tells the operator that create undeleted a soft-deleted blob, and whether
+// its content had to be overwritten
+func restoredBlobWarning(config blobclient.BlobLeaseConfig, matched bool) diag.Diagnostic {
+	content := "Its content differed from the configured content and was overwritten."
+	if matched {
+		content = "It already had the configured content, which was not rewritten."
+	}
+	return diag.NewAttributeWarningDiagnostic(
+		path.Root("restore_if_soft_deleted"),
+		"Restored Soft-Deleted Blob",
+		fmt.Sprintf("Restored soft-deleted blob %s/%s/%s because restore_if_soft_deleted is true. %s",
+			config.StorageAccount, config.ContainerName, config.BlobName, content),
+	)
+}
+
+// adoptedBlobWarning tells the operator that create adopted an existing blob instead of writing it
+func adoptedBlobWarning(config blobclient.BlobLeaseConfig) diag.Diagnostic {
+	return diag.NewAttributeWarningDiagnostic(
+		path.Root("adopt_matching"),
+		"Adopted Existing Blob",
+		fmt.Sprintf("Adopted existing blob %s/%s/%s, which already had the configured content, because adopt_matching is true. Its lease was acquired or continued and the configured properties were applied; the content was not rewritten.",
+			config.StorageAccount, config.ContainerName, config.BlobName),
+	)
+}
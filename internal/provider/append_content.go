@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+)
+
+// appendContentRequiresReplace replaces the resource unless the planned append_content starts
+// with the records in state, since an append blob cannot be truncated. Records that are unknown
+// at plan time may differ, so they count as changed.
+func appendContentRequiresReplace(ctx context.Context, req planmodifier.ListRequest, resp *listplanmodifier.RequiresReplaceIfFuncResponse) {
+	if req.StateValue.IsNull() {
+		return
+	}
+	if req.PlanValue.IsUnknown() || req.StateValue.IsUnknown() {
+		resp.RequiresReplace = true
+		return
+	}
+
+	prior, planned := req.StateValue.Elements(), req.PlanValue.Elements()
+	if len(planned) < len(prior) {
+		resp.RequiresReplace = true
+		return
+	}
+	for i, record := range prior {
+		if !planned[i].Equal(record) {
+			resp.RequiresReplace = true
+			return
+		}
+	}
+}
+
+// appendRecords appends the append_content records of data from index from on to the blob under
+// the held lease. When an append fails, data keeps only the records that were appended, so the
+// next apply resumes with the first missing one.
+func (r *BlobLeaseResource) appendRecords(ctx context.Context, data *BlobLeaseResourceModel, from int) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if data.AppendContent.IsNull() || data.AppendContent.IsUnknown() {
+		return diags
+	}
+
+	var records []string
+	diags.Append(data.AppendContent.ElementsAs(ctx, &records, false)...)
+	if diags.HasError() || from >= len(records) {
+		return diags
+	}
+
+	config := blobclient.BlobLeaseConfig{
+		StorageAccount:  data.StorageAccount.ValueString(),
+		ContainerName:   data.ContainerName.ValueString(),
+		BlobName:        data.BlobName.ValueString(),
+		LeaseID:         data.LeaseID.ValueString(),
+		EncryptionScope: data.EncryptionScope.ValueString(),
+	}
+
+	appended, etag, err := r.client.AppendBlobRecords(ctx, config, records[from:])
+	if etag != "" {
+		data.ETag = types.StringValue(etag)
+	}
+	if err == nil {
+		return diags
+	}
+
+	data.AppendContent, diags = types.ListValueFrom(ctx, types.StringType, records[:from+appended])
+	if errors.Is(err, blobclient.ErrLeaseLost) {
+		diags.AddAttributeError(path.Root("append_content"), "Lease No Longer Held", err.Error())
+		return diags
+	}
+	diags.AddAttributeError(
+		path.Root("append_content"),
+		"Append Failed",
+		fmt.Sprintf("%s. %d of %d new records were appended; the next apply appends the rest.", err, appended, len(records)-from),
+	)
+	return diags
+}
@@ -0,0 +1,271 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &BlobContentDataSource{}
+
+// defaultMaxContentSize is the largest blob read into state without max_size, 1 MiB
+const defaultMaxContentSize = 1 << 20
+
+// positiveInt64Validator ensures an int64 attribute is at least 1
+type positiveInt64Validator struct{}
+
+func (v positiveInt64Validator) Description(ctx context.Context) string {
+	return "value must be at least 1"
+}
+
+func (v positiveInt64Validator) MarkdownDescription(ctx context.Context) string {
+	return "value must be at least `1`"
+}
+
+func (v positiveInt64Validator) ValidateInt64(ctx context.Context, req validator.Int64Request, resp *validator.Int64Response) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if req.ConfigValue.ValueInt64() < 1 {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Attribute Value",
+			fmt.Sprintf("%s %s, got: %d", req.Path, v.Description(ctx), req.ConfigValue.ValueInt64()),
+		)
+	}
+}
+
+func NewBlobContentDataSource() datasource.DataSource {
+	return &BlobContentDataSource{}
+}
+
+// BlobContentDataSource reads the content of a small blob into the configuration
+type BlobContentDataSource struct {
+	client *blobclient.AzureBlobLeaseClient
+}
+
+// BlobContentDataSourceModel describes the data source data model.
+type BlobContentDataSourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	StorageAccount types.String `tfsdk:"storage_account_name"`
+	BlobEndpoint   types.String `tfsdk:"blob_endpoint"`
+	ContainerName  types.String `tfsdk:"storage_container_name"`
+	BlobName       types.String `tfsdk:"name"`
+	LeaseID        types.String `tfsdk:"lease_id"`
+	MaxSize        types.Int64  `tfsdk:"max_size"`
+	FailIfMissing  types.Bool   `tfsdk:"fail_if_missing"`
+	Exists         types.Bool   `tfsdk:"exists"`
+	Content        types.String `tfsdk:"content"`
+	ContentBase64  types.String `tfsdk:"content_base64"`
+	ContentType    types.String `tfsdk:"content_type"`
+	ETag           types.String `tfsdk:"etag"`
+}
+
+func (d *BlobContentDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_blob_content"
+}
+
+func (d *BlobContentDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Reads the content of a small Azure Storage blob, such as a JSON document, into the configuration",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Data source identifier, `storage_account_name/storage_container_name/name`",
+			},
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name",
+				Required:            true,
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, e.g. a private endpoint or sovereign cloud URL, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`",
+				Optional:            true,
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"storage_container_name": schema.StringAttribute{
+				MarkdownDescription: "The container of the blob",
+				Required:            true,
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The name of the blob",
+				Required:            true,
+				Validators: []validator.String{
+					validators.BlobName(),
+				},
+			},
+			"lease_id": schema.StringAttribute{
+				MarkdownDescription: "The ID of a lease on the blob to read it under. The read fails when the blob is not leased with it",
+				Optional:            true,
+				Sensitive:           true,
+				Validators: []validator.String{
+					uuidValidator{},
+				},
+			},
+			"max_size": schema.Int64Attribute{
+				MarkdownDescription: "The largest blob in bytes that is read; a larger blob fails instead of being stored in state. Defaults to 1048576 (1 MiB)",
+				Optional:            true,
+				Validators: []validator.Int64{
+					positiveInt64Validator{},
+				},
+			},
+			"fail_if_missing": schema.BoolAttribute{
+				MarkdownDescription: "Whether a blob that does not exist fails the read. Otherwise `exists` is false and the content is null. Defaults to `true`",
+				Optional:            true,
+			},
+			"exists": schema.BoolAttribute{
+				MarkdownDescription: "Whether the blob exists. Always true unless `fail_if_missing` is false",
+				Computed:            true,
+			},
+			"content": schema.StringAttribute{
+				MarkdownDescription: "The content of the blob as text, decompressed when its Content-Encoding is `gzip`. Null when it is not valid UTF-8; use `content_base64` for binary content",
+				Computed:            true,
+			},
+			"content_base64": schema.StringAttribute{
+				MarkdownDescription: "The content of the blob, decompressed like `content` and base64-encoded",
+				Computed:            true,
+			},
+			"content_type": schema.StringAttribute{
+				MarkdownDescription: "The Content-Type of the blob. Null when unset",
+				Computed:            true,
+			},
+			"etag": schema.StringAttribute{
+				MarkdownDescription: "The ETag of the content read",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *BlobContentDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *BlobContentDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data BlobContentDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+
+	endpoint := data.BlobEndpoint.ValueString()
+	if endpoint != "" {
+		if err := d.client.CheckBlobEndpoint(endpoint); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+			return
+		}
+	}
+	ctx = blobclient.WithBlobEndpoint(ctx, endpoint)
+
+	maxSize := int64(defaultMaxContentSize)
+	if !data.MaxSize.IsNull() {
+		maxSize = data.MaxSize.ValueInt64()
+	}
+	config := blobclient.BlobLeaseConfig{
+		StorageAccount: data.StorageAccount.ValueString(),
+		ContainerName:  data.ContainerName.ValueString(),
+		BlobName:       data.BlobName.ValueString(),
+		LeaseID:        data.LeaseID.ValueString(),
+	}
+	data.ID = types.StringValue(blobLeaseID(config.StorageAccount, config.ContainerName, config.BlobName))
+
+	content, err := d.client.DownloadBlobContent(ctx, config, maxSize)
+	switch {
+	case errors.Is(err, blobclient.ErrBlobNotFound) && !data.FailIfMissing.IsNull() && !data.FailIfMissing.ValueBool():
+		data.Exists = types.BoolValue(false)
+		data.Content = types.StringNull()
+		data.ContentBase64 = types.StringNull()
+		data.ContentType = types.StringNull()
+		data.ETag = types.StringNull()
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	case errors.Is(err, blobclient.ErrBlobNotFound):
+		resp.Diagnostics.AddAttributeError(path.Root("name"), "Blob Not Found", fmt.Sprintf("%s. Set fail_if_missing to false to read a missing blob as exists = false.", err))
+		return
+	case errors.Is(err, blobclient.ErrBlobTooLarge):
+		resp.Diagnostics.AddAttributeError(path.Root("max_size"), "Blob Too Large", fmt.Sprintf("%s. Raise max_size to read it; its content is stored in state.", err))
+		return
+	case err != nil:
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to download blob content, got error: %s", err))
+		return
+	}
+
+	// A read served by the secondary endpoint may lag the primary
+	if content.StaleRead {
+		resp.Diagnostics.AddWarning(
+			"Stale Read From Secondary Endpoint",
+			fmt.Sprintf("The primary endpoint of storage account %s is unavailable; the content of blob %s was read from the secondary endpoint and may be out of date.",
+				config.StorageAccount, config.BlobName),
+		)
+	}
+
+	// Compressed content is read as it was written
+	decoded, err := blobclient.DecodeContent(content.Content, content.ContentEncoding, maxSize)
+	switch {
+	case errors.Is(err, blobclient.ErrUnsupportedEncoding):
+		resp.Diagnostics.AddAttributeError(path.Root("name"), "Unsupported Content Encoding", fmt.Sprintf("Blob %s has Content-Encoding %q, which cannot be decompressed; only gzip is supported.", config.BlobName, content.ContentEncoding))
+		return
+	case errors.Is(err, blobclient.ErrBlobTooLarge):
+		resp.Diagnostics.AddAttributeError(path.Root("max_size"), "Blob Too Large", fmt.Sprintf("Blob %s: %s. Raise max_size to read it; its content is stored in state.", config.BlobName, err))
+		return
+	case err != nil:
+		resp.Diagnostics.AddError("Invalid Blob Content", fmt.Sprintf("Unable to decompress the %s content of blob %s, got error: %s", content.ContentEncoding, config.BlobName, err))
+		return
+	}
+
+	data.Exists = types.BoolValue(true)
+	data.Content = types.StringNull()
+	if utf8.Valid(decoded) {
+		data.Content = types.StringValue(string(decoded))
+	}
+	data.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(decoded))
+	data.ContentType = stringOrNull(content.ContentType)
+	data.ETag = stringOrNull(content.ETag)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestBlobContentDataSourceEncodings(t *testing.T) {
+	const content = `{"holder":"ci","reason":"deploy"}`
+	source := map[string]any{
+		"storage_account_name":   testAccount,
+		"storage_container_name": testContainer,
+		"name":                   "env/app.lock",
+	}
+
+	t.Run("gzip", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"content": content, "content_compression": "gzip"}))
+		if blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock"); blob.ContentEncoding != "gzip" || string(blob.Content) == content {
+			t.Fatalf("expected the blob to be stored gzip compressed, got encoding %q", blob.ContentEncoding)
+		}
+
+		// The content is read as it was written
+		got, diags := p.readDataSource("blobleas_blob_content", source)
+		requireNoErrors(t, "read", diags)
+		if s := stringAttr(t, got, "content"); s != content {
+			t.Errorf("expected content %q, got %q", content, s)
+		}
+		if s := stringAttr(t, got, "content_base64"); s != base64.StdEncoding.EncodeToString([]byte(content)) {
+			t.Errorf("expected content_base64 of the decompressed content, got %q", s)
+		}
+	})
+
+	t.Run("gzip larger than max_size", func(t *testing.T) {
+		// The content compresses to far less than max_size, but is larger once decompressed
+		p := newTestProvider(t, nil)
+		p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"content": strings.Repeat("a", 4096), "content_compression": "gzip"}))
+		config := map[string]any{"max_size": 1024}
+		for k, v := range source {
+			config[k] = v
+		}
+		_, diags := p.readDataSource("blobleas_blob_content", config)
+		requireError(t, diags, "content decompresses to more than 1024 bytes")
+	})
+
+	t.Run("invalid gzip", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"content": content, "content_encoding": "gzip"}))
+		_, diags := p.readDataSource("blobleas_blob_content", source)
+		requireError(t, diags, "Unable to decompress the gzip content of blob env/app.lock")
+	})
+
+	t.Run("unsupported encoding", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"content": content, "content_encoding": "br"}))
+		_, diags := p.readDataSource("blobleas_blob_content", source)
+		requireError(t, diags, `Blob env/app.lock has Content-Encoding "br", which cannot be decompressed`)
+	})
+}
@@ -0,0 +1,294 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &BlobDataSource{}
+
+func NewBlobDataSource() datasource.DataSource {
+	return &BlobDataSource{}
+}
+
+// BlobDataSource reads the properties of an existing blob
+type BlobDataSource struct {
+	client *blobclient.AzureBlobLeaseClient
+}
+
+// BlobDataSourceModel describes the data source data model.
+type BlobDataSourceModel struct {
+	ID                 types.String `tfsdk:"id"`
+	StorageAccount     types.String `tfsdk:"storage_account_name"`
+	BlobEndpoint       types.String `tfsdk:"blob_endpoint"`
+	ContainerName      types.String `tfsdk:"storage_container_name"`
+	BlobName           types.String `tfsdk:"name"`
+	BlobURL            types.String `tfsdk:"blob_url"`
+	BlobType           types.String `tfsdk:"blob_type"`
+	ETag               types.String `tfsdk:"etag"`
+	ContentLength      types.Int64  `tfsdk:"content_length"`
+	ContentMD5         types.String `tfsdk:"content_md5"`
+	ContentType        types.String `tfsdk:"content_type"`
+	CacheControl       types.String `tfsdk:"cache_control"`
+	ContentEncoding    types.String `tfsdk:"content_encoding"`
+	ContentLanguage    types.String `tfsdk:"content_language"`
+	ContentDisposition types.String `tfsdk:"content_disposition"`
+	Metadata           types.Map    `tfsdk:"metadata"`
+	Tags               types.Map    `tfsdk:"tags"`
+	AccessTier         types.String `tfsdk:"access_tier"`
+	VersionID          types.String `tfsdk:"version_id"`
+	EncryptionScope    types.String `tfsdk:"encryption_scope"`
+	CreationTime       types.String `tfsdk:"creation_time"`
+	LastModified       types.String `tfsdk:"last_modified"`
+}
+
+func (d *BlobDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_blob"
+}
+
+func (d *BlobDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Reads the properties of an existing Azure Storage blob",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Data source identifier, `storage_account_name/storage_container_name/name`",
+			},
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name",
+				Required:            true,
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, e.g. a private endpoint or sovereign cloud URL, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`",
+				Optional:            true,
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"storage_container_name": schema.StringAttribute{
+				MarkdownDescription: "The container of the blob",
+				Required:            true,
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The name of the blob",
+				Required:            true,
+				Validators: []validator.String{
+					validators.BlobName(),
+				},
+			},
+			"blob_url": schema.StringAttribute{
+				MarkdownDescription: "The URL of the blob",
+				Computed:            true,
+			},
+			"blob_type": schema.StringAttribute{
+				MarkdownDescription: "The type of the blob, `block`, `append` or `page`",
+				Computed:            true,
+			},
+			"etag": schema.StringAttribute{
+				MarkdownDescription: "The ETag of the blob",
+				Computed:            true,
+			},
+			"content_length": schema.Int64Attribute{
+				MarkdownDescription: "The size of the blob content in bytes",
+				Computed:            true,
+			},
+			"content_md5": schema.StringAttribute{
+				MarkdownDescription: "The base64-encoded MD5 of the content stored on the blob. Null when the blob has none",
+				Computed:            true,
+			},
+			"content_type": schema.StringAttribute{
+				MarkdownDescription: "The Content-Type of the blob. Null when unset",
+				Computed:            true,
+			},
+			"cache_control": schema.StringAttribute{
+				MarkdownDescription: "The Cache-Control header of the blob. Null when unset",
+				Computed:            true,
+			},
+			"content_encoding": schema.StringAttribute{
+				MarkdownDescription: "The Content-Encoding header of the blob. Null when unset",
+				Computed:            true,
+			},
+			"content_language": schema.StringAttribute{
+				MarkdownDescription: "The Content-Language header of the blob. Null when unset",
+				Computed:            true,
+			},
+			"content_disposition": schema.StringAttribute{
+				MarkdownDescription: "The Content-Disposition header of the blob. Null when unset",
+				Computed:            true,
+			},
+			"metadata": schema.MapAttribute{
+				MarkdownDescription: "The metadata of the blob",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"tags": schema.MapAttribute{
+				MarkdownDescription: "The blob index tags of the blob. Reading them needs the `Microsoft.Storage/storageAccounts/blobServices/containers/blobs/tags/read` permission, but only when the blob has tags",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"access_tier": schema.StringAttribute{
+				MarkdownDescription: "The access tier of the blob, possibly inferred from the account default. Null when the service reports none",
+				Computed:            true,
+			},
+			"version_id": schema.StringAttribute{
+				MarkdownDescription: "The current version of the blob. Null without blob versioning",
+				Computed:            true,
+			},
+			"encryption_scope": schema.StringAttribute{
+				MarkdownDescription: "The encryption scope of the content. Null when encrypted with the account key",
+				Computed:            true,
+			},
+			"creation_time": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time the blob was created",
+				Computed:            true,
+			},
+			"last_modified": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time of the last write to the blob",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *BlobDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *BlobDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data BlobDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+
+	endpoint := data.BlobEndpoint.ValueString()
+	if endpoint != "" {
+		if err := d.client.CheckBlobEndpoint(endpoint); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+			return
+		}
+	}
+	ctx = blobclient.WithBlobEndpoint(ctx, endpoint)
+
+	storageAccount, containerName, blobName := data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString()
+	exists, err := d.client.BlobExists(ctx, storageAccount, containerName, blobName)
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence, got error: %s", err))
+		return
+	}
+	if !exists {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Blob Not Found",
+			fmt.Sprintf("Blob %s does not exist in container %s of storage account %s", blobName, containerName, storageAccount),
+		)
+		return
+	}
+
+	props, err := d.client.GetBlobLeaseState(ctx, storageAccount, containerName, blobName)
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read blob properties, got error: %s", err))
+		return
+	}
+
+	// A read served by the secondary endpoint may lag the primary
+	if props.StaleRead {
+		resp.Diagnostics.AddWarning(
+			"Stale Read From Secondary Endpoint",
+			fmt.Sprintf("The primary endpoint of storage account %s is unavailable; the properties of blob %s were read from the secondary endpoint and may be out of date.",
+				storageAccount, blobName),
+		)
+	}
+
+	// Tags are only read when the blob has some, so that reading untagged blobs needs no tags
+	// permission
+	var tags map[string]string
+	if props.TagCount > 0 {
+		tags, err = d.client.GetBlobTags(ctx, storageAccount, containerName, blobName)
+		if err != nil {
+			resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read blob tags, got error: %s", err))
+			return
+		}
+	}
+
+	data.ID = types.StringValue(blobLeaseID(storageAccount, containerName, blobName))
+	resp.Diagnostics.Append(setBlobProperties(ctx, &data, props, tags)...)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// setBlobProperties records the properties of a property read and the tags of the blob in data.
+// Properties the service does not report are null.
+func setBlobProperties(ctx context.Context, data *BlobDataSourceModel, props *blobclient.BlobLeaseResult, tags map[string]string) diag.Diagnostics {
+	var diags, d diag.Diagnostics
+
+	data.BlobURL = types.StringValue(props.BlobURL)
+	data.BlobType = stringOrNull(props.BlobType)
+	data.ETag = stringOrNull(props.ETag)
+	data.ContentLength = types.Int64Value(props.Size)
+	data.ContentMD5 = stringOrNull(props.ContentMD5)
+	data.ContentType = stringOrNull(props.ContentType)
+	data.CacheControl = stringOrNull(props.Headers.CacheControl)
+	data.ContentEncoding = stringOrNull(props.Headers.ContentEncoding)
+	data.ContentLanguage = stringOrNull(props.Headers.ContentLanguage)
+	data.ContentDisposition = stringOrNull(props.Headers.ContentDisposition)
+	data.AccessTier = stringOrNull(props.AccessTier)
+	data.VersionID = stringOrNull(props.VersionID)
+	data.EncryptionScope = stringOrNull(props.EncryptionScope)
+	data.CreationTime = timestampValue(props.CreatedOn)
+	data.LastModified = timestampValue(props.LastModified)
+
+	if props.Metadata == nil {
+		props.Metadata = map[string]string{}
+	}
+	data.Metadata, d = types.MapValueFrom(ctx, types.StringType, props.Metadata)
+	diags.Append(d...)
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	data.Tags, d = types.MapValueFrom(ctx, types.StringType, tags)
+	diags.Append(d...)
+	return diags
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"maps"
+	"math/big"
+	"testing"
+)
+
+func TestBlobDataSource(t *testing.T) {
+	p := newTestProvider(t, nil)
+	state := p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{
+		"content":  `{"holder":"ci"}`,
+		"metadata": map[string]string{"team": "platform"},
+		"tags":     map[string]string{"env": "prod"},
+	}))
+	source := map[string]any{
+		"storage_account_name":   testAccount,
+		"storage_container_name": testContainer,
+		"name":                   stringAttr(t, state.value, "name"),
+	}
+
+	// The data source reads the blob the resource wrote
+	blob, diags := p.readDataSource("blobleas_blob", source)
+	requireNoErrors(t, "read", diags)
+	for _, name := range []string{"id", "blob_type", "etag", "content_md5", "creation_time", "last_modified"} {
+		if got, want := stringAttr(t, blob, name), stringAttr(t, state.value, name); got != want {
+			t.Errorf("expected %s %q, got %q", name, want, got)
+		}
+	}
+	var length big.Float
+	if err := attrValue(t, blob, "content_length").As(&length); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := length.Int64(); n != int64(len(`{"holder":"ci"}`)) {
+		t.Errorf("expected content_length %d, got %d", len(`{"holder":"ci"}`), n)
+	}
+	if got := stringMapAttr(t, blob, "metadata"); got["Team"] != "platform" && got["team"] != "platform" {
+		t.Errorf("expected metadata team=platform, got %v", got)
+	}
+	if got := stringMapAttr(t, blob, "tags"); !maps.Equal(got, map[string]string{"env": "prod"}) {
+		t.Errorf("expected tags env=prod, got %v", got)
+	}
+
+	// Properties the blob does not have are null rather than empty
+	for _, name := range []string{"version_id", "encryption_scope", "content_encoding"} {
+		if v := attrValue(t, blob, name); !v.IsNull() {
+			t.Errorf("expected %s to be null, got %s", name, v)
+		}
+	}
+
+	// A blob that does not exist is an error rather than an empty result
+	source["name"] = "env/missing.lock"
+	_, diags = p.readDataSource("blobleas_blob", source)
+	requireError(t, diags, "Blob Not Found")
+
+	// The name is validated as for the resource
+	source["name"] = "env/app.lock."
+	_, diags = p.readDataSource("blobleas_blob", source)
+	if !hasErrors(diags) {
+		t.Error("expected a blob name ending in a dot to be rejected")
+	}
+}
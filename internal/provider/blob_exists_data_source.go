@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &BlobExistsDataSource{}
+
+func NewBlobExistsDataSource() datasource.DataSource {
+	return &BlobExistsDataSource{}
+}
+
+// BlobExistsDataSource reports whether a blob exists, for conditional creation
+type BlobExistsDataSource struct {
+	client *blobclient.AzureBlobLeaseClient
+}
+
+// BlobExistsDataSourceModel describes the data source data model.
+type BlobExistsDataSourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	StorageAccount types.String `tfsdk:"storage_account_name"`
+	BlobEndpoint   types.String `tfsdk:"blob_endpoint"`
+	ContainerName  types.String `tfsdk:"storage_container_name"`
+	BlobName       types.String `tfsdk:"name"`
+	Exists         types.Bool   `tfsdk:"exists"`
+	CheckedAt      types.String `tfsdk:"checked_at"`
+}
+
+func (d *BlobExistsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_blob_exists"
+}
+
+func (d *BlobExistsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Reports whether an Azure Storage blob exists",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Data source identifier, `storage_account_name/storage_container_name/name`",
+			},
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name",
+				Required:            true,
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, e.g. a private endpoint or sovereign cloud URL, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`",
+				Optional:            true,
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"storage_container_name": schema.StringAttribute{
+				MarkdownDescription: "The container of the blob",
+				Required:            true,
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The name of the blob",
+				Required:            true,
+				Validators: []validator.String{
+					validators.BlobName(),
+				},
+			},
+			"exists": schema.BoolAttribute{
+				MarkdownDescription: "Whether the blob exists. False as well when the container does not exist; a principal that may not read the blob fails the read instead",
+				Computed:            true,
+			},
+			"checked_at": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time the blob was checked",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *BlobExistsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *BlobExistsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data BlobExistsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+
+	endpoint := data.BlobEndpoint.ValueString()
+	if endpoint != "" {
+		if err := d.client.CheckBlobEndpoint(endpoint); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+			return
+		}
+	}
+	ctx = blobclient.WithBlobEndpoint(ctx, endpoint)
+
+	// A blob that cannot be read is not reported missing, since that would make a count or
+	// for_each on exists create it
+	storageAccount, containerName, blobName := data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString()
+	checkedAt := time.Now()
+	exists, err := d.client.BlobExists(ctx, storageAccount, containerName, blobName)
+	switch {
+	case errors.Is(err, blobclient.ErrAuthorizationFailed):
+		resp.Diagnostics.AddAttributeError(path.Root("name"), "Blob Read Not Permitted", err.Error())
+		return
+	case err != nil:
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence, got error: %s", err))
+		return
+	}
+
+	data.ID = types.StringValue(blobLeaseID(storageAccount, containerName, blobName))
+	data.Exists = types.BoolValue(exists)
+	data.CheckedAt = types.StringValue(checkedAt.UTC().Format(time.RFC3339))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,220 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &BlobLeaseDataSource{}
+
+func NewBlobLeaseDataSource() datasource.DataSource {
+	return &BlobLeaseDataSource{}
+}
+
+// BlobLeaseDataSource reads the lease state of a blob without leasing it, for configurations
+// that only need to know whether someone else holds the lease
+type BlobLeaseDataSource struct {
+	client *blobclient.AzureBlobLeaseClient
+}
+
+// BlobLeaseDataSourceModel describes the data source data model.
+type BlobLeaseDataSourceModel struct {
+	ID                types.String `tfsdk:"id"`
+	StorageAccount    types.String `tfsdk:"storage_account_name"`
+	BlobEndpoint      types.String `tfsdk:"blob_endpoint"`
+	ContainerName     types.String `tfsdk:"storage_container_name"`
+	BlobName          types.String `tfsdk:"name"`
+	AllowMissing      types.Bool   `tfsdk:"allow_missing"`
+	Exists            types.Bool   `tfsdk:"exists"`
+	LeaseState        types.String `tfsdk:"lease_state"`
+	LeaseStatus       types.String `tfsdk:"lease_status"`
+	LeaseDurationKind types.String `tfsdk:"lease_duration_kind"`
+	ETag              types.String `tfsdk:"etag"`
+	LastModified      types.String `tfsdk:"last_modified"`
+	BlobURL           types.String `tfsdk:"blob_url"`
+}
+
+func (d *BlobLeaseDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_blob_lease"
+}
+
+func (d *BlobLeaseDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Reads the lease state of an Azure Storage blob without leasing it",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Data source identifier, `storage_account_name/storage_container_name/name`",
+			},
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name",
+				Required:            true,
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, e.g. a private endpoint or sovereign cloud URL, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`",
+				Optional:            true,
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"storage_container_name": schema.StringAttribute{
+				MarkdownDescription: "The container of the blob",
+				Required:            true,
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The name of the blob",
+				Required:            true,
+				Validators: []validator.String{
+					validators.BlobName(),
+				},
+			},
+			"allow_missing": schema.BoolAttribute{
+				MarkdownDescription: "Whether a blob that does not exist sets `exists` to false instead of failing. Defaults to `false`",
+				Optional:            true,
+			},
+			"exists": schema.BoolAttribute{
+				MarkdownDescription: "Whether the blob exists. Always true unless `allow_missing` is set",
+				Computed:            true,
+			},
+			"lease_state": schema.StringAttribute{
+				MarkdownDescription: "The lease state of the blob: `available`, `leased`, `expired`, `breaking` or `broken`. Null when the blob does not exist",
+				Computed:            true,
+			},
+			"lease_status": schema.StringAttribute{
+				MarkdownDescription: "The lease status of the blob, `locked` or `unlocked`. Null when the blob does not exist",
+				Computed:            true,
+			},
+			"lease_duration_kind": schema.StringAttribute{
+				MarkdownDescription: "Whether the lease on the blob is `fixed` or `infinite`, empty when the blob is not leased. Null when the blob does not exist",
+				Computed:            true,
+			},
+			"etag": schema.StringAttribute{
+				MarkdownDescription: "The ETag of the blob. Null when the blob does not exist",
+				Computed:            true,
+			},
+			"last_modified": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time of the last write to the blob. Null when the blob does not exist",
+				Computed:            true,
+			},
+			"blob_url": schema.StringAttribute{
+				MarkdownDescription: "The URL of the blob. Null when the blob does not exist",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *BlobLeaseDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *BlobLeaseDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data BlobLeaseDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+
+	endpoint := data.BlobEndpoint.ValueString()
+	if endpoint != "" {
+		if err := d.client.CheckBlobEndpoint(endpoint); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+			return
+		}
+	}
+	ctx = blobclient.WithBlobEndpoint(ctx, endpoint)
+
+	storageAccount, containerName, blobName := data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString()
+	data.ID = types.StringValue(blobLeaseID(storageAccount, containerName, blobName))
+
+	exists, err := d.client.BlobExists(ctx, storageAccount, containerName, blobName)
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence, got error: %s", err))
+		return
+	}
+	data.Exists = types.BoolValue(exists)
+	if !exists {
+		if !data.AllowMissing.ValueBool() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("name"),
+				"Blob Not Found",
+				fmt.Sprintf("Blob %s does not exist in container %s of storage account %s. Set allow_missing to read a missing blob as exists = false.",
+					blobName, containerName, storageAccount),
+			)
+			return
+		}
+		data.LeaseState = types.StringNull()
+		data.LeaseStatus = types.StringNull()
+		data.LeaseDurationKind = types.StringNull()
+		data.ETag = types.StringNull()
+		data.LastModified = types.StringNull()
+		data.BlobURL = types.StringNull()
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	leaseResult, err := d.client.GetBlobLeaseState(ctx, storageAccount, containerName, blobName)
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read blob lease state, got error: %s", err))
+		return
+	}
+
+	// A read served by the secondary endpoint may lag the primary
+	if leaseResult.StaleRead {
+		resp.Diagnostics.AddWarning(
+			"Stale Read From Secondary Endpoint",
+			fmt.Sprintf("The primary endpoint of storage account %s is unavailable; lease state for blob %s was read from the secondary endpoint and may be out of date.",
+				storageAccount, blobName),
+		)
+	}
+
+	data.LeaseState = types.StringValue(leaseResult.LeaseState)
+	data.LeaseStatus = leaseStatusValue(leaseResult)
+	data.LeaseDurationKind = leaseDurationKindValue(leaseResult, types.Int32Null())
+	data.ETag = types.StringValue(leaseResult.ETag)
+	data.LastModified = timestampValue(leaseResult.LastModified)
+	data.BlobURL = types.StringValue(leaseResult.BlobURL)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -2,147 +2,2561 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/lease"
 	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 
 	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &BlobLeaseResource{}
 var _ resource.ResourceWithImportState = &BlobLeaseResource{}
+var _ resource.ResourceWithValidateConfig = &BlobLeaseResource{}
+var _ resource.ResourceWithModifyPlan = &BlobLeaseResource{}
+var _ resource.ResourceWithUpgradeState = &BlobLeaseResource{}
 
-// Custom plan modifier to check lease state and trigger updates when needed
-type leaseStatePlanModifier struct{}
+// leaseStateLeasedByOther is the lease_state recorded when refresh finds the blob leased with a
+// lease ID other than the one in state
+const leaseStateLeasedByOther = "leased-by-other"
 
-func (m leaseStatePlanModifier) Description(ctx context.Context) string {
-	return "Checks lease state and triggers update if lease needs renewal"
+// Values of lease_action_on_drift
+const (
+	leaseActionOnDriftReacquire = "reacquire"
+	leaseActionOnDriftFail      = "fail"
+	leaseActionOnDriftIgnore    = "ignore"
+)
+
+// renewalDue reports whether a lease expiring at expiresAt has less than threshold seconds left
+// at now. Infinite leases, which have no expiry, and an unset threshold never make it due.
+func renewalDue(expiresAt types.String, threshold types.Int32, now time.Time) bool {
+	if expiresAt.IsNull() || expiresAt.IsUnknown() || threshold.IsNull() || threshold.IsUnknown() {
+		return false
+	}
+
+	expiry, err := time.Parse(time.RFC3339, expiresAt.ValueString())
+	if err != nil {
+		return false
+	}
+	return expiry.Sub(now) < time.Duration(threshold.ValueInt32())*time.Second
+}
+
+// leaseDurationValidator ensures lease_duration is -1 (infinite) or between 15 and 60 seconds
+type leaseDurationValidator struct{}
+
+func (v leaseDurationValidator) Description(ctx context.Context) string {
+	return "value must be -1 (infinite) or between 15 and 60 seconds"
+}
+
+func (v leaseDurationValidator) MarkdownDescription(ctx context.Context) string {
+	return "value must be `-1` (infinite) or between `15` and `60` seconds"
+}
+
+func (v leaseDurationValidator) ValidateInt32(ctx context.Context, req validator.Int32Request, resp *validator.Int32Response) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	duration := req.ConfigValue.ValueInt32()
+	if duration != -1 && (duration < 15 || duration > 60) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Lease Duration",
+			fmt.Sprintf("lease_duration %s, got: %d", v.Description(ctx), duration),
+		)
+	}
+}
+
+// rfc3339Validator ensures a string attribute holds an RFC3339 timestamp
+type rfc3339Validator struct{}
+
+func (v rfc3339Validator) Description(ctx context.Context) string {
+	return "value must be an RFC3339 timestamp, e.g. 2030-01-02T15:04:05Z"
+}
+
+func (v rfc3339Validator) MarkdownDescription(ctx context.Context) string {
+	return "value must be an RFC3339 timestamp, e.g. `2030-01-02T15:04:05Z`"
+}
+
+func (v rfc3339Validator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := time.Parse(time.RFC3339, req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Timestamp",
+			fmt.Sprintf("%s %s, got: %s", req.Path, v.Description(ctx), req.ConfigValue.ValueString()),
+		)
+	}
+}
+
+// durationValidator ensures a string attribute holds a non-negative Go duration
+type durationValidator struct{}
+
+func (v durationValidator) Description(ctx context.Context) string {
+	return "value must be a non-negative duration, e.g. 30s or 5m"
+}
+
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return "value must be a non-negative duration, e.g. `30s` or `5m`"
+}
+
+func (v durationValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if d, err := time.ParseDuration(req.ConfigValue.ValueString()); err != nil || d < 0 {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Duration",
+			fmt.Sprintf("%s %s, got: %s", req.Path, v.Description(ctx), req.ConfigValue.ValueString()),
+		)
+	}
+}
+
+// blobEndpointValidator ensures a string attribute is an absolute http or https URL without a query.
+// Whether http is allowed depends on the provider configuration, so it is checked at plan time.
+type blobEndpointValidator struct{}
+
+func (v blobEndpointValidator) Description(ctx context.Context) string {
+	return "value must be an https URL without a query, e.g. https://account.privatelink.blob.core.windows.net/"
+}
+
+func (v blobEndpointValidator) MarkdownDescription(ctx context.Context) string {
+	return "value must be an https URL without a query, e.g. `https://account.privatelink.blob.core.windows.net/`"
+}
+
+func (v blobEndpointValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	u, err := url.Parse(req.ConfigValue.ValueString())
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") || u.RawQuery != "" || u.Fragment != "" {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Blob Endpoint",
+			fmt.Sprintf("%s %s, got: %s", req.Path, v.Description(ctx), req.ConfigValue.ValueString()),
+		)
+	}
+}
+
+// copySourceValidator ensures a string attribute is an absolute http or https blob URL. A query,
+// such as a SAS token, is allowed.
+type copySourceValidator struct{}
+
+func (v copySourceValidator) Description(ctx context.Context) string {
+	return "value must be the https URL of a blob, optionally with a SAS token"
+}
+
+func (v copySourceValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v copySourceValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	// The value is sensitive, so it is not repeated in the error
+	u, err := url.Parse(req.ConfigValue.ValueString())
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") || strings.Trim(u.Path, "/") == "" {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Copy Source",
+			fmt.Sprintf("%s %s", req.Path, v.Description(ctx)),
+		)
+	}
+}
+
+// metadataValueValidator ensures a string attribute can be sent as a blob metadata value: non-empty
+// printable ASCII without leading or trailing spaces, since metadata is sent as HTTP headers
+type metadataValueValidator struct{}
+
+func (v metadataValueValidator) Description(ctx context.Context) string {
+	return "value must be non-empty printable ASCII without leading or trailing spaces"
+}
+
+func (v metadataValueValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v metadataValueValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	valid := value != "" && strings.TrimSpace(value) == value
+	for _, c := range value {
+		if c < ' ' || c > '~' {
+			valid = false
+		}
+	}
+	if !valid {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Metadata Value",
+			fmt.Sprintf("%s %s, got: %q", req.Path, v.Description(ctx), value),
+		)
+	}
+}
+
+// stringOneOfValidator ensures a string attribute is one of a fixed set of values
+type stringOneOfValidator struct {
+	values []string
+}
+
+func (v stringOneOfValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be one of: %s", strings.Join(v.values, ", "))
+}
+
+func (v stringOneOfValidator) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprintf("value must be one of: `%s`", strings.Join(v.values, "`, `"))
+}
+
+func (v stringOneOfValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if !slices.Contains(v.values, req.ConfigValue.ValueString()) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Attribute Value",
+			fmt.Sprintf("%s %s, got: %s", req.Path, v.Description(ctx), req.ConfigValue.ValueString()),
+		)
+	}
+}
+
+// metadataValidator ensures map keys are valid blob metadata names that are unique ignoring case
+type metadataValidator struct{}
+
+func (v metadataValidator) Description(ctx context.Context) string {
+	return "keys must be valid C# identifiers and unique ignoring case"
+}
+
+func (v metadataValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v metadataValidator) ValidateMap(ctx context.Context, req validator.MapRequest, resp *validator.MapResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	seen := map[string]string{}
+	for key := range req.ConfigValue.Elements() {
+		if err := blobclient.ValidateMetadataKey(key); err != nil {
+			resp.Diagnostics.AddAttributeError(req.Path.AtMapKey(key), "Invalid Metadata Key", err.Error())
+			continue
+		}
+		if other, ok := seen[strings.ToLower(key)]; ok {
+			resp.Diagnostics.AddAttributeError(
+				req.Path.AtMapKey(key),
+				"Duplicate Metadata Key",
+				fmt.Sprintf("metadata keys are case-insensitive, %q and %q refer to the same key", other, key),
+			)
+		}
+		seen[strings.ToLower(key)] = key
+	}
+}
+
+// tagsValidator ensures a map holds valid blob index tags
+type tagsValidator struct{}
+
+func (v tagsValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("at most %d tags with keys of 1-128 and values of up to 256 letters, digits, spaces or + - . / : = _", blobclient.MaxBlobTags)
+}
+
+func (v tagsValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v tagsValidator) ValidateMap(ctx context.Context, req validator.MapRequest, resp *validator.MapResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	elements := req.ConfigValue.Elements()
+	if len(elements) > blobclient.MaxBlobTags {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Too Many Tags",
+			fmt.Sprintf("a blob can have at most %d index tags, got: %d", blobclient.MaxBlobTags, len(elements)),
+		)
+	}
+	for key, element := range elements {
+		value, ok := element.(types.String)
+		if !ok || value.IsUnknown() {
+			continue
+		}
+		if err := blobclient.ValidateTag(key, value.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(req.Path.AtMapKey(key), "Invalid Tag", err.Error())
+		}
+	}
+}
+
+// attributeHeaders maps the upload headers that an attribute manages to that attribute, so that
+// extra_headers cannot set them behind its back
+var attributeHeaders = map[string]string{
+	"cache-control":                       "cache_control",
+	"x-ms-blob-cache-control":             "cache_control",
+	"content-encoding":                    "content_encoding",
+	"x-ms-blob-content-encoding":          "content_encoding",
+	"content-language":                    "content_language",
+	"x-ms-blob-content-language":          "content_language",
+	"content-disposition":                 "content_disposition",
+	"x-ms-blob-content-disposition":       "content_disposition",
+	"x-ms-tags":                           "tags",
+	"x-ms-access-tier":                    "access_tier",
+	"x-ms-encryption-scope":               "encryption_scope",
+	"x-ms-legal-hold":                     "legal_hold",
+	"x-ms-immutability-policy-until-date": "immutability_policy",
+	"x-ms-immutability-policy-mode":       "immutability_policy",
+	"x-ms-copy-source":                    "copy_source",
+	"x-ms-proposed-lease-id":              "lease_id",
+	"x-ms-blob-type":                      "blob_type",
+	"x-ms-blob-content-length":            "page_blob_size",
+}
+
+// extraHeadersValidator ensures a map holds extra upload headers that do not interfere with the
+// client or with a header managed by another attribute
+type extraHeadersValidator struct{}
+
+func (v extraHeadersValidator) Description(ctx context.Context) string {
+	return "names must be Content-Type or x-ms-* headers not managed by another attribute, unique ignoring case"
+}
+
+func (v extraHeadersValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v extraHeadersValidator) ValidateMap(ctx context.Context, req validator.MapRequest, resp *validator.MapResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	seen := map[string]string{}
+	for name, element := range req.ConfigValue.Elements() {
+		value, ok := element.(types.String)
+		if !ok || value.IsUnknown() {
+			continue
+		}
+		if err := blobclient.ValidateExtraHeader(name, value.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(req.Path.AtMapKey(name), "Invalid Extra Header", err.Error())
+			continue
+		}
+
+		lower := strings.ToLower(name)
+		attribute, managed := attributeHeaders[lower]
+		if strings.HasPrefix(lower, "x-ms-meta-") {
+			attribute, managed = "metadata", true
+		}
+		if managed {
+			resp.Diagnostics.AddAttributeError(
+				req.Path.AtMapKey(name),
+				"Conflicting Extra Header",
+				fmt.Sprintf("header %q is managed by the %s attribute; set it there instead", name, attribute),
+			)
+			continue
+		}
+		if other, ok := seen[lower]; ok {
+			resp.Diagnostics.AddAttributeError(
+				req.Path.AtMapKey(name),
+				"Duplicate Extra Header",
+				fmt.Sprintf("header names are case-insensitive, %q and %q refer to the same header", other, name),
+			)
+		}
+		seen[lower] = name
+	}
+}
+
+// uuidValidator ensures a string attribute holds a UUID, the only lease ID format Azure accepts
+type uuidValidator struct{}
+
+func (v uuidValidator) Description(ctx context.Context) string {
+	return "value must be a UUID, e.g. 3f2504e0-4f89-11d3-9a0c-0305e82c3301"
+}
+
+func (v uuidValidator) MarkdownDescription(ctx context.Context) string {
+	return "value must be a UUID, e.g. `3f2504e0-4f89-11d3-9a0c-0305e82c3301`"
+}
+
+func (v uuidValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	// The value is sensitive, so it is not repeated in the diagnostic
+	if _, err := uuid.Parse(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Lease ID",
+			fmt.Sprintf("%s %s", req.Path, v.Description(ctx)),
+		)
+	}
+}
+
+// leaseIDPlanModifier keeps the generated lease ID from state while the lease is held. When the
+// lease was lost, a rotation trigger changed or the lease ID is older than rotation_days, the next
+// apply acquires or rotates to a new ID, so the ID is unknown until then.
+type leaseIDPlanModifier struct {
+	now func() time.Time
+}
+
+func (m leaseIDPlanModifier) Description(ctx context.Context) string {
+	return "Keeps the lease ID from state unless the lease must be re-acquired"
+}
+
+func (m leaseIDPlanModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m leaseIDPlanModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	// A configured lease ID is planned as is; nothing to keep on create or destroy
+	if !req.ConfigValue.IsNull() || req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var leaseState, rotatedAt types.String
+	var planTriggers, stateTriggers types.Map
+	var rotationDays types.Int32
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("lease_state"), &leaseState)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("rotation_triggers"), &planTriggers)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("rotation_triggers"), &stateTriggers)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("lease_rotated_at"), &rotatedAt)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("rotation_days"), &rotationDays)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if leaseState.ValueString() != "leased" {
+		return
+	}
+	// The plan may otherwise be unchanged, so a due rotation is planned explicitly
+	if rotationDue(rotatedAt, rotationDays, m.now()) {
+		resp.PlanValue = types.StringUnknown()
+		return
+	}
+	if planTriggers.Equal(stateTriggers) {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+// rotationDue reports whether a lease ID set at rotatedAt is at least days old at now. An unset
+// rotation_days or an unknown rotation time never makes it due.
+func rotationDue(rotatedAt types.String, days types.Int32, now time.Time) bool {
+	if rotatedAt.IsNull() || rotatedAt.IsUnknown() || days.IsNull() || days.IsUnknown() {
+		return false
+	}
+
+	at, err := time.Parse(time.RFC3339, rotatedAt.ValueString())
+	if err != nil {
+		return false
+	}
+	return now.Sub(at) >= time.Duration(days.ValueInt32())*24*time.Hour
+}
+
+// positiveInt32Validator ensures an int32 attribute is at least 1
+type positiveInt32Validator struct{}
+
+func (v positiveInt32Validator) Description(ctx context.Context) string {
+	return "value must be at least 1"
+}
+
+func (v positiveInt32Validator) MarkdownDescription(ctx context.Context) string {
+	return "value must be at least `1`"
+}
+
+func (v positiveInt32Validator) ValidateInt32(ctx context.Context, req validator.Int32Request, resp *validator.Int32Response) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if req.ConfigValue.ValueInt32() < 1 {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Attribute Value",
+			fmt.Sprintf("%s %s, got: %d", req.Path, v.Description(ctx), req.ConfigValue.ValueInt32()),
+		)
+	}
+}
+
+// expiresOnPlanModifier keeps expires_on from state unless the configured expiry changes
+type expiresOnPlanModifier struct{}
+
+func (m expiresOnPlanModifier) Description(ctx context.Context) string {
+	return "Keeps the known expiry time unless expiry or expiry_days change"
+}
+
+func (m expiresOnPlanModifier) MarkdownDescription(ctx context.Context) string {
+	return "Keeps the known expiry time unless `expiry` or `expiry_days` change"
+}
+
+func (m expiresOnPlanModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	// Don't modify during create or destroy
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var planExpiry, stateExpiry types.String
+	var planDays, stateDays types.Int32
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("expiry"), &planExpiry)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("expiry"), &stateExpiry)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("expiry_days"), &planDays)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("expiry_days"), &stateDays)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if planExpiry.Equal(stateExpiry) && planDays.Equal(stateDays) {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+// contentWritePlanModifier keeps an attribute set by writing the content, such as content_md5,
+// from state unless content changes and is rewritten
+type contentWritePlanModifier struct{}
+
+func (m contentWritePlanModifier) Description(ctx context.Context) string {
+	return "Keeps the known value unless content changes"
+}
+
+func (m contentWritePlanModifier) MarkdownDescription(ctx context.Context) string {
+	return "Keeps the known value unless `content` changes"
+}
+
+func (m contentWritePlanModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	// Don't modify during create or destroy
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	changed, diags := plannedContentChange(ctx, req.Config, req.Plan, req.State)
+	resp.Diagnostics.Append(diags...)
+	if !resp.Diagnostics.HasError() && !changed {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+// Values of content_format
+const (
+	contentFormatText = "text"
+	contentFormatJSON = "json"
+)
+
+// defaultContent is written to a blob whose content is not configured. Configured empty content
+// is written as is, as a zero-byte blob.
+const defaultContent = "managed by terraform-provider-blobleas"
+
+// contentPlanModifier plans unconfigured content. An existing resource keeps the content in
+// state, which is null after import or when the blob was written outside Terraform. On create, a
+// resource that does not write content, with acquire_existing, copy_source or a page blob, plans
+// null, as does one reading its content from source; otherwise the plan shows defaultContent.
+type contentPlanModifier struct{}
+
+func (m contentPlanModifier) Description(ctx context.Context) string {
+	return "Keeps the content in state when content is not configured"
+}
+
+func (m contentPlanModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m contentPlanModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if !req.ConfigValue.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+	if !req.State.Raw.IsNull() {
+		resp.PlanValue = req.StateValue
+		return
+	}
+
+	var acquireExisting, writeLockInfo types.Bool
+	var copySource, source, blobType types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("acquire_existing"), &acquireExisting)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("write_lock_info"), &writeLockInfo)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("copy_source"), &copySource)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("source"), &source)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("blob_type"), &blobType)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A lock document is recorded in lock_info_document, since it changes with every acquisition
+	if acquireExisting.ValueBool() || writeLockInfo.ValueBool() || !copySource.IsNull() || !source.IsNull() || blobType.ValueString() == blobclient.BlobTypePage {
+		resp.PlanValue = types.StringNull()
+		return
+	}
+	if !acquireExisting.IsUnknown() && !writeLockInfo.IsUnknown() && !copySource.IsUnknown() && !source.IsUnknown() && !blobType.IsUnknown() {
+		resp.PlanValue = types.StringValue(defaultContent)
+	}
+}
+
+// jsonContentPlanModifier keeps the content in state when content_format is json and the
+// configured content is the same JSON value, so formatting and key order do not cause a diff
+type jsonContentPlanModifier struct{}
+
+func (m jsonContentPlanModifier) Description(ctx context.Context) string {
+	return "Keeps the content in state when it is semantically equal JSON"
+}
+
+func (m jsonContentPlanModifier) MarkdownDescription(ctx context.Context) string {
+	return "Keeps the content in state when it is semantically equal JSON"
+}
+
+func (m jsonContentPlanModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || req.PlanValue.Equal(req.StateValue) {
+		return
+	}
+
+	changed, diags := plannedContentChange(ctx, req.Config, req.Plan, req.State)
+	resp.Diagnostics.Append(diags...)
+	if !resp.Diagnostics.HasError() && !changed {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+// plannedContentChange reports whether the planned content differs from the content in state,
+// comparing JSON values instead of text when content_format is json. Unconfigured content keeps
+// the content in state.
+func plannedContentChange(ctx context.Context, config tfsdk.Config, plan tfsdk.Plan, state tfsdk.State) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var configContent, planContent, stateContent, format types.String
+	diags.Append(config.GetAttribute(ctx, path.Root("content"), &configContent)...)
+	diags.Append(plan.GetAttribute(ctx, path.Root("content"), &planContent)...)
+	diags.Append(state.GetAttribute(ctx, path.Root("content"), &stateContent)...)
+	diags.Append(plan.GetAttribute(ctx, path.Root("content_format"), &format)...)
+	if diags.HasError() {
+		return true, diags
+	}
+
+	if configContent.IsNull() || planContent.Equal(stateContent) {
+		return false, diags
+	}
+	if format.ValueString() != contentFormatJSON || planContent.IsNull() || planContent.IsUnknown() || stateContent.IsNull() || stateContent.IsUnknown() {
+		return true, diags
+	}
+	return !jsonEqual(planContent.ValueString(), stateContent.ValueString()), diags
+}
+
+// jsonEqual reports whether two documents hold the same JSON value. Numbers are compared by
+// value, so 1, 1.0 and 1e0 are equal, without the rounding of float64 that would make large
+// integers such as IDs equal to their neighbours.
+func jsonEqual(a, b string) bool {
+	valueA, ok := decodeJSON(a)
+	if !ok {
+		return false
+	}
+	valueB, ok := decodeJSON(b)
+	if !ok {
+		return false
+	}
+	return jsonValuesEqual(valueA, valueB)
+}
+
+// decodeJSON decodes a document holding a single JSON value, keeping numbers as json.Number
+func decodeJSON(document string) (interface{}, bool) {
+	decoder := json.NewDecoder(strings.NewReader(document))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, false
+	}
+	return value, true
+}
+
+// jsonNumberPrecision is the precision, in bits, JSON numbers are compared with. It holds any
+// integer of up to 150 digits exactly.
+const jsonNumberPrecision = 512
+
+// jsonValuesEqual compares two values decoded by decodeJSON
+func jsonValuesEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		numberA, _, errA := big.ParseFloat(a.String(), 10, jsonNumberPrecision, big.ToNearestEven)
+		numberB, _, errB := big.ParseFloat(b.String(), 10, jsonNumberPrecision, big.ToNearestEven)
+		return errA == nil && errB == nil && numberA.Cmp(numberB) == 0
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, valueA := range a {
+			valueB, ok := b[key]
+			if !ok || !jsonValuesEqual(valueA, valueB) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonValuesEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// keepersRequireReplace replaces the resource when keepers change. A value that is unknown at
+// plan time may change, so it counts as changed instead of failing the plan.
+func keepersRequireReplace(ctx context.Context, req planmodifier.MapRequest, resp *mapplanmodifier.RequiresReplaceIfFuncResponse) {
+	changed := changedKeepers(req.StateValue, req.PlanValue)
+	if changed == nil {
+		changed = []string{"*"}
+	}
+
+	tflog.Info(ctx, "keepers changed, replacing blob lease", map[string]interface{}{
+		"changed_keys": strings.Join(changed, ", "),
+	})
+	resp.RequiresReplace = true
+}
+
+// changedKeepers returns the sorted keys whose value differs between the prior and planned
+// keepers, counting a value that is unknown as changed. It returns nil when either map is
+// unknown, so which keys change is not known either.
+func changedKeepers(prior, planned types.Map) []string {
+	if prior.IsUnknown() || planned.IsUnknown() {
+		return nil
+	}
+
+	changed := []string{}
+	priorElements, plannedElements := prior.Elements(), planned.Elements()
+	for key, value := range plannedElements {
+		if priorValue, ok := priorElements[key]; !ok || !value.Equal(priorValue) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range priorElements {
+		if _, ok := plannedElements[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+// lastSnapshotPlanModifier keeps last_snapshot_id from state unless the update snapshots the blob
+type lastSnapshotPlanModifier struct{}
+
+func (m lastSnapshotPlanModifier) Description(ctx context.Context) string {
+	return "Keeps the last snapshot ID unless content changes with snapshot_before_update enabled"
+}
+
+func (m lastSnapshotPlanModifier) MarkdownDescription(ctx context.Context) string {
+	return "Keeps the last snapshot ID unless `content` changes with `snapshot_before_update` enabled"
+}
+
+func (m lastSnapshotPlanModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	// Nothing to do on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	// A new resource has not been snapshotted yet
+	if req.State.Raw.IsNull() {
+		resp.PlanValue = types.StringNull()
+		return
+	}
+
+	changed, diags := plannedContentChange(ctx, req.Config, req.Plan, req.State)
+	resp.Diagnostics.Append(diags...)
+	var snapshotBeforeUpdate types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("snapshot_before_update"), &snapshotBeforeUpdate)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !changed || snapshotBeforeUpdate.IsNull() || (!snapshotBeforeUpdate.IsUnknown() && !snapshotBeforeUpdate.ValueBool()) {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+// encryptionScopePlanModifier keeps the encryption scope the blob was written with, which is null
+// for the account default, while encryption_scope is not configured. UseStateForUnknown does
+// not keep a null scope, so the planned unknown would otherwise require replacement on every
+// update.
+type encryptionScopePlanModifier struct{}
+
+func (m encryptionScopePlanModifier) Description(ctx context.Context) string {
+	return "Keeps the encryption scope of the existing blob unless one is configured"
+}
+
+func (m encryptionScopePlanModifier) MarkdownDescription(ctx context.Context) string {
+	return "Keeps the encryption scope of the existing blob unless `encryption_scope` is configured"
+}
+
+func (m encryptionScopePlanModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	// Nothing to keep on create or destroy
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	if req.ConfigValue.IsNull() {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+// sourceMD5PlanModifier hashes the file named by source at plan time so that a changed file
+// replaces the blob, and reports a missing or unreadable file before apply
+type sourceMD5PlanModifier struct{}
+
+func (m sourceMD5PlanModifier) Description(ctx context.Context) string {
+	return "Computes the MD5 of the source file and requires replacement when it changes"
+}
+
+func (m sourceMD5PlanModifier) MarkdownDescription(ctx context.Context) string {
+	return "Computes the MD5 of the `source` file and requires replacement when it changes"
+}
+
+func (m sourceMD5PlanModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	// Nothing to do on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var source types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("source"), &source)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if source.IsNull() {
+		resp.PlanValue = types.StringNull()
+		return
+	}
+	if source.IsUnknown() {
+		resp.PlanValue = types.StringUnknown()
+		return
+	}
+
+	sum, err := blobclient.SourceFileMD5(source.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("source"),
+			"Invalid Source File",
+			fmt.Sprintf("Unable to read source file %s, got error: %s", source.ValueString(), err),
+		)
+		return
+	}
+
+	resp.PlanValue = types.StringValue(sum)
+	if !req.State.Raw.IsNull() && !req.StateValue.IsNull() && req.StateValue.ValueString() != sum {
+		resp.RequiresReplace = true
+	}
+}
+
+func NewBlobLeaseResource() resource.Resource {
+	return &BlobLeaseResource{now: time.Now}
+}
+
+// BlobLeaseResource defines the resource implementation.
+type BlobLeaseResource struct {
+	client *blobclient.AzureBlobLeaseClient
+	// now is the clock rotation_days is measured with
+	now func() time.Time
+}
+
+// BlobLeaseResourceModel describes the resource data model.
+type BlobLeaseResourceModel struct {
+	ID                    types.String `tfsdk:"id"`
+	StorageAccount        types.String `tfsdk:"storage_account_name"`
+	OldStorageAccount     types.String `tfsdk:"storage_account"`
+	BlobEndpoint          types.String `tfsdk:"blob_endpoint"`
+	ContainerName         types.String `tfsdk:"storage_container_name"`
+	OldContainerName      types.String `tfsdk:"container_name"`
+	BlobName              types.String `tfsdk:"name"`
+	OldBlobName           types.String `tfsdk:"blob_name"`
+	BlobNamePrefix        types.String `tfsdk:"blob_name_prefix"`
+	Content               types.String `tfsdk:"content"`
+	ContentFormat         types.String `tfsdk:"content_format"`
+	ContentCompression    types.String `tfsdk:"content_compression"`
+	AppendContent         types.List   `tfsdk:"append_content"`
+	WriteLockInfo         types.Bool   `tfsdk:"write_lock_info"`
+	LockInfo              types.Map    `tfsdk:"lock_info"`
+	LockInfoDocument      types.String `tfsdk:"lock_info_document"`
+	LeaseDuration         types.Int32  `tfsdk:"lease_duration"`
+	LeaseID               types.String `tfsdk:"lease_id"`
+	ExposeLeaseID         types.Bool   `tfsdk:"expose_lease_id"`
+	BlobURL               types.String `tfsdk:"blob_url"`
+	ETag                  types.String `tfsdk:"etag"`
+	LeaseExpiresAt        types.String `tfsdk:"lease_expires_at"`
+	RenewThreshold        types.Int32  `tfsdk:"renew_threshold_seconds"`
+	LeaseState            types.String `tfsdk:"lease_state"`
+	LeaseStatus           types.String `tfsdk:"lease_status"`
+	LeaseDurationKind     types.String `tfsdk:"lease_duration_kind"`
+	RotationDays          types.Int32  `tfsdk:"rotation_days"`
+	LeaseRotatedAt        types.String `tfsdk:"lease_rotated_at"`
+	LastModified          types.String `tfsdk:"last_modified"`
+	CreationTime          types.String `tfsdk:"creation_time"`
+	VersionID             types.String `tfsdk:"version_id"`
+	SnapshotBeforeDestroy types.Bool   `tfsdk:"snapshot_before_destroy"`
+	ArchiveOnDestroy      types.Bool   `tfsdk:"archive_on_destroy"`
+	SnapshotBeforeUpdate  types.Bool   `tfsdk:"snapshot_before_update"`
+	LastSnapshotID        types.String `tfsdk:"last_snapshot_id"`
+	Expiry                types.String `tfsdk:"expiry"`
+	ExpiryDays            types.Int32  `tfsdk:"expiry_days"`
+	ExpiresOn             types.String `tfsdk:"expires_on"`
+	AcquireExisting       types.Bool   `tfsdk:"acquire_existing"`
+	AdoptMatching         types.Bool   `tfsdk:"adopt_matching"`
+	RestoreIfSoftDeleted  types.Bool   `tfsdk:"restore_if_soft_deleted"`
+	DeletionProtection    types.Bool   `tfsdk:"deletion_protection"`
+	Overwrite             types.Bool   `tfsdk:"overwrite"`
+	UseETagPrecondition   types.Bool   `tfsdk:"use_etag_precondition"`
+	AcquireTimeout        types.String `tfsdk:"acquire_timeout"`
+	ForceBreak            types.Bool   `tfsdk:"force_break_existing_lease"`
+	StealIfOlderThan      types.String `tfsdk:"steal_if_older_than"`
+	CreateContainer       types.Bool   `tfsdk:"create_container"`
+	ContainerAccess       types.String `tfsdk:"container_access_type"`
+	Source                types.String `tfsdk:"source"`
+	CopySource            types.String `tfsdk:"copy_source"`
+	SourceMD5             types.String `tfsdk:"source_md5"`
+	ContentMD5            types.String `tfsdk:"content_md5"`
+	ContentLength         types.Int64  `tfsdk:"content_length"`
+	DetectDrift           types.Bool   `tfsdk:"detect_content_drift"`
+	CacheControl          types.String `tfsdk:"cache_control"`
+	ContentEncoding       types.String `tfsdk:"content_encoding"`
+	ContentLanguage       types.String `tfsdk:"content_language"`
+	ContentDisposition    types.String `tfsdk:"content_disposition"`
+	Metadata              types.Map    `tfsdk:"metadata"`
+	Owner                 types.String `tfsdk:"owner"`
+	TerraformMetadata     types.Bool   `tfsdk:"write_terraform_metadata"`
+	Tags                  types.Map    `tfsdk:"tags"`
+	ExtraHeaders          types.Map    `tfsdk:"extra_headers"`
+	AccessTier            types.String `tfsdk:"access_tier"`
+	EncryptionScope       types.String `tfsdk:"encryption_scope"`
+	LegalHold             types.Bool   `tfsdk:"legal_hold"`
+	ImmutabilityPolicy    types.Object `tfsdk:"immutability_policy"`
+	RotationTriggers      types.Map    `tfsdk:"rotation_triggers"`
+	Keepers               types.Map    `tfsdk:"keepers"`
+	Timeouts              types.Object `tfsdk:"timeouts"`
+	RenewOnRead           types.Bool   `tfsdk:"renew_on_read"`
+	VerifyOwnership       types.Bool   `tfsdk:"verify_ownership"`
+	LeaseActionOnDrift    types.String `tfsdk:"lease_action_on_drift"`
+	RenewDuringApply      types.Bool   `tfsdk:"renew_during_apply"`
+	BlobType              types.String `tfsdk:"blob_type"`
+	PageBlobSize          types.Int64  `tfsdk:"page_blob_size"`
+}
+
+func (r *BlobLeaseResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_blob_lease"
+}
+
+func (r *BlobLeaseResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Azure Blob Storage lease resource",
+		Version:             blobLeaseSchemaVersion,
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name. Exactly one of `storage_account_name` and the deprecated `storage_account` must be set",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					aliasPlanModifier{other: "storage_account"},
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"storage_account": schema.StringAttribute{
+				MarkdownDescription: "Deprecated alias of `storage_account_name`",
+				DeprecationMessage:  deprecationMessage("storage_account", "storage_account_name"),
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					aliasPlanModifier{other: "storage_account_name"},
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, e.g. a private endpoint or sovereign cloud URL, used instead of `https://<storage_account>.blob.core.windows.net/` for every request of this resource. http URLs are only accepted when the provider sets `allow_http_endpoints`. Changing it forces a new resource",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"storage_container_name": schema.StringAttribute{
+				MarkdownDescription: "The container name where the blob will be created. Exactly one of `storage_container_name` and the deprecated `container_name` must be set",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					aliasPlanModifier{other: "container_name"},
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"container_name": schema.StringAttribute{
+				MarkdownDescription: "Deprecated alias of `storage_container_name`",
+				DeprecationMessage:  deprecationMessage("container_name", "storage_container_name"),
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					aliasPlanModifier{other: "storage_container_name"},
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The name of the blob to create and lease. Exactly one of `name`, the deprecated `blob_name` and `blob_name_prefix` must be set; with `blob_name_prefix`, it holds the generated name",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					aliasPlanModifier{other: "blob_name"},
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.BlobName(),
+				},
+			},
+			"blob_name": schema.StringAttribute{
+				MarkdownDescription: "Deprecated alias of `name`",
+				DeprecationMessage:  deprecationMessage("blob_name", "name"),
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					aliasPlanModifier{other: "name"},
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.BlobName(),
+				},
+			},
+			"blob_name_prefix": schema.StringAttribute{
+				MarkdownDescription: "Creates a blob with a unique name starting with this prefix, followed by the creation time and a random suffix. Conflicts with `name`. Changing it forces a new resource",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.BlobNamePrefix(blobNameSuffixLength),
+				},
+			},
+			"blob_type": schema.StringAttribute{
+				MarkdownDescription: "The type of blob to create: `block`, `append` or `page`. Defaults to `block`",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(blobclient.BlobTypeBlock),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringOneOfValidator{values: []string{blobclient.BlobTypeBlock, blobclient.BlobTypeAppend, blobclient.BlobTypePage}},
+				},
+			},
+			"page_blob_size": schema.Int64Attribute{
+				MarkdownDescription: "The size in bytes of a page blob, a multiple of 512. Required when `blob_type` is `page`",
+				Optional:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"acquire_existing": schema.BoolAttribute{
+				MarkdownDescription: "Whether to lease an existing blob instead of creating it. No container is created and no content is written, and destroying the resource only releases the lease. Conflicts with `content` and `source`. Defaults to `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"adopt_matching": schema.BoolAttribute{
+				MarkdownDescription: "Whether create adopts an existing blob that already has exactly the content it would write, for example after a retried apply, instead of failing or overwriting it. The blob must not be leased, or be leased with the configured `lease_id`. Conflicts with `acquire_existing`. Defaults to `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"restore_if_soft_deleted": schema.BoolAttribute{
+				MarkdownDescription: "Whether create undeletes a soft-deleted blob of the same name instead of creating a new one, when no live blob exists. Content that differs from the configured content is overwritten, and the lease is then acquired. Without blob soft delete or list permission on the container, create proceeds as usual. Conflicts with `acquire_existing`. Defaults to `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"overwrite": schema.BoolAttribute{
+				MarkdownDescription: "Whether create may overwrite a blob that already exists at the target. When `false`, create fails if the blob exists. Defaults to `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"use_etag_precondition": schema.BoolAttribute{
+				MarkdownDescription: "Whether a content update is only applied while the blob still has the ETag recorded in state, so a blob written by someone else since the last refresh is never overwritten. Defaults to `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"acquire_timeout": schema.StringAttribute{
+				MarkdownDescription: "How long to wait for a lease held by someone else to be released before failing, e.g. `5m`. Unset or `0s` fails immediately",
+				Optional:            true,
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"steal_if_older_than": schema.StringAttribute{
+				MarkdownDescription: "Breaks a lease held by someone else without waiting when it was acquired at least this long ago, e.g. `24h`, so that locks left behind by crashed runs are taken over. The age comes from the `tf_acquired_at` metadata written with `write_terraform_metadata`, or else from the blob's last modification. Younger leases are waited for or fail as usual, and a lease held with this resource's lease ID is never broken",
+				Optional:            true,
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"force_break_existing_lease": schema.BoolAttribute{
+				MarkdownDescription: "Whether create breaks a lease held by someone else on the blob, after any `acquire_timeout` has expired, and then acquires its own. Defaults to `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"snapshot_before_destroy": schema.BoolAttribute{
+				MarkdownDescription: "Whether to snapshot the blob before destroying the resource. Defaults to `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"archive_on_destroy": schema.BoolAttribute{
+				MarkdownDescription: "Whether destroying the resource releases the lease and moves the blob to the Archive tier instead of deleting it, for blobs that must be retained. Only supported for block blobs in standard accounts. Defaults to `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"snapshot_before_update": schema.BoolAttribute{
+				MarkdownDescription: "Whether to snapshot the blob before its content is rewritten in place. Defaults to `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"create_container": schema.BoolAttribute{
+				MarkdownDescription: "Whether to create the container if it does not exist. Set to `false` when the principal has no container create permission; a missing container is then reported as an error. Defaults to `true`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"container_access_type": schema.StringAttribute{
+				MarkdownDescription: "The public access level (`private`, `blob` or `container`) of the container. Used when the provider creates the container; changing it updates the access level of the existing container",
+				Optional:            true,
+				Validators: []validator.String{
+					stringOneOfValidator{values: []string{blobclient.ContainerAccessPrivate, blobclient.ContainerAccessBlob, blobclient.ContainerAccessContainer}},
+				},
+			},
+			"content": schema.StringAttribute{
+				MarkdownDescription: "The content to write to the blob. Changing it rewrites the blob in place under the held lease. When unset, a default content is written on create, and the content in state is kept",
+				Optional:            true,
+				Computed:            true,
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					contentPlanModifier{},
+					jsonContentPlanModifier{},
+				},
+			},
+			"content_format": schema.StringAttribute{
+				MarkdownDescription: "How `content` is compared with the content in state: `text` (default) compares it exactly, `json` compares JSON values, so reordered keys, whitespace and number formatting do not cause a diff. With `json`, `content` must be valid JSON",
+				Optional:            true,
+				Validators: []validator.String{
+					stringOneOfValidator{values: []string{contentFormatText, contentFormatJSON}},
+				},
+			},
+			"content_compression": schema.StringAttribute{
+				MarkdownDescription: "How `content` is compressed before upload: `none` (default) or `gzip`, which also sets the Content-Encoding header to `gzip`. `content_md5` and drift detection are based on the compressed bytes stored. Changing it forces a new resource",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(contentCompressionNone),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringOneOfValidator{values: []string{contentCompressionNone, contentCompressionGzip}},
+				},
+			},
+			"append_content": schema.ListAttribute{
+				MarkdownDescription: "Records appended to an append blob after its `content`, in order. Records added to the end of the list are appended under the lease in place; removing or changing a record forces a new resource, since an append blob cannot be truncated. Requires `blob_type` `append`",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplaceIf(appendContentRequiresReplace, "Requires replacement unless records are only added to the end", "Requires replacement unless records are only added to the end"),
+				},
+			},
+			"write_lock_info": schema.BoolAttribute{
+				MarkdownDescription: "Whether to write a JSON lock document describing who holds the lease as the blob content, like the lock info Terraform writes for its own state locks. A new document is written each time the lease is acquired and when `lock_info` changes. Conflicts with `content`, `source` and `copy_source`. Defaults to `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"lock_info": schema.MapAttribute{
+				MarkdownDescription: "Additional entries, such as a run URL, for the `Info` of the lock document. Requires `write_lock_info`. Changes write a new document in place",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"lock_info_document": schema.StringAttribute{
+				MarkdownDescription: "The lock document last written with `write_lock_info`",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"source": schema.StringAttribute{
+				MarkdownDescription: "Path to a local file streamed as the blob content. The file content is never stored in state. Conflicts with `content`",
+				Optional:            true,
+			},
+			"copy_source": schema.StringAttribute{
+				MarkdownDescription: "The URL of a block blob, optionally with a SAS token, to create the blob from with a server-side copy instead of uploading content. Conflicts with `content`, `source` and `encryption_scope`. Changing it forces a new resource",
+				Optional:            true,
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					copySourceValidator{},
+				},
+			},
+			"source_md5": schema.StringAttribute{
+				MarkdownDescription: "The base64-encoded MD5 of the `source` file. A changed file replaces the blob",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					sourceMD5PlanModifier{},
+				},
+			},
+			"content_md5": schema.StringAttribute{
+				MarkdownDescription: "The base64-encoded MD5 of the blob content",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					contentWritePlanModifier{},
+				},
+			},
+			"content_length": schema.Int64Attribute{
+				MarkdownDescription: "The size of the blob content in bytes, as stored, so after any `content_compression`. Refreshed on every read",
+				Computed:            true,
+			},
+			"detect_content_drift": schema.BoolAttribute{
+				MarkdownDescription: "Whether refresh compares the blob's Content-MD5 with `content_md5` and plans to rewrite the blob when it was changed outside Terraform. Defaults to `true`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"cache_control": schema.StringAttribute{
+				MarkdownDescription: "The Cache-Control header of the blob",
+				Optional:            true,
+			},
+			"content_encoding": schema.StringAttribute{
+				MarkdownDescription: "The Content-Encoding header of the blob",
+				Optional:            true,
+			},
+			"content_language": schema.StringAttribute{
+				MarkdownDescription: "The Content-Language header of the blob",
+				Optional:            true,
+			},
+			"content_disposition": schema.StringAttribute{
+				MarkdownDescription: "The Content-Disposition header of the blob",
+				Optional:            true,
+			},
+			"metadata": schema.MapAttribute{
+				MarkdownDescription: "Metadata of the blob. Keys must be valid C# identifiers. Merged over the provider's `default_metadata`, winning per key. Changes are applied in place",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Validators: []validator.Map{
+					metadataValidator{},
+				},
+			},
+			"owner": schema.StringAttribute{
+				MarkdownDescription: "The team or pipeline holding the lease, written as `lease_owner` metadata so that someone blocked by the lease can find out who holds it. It wins over a `lease_owner` key in `metadata` or `default_metadata`. Changes are applied in place",
+				Optional:            true,
+				Validators: []validator.String{
+					metadataValueValidator{},
+				},
+			},
+			"write_terraform_metadata": schema.BoolAttribute{
+				MarkdownDescription: "Whether the blob metadata records the Terraform run that wrote it: `tf_workspace` and `tf_run_id` from the HCP Terraform or Terraform Enterprise run, or `TF_WORKSPACE`, `tf_provider_version` and the `tf_acquired_at` time of the lease. These keys never replace keys in `metadata` or `default_metadata`, and are not reported in `metadata`. Defaults to `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"tags": schema.MapAttribute{
+				MarkdownDescription: "Blob index tags. At most 10 tags; keys of 1-128 and values of up to 256 letters, digits, spaces or `+ - . / : = _`. Changes are applied in place",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Validators: []validator.Map{
+					tagsValidator{},
+				},
+			},
+			"extra_headers": schema.MapAttribute{
+				MarkdownDescription: "Additional headers sent with the requests that upload the content, on create and on content updates, for service features without an attribute of their own. Names must be `Content-Type` or `x-ms-*` headers that the provider does not set itself or manage through another attribute. Changes take effect the next time the content is written",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Validators: []validator.Map{
+					extraHeadersValidator{},
+				},
+			},
+			"access_tier": schema.StringAttribute{
+				MarkdownDescription: "The access tier of the blob: `Hot`, `Cool`, `Cold` or `Archive`. When unset, the tier chosen by the account default or lifecycle policies is reported but not managed. Changes are applied in place",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+				Validators: []validator.String{
+					stringOneOfValidator{values: []string{blobclient.AccessTierHot, blobclient.AccessTierCool, blobclient.AccessTierCold, blobclient.AccessTierArchive}},
+				},
+			},
+			"encryption_scope": schema.StringAttribute{
+				MarkdownDescription: "The name of the encryption scope to encrypt the content with. When unset, the container or account default is used and the scope the blob was written with is reported. Changing it forces a new resource, since the scope of an existing blob cannot change",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					encryptionScopePlanModifier{},
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"legal_hold": schema.BoolAttribute{
+				MarkdownDescription: "Whether the blob has a legal hold, which keeps it from being modified or deleted until the hold is cleared. When unset, the hold is reported but not managed. The container must have version-level immutability support enabled. Changes are applied in place",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"immutability_policy": immutabilityPolicyAttribute(),
+			"deletion_protection": schema.BoolAttribute{
+				MarkdownDescription: "Whether destroying the resource is refused. Set it to `false` and apply before destroying. Changes only update state. Defaults to `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"renew_on_read": schema.BoolAttribute{
+				MarkdownDescription: "Whether refresh renews (or re-acquires) the lease with the known lease ID, so that time-limited leases do not expire between applies. Defaults to `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"verify_ownership": schema.BoolAttribute{
+				MarkdownDescription: "Whether refresh and plan check that a leased blob is still leased with `lease_id`, which costs a Renew Lease request per resource. A lease broken and taken by someone else is then recorded as lease state `leased-by-other`. Defaults to `true`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"renew_during_apply": schema.BoolAttribute{
+				MarkdownDescription: "Whether create and update keep renewing a time-limited lease in the background, at half of `lease_duration`, until the provider exits at the end of the run, so the lease does not lapse while other resources are still being applied. Failed renewals are logged as warnings, and a lease broken or taken over in the meantime is not acquired again. Has no effect on infinite leases. Defaults to `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"lease_action_on_drift": schema.StringAttribute{
+				MarkdownDescription: "What an apply does when refresh found the lease no longer held: `reacquire` renews or acquires the lease again without rewriting the blob, `fail` fails the apply with an error, and `ignore` keeps the observed lease state in state without any request that changes the blob or lease. Defaults to `reacquire`",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(leaseActionOnDriftReacquire),
+				Validators: []validator.String{
+					stringOneOfValidator{values: []string{leaseActionOnDriftReacquire, leaseActionOnDriftFail, leaseActionOnDriftIgnore}},
+				},
+			},
+			"rotation_triggers": schema.MapAttribute{
+				MarkdownDescription: "Arbitrary values that rotate the lease ID to a new random UUID with Change Lease when any of them changes, without releasing the lease or touching the blob",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"rotation_days": schema.Int32Attribute{
+				MarkdownDescription: "Rotate the lease ID to a new random UUID with Change Lease once it is this many days old, without releasing the lease or touching the blob. The rotation is planned by the first plan after the period has elapsed",
+				Optional:            true,
+				Validators: []validator.Int32{
+					positiveInt32Validator{},
+				},
+			},
+			"lease_rotated_at": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time at which the current lease ID was set, the start of the `rotation_days` period",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"keepers": schema.MapAttribute{
+				MarkdownDescription: "Arbitrary values that replace the resource, releasing the lease and acquiring a new one, when any of them changes",
+				ElementType:         types.StringType,
+				Optional:            true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplaceIf(keepersRequireReplace, "Requires replacement when any keeper changes", "Requires replacement when any keeper changes"),
+				},
+			},
+			"timeouts": timeoutsAttribute(),
+			"lease_duration": schema.Int32Attribute{
+				MarkdownDescription: "The lease duration in seconds. Use -1 for infinite lease (default), or 15-60 for time-limited lease. Changing it re-acquires the held lease with the new duration",
+				Optional:            true,
+				Computed:            true,
+				Default:             int32default.StaticInt32(-1),
+				Validators: []validator.Int32{
+					leaseDurationValidator{},
+				},
+			},
+			"lease_id": schema.StringAttribute{
+				MarkdownDescription: "The lease ID for the blob. Set it to a UUID to use a pre-agreed proposed lease ID; otherwise one is generated. Changing it changes the ID of the held lease in place",
+				Optional:            true,
+				Computed:            true,
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					leaseIDPlanModifier{now: r.now},
+				},
+				Validators: []validator.String{
+					uuidValidator{},
+				},
+			},
+			"expose_lease_id": schema.BoolAttribute{
+				MarkdownDescription: "Whether a generated lease ID is stored in the `lease_id` attribute, where other resources can reference it. Otherwise it is kept in the private state of the resource and `lease_id` is null. A configured `lease_id` is always stored in the attribute. Defaults to `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"blob_url": schema.StringAttribute{
+				MarkdownDescription: "The URL of the blob",
+				Computed:            true,
+			},
+			"etag": schema.StringAttribute{
+				MarkdownDescription: "The ETag of the blob",
+				Computed:            true,
+			},
+			"lease_expires_at": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time at which a time-limited lease lapses unless renewed, tracked from the last acquisition or renewal by the provider. Null for infinite leases",
+				Computed:            true,
+			},
+			"renew_threshold_seconds": schema.Int32Attribute{
+				MarkdownDescription: "Renew a time-limited lease during apply when plan finds it lapses within this many seconds. Has no effect on infinite leases",
+				Optional:            true,
+				Validators: []validator.Int32{
+					positiveInt32Validator{},
+				},
+			},
+			"lease_state": schema.StringAttribute{
+				MarkdownDescription: "The current lease state of the blob, or `leased-by-other` when it is leased with a different lease ID",
+				Computed:            true,
+			},
+			"lease_status": schema.StringAttribute{
+				MarkdownDescription: "The current lease status of the blob, `locked` or `unlocked`",
+				Computed:            true,
+			},
+			"lease_duration_kind": schema.StringAttribute{
+				MarkdownDescription: "Whether the current lease on the blob is `fixed` or `infinite`, empty when the blob is not leased",
+				Computed:            true,
+			},
+			"last_modified": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time at which the blob was last written",
+				Computed:            true,
+			},
+			"creation_time": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time at which the blob was created",
+				Computed:            true,
+				// Rewriting the blob in place keeps its creation time
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"version_id": schema.StringAttribute{
+				MarkdownDescription: "The ID of the blob version written by the provider, empty when blob versioning is disabled",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					contentWritePlanModifier{},
+				},
+			},
+			"last_snapshot_id": schema.StringAttribute{
+				MarkdownDescription: "The ID of the last snapshot taken because of `snapshot_before_update`",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					lastSnapshotPlanModifier{},
+				},
+			},
+			"expiry": schema.StringAttribute{
+				MarkdownDescription: "RFC3339 timestamp at which Azure deletes the blob. Requires a storage account with hierarchical namespace enabled. Conflicts with `expiry_days`",
+				Optional:            true,
+				Validators: []validator.String{
+					rfc3339Validator{},
+				},
+			},
+			"expiry_days": schema.Int32Attribute{
+				MarkdownDescription: "Number of days after blob creation at which Azure deletes the blob. Requires a storage account with hierarchical namespace enabled. Conflicts with `expiry`",
+				Optional:            true,
+				Validators: []validator.Int32{
+					positiveInt32Validator{},
+				},
+			},
+			"expires_on": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time at which Azure will delete the blob, if an expiry is set",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					expiresOnPlanModifier{},
+				},
+			},
+		},
+	}
+}
+
+func (r *BlobLeaseResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data BlobLeaseResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The deprecated names are aliases, so only one spelling of each can be set
+	for _, renamed := range []struct {
+		name, deprecated   string
+		set, deprecatedSet bool
+	}{
+		{"storage_account_name", "storage_account", !data.StorageAccount.IsNull(), !data.OldStorageAccount.IsNull()},
+		{"storage_container_name", "container_name", !data.ContainerName.IsNull(), !data.OldContainerName.IsNull()},
+		{"name", "blob_name", !data.BlobName.IsNull(), !data.OldBlobName.IsNull()},
+	} {
+		switch {
+		case renamed.set && renamed.deprecatedSet:
+			resp.Diagnostics.AddAttributeError(
+				path.Root(renamed.deprecated),
+				"Conflicting Attributes",
+				fmt.Sprintf("Only one of %s and its deprecated alias %s can be set.", renamed.name, renamed.deprecated),
+			)
+		case !renamed.set && !renamed.deprecatedSet && renamed.name != "name":
+			resp.Diagnostics.AddAttributeError(
+				path.Root(renamed.name),
+				"Missing Attribute",
+				fmt.Sprintf("One of %s and its deprecated alias %s must be set.", renamed.name, renamed.deprecated),
+			)
+		}
+	}
+
+	named := !data.BlobName.IsNull() || !data.OldBlobName.IsNull()
+	switch {
+	case named && !data.BlobNamePrefix.IsNull():
+		resp.Diagnostics.AddAttributeError(
+			path.Root("blob_name_prefix"),
+			"Conflicting Attributes",
+			"Only one of name and blob_name_prefix can be set.",
+		)
+	case !named && data.BlobNamePrefix.IsNull():
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Missing Attribute",
+			"One of name and blob_name_prefix must be set.",
+		)
+	}
+
+	if !data.Source.IsNull() && !data.Content.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("source"),
+			"Conflicting Attributes",
+			"Only one of source and content can be set.",
+		)
+	}
+
+	if !data.CopySource.IsNull() {
+		for _, conflict := range []struct {
+			name string
+			set  bool
+		}{
+			{"content", !data.Content.IsNull()},
+			{"source", !data.Source.IsNull()},
+			{"encryption_scope", !data.EncryptionScope.IsNull()},
+		} {
+			if conflict.set {
+				resp.Diagnostics.AddAttributeError(
+					path.Root(conflict.name),
+					"Conflicting Attributes",
+					fmt.Sprintf("%s cannot be set together with copy_source; the content is copied from the source blob.", conflict.name),
+				)
+			}
+		}
+		if !data.BlobType.IsNull() && !data.BlobType.IsUnknown() && data.BlobType.ValueString() != blobclient.BlobTypeBlock {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("copy_source"),
+				"Invalid Attribute Combination",
+				"copy_source is only supported for block blobs.",
+			)
+		}
+	}
+
+	if data.WriteLockInfo.ValueBool() {
+		for _, conflict := range []struct {
+			name string
+			set  bool
+		}{
+			{"content", !data.Content.IsNull()},
+			{"source", !data.Source.IsNull()},
+			{"copy_source", !data.CopySource.IsNull()},
+		} {
+			if conflict.set {
+				resp.Diagnostics.AddAttributeError(
+					path.Root(conflict.name),
+					"Conflicting Attributes",
+					fmt.Sprintf("%s cannot be set when write_lock_info is true; the content is the generated lock document.", conflict.name),
+				)
+			}
+		}
+		if data.BlobType.ValueString() == blobclient.BlobTypePage {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("write_lock_info"),
+				"Invalid Attribute Combination",
+				"write_lock_info is only supported for block and append blobs.",
+			)
+		}
+	} else if !data.LockInfo.IsNull() && !data.WriteLockInfo.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("lock_info"),
+			"Invalid Attribute Combination",
+			"lock_info can only be set when write_lock_info is true.",
+		)
+	}
+
+	if !data.RotationDays.IsNull() && !data.LeaseID.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rotation_days"),
+			"Conflicting Attributes",
+			"Only one of rotation_days and lease_id can be set; rotation_days rotates to random lease IDs.",
+		)
+	}
+
+	if data.ContentFormat.ValueString() == contentFormatJSON && !data.Content.IsNull() && !data.Content.IsUnknown() && !json.Valid([]byte(data.Content.ValueString())) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("content"),
+			"Invalid JSON Content",
+			"content_format is json, but content is not valid JSON.",
+		)
+	}
+
+	if data.ContentCompression.ValueString() == contentCompressionGzip {
+		for _, conflict := range []struct {
+			name string
+			set  bool
+		}{
+			{"content_encoding", !data.ContentEncoding.IsNull()},
+			{"source", !data.Source.IsNull()},
+			{"copy_source", !data.CopySource.IsNull()},
+			{"append_content", !data.AppendContent.IsNull()},
+			{"acquire_existing", data.AcquireExisting.ValueBool()},
+		} {
+			if conflict.set {
+				resp.Diagnostics.AddAttributeError(
+					path.Root(conflict.name),
+					"Conflicting Attributes",
+					fmt.Sprintf("%s cannot be set when content_compression is gzip; only content written by the provider is compressed.", conflict.name),
+				)
+			}
+		}
+		if data.BlobType.ValueString() == blobclient.BlobTypePage {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("content_compression"),
+				"Invalid Attribute Combination",
+				"content_compression is only supported for block and append blobs.",
+			)
+		}
+	}
+
+	acquireExisting := data.AcquireExisting.ValueBool()
+	if acquireExisting {
+		conflicts := []struct {
+			name string
+			set  bool
+		}{
+			{"content", !data.Content.IsNull()},
+			{"source", !data.Source.IsNull()},
+			{"copy_source", !data.CopySource.IsNull()},
+			{"container_access_type", !data.ContainerAccess.IsNull()},
+			{"archive_on_destroy", data.ArchiveOnDestroy.ValueBool()},
+			{"write_lock_info", data.WriteLockInfo.ValueBool()},
+			{"adopt_matching", data.AdoptMatching.ValueBool()},
+			{"restore_if_soft_deleted", data.RestoreIfSoftDeleted.ValueBool()},
+			{"append_content", !data.AppendContent.IsNull()},
+		}
+		for _, conflict := range conflicts {
+			if conflict.set {
+				resp.Diagnostics.AddAttributeError(
+					path.Root(conflict.name),
+					"Conflicting Attributes",
+					fmt.Sprintf("%s cannot be set when acquire_existing is true; the existing blob and its container are leased as they are.", conflict.name),
+				)
+			}
+		}
+	}
+
+	if data.BlobType.ValueString() == blobclient.BlobTypePage {
+		switch {
+		case data.PageBlobSize.IsNull() && !acquireExisting:
+			resp.Diagnostics.AddAttributeError(
+				path.Root("page_blob_size"),
+				"Missing Page Blob Size",
+				"page_blob_size must be set when blob_type is page.",
+			)
+		case !data.PageBlobSize.IsUnknown() && (data.PageBlobSize.ValueInt64() <= 0 || data.PageBlobSize.ValueInt64()%blobclient.PageBlobAlignment != 0):
+			resp.Diagnostics.AddAttributeError(
+				path.Root("page_blob_size"),
+				"Invalid Page Blob Size",
+				fmt.Sprintf("page_blob_size must be a positive multiple of %d bytes, got: %d", blobclient.PageBlobAlignment, data.PageBlobSize.ValueInt64()),
+			)
+		}
+		if (!data.Content.IsNull() || !data.Source.IsNull()) && !acquireExisting {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("blob_type"),
+				"Unsupported Page Blob Content",
+				"content and source are only supported for block and append blobs; page blobs are created empty.",
+			)
+		}
+	} else if !data.BlobType.IsUnknown() && !data.PageBlobSize.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("page_blob_size"),
+			"Invalid Attribute Combination",
+			"page_blob_size can only be set when blob_type is page.",
+		)
+	}
+
+	if !data.AccessTier.IsNull() && !data.BlobType.IsNull() && !data.BlobType.IsUnknown() && data.BlobType.ValueString() != blobclient.BlobTypeBlock {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("access_tier"),
+			"Invalid Attribute Combination",
+			"access_tier is only supported for block blobs.",
+		)
+	}
+
+	if !data.AppendContent.IsNull() && !data.BlobType.IsUnknown() && data.BlobType.ValueString() != blobclient.BlobTypeAppend {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("append_content"),
+			"Invalid Attribute Combination",
+			"append_content is only supported when blob_type is append.",
+		)
+	}
+
+	if data.ArchiveOnDestroy.ValueBool() && !data.BlobType.IsNull() && !data.BlobType.IsUnknown() && data.BlobType.ValueString() != blobclient.BlobTypeBlock {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("archive_on_destroy"),
+			"Invalid Attribute Combination",
+			"archive_on_destroy is only supported for block blobs.",
+		)
+	}
+
+	if !data.Expiry.IsNull() && !data.ExpiryDays.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("expiry_days"),
+			"Conflicting Attributes",
+			"Only one of expiry and expiry_days can be set.",
+		)
+	}
+
+	// A threshold of the full duration would renew the lease on every plan
+	if !data.RenewThreshold.IsNull() && !data.RenewThreshold.IsUnknown() && !data.LeaseDuration.IsUnknown() &&
+		data.LeaseDuration.ValueInt32() > 0 && data.RenewThreshold.ValueInt32() >= data.LeaseDuration.ValueInt32() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("renew_threshold_seconds"),
+			"Invalid Renew Threshold",
+			fmt.Sprintf("renew_threshold_seconds must be less than lease_duration (%d), got: %d", data.LeaseDuration.ValueInt32(), data.RenewThreshold.ValueInt32()),
+		)
+	}
+}
+
+// ModifyPlan plans an update that renews or re-acquires the lease when refresh shows it is no
+// longer held by this resource, or when it lapses within renew_threshold_seconds. A lease that
+// was lost is reported with a warning saying what the apply will do.
+func (r *BlobLeaseResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	// Whether an http endpoint is allowed is only known once the provider is configured
+	var endpoint types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("blob_endpoint"), &endpoint)...)
+	if r.client != nil && !endpoint.IsNull() && !endpoint.IsUnknown() {
+		if err := r.client.CheckBlobEndpoint(endpoint.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx = blobclient.WithBlobEndpoint(ctx, endpoint.ValueString())
+
+	// Two resources managing the same blob would take the lease from each other
+	claimBlobTarget(ctx, r.client, req, resp)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Nothing else to check on create
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var plan, state BlobLeaseResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The keepers plan modifier replaces the resource; naming the changed keys makes Terraform
+	// show which keeper forces the replacement
+	for _, key := range changedKeepers(state.Keepers, plan.Keepers) {
+		resp.RequiresReplace.Append(path.Root("keepers").AtMapKey(key))
+	}
+
+	// When plan modifiers kept the prior value of every changed attribute, for example content
+	// that is equal JSON, only computed attributes are left unknown and there is nothing to apply.
+	// A due rotation leaves only lease_id unknown, but has to be applied.
+	rotate := plan.LeaseID.IsUnknown() && rotationDue(state.LeaseRotatedAt, plan.RotationDays, r.now())
+	if !rotate && !req.Plan.Raw.Equal(req.State.Raw) && computedUnknownsOnly(req.Plan, req.State, req.Config) {
+		resp.Plan.Raw = req.State.Raw.Copy()
+		resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// A lease ID kept in private state is planned like one in state
+	if state.LeaseID.IsNull() {
+		resp.Diagnostics.Append(resolveLeaseID(ctx, &state, req.Private)...)
+		if plan.LeaseID.IsNull() {
+			plan.LeaseID = state.LeaseID
+		}
+	}
+
+	// A new lease ID, rotated or configured, restarts the rotation period
+	if plan.LeaseID.IsUnknown() || !plan.LeaseID.Equal(state.LeaseID) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("lease_rotated_at"), types.StringUnknown())...)
+	}
+
+	// The apply moves a hidden lease ID into private state, which also migrates a state that
+	// still has it, and stores an exposed one in lease_id
+	hidden, diags := leaseIDHidden(ctx, req.Config, plan)
+	resp.Diagnostics.Append(diags...)
+	switch {
+	case plan.LeaseID.IsUnknown():
+	case plan.ExposeLeaseID.IsUnknown():
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("lease_id"), types.StringUnknown())...)
+	case hidden:
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("lease_id"), types.StringNull())...)
+	default:
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("lease_id"), plan.LeaseID)...)
+	}
+
+	// Refresh records a lease taken over by someone else when verify_ownership is set
+	leaseState := state.LeaseState.ValueString()
+	heldElsewhere := leaseState == leaseStateLeasedByOther
+	lost := heldElsewhere || (!state.LeaseState.IsNull() && leaseState != "leased")
+
+	blobPath := fmt.Sprintf("%s/%s/%s", state.StorageAccount.ValueString(), state.ContainerName.ValueString(), state.BlobName.ValueString())
+	situation := fmt.Sprintf("The lease on blob %s is no longer held by this resource (observed lease state: %s).", blobPath, leaseState)
+	switch leaseState {
+	case leaseStateLeasedByOther:
+		situation = fmt.Sprintf("Blob %s is leased by someone else with a different lease ID (observed lease state: %s).", blobPath, leaseState)
+	case string(lease.StateTypeBreaking):
+		situation = fmt.Sprintf("The lease on blob %s is being broken (observed lease state: %s). The apply waits for the break to finish before acquiring a new lease.", blobPath, leaseState)
+	}
+
+	// With lease_action_on_drift ignore, the observed lease state is kept, so a lost lease plans
+	// no change of its own
+	ignored := lost && plan.LeaseActionOnDrift.ValueString() == leaseActionOnDriftIgnore
+
+	// The lock document is generated per acquisition, so it only changes when the lease is
+	// acquired again or its inputs change
+	if plan.WriteLockInfo.ValueBool() && lockInfoDue(plan, state, lost && !ignored) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("lock_info_document"), types.StringUnknown())...)
+	} else if !plan.WriteLockInfo.ValueBool() && !plan.LockInfoDocument.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("lock_info_document"), types.StringNull())...)
+	}
+	if ignored {
+		resp.Diagnostics.AddWarning("Lease Not Held", situation+" lease_action_on_drift is ignore, so the apply does not re-acquire it.")
+		return
+	}
+	if !lost && !renewalDue(state.LeaseExpiresAt, plan.RenewThreshold, r.now()) {
+		return
+	}
+
+	// The apply renews or re-acquires the lease, which changes these attributes
+	for _, name := range []string{"lease_state", "etag", "lease_expires_at"} {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(name), types.StringUnknown())...)
+	}
+	if !lost {
+		return
+	}
+	// A re-acquired lease is locked again and has the lease_duration of this resource, not the
+	// duration observed, and a new lease ID unless one is configured
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("lease_status"), types.StringUnknown())...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("lease_duration_kind"), types.StringUnknown())...)
+	if plan.LeaseID.Equal(state.LeaseID) {
+		var configured types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("lease_id"), &configured)...)
+		if configured.IsNull() {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("lease_id"), types.StringUnknown())...)
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("lease_rotated_at"), types.StringUnknown())...)
+		}
+	}
+
+	resp.Diagnostics.AddWarning("Lease Not Held", situation+" "+lostLeaseAction(plan, state, heldElsewhere))
+}
+
+// lostLeaseAction describes what Update does about a lease that is no longer held, which
+// depends on lease_action_on_drift, whether the content changes and whether someone else holds it
+func lostLeaseAction(plan, state BlobLeaseResourceModel, heldElsewhere bool) string {
+	wait := "fails immediately"
+	if acquireTimeout(plan) > 0 {
+		wait = fmt.Sprintf("fails after waiting up to %s for it to be released", plan.AcquireTimeout.ValueString())
+	}
+	noBreak := ""
+	if plan.ForceBreak.ValueBool() {
+		noBreak = " force_break_existing_lease only applies on create, so the other lease is not broken."
+	}
+
+	switch {
+	case plan.LeaseActionOnDrift.ValueString() == leaseActionOnDriftFail:
+		return "lease_action_on_drift is fail, so the apply will fail with a \"Lease Lost Outside Terraform\" error."
+	case !heldElsewhere:
+		return "The apply will renew the lease with the same lease ID, or acquire a new one without rewriting the blob."
+	case !plan.Content.Equal(state.Content) && !plan.AcquireExisting.ValueBool():
+		return "The apply will fail with a \"Lease No Longer Held\" error instead of rewriting the blob."
+	default:
+		return fmt.Sprintf("The apply will try to acquire a new lease without rewriting the blob, and %s while the other lease is held.%s", wait, noBreak)
+	}
+}
+
+// blobExpiry builds the client expiry from the model, returning nil when no expiry is configured
+func blobExpiry(data BlobLeaseResourceModel) *blobclient.BlobExpiry {
+	if !data.Expiry.IsNull() && !data.Expiry.IsUnknown() {
+		// The value was validated as RFC3339 at plan time
+		at, _ := time.Parse(time.RFC3339, data.Expiry.ValueString())
+		return &blobclient.BlobExpiry{At: at}
+	}
+	if !data.ExpiryDays.IsNull() && !data.ExpiryDays.IsUnknown() {
+		return &blobclient.BlobExpiry{Days: data.ExpiryDays.ValueInt32()}
+	}
+	return nil
+}
+
+// Values of content_compression
+const (
+	contentCompressionNone = "none"
+	contentCompressionGzip = "gzip"
+)
+
+// blobContent returns the bytes written for content, compressed as content_compression says
+func blobContent(data BlobLeaseResourceModel, content string) ([]byte, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if data.ContentCompression.ValueString() != contentCompressionGzip {
+		return []byte(content), diags
+	}
+
+	compressed, err := blobclient.GzipContent([]byte(content))
+	if err != nil {
+		diags.AddAttributeError(path.Root("content_compression"), "Compression Error", err.Error())
+		return nil, diags
+	}
+	return compressed, diags
+}
+
+// blobHeaders builds the managed HTTP headers from the model. Gzip compressed content is
+// written with its Content-Encoding.
+func blobHeaders(data BlobLeaseResourceModel) blobclient.BlobHTTPHeaders {
+	headers := blobclient.BlobHTTPHeaders{
+		CacheControl:       data.CacheControl.ValueString(),
+		ContentEncoding:    data.ContentEncoding.ValueString(),
+		ContentLanguage:    data.ContentLanguage.ValueString(),
+		ContentDisposition: data.ContentDisposition.ValueString(),
+	}
+	if data.ContentCompression.ValueString() == contentCompressionGzip {
+		headers.ContentEncoding = blobclient.GzipEncoding
+	}
+	return headers
+}
+
+// refreshHeaders updates the header attributes from the blob properties; unset headers are null.
+// The Content-Encoding set by content_compression is not reported as content_encoding.
+func refreshHeaders(data *BlobLeaseResourceModel, headers blobclient.BlobHTTPHeaders) {
+	data.CacheControl = stringOrNull(headers.CacheControl)
+	data.ContentEncoding = stringOrNull(headers.ContentEncoding)
+	if data.ContentCompression.ValueString() == contentCompressionGzip && headers.ContentEncoding == blobclient.GzipEncoding {
+		data.ContentEncoding = types.StringNull()
+	}
+	data.ContentLanguage = stringOrNull(headers.ContentLanguage)
+	data.ContentDisposition = stringOrNull(headers.ContentDisposition)
+}
+
+// stringOrNull converts an empty string reported by the service to null
+func stringOrNull(value string) types.String {
+	if value == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(value)
+}
+
+// blobMetadata returns the configured metadata of the model
+func blobMetadata(ctx context.Context, data BlobLeaseResourceModel) (map[string]string, diag.Diagnostics) {
+	var metadata map[string]string
+	if data.Metadata.IsNull() || data.Metadata.IsUnknown() {
+		return metadata, nil
+	}
+	diags := data.Metadata.ElementsAs(ctx, &metadata, false)
+	return metadata, diags
+}
+
+// refreshMetadata updates metadata from the blob so out-of-band edits show as drift. Keys
+// matching a provider default are left out unless the resource manages them, and keys keep
+// the spelling used in state since Azure may return them in a different case. The owner key
+// is left out while owner is set, and the Terraform metadata keys while write_terraform_metadata
+// is, unless metadata sets them.
+func refreshMetadata(ctx context.Context, data *BlobLeaseResourceModel, actual, defaults map[string]string) diag.Diagnostics {
+	prior, diags := blobMetadata(ctx, *data)
+	if diags.HasError() {
+		return diags
+	}
+
+	managed := map[string]string{}
+	for key, value := range actual {
+		if !data.Owner.IsNull() && strings.EqualFold(key, blobclient.LeaseOwnerMetadataKey) {
+			continue
+		}
+		if priorKey, ok := lookupFold(prior, key); ok {
+			managed[priorKey] = value
+			continue
+		}
+		if data.TerraformMetadata.ValueBool() && isTerraformMetadataKey(key) {
+			continue
+		}
+		if defaultKey, ok := lookupFold(defaults, key); ok && defaults[defaultKey] == value {
+			continue
+		}
+		managed[key] = value
+	}
+
+	if len(managed) == 0 && data.Metadata.IsNull() {
+		return diags
+	}
+	data.Metadata, diags = types.MapValueFrom(ctx, types.StringType, managed)
+	return diags
+}
+
+// refreshOwner updates a configured owner from the metadata of the blob, so that an owner
+// changed or removed outside Terraform shows as drift
+func refreshOwner(data *BlobLeaseResourceModel, actual map[string]string) {
+	if data.Owner.IsNull() {
+		return
+	}
+	data.Owner = types.StringNull()
+	if key, ok := lookupFold(actual, blobclient.LeaseOwnerMetadataKey); ok {
+		data.Owner = stringOrNull(actual[key])
+	}
 }
 
-func (m leaseStatePlanModifier) MarkdownDescription(ctx context.Context) string {
-	return "Checks lease state and triggers update if lease needs renewal"
+// blobTags returns the configured index tags of the model
+func blobTags(ctx context.Context, data BlobLeaseResourceModel) (map[string]string, diag.Diagnostics) {
+	var tags map[string]string
+	if data.Tags.IsNull() || data.Tags.IsUnknown() {
+		return tags, nil
+	}
+	diags := data.Tags.ElementsAs(ctx, &tags, false)
+	return tags, diags
+}
+
+// extraHeaders returns the configured extra upload headers of the model
+func extraHeaders(ctx context.Context, data BlobLeaseResourceModel) (map[string]string, diag.Diagnostics) {
+	var headers map[string]string
+	if data.ExtraHeaders.IsNull() || data.ExtraHeaders.IsUnknown() {
+		return headers, nil
+	}
+	diags := data.ExtraHeaders.ElementsAs(ctx, &headers, false)
+	return headers, diags
+}
+
+// refreshTags reads the index tags of the blob into the model so out-of-band edits show as drift
+func (r *BlobLeaseResource) refreshTags(ctx context.Context, data *BlobLeaseResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	tags, err := r.client.GetBlobTags(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString())
+	if err != nil {
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read blob tags, got error: %s", err))
+		return diags
+	}
+
+	if len(tags) == 0 && data.Tags.IsNull() {
+		return diags
+	}
+	data.Tags, diags = types.MapValueFrom(ctx, types.StringType, tags)
+	return diags
+}
+
+// lookupFold finds the key of m equal to key ignoring case
+func lookupFold(m map[string]string, key string) (string, bool) {
+	for existing := range m {
+		if strings.EqualFold(existing, key) {
+			return existing, true
+		}
+	}
+	return "", false
+}
+
+// containerAccessValue maps the public access reported for a container to container_access_type
+func containerAccessValue(publicAccess string) string {
+	if publicAccess == "" {
+		return blobclient.ContainerAccessPrivate
+	}
+	return publicAccess
+}
+
+// stolenLeaseWarning records that steal_if_older_than broke a stale lease held by someone else
+func stolenLeaseWarning(config blobclient.BlobLeaseConfig, result *blobclient.BlobLeaseResult) diag.Diagnostic {
+	return diag.NewAttributeWarningDiagnostic(
+		path.Root("steal_if_older_than"),
+		"Stale Lease Broken",
+		fmt.Sprintf("Blob %s/%s/%s was leased by another holder (%s) for longer than steal_if_older_than. The lease was broken and a new lease was acquired.",
+			config.StorageAccount, config.ContainerName, config.BlobName, result.StolenFrom),
+	)
+}
+
+// leaseBreakWarnings reports a lease held by someone else that was broken to acquire result
+func leaseBreakWarnings(config blobclient.BlobLeaseConfig, result *blobclient.BlobLeaseResult) diag.Diagnostics {
+	var diags diag.Diagnostics
+	switch {
+	case result.StolenFrom != "":
+		diags.Append(stolenLeaseWarning(config, result))
+	case result.BrokeLease:
+		diags.Append(brokenLeaseWarning(config))
+	}
+	return diags
+}
+
+// brokenLeaseWarning records that force_break_existing_lease broke a lease held by someone else
+func brokenLeaseWarning(config blobclient.BlobLeaseConfig) diag.Diagnostic {
+	return diag.NewAttributeWarningDiagnostic(
+		path.Root("force_break_existing_lease"),
+		"Existing Lease Broken",
+		fmt.Sprintf("Blob %s/%s/%s was leased by another holder. The lease was broken because force_break_existing_lease is true, and a new lease was acquired.",
+			config.StorageAccount, config.ContainerName, config.BlobName),
+	)
+}
+
+// leaseStatusValue returns the lease status of a result, deriving it from the lease state when
+// the result was not read from the blob properties
+func leaseStatusValue(result *blobclient.BlobLeaseResult) types.String {
+	if result.LeaseStatus != "" {
+		return types.StringValue(result.LeaseStatus)
+	}
+	switch result.LeaseState {
+	case string(lease.StateTypeLeased), string(lease.StateTypeBreaking):
+		return types.StringValue(string(lease.StatusTypeLocked))
+	default:
+		return types.StringValue(string(lease.StatusTypeUnlocked))
+	}
+}
+
+// copySourceDiags reports a failed copy from copy_source on the attribute, with a summary that
+// tells a missing source from one that cannot be read. It is empty for other errors.
+func copySourceDiags(err error) diag.Diagnostics {
+	var diags diag.Diagnostics
+	switch {
+	case errors.Is(err, blobclient.ErrCopySourceNotFound):
+		diags.AddAttributeError(path.Root("copy_source"), "Copy Source Not Found", err.Error())
+	case errors.Is(err, blobclient.ErrCopySourceDenied):
+		diags.AddAttributeError(path.Root("copy_source"), "Copy Source Access Denied", err.Error())
+	case errors.Is(err, blobclient.ErrCopyFailed):
+		diags.AddAttributeError(path.Root("copy_source"), "Copy Failed", err.Error())
+	}
+	return diags
+}
+
+// leaseDurationKindValue returns whether the lease of a result is fixed or infinite, empty when the
+// blob is not leased. When the result was not read from the blob properties, it is derived from
+// the lease_duration the lease was acquired with.
+func leaseDurationKindValue(result *blobclient.BlobLeaseResult, duration types.Int32) types.String {
+	if result.LeaseDuration != "" {
+		return types.StringValue(result.LeaseDuration)
+	}
+	if result.LeaseStatus != "" || result.LeaseState != string(lease.StateTypeLeased) {
+		return types.StringValue("")
+	}
+	if duration.ValueInt32() == -1 {
+		return types.StringValue(string(lease.DurationTypeInfinite))
+	}
+	return types.StringValue(string(lease.DurationTypeFixed))
+}
+
+// acquireTimeout returns the configured acquire_timeout, zero when unset
+func acquireTimeout(data BlobLeaseResourceModel) time.Duration {
+	// The value was validated as a duration at plan time
+	timeout, _ := time.ParseDuration(data.AcquireTimeout.ValueString())
+	return timeout
+}
+
+// stealAge returns the age from which a lease held by someone else is stolen, zero when unset
+func stealAge(data BlobLeaseResourceModel) time.Duration {
+	// The value was validated as a duration at plan time
+	age, _ := time.ParseDuration(data.StealIfOlderThan.ValueString())
+	return age
+}
+
+// setPartialState saves data, as left by an apply that failed after it created or leased the
+// blob, in state. Attributes that are still unknown are saved as null, since state cannot hold
+// unknown values.
+func setPartialState(ctx context.Context, state *tfsdk.State, data BlobLeaseResourceModel) diag.Diagnostics {
+	diags := state.Set(ctx, &data)
+	if diags.HasError() {
+		return diags
+	}
+
+	raw, err := tftypes.Transform(state.Raw, func(_ *tftypes.AttributePath, value tftypes.Value) (tftypes.Value, error) {
+		if !value.IsKnown() {
+			return tftypes.NewValue(value.Type(), nil), nil
+		}
+		return value, nil
+	})
+	if err != nil {
+		diags.AddError("Invalid State", fmt.Sprintf("Unable to save the partially applied state, got error: %s", err))
+		return diags
+	}
+	state.Raw = raw
+	return diags
+}
+
+// timestampValue formats a time reported by the service for state
+func timestampValue(t *time.Time) types.String {
+	if t == nil {
+		return types.StringNull()
+	}
+	return types.StringValue(t.UTC().Format(time.RFC3339))
+}
+
+// snapshot snapshots the blob under its lease before it is overwritten or destroyed and logs the
+// snapshot ID. flag names the attribute that asked for it, so a rejection says what to turn off.
+func (r *BlobLeaseResource) snapshot(ctx context.Context, data BlobLeaseResourceModel, flag string) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	config := blobclient.BlobLeaseConfig{
+		StorageAccount: data.StorageAccount.ValueString(),
+		ContainerName:  data.ContainerName.ValueString(),
+		BlobName:       data.BlobName.ValueString(),
+		LeaseID:        data.LeaseID.ValueString(),
+	}
+
+	snapshot, err := r.client.SnapshotBlob(ctx, config)
+	if errors.Is(err, blobclient.ErrSnapshotRejected) {
+		diags.AddAttributeError(
+			path.Root(flag),
+			"Snapshot Rejected",
+			fmt.Sprintf("%s. Set %s to false if snapshots cannot be used on this storage account.", err, flag),
+		)
+		return "", diags
+	}
+	if err != nil {
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to snapshot blob, got error: %s", err))
+		return "", diags
+	}
+
+	tflog.Info(ctx, "Created blob snapshot", map[string]interface{}{
+		"blob":     fmt.Sprintf("%s/%s/%s", config.StorageAccount, config.ContainerName, config.BlobName),
+		"snapshot": snapshot,
+	})
+	return snapshot, diags
 }
 
-func (m leaseStatePlanModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
-	// Don't modify during create
-	if req.State.Raw.IsNull() {
-		return
+// onlyChanged reports whether the plan differs from the state in no attribute other than names.
+// Unknown plan values are computed from the others and are ignored.
+func onlyChanged(plan tfsdk.Plan, state tfsdk.State, names ...string) bool {
+	var planValues, stateValues map[string]tftypes.Value
+	if err := plan.Raw.As(&planValues); err != nil {
+		return false
 	}
-
-	// Get current state value
-	var stateValue types.String
-	diags := req.State.GetAttribute(ctx, req.Path, &stateValue)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if err := state.Raw.As(&stateValues); err != nil {
+		return false
 	}
 
-	// If lease state is not "leased", mark for update
-	if !stateValue.IsNull() && !stateValue.IsUnknown() && stateValue.ValueString() != "leased" {
-		resp.PlanValue = types.StringUnknown()
-		resp.RequiresReplace = false
+	for name, value := range planValues {
+		if slices.Contains(names, name) || !value.IsFullyKnown() {
+			continue
+		}
+		if !value.Equal(stateValues[name]) {
+			return false
+		}
 	}
+	return true
 }
 
-func NewBlobLeaseResource() resource.Resource {
-	return &BlobLeaseResource{}
+// computedUnknownsOnly reports whether the plan has no change beyond marking unconfigured
+// computed attributes unknown: every known value equals the state, and every unknown value is
+// one that is not set in the configuration
+func computedUnknownsOnly(plan tfsdk.Plan, state tfsdk.State, config tfsdk.Config) bool {
+	var planValues, stateValues, configValues map[string]tftypes.Value
+	if plan.Raw.As(&planValues) != nil || state.Raw.As(&stateValues) != nil || config.Raw.As(&configValues) != nil {
+		return false
+	}
+
+	for name, value := range planValues {
+		if value.IsFullyKnown() {
+			if !value.Equal(stateValues[name]) {
+				return false
+			}
+		} else if configValue, ok := configValues[name]; !ok || !configValue.IsNull() {
+			return false
+		}
+	}
+	return true
 }
 
-// BlobLeaseResource defines the resource implementation.
-type BlobLeaseResource struct {
-	client *blobclient.AzureBlobLeaseClient
+// refreshBlobProperties updates last_modified, creation_time and content_length from the blob
+// properties
+func refreshBlobProperties(data *BlobLeaseResourceModel, result *blobclient.BlobLeaseResult) {
+	data.LastModified = timestampValue(result.LastModified)
+	data.CreationTime = timestampValue(result.CreatedOn)
+	data.ContentLength = types.Int64Value(result.Size)
 }
 
-// BlobLeaseResourceModel describes the resource data model.
-type BlobLeaseResourceModel struct {
-	ID             types.String `tfsdk:"id"`
-	StorageAccount types.String `tfsdk:"storage_account"`
-	ContainerName  types.String `tfsdk:"container_name"`
-	BlobName       types.String `tfsdk:"blob_name"`
-	Content        types.String `tfsdk:"content"`
-	LeaseDuration  types.Int32  `tfsdk:"lease_duration"`
-	LeaseID        types.String `tfsdk:"lease_id"`
-	BlobURL        types.String `tfsdk:"blob_url"`
-	ETag           types.String `tfsdk:"etag"`
-	LeaseState     types.String `tfsdk:"lease_state"`
+// readBlobProperties reads the blob properties after a write to record last_modified,
+// creation_time and content_length. The write already succeeded, so a failed read only warns.
+func (r *BlobLeaseResource) readBlobProperties(ctx context.Context, data *BlobLeaseResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	result, err := r.client.GetBlobLeaseState(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString())
+	if err != nil {
+		diags.AddWarning(
+			"Unable To Read Blob Properties",
+			fmt.Sprintf("last_modified, creation_time and content_length of blob %s are left empty until the next refresh, got error: %s", data.BlobName.ValueString(), err),
+		)
+		data.LastModified = types.StringNull()
+		data.CreationTime = types.StringNull()
+		data.ContentLength = types.Int64Null()
+		return diags
+	}
+
+	refreshBlobProperties(data, result)
+	return diags
 }
 
-func (r *BlobLeaseResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_blob_lease"
+// refreshExpiry updates the expiry attributes from the blob properties so that an expiry
+// changed or removed outside Terraform shows up as drift
+func refreshExpiry(data *BlobLeaseResourceModel, result *blobclient.BlobLeaseResult) {
+	data.ExpiresOn = timestampValue(result.ExpiresOn)
+
+	if !data.ExpiryDays.IsNull() {
+		switch {
+		case result.ExpiresOn == nil:
+			data.ExpiryDays = types.Int32Null()
+		case result.CreatedOn != nil:
+			days := math.Round(result.ExpiresOn.Sub(*result.CreatedOn).Hours() / 24)
+			data.ExpiryDays = types.Int32Value(int32(days))
+		}
+		return
+	}
+
+	if result.ExpiresOn == nil {
+		data.Expiry = types.StringNull()
+		return
+	}
+	configured, err := time.Parse(time.RFC3339, data.Expiry.ValueString())
+	if data.Expiry.IsNull() || err != nil || !configured.Truncate(time.Second).Equal(result.ExpiresOn.Truncate(time.Second)) {
+		data.Expiry = data.ExpiresOn
+	}
 }
 
-func (r *BlobLeaseResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
-	resp.Schema = schema.Schema{
-		// This description is used by the documentation generator and the language server.
-		MarkdownDescription: "Azure Blob Storage lease resource",
+// acquireExisting leases the existing blob described by config without writing content, then
+// applies the configured properties under the new lease and records the rest as found. It reports
+// whether the blob was leased, which it stays when applying the properties fails.
+func (r *BlobLeaseResource) acquireExisting(ctx context.Context, data *BlobLeaseResourceModel, config blobclient.BlobLeaseConfig) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
 
-		Attributes: map[string]schema.Attribute{
-			"id": schema.StringAttribute{
-				Computed:            true,
-				MarkdownDescription: "Resource identifier",
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.UseStateForUnknown(),
-				},
-			},
-			"storage_account": schema.StringAttribute{
-				MarkdownDescription: "The Azure Storage Account name",
-				Required:            true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
-			"container_name": schema.StringAttribute{
-				MarkdownDescription: "The container name where the blob will be created",
-				Required:            true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
-			"blob_name": schema.StringAttribute{
-				MarkdownDescription: "The name of the blob to create and lease",
-				Required:            true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
-			"content": schema.StringAttribute{
-				MarkdownDescription: "The content to write to the blob",
-				Optional:            true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
-			"lease_duration": schema.Int32Attribute{
-				MarkdownDescription: "The lease duration in seconds. Use -1 for infinite lease (default), or 15-60 for time-limited lease",
-				Optional:            true,
-			},
-			"lease_id": schema.StringAttribute{
-				MarkdownDescription: "The lease ID for the blob",
-				Computed:            true,
-			},
-			"blob_url": schema.StringAttribute{
-				MarkdownDescription: "The URL of the blob",
-				Computed:            true,
-			},
-			"etag": schema.StringAttribute{
-				MarkdownDescription: "The ETag of the blob",
-				Computed:            true,
-			},
-			"lease_state": schema.StringAttribute{
-				MarkdownDescription: "The current lease state of the blob",
-				Computed:            true,
-				PlanModifiers: []planmodifier.String{
-					leaseStatePlanModifier{},
-				},
-			},
-		},
+	exists, err := r.client.BlobExists(ctx, config.StorageAccount, config.ContainerName, config.BlobName)
+	if err != nil {
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence, got error: %s", err))
+		return false, diags
+	}
+	if !exists {
+		diags.AddAttributeError(
+			path.Root("name"),
+			"Blob Not Found",
+			fmt.Sprintf("acquire_existing is true, but blob %s does not exist in container %s of storage account %s.", config.BlobName, config.ContainerName, config.StorageAccount),
+		)
+		return false, diags
+	}
+
+	existing, err := r.client.GetBlobLeaseState(ctx, config.StorageAccount, config.ContainerName, config.BlobName)
+	if err != nil {
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read blob properties, got error: %s", err))
+		return false, diags
+	}
+	if existing.BlobType != config.BlobType {
+		diags.AddAttributeError(
+			path.Root("blob_type"),
+			"Blob Type Mismatch",
+			fmt.Sprintf("Blob %s is a %s blob, but blob_type is %s. Set blob_type to %q.", config.BlobName, existing.BlobType, config.BlobType, existing.BlobType),
+		)
+		return false, diags
+	}
+	if !data.EncryptionScope.IsNull() && !data.EncryptionScope.IsUnknown() && data.EncryptionScope.ValueString() != existing.EncryptionScope {
+		diags.AddAttributeError(
+			path.Root("encryption_scope"),
+			"Encryption Scope Mismatch",
+			fmt.Sprintf("Blob %s is encrypted with scope %q, but encryption_scope is %q. Remove encryption_scope or set it to the scope of the blob.", config.BlobName, existing.EncryptionScope, data.EncryptionScope.ValueString()),
+		)
+		return false, diags
+	}
+	if !data.PageBlobSize.IsNull() && data.PageBlobSize.ValueInt64() != existing.Size {
+		diags.AddAttributeError(
+			path.Root("page_blob_size"),
+			"Page Blob Size Mismatch",
+			fmt.Sprintf("Blob %s is %d bytes, but page_blob_size is %d. Remove page_blob_size or set it to the size of the blob.", config.BlobName, existing.Size, data.PageBlobSize.ValueInt64()),
+		)
+		return false, diags
+	}
+
+	result, err := r.client.AcquireBlobLease(ctx, config)
+	if errors.Is(err, blobclient.ErrLeaseWaitTimeout) {
+		diags.AddAttributeError(path.Root("acquire_timeout"), "Lease Wait Timed Out", err.Error())
+		return false, diags
+	}
+	if err != nil {
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to acquire lease on existing blob, got error: %s", err))
+		return false, diags
+	}
+	diags.Append(leaseBreakWarnings(config, result)...)
+	config.LeaseID = result.LeaseID
+
+	data.LeaseID = types.StringValue(result.LeaseID)
+	data.BlobURL = types.StringValue(result.BlobURL)
+	data.ETag = types.StringValue(result.ETag)
+	data.LeaseState = types.StringValue(result.LeaseState)
+	data.LeaseStatus = leaseStatusValue(result)
+	data.LeaseDurationKind = leaseDurationKindValue(result, data.LeaseDuration)
+	data.LeaseExpiresAt = timestampValue(result.LeaseExpiresOn)
+	data.ContentMD5 = types.StringValue(existing.ContentMD5)
+	data.VersionID = stringOrNull(existing.VersionID)
+	data.EncryptionScope = stringOrNull(existing.EncryptionScope)
+	data.SourceMD5 = types.StringNull()
+
+	// Properties that are configured are applied under the lease; the others keep the values
+	// of the existing blob
+	if !data.CacheControl.IsNull() || !data.ContentEncoding.IsNull() || !data.ContentLanguage.IsNull() || !data.ContentDisposition.IsNull() {
+		if config.Headers != existing.Headers {
+			etag, err := r.client.SetBlobHTTPHeaders(ctx, config)
+			if err != nil {
+				diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to set blob HTTP headers, got error: %s", err))
+				return true, diags
+			}
+			data.ETag = types.StringValue(etag)
+		}
+	} else {
+		refreshHeaders(data, existing.Headers)
+	}
+
+	if data.Metadata.IsNull() {
+		diags.Append(refreshMetadata(ctx, data, existing.Metadata, r.client.DefaultMetadata())...)
+		// With only the owner configured, the other metadata of the blob is kept
+		config.Metadata = existing.Metadata
+		if config.TerraformMetadata != nil {
+			config.Metadata = map[string]string{}
+			for key, value := range existing.Metadata {
+				if !isTerraformMetadataKey(key) {
+					config.Metadata[key] = value
+				}
+			}
+		}
+	}
+	if !data.Metadata.IsNull() || !data.Owner.IsNull() || config.TerraformMetadata != nil {
+		etag, err := r.client.SetBlobMetadata(ctx, config)
+		if err != nil {
+			diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to set blob metadata, got error: %s", err))
+			return true, diags
+		}
+		data.ETag = types.StringValue(etag)
+	}
+
+	if !data.Tags.IsNull() {
+		if err := r.client.SetBlobTags(ctx, config); err != nil {
+			diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to set blob tags, got error: %s", err))
+			return true, diags
+		}
+	} else if existing.TagCount > 0 {
+		diags.Append(r.refreshTags(ctx, data)...)
 	}
+	if diags.HasError() {
+		return true, diags
+	}
+
+	if !data.AccessTier.IsNull() && !data.AccessTier.IsUnknown() {
+		if err := r.client.SetBlobTier(ctx, config); err != nil {
+			if errors.Is(err, blobclient.ErrRehydrationPending) {
+				diags.AddAttributeError(path.Root("access_tier"), "Rehydration In Progress", err.Error())
+				return true, diags
+			}
+			diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to set blob access tier, got error: %s", err))
+			return true, diags
+		}
+	} else {
+		data.AccessTier = stringOrNull(existing.AccessTier)
+	}
+
+	if config.Expiry != nil {
+		expiresOn, err := r.client.SetBlobExpiry(ctx, config)
+		if err != nil {
+			diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to set blob expiry, got error: %s", err))
+			return true, diags
+		}
+		data.ExpiresOn = timestampValue(expiresOn)
+	} else {
+		refreshExpiry(data, existing)
+	}
+
+	diags.Append(r.applyImmutability(ctx, data, config, existing.LegalHold, existing.ImmutabilityPolicy)...)
+	return true, diags
 }
 
 func (r *BlobLeaseResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
@@ -170,19 +2584,63 @@ func (r *BlobLeaseResource) Create(ctx context.Context, req resource.CreateReque
 
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	hideLeaseID, diags := leaseIDHidden(ctx, req.Config, data)
+	resp.Diagnostics.Append(diags...)
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	// A blob_name_prefix is completed to a unique name, which everything below uses
+	if data.BlobName.IsUnknown() && !data.BlobNamePrefix.IsNull() {
+		blobName, err := generateBlobName(data.BlobNamePrefix.ValueString(), r.now())
+		if err != nil {
+			resp.Diagnostics.AddError("Blob Name Error", err.Error())
+			return
+		}
+		data.BlobName = types.StringValue(blobName)
+		data.OldBlobName = data.BlobName
+	}
+
+	timeout := operationTimeout(data.Timeouts, "create", defaultCreateTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "create", data.BlobName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
 	// Set default content if not provided
-	content := "managed by terraform-provider-blobleas"
+	content := defaultContent
 	if !data.Content.IsNull() && !data.Content.IsUnknown() {
 		content = data.Content.ValueString()
 	}
 
-	// Generate a unique lease ID (must be a valid UUID for Azure)
+	metadata, diags := blobMetadata(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	tags, diags := blobTags(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	headers, diags := extraHeaders(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The lock document replaces the default content
+	data.LockInfoDocument = types.StringNull()
+	if data.WriteLockInfo.ValueBool() {
+		document, diags := newLockInfo(ctx, data, "create", r.now())
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		content = document
+		data.LockInfoDocument = types.StringValue(document)
+	}
+
+	// Use the configured lease ID or generate a unique one (must be a valid UUID for Azure)
 	leaseID := uuid.New().String()
+	if !data.LeaseID.IsNull() && !data.LeaseID.IsUnknown() {
+		leaseID = data.LeaseID.ValueString()
+	}
 
 	// Get lease duration or default to -1 (infinite)
 	leaseDuration := int32(-1)
@@ -190,70 +2648,345 @@ func (r *BlobLeaseResource) Create(ctx context.Context, req resource.CreateReque
 		leaseDuration = data.LeaseDuration.ValueInt32()
 	}
 
+	body, diags := blobContent(data, content)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Create blob with lease
 	config := blobclient.BlobLeaseConfig{
 		StorageAccount: data.StorageAccount.ValueString(),
 		ContainerName:  data.ContainerName.ValueString(),
 		BlobName:       data.BlobName.ValueString(),
-		Content:        []byte(content),
+		Content:        body,
+		SourcePath:     data.Source.ValueString(),
+		Headers:        blobHeaders(data),
+		Metadata:       metadata,
+		Owner:          data.Owner.ValueString(),
+		Tags:           tags,
+		AccessTier:     data.AccessTier.ValueString(),
+		BlobType:       data.BlobType.ValueString(),
+		PageBlobSize:   data.PageBlobSize.ValueInt64(),
 		LeaseID:        leaseID,
 		LeaseDuration:  leaseDuration,
+		Expiry:         blobExpiry(data),
+
+		SkipContainerCreate: !data.CreateContainer.ValueBool(),
+		ExtraHeaders:        headers,
+		TerraformMetadata:   r.terraformMetadata(data, r.now()),
+		EncryptionScope:     data.EncryptionScope.ValueString(),
+		CopySource:          data.CopySource.ValueString(),
+		Overwrite:           data.Overwrite.ValueBool(),
+		ContainerAccess:     data.ContainerAccess.ValueString(),
+		AcquireTimeout:      acquireTimeout(data),
+		BreakExistingLease:  data.ForceBreak.ValueBool(),
+		StealIfOlderThan:    stealAge(data),
+	}
+
+	// A soft-deleted blob is brought back instead of being replaced by a new generation
+	restored := false
+	if data.RestoreIfSoftDeleted.ValueBool() {
+		restored, diags = r.restoreSoftDeleted(ctx, config)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// A blob left behind with this content by an earlier, crashed apply is adopted as it is,
+	// and so is a restored blob that still has it; otherwise a restored blob is overwritten
+	adopted := false
+	if data.AdoptMatching.ValueBool() || restored {
+		adopted, diags = r.adoptMatching(ctx, config)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if restored {
+		resp.Diagnostics.Append(restoredBlobWarning(config, adopted))
+		config.Overwrite = !adopted
+	}
+
+	// The lease ID set by create starts the rotation_days period
+	rotatedAt := r.now()
+	if data.AcquireExisting.ValueBool() || adopted {
+		acquired, diags := r.acquireExisting(ctx, &data, config)
+		resp.Diagnostics.Append(diags...)
+		if !acquired {
+			return
+		}
+		data.ID = types.StringValue(blobLeaseID(config.StorageAccount, config.ContainerName, config.BlobName))
+		data.LeaseRotatedAt = timestampValue(&rotatedAt)
+		// A blob leased before its properties failed to apply is recorded with its lease, so
+		// that the lease is released later instead of being left behind
+		if resp.Diagnostics.HasError() {
+			resp.Diagnostics.Append(storeLeaseID(ctx, &data, hideLeaseID, resp.Private)...)
+			resp.Diagnostics.Append(setPartialState(ctx, &resp.State, data)...)
+			return
+		}
+		if adopted {
+			if !restored {
+				resp.Diagnostics.Append(adoptedBlobWarning(config))
+			}
+			if !data.Source.IsNull() {
+				data.SourceMD5 = data.ContentMD5
+			}
+		}
+		if data.Content.IsUnknown() {
+			data.Content = types.StringNull()
+		}
+		resp.Diagnostics.Append(r.readBlobProperties(ctx, &data)...)
+		r.keepLeaseRenewed(ctx, data, data.LeaseID.ValueString())
+		resp.Diagnostics.Append(storeLeaseID(ctx, &data, hideLeaseID, resp.Private)...)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
 	}
 
 	result, err := r.client.CreateBlobWithLease(ctx, config)
+	var existsErr *blobclient.BlobExistsError
+	if errors.As(err, &existsErr) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Blob Already Exists",
+			fmt.Sprintf("%s. It was not overwritten because overwrite is false. Check that name is correct, set acquire_existing to lease the blob as it is, or set overwrite to true to replace it.", existsErr),
+		)
+		return
+	}
+	if errors.Is(err, blobclient.ErrLeaseWaitTimeout) {
+		resp.Diagnostics.AddAttributeError(path.Root("acquire_timeout"), "Lease Wait Timed Out", err.Error())
+		return
+	}
+	if errors.Is(err, blobclient.ErrEncryptionScopeDenied) {
+		resp.Diagnostics.AddAttributeError(path.Root("encryption_scope"), "Write Denied", err.Error())
+		return
+	}
+	if diags := copySourceDiags(err); diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create blob with lease, got error: %s", err))
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to create blob with lease, got error: %s", err))
 		return
 	}
 
+	resp.Diagnostics.Append(leaseBreakWarnings(config, result)...)
+
 	// Set computed attributes
-	data.ID = types.StringValue(fmt.Sprintf("%s/%s/%s", config.StorageAccount, config.ContainerName, config.BlobName))
+	data.ID = types.StringValue(blobLeaseID(config.StorageAccount, config.ContainerName, config.BlobName))
 	data.LeaseID = types.StringValue(result.LeaseID)
+	data.LeaseRotatedAt = timestampValue(&rotatedAt)
 	data.BlobURL = types.StringValue(result.BlobURL)
 	data.ETag = types.StringValue(result.ETag)
 	data.LeaseState = types.StringValue(result.LeaseState)
-	if data.Content.IsNull() || data.Content.IsUnknown() {
-		data.Content = types.StringValue(content)
+	data.LeaseStatus = leaseStatusValue(result)
+	data.LeaseDurationKind = leaseDurationKindValue(result, data.LeaseDuration)
+	data.LeaseExpiresAt = timestampValue(result.LeaseExpiresOn)
+	data.ExpiresOn = timestampValue(result.ExpiresOn)
+	data.ContentMD5 = types.StringValue(result.ContentMD5)
+	data.VersionID = stringOrNull(result.VersionID)
+	data.AccessTier = stringOrNull(result.AccessTier)
+	if data.EncryptionScope.IsUnknown() {
+		data.EncryptionScope = stringOrNull(result.EncryptionScope)
+	}
+	if data.Source.IsNull() {
+		data.SourceMD5 = types.StringNull()
+		if (data.Content.IsNull() || data.Content.IsUnknown()) && config.BlobType != blobclient.BlobTypePage && config.CopySource == "" && !data.WriteLockInfo.ValueBool() {
+			data.Content = types.StringValue(content)
+		}
+	} else {
+		data.SourceMD5 = types.StringValue(result.ContentMD5)
+	}
+	if data.Content.IsUnknown() {
+		data.Content = types.StringNull()
+	}
+
+	// A blob whose records could not all be appended is recorded, so it is replaced or
+	// destroyed later instead of being left behind with its lease
+	resp.Diagnostics.Append(r.appendRecords(ctx, &data, 0)...)
+	if resp.Diagnostics.HasError() {
+		resp.Diagnostics.Append(storeLeaseID(ctx, &data, hideLeaseID, resp.Private)...)
+		resp.Diagnostics.Append(setPartialState(ctx, &resp.State, data)...)
+		return
+	}
+
+	// Legal holds and immutability policies only take effect once the content is written. A blob
+	// they could not be applied to is recorded like one with missing records.
+	resp.Diagnostics.Append(r.applyImmutability(ctx, &data, config, false, nil)...)
+	if resp.Diagnostics.HasError() {
+		resp.Diagnostics.Append(storeLeaseID(ctx, &data, hideLeaseID, resp.Private)...)
+		resp.Diagnostics.Append(setPartialState(ctx, &resp.State, data)...)
+		return
+	}
+	resp.Diagnostics.Append(r.readBlobProperties(ctx, &data)...)
+	r.keepLeaseRenewed(ctx, data, data.LeaseID.ValueString())
+	resp.Diagnostics.Append(storeLeaseID(ctx, &data, hideLeaseID, resp.Private)...)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BlobLeaseResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data BlobLeaseResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A hidden lease ID is used from private state and stays out of state
+	leaseID, diags := heldLeaseID(ctx, data, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := operationTimeout(data.Timeouts, "read", defaultReadTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "read", data.BlobName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
+	// Check if blob still exists
+	exists, err := r.client.BlobExists(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence, got error: %s", err))
+		return
+	}
+
+	if !exists {
+		// Blob was deleted outside of Terraform
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Get current lease state
+	leaseResult, err := r.client.GetBlobLeaseState(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read blob lease state, got error: %s", err))
+		return
+	}
+
+	// A read served by the secondary endpoint may lag the primary, so keep the prior state
+	if leaseResult.StaleRead {
+		resp.Diagnostics.AddWarning(
+			"Stale Read From Secondary Endpoint",
+			fmt.Sprintf("The primary endpoint of storage account %s is unavailable; lease state for blob %s was read from the secondary endpoint and may be out of date. Keeping the previously known state.",
+				data.StorageAccount.ValueString(), data.BlobName.ValueString()),
+		)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	// Update computed attributes
+	data.ETag = types.StringValue(leaseResult.ETag)
+	data.LeaseState = types.StringValue(leaseResult.LeaseState)
+	data.LeaseStatus = leaseStatusValue(leaseResult)
+	data.LeaseDurationKind = leaseDurationKindValue(leaseResult, data.LeaseDuration)
+
+	// Renew opportunistically so a finite lease is still held at the next apply. The raw
+	// lease state is only reported when renewal fails.
+	verified := false
+	if data.RenewOnRead.ValueBool() && leaseID != "" {
+		config := blobclient.BlobLeaseConfig{
+			StorageAccount: data.StorageAccount.ValueString(),
+			ContainerName:  data.ContainerName.ValueString(),
+			BlobName:       data.BlobName.ValueString(),
+			LeaseID:        leaseID,
+			LeaseDuration:  data.LeaseDuration.ValueInt32(),
+		}
+
+		renewed, err := r.client.RenewBlobLease(ctx, config)
+		if err != nil {
+			resp.Diagnostics.AddWarning(
+				"Lease Renewal Failed",
+				fmt.Sprintf("renew_on_read is set, but the lease on blob %s could not be renewed; reporting lease state %q. Error: %s",
+					data.BlobName.ValueString(), leaseResult.LeaseState, err),
+			)
+		} else {
+			verified = true
+			data.ETag = types.StringValue(renewed.ETag)
+			data.LeaseState = types.StringValue(renewed.LeaseState)
+			data.LeaseStatus = leaseStatusValue(renewed)
+			data.LeaseDurationKind = leaseDurationKindValue(renewed, data.LeaseDuration)
+			data.LeaseExpiresAt = timestampValue(renewed.LeaseExpiresOn)
+		}
 	}
 
-	// Save data into Terraform state
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
-}
-
-func (r *BlobLeaseResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var data BlobLeaseResourceModel
+	// A lease broken and re-acquired by someone else still reads as leased, so check that it is
+	// held with the lease ID in state
+	if data.VerifyOwnership.ValueBool() && !verified && data.LeaseState.ValueString() == "leased" && leaseID != "" {
+		config := blobclient.BlobLeaseConfig{
+			StorageAccount: data.StorageAccount.ValueString(),
+			ContainerName:  data.ContainerName.ValueString(),
+			BlobName:       data.BlobName.ValueString(),
+			LeaseID:        leaseID,
+		}
 
-	// Read Terraform prior state data into the model
-	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+		owned, err := r.client.ProbeBlobLease(ctx, config)
+		if err != nil {
+			// Plan checks the lease again, so a failed check only loses the early drift
+			tflog.Warn(ctx, "Unable to verify blob lease ownership during refresh", map[string]interface{}{
+				"blob":  data.BlobName.ValueString(),
+				"error": err.Error(),
+			})
+		} else if !owned {
+			data.LeaseState = types.StringValue(leaseStateLeasedByOther)
+		}
+	}
 
+	refreshExpiry(&data, leaseResult)
+	refreshBlobProperties(&data, leaseResult)
+	data.VersionID = stringOrNull(leaseResult.VersionID)
+	data.EncryptionScope = stringOrNull(leaseResult.EncryptionScope)
+	data.LegalHold = types.BoolValue(leaseResult.LegalHold)
+	data.ImmutabilityPolicy = immutabilityPolicyValue(leaseResult.ImmutabilityPolicy, data.ImmutabilityPolicy)
+	refreshHeaders(&data, leaseResult.Headers)
+	data.AccessTier = stringOrNull(leaseResult.AccessTier)
+	refreshOwner(&data, leaseResult.Metadata)
+	resp.Diagnostics.Append(refreshMetadata(ctx, &data, leaseResult.Metadata, r.client.DefaultMetadata())...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Check if blob still exists
-	exists, err := r.client.BlobExists(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to check blob existence, got error: %s", err))
-		return
+	// Tags are not part of the blob properties, so only fetch them when there is something to compare
+	if !data.Tags.IsNull() || leaseResult.TagCount > 0 {
+		resp.Diagnostics.Append(r.refreshTags(ctx, &data)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
 	}
 
-	if !exists {
-		// Blob was deleted outside of Terraform
-		resp.State.RemoveResource(ctx)
-		return
+	// Content overwritten outside Terraform shows up as a changed content, source_md5 or
+	// lock_info_document, which plans a rewrite. Blobs without a stored MD5 cannot be compared.
+	if data.DetectDrift.ValueBool() && leaseResult.ContentMD5 != "" {
+		if !data.ContentMD5.IsNull() && data.ContentMD5.ValueString() != leaseResult.ContentMD5 {
+			if data.WriteLockInfo.ValueBool() {
+				data.LockInfoDocument = types.StringNull()
+			} else if data.Source.IsNull() {
+				data.Content = types.StringNull()
+			} else {
+				data.SourceMD5 = types.StringValue(leaseResult.ContentMD5)
+			}
+		}
+		data.ContentMD5 = types.StringValue(leaseResult.ContentMD5)
 	}
 
-	// Get current lease state
-	leaseResult, err := r.client.GetBlobLeaseState(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read blob lease state, got error: %s", err))
-		return
+	// Report a container access level changed outside Terraform as drift instead of resetting it
+	if !data.ContainerAccess.IsNull() {
+		props, err := r.client.GetContainerProperties(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read container properties, got error: %s", err))
+			return
+		}
+		if props.Exists {
+			data.ContainerAccess = types.StringValue(containerAccessValue(props.PublicAccess))
+		}
 	}
 
-	// Update computed attributes
-	data.ETag = types.StringValue(leaseResult.ETag)
-	data.LeaseState = types.StringValue(leaseResult.LeaseState)
-
 	// Don't automatically renew lease during read - let Terraform detect drift
 	// The Update function will handle lease renewal during apply
 
@@ -273,19 +3006,167 @@ func (r *BlobLeaseResource) Update(ctx context.Context, req resource.UpdateReque
 
 	// Read current state
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(resolveLeaseID(ctx, &state, req.Private)...)
+	hideLeaseID, diags := leaseIDHidden(ctx, req.Config, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Toggling deletion protection needs no request to Azure, unless the lease is also renewed or
+	// re-acquired, which the plan only shows as unknown lease attributes
+	leaseDue := state.LeaseState.ValueString() != "leased" || renewalDue(state.LeaseExpiresAt, data.RenewThreshold, r.now())
+	if !data.DeletionProtection.Equal(state.DeletionProtection) && !leaseDue && onlyChanged(req.Plan, req.State, "deletion_protection") {
+		state.DeletionProtection = data.DeletionProtection
+		resp.Diagnostics.Append(storeLeaseID(ctx, &state, hideLeaseID, resp.Private)...)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		return
+	}
+
+	timeout := operationTimeout(data.Timeouts, "update", defaultUpdateTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "update", data.BlobName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
+	// Once the blob is leased anew or with a new lease ID, an update that fails later still
+	// records the lease with the prior state. Otherwise the lease would be left on the blob with
+	// an ID that Terraform does not know, and could neither renew nor release it.
+	leaseChanged := false
+	defer func() {
+		if !leaseChanged || !resp.Diagnostics.HasError() {
+			return
+		}
+		rotated := !data.LeaseID.Equal(state.LeaseID)
+		state.LeaseID = data.LeaseID
+		state.ETag = data.ETag
+		state.LeaseState = data.LeaseState
+		state.LeaseStatus = data.LeaseStatus
+		state.LeaseDurationKind = data.LeaseDurationKind
+		if !data.LeaseExpiresAt.IsUnknown() {
+			state.LeaseExpiresAt = data.LeaseExpiresAt
+		}
+		state.BlobURL = data.BlobURL
+
+		// A new lease ID also carried out any rotation
+		if rotated {
+			rotatedAt := r.now()
+			state.LeaseRotatedAt = timestampValue(&rotatedAt)
+			state.RotationTriggers = data.RotationTriggers
+		}
+		resp.Diagnostics.Append(storeLeaseID(ctx, &state, hideLeaseID, resp.Private)...)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	}()
+
+	metadata, diags := blobMetadata(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	tags, diags := blobTags(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	headers, diags := extraHeaders(ctx, data)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	terraformMetadata := r.terraformMetadata(data, leaseAcquiredAt(data, r.now()))
 
 	// Check current lease state
 	leaseResult, err := r.client.GetBlobLeaseState(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read blob lease state, got error: %s", err))
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read blob lease state, got error: %s", err))
+		return
+	}
+
+	// New content is written under the held lease; a lost lease is never silently replaced
+	contentChanged := !data.Content.Equal(state.Content)
+
+	// A lock document the plan left unknown is generated anew and written with the lease that
+	// this update ends up holding
+	lockInfo := ""
+	if data.WriteLockInfo.ValueBool() && data.LockInfoDocument.IsUnknown() {
+		document, diags := newLockInfo(ctx, data, "update", r.now())
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		lockInfo = document
+	}
+
+	// A configured lease ID is the proposed ID for any acquire during this update. Otherwise a
+	// due rotation_days period or a changed rotation trigger rotates to a new random ID.
+	proposedID := ""
+	if !data.LeaseID.IsNull() && !data.LeaseID.IsUnknown() {
+		proposedID = data.LeaseID.ValueString()
+	}
+	if data.LeaseID.IsUnknown() && rotationDue(state.LeaseRotatedAt, data.RotationDays, r.now()) {
+		proposedID = uuid.New().String()
+	}
+	if !data.RotationTriggers.Equal(state.RotationTriggers) {
+		if proposedID == "" {
+			proposedID = uuid.New().String()
+		} else if proposedID == state.LeaseID.ValueString() {
+			resp.Diagnostics.AddAttributeWarning(
+				path.Root("rotation_triggers"),
+				"Lease ID Not Rotated",
+				"rotation_triggers changed, but lease_id is set explicitly to the current lease ID. Set a new lease_id, or remove it to rotate to a random one.",
+			)
+		}
+	}
+
+	// A lease taken over by someone else is handled like one that is no longer active
+	held := leaseResult.LeaseState == "leased"
+	if held && state.LeaseID.ValueString() != "" {
+		config := blobclient.BlobLeaseConfig{
+			StorageAccount: data.StorageAccount.ValueString(),
+			ContainerName:  data.ContainerName.ValueString(),
+			BlobName:       data.BlobName.ValueString(),
+			LeaseID:        state.LeaseID.ValueString(),
+		}
+
+		held, err = r.client.ProbeBlobLease(ctx, config)
+		if err != nil {
+			resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob lease, got error: %s", err))
+			return
+		}
+	}
+
+	// A lease that is no longer held is only re-acquired with lease_action_on_drift reacquire
+	if !held && data.LeaseActionOnDrift.ValueString() == leaseActionOnDriftFail {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("lease_action_on_drift"),
+			"Lease Lost Outside Terraform",
+			fmt.Sprintf("The lease on blob %s is no longer held by this resource (lease state: %s): it was broken, released or taken over outside Terraform, or it expired. lease_action_on_drift is fail, so the lease is not re-acquired. Find out what broke the lease, then set lease_action_on_drift to reacquire and apply again to take it back.",
+				data.BlobName.ValueString(), leaseResult.LeaseState),
+		)
+		return
+	}
+	if !held && data.LeaseActionOnDrift.ValueString() == leaseActionOnDriftIgnore {
+		if !onlyChanged(req.Plan, req.State, "lease_action_on_drift", "timeouts") {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("lease_action_on_drift"),
+				"Lease No Longer Held",
+				fmt.Sprintf("The lease on blob %s is no longer held by this resource (lease state: %s), and lease_action_on_drift is ignore, so it is not re-acquired. The other changes of this apply need the lease. Set lease_action_on_drift to reacquire, or revert the other changes.",
+					data.BlobName.ValueString(), leaseResult.LeaseState),
+			)
+			return
+		}
+
+		// Record the lease as it is, without touching the blob
+		state.LeaseActionOnDrift = data.LeaseActionOnDrift
+		state.Timeouts = data.Timeouts
+		state.ETag = types.StringValue(leaseResult.ETag)
+		state.LeaseState = types.StringValue(leaseResult.LeaseState)
+		if leaseResult.LeaseState == "leased" {
+			state.LeaseState = types.StringValue(leaseStateLeasedByOther)
+		}
+		state.LeaseStatus = leaseStatusValue(leaseResult)
+		state.LeaseDurationKind = leaseDurationKindValue(leaseResult, state.LeaseDuration)
+		resp.Diagnostics.Append(storeLeaseID(ctx, &state, hideLeaseID, resp.Private)...)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 		return
 	}
 
 	// If lease is not active, try to renew or acquire a new lease
-	if leaseResult.LeaseState != "leased" {
+	if !held {
 		// Get lease duration or default to -1 (infinite)
 		leaseDuration := int32(-1)
 		if !data.LeaseDuration.IsNull() && !data.LeaseDuration.IsUnknown() {
@@ -298,40 +3179,357 @@ func (r *BlobLeaseResource) Update(ctx context.Context, req resource.UpdateReque
 			BlobName:       data.BlobName.ValueString(),
 			LeaseID:        state.LeaseID.ValueString(),
 			LeaseDuration:  leaseDuration,
+			AcquireTimeout: acquireTimeout(data),
+
+			StealIfOlderThan: stealAge(data),
+			OwnLeaseID:       state.LeaseID.ValueString(),
+		}
+		if proposedID != "" {
+			config.LeaseID = proposedID
 		}
 
-		// Try to renew existing lease first
+		// Try to renew existing lease first, and acquire a new one on the blob as it is if that
+		// fails. The blob is never rewritten to get a lease.
 		result, err := r.client.RenewBlobLease(ctx, config)
-		if err != nil {
-			// If renewal fails, try to acquire a new lease
+		if err != nil && contentChanged && !data.AcquireExisting.ValueBool() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("content"),
+				"Lease No Longer Held",
+				fmt.Sprintf("The content of blob %s cannot be updated because its lease is no longer held (lease state: %s) and could not be renewed: %s",
+					data.BlobName.ValueString(), leaseResult.LeaseState, err),
+			)
+			return
+		} else if err != nil {
 			config.LeaseID = uuid.New().String()
-			content := "managed by terraform-provider-blobleas"
-			if !data.Content.IsNull() && !data.Content.IsUnknown() {
-				content = data.Content.ValueString()
+			if proposedID != "" {
+				config.LeaseID = proposedID
+			}
+			result, err = r.client.AcquireBlobLease(ctx, config)
+			if errors.Is(err, blobclient.ErrLeaseWaitTimeout) {
+				resp.Diagnostics.AddAttributeError(path.Root("acquire_timeout"), "Lease Wait Timed Out", err.Error())
+				return
 			}
-			config.Content = []byte(content)
-			config.LeaseDuration = leaseDuration
-			
-			result, err = r.client.CreateBlobWithLease(ctx, config)
 			if err != nil {
-				resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to renew or acquire blob lease, got error: %s", err))
+				resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to renew or acquire blob lease, got error: %s", err))
 				return
 			}
 		}
 
+		resp.Diagnostics.Append(leaseBreakWarnings(config, result)...)
+		leaseChanged = true
+
 		// Update computed attributes
 		data.LeaseID = types.StringValue(result.LeaseID)
 		data.ETag = types.StringValue(result.ETag)
 		data.LeaseState = types.StringValue(result.LeaseState)
+		data.LeaseStatus = leaseStatusValue(result)
+		data.LeaseDurationKind = leaseDurationKindValue(result, data.LeaseDuration)
+		data.LeaseExpiresAt = timestampValue(result.LeaseExpiresOn)
 		data.BlobURL = types.StringValue(result.BlobURL)
 	} else {
 		// Lease is still active, just update metadata
 		data.ETag = types.StringValue(leaseResult.ETag)
 		data.LeaseState = types.StringValue(leaseResult.LeaseState)
+		data.LeaseStatus = leaseStatusValue(leaseResult)
+		data.LeaseDurationKind = leaseDurationKindValue(leaseResult, data.LeaseDuration)
 		data.BlobURL = types.StringValue(leaseResult.BlobURL)
 		data.LeaseID = state.LeaseID // Keep existing lease ID
+
+		// A changed lease_id is applied to the held lease with Change Lease
+		if proposedID != "" && proposedID != state.LeaseID.ValueString() {
+			if state.LeaseID.ValueString() == "" {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("lease_id"),
+					"Unknown Current Lease ID",
+					fmt.Sprintf("Blob %s is leased, but the current lease ID is not known (for example after import), so it cannot be changed. Break the lease and apply again.", data.BlobName.ValueString()),
+				)
+				return
+			}
+
+			config := blobclient.BlobLeaseConfig{
+				StorageAccount: data.StorageAccount.ValueString(),
+				ContainerName:  data.ContainerName.ValueString(),
+				BlobName:       data.BlobName.ValueString(),
+				LeaseID:        state.LeaseID.ValueString(),
+			}
+
+			result, err := r.client.ChangeBlobLease(ctx, config, proposedID)
+			if err != nil {
+				resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to change lease ID, got error: %s", err))
+				return
+			}
+
+			data.LeaseID = types.StringValue(result.LeaseID)
+			data.ETag = types.StringValue(result.ETag)
+			leaseChanged = true
+		}
+
+		// Azure cannot change the duration of an active lease, but acquiring again with the
+		// active lease ID replaces it in place with the new duration
+		if !data.LeaseDuration.Equal(state.LeaseDuration) && data.LeaseID.ValueString() != "" {
+			config := blobclient.BlobLeaseConfig{
+				StorageAccount: data.StorageAccount.ValueString(),
+				ContainerName:  data.ContainerName.ValueString(),
+				BlobName:       data.BlobName.ValueString(),
+				LeaseID:        data.LeaseID.ValueString(),
+				LeaseDuration:  data.LeaseDuration.ValueInt32(),
+			}
+
+			result, err := r.client.AcquireBlobLease(ctx, config)
+			if err != nil {
+				resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to change lease duration, got error: %s", err))
+				return
+			}
+
+			data.LeaseID = types.StringValue(result.LeaseID)
+			data.ETag = types.StringValue(result.ETag)
+			data.LeaseState = types.StringValue(result.LeaseState)
+			data.LeaseStatus = leaseStatusValue(result)
+			data.LeaseDurationKind = leaseDurationKindValue(result, data.LeaseDuration)
+			data.LeaseExpiresAt = timestampValue(result.LeaseExpiresOn)
+		}
+
+		// Any other apply renews a time-limited lease, which also covers a renewal planned
+		// because the lease lapses within renew_threshold_seconds
+		if data.LeaseExpiresAt.IsUnknown() && data.LeaseDuration.ValueInt32() > 0 && data.LeaseID.ValueString() != "" {
+			config := blobclient.BlobLeaseConfig{
+				StorageAccount: data.StorageAccount.ValueString(),
+				ContainerName:  data.ContainerName.ValueString(),
+				BlobName:       data.BlobName.ValueString(),
+				LeaseID:        data.LeaseID.ValueString(),
+				LeaseDuration:  data.LeaseDuration.ValueInt32(),
+			}
+
+			result, err := r.client.RenewBlobLease(ctx, config)
+			if err != nil {
+				resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to renew blob lease, got error: %s", err))
+				return
+			}
+
+			data.ETag = types.StringValue(result.ETag)
+			data.LeaseExpiresAt = timestampValue(result.LeaseExpiresOn)
+		} else if data.LeaseExpiresAt.IsUnknown() {
+			data.LeaseExpiresAt = state.LeaseExpiresAt
+		}
+	}
+
+	writeContent := contentChanged || lockInfo != ""
+	if writeContent && data.SnapshotBeforeUpdate.ValueBool() {
+		snapshot, diags := r.snapshot(ctx, data, "snapshot_before_update")
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		data.LastSnapshotID = types.StringValue(snapshot)
+	} else if data.LastSnapshotID.IsUnknown() {
+		data.LastSnapshotID = state.LastSnapshotID
+	}
+
+	if writeContent {
+		content := defaultContent
+		if !data.Content.IsNull() {
+			content = data.Content.ValueString()
+		} else if lockInfo != "" {
+			content = lockInfo
+		}
+		body, diags := blobContent(data, content)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		config := blobclient.BlobLeaseConfig{
+			StorageAccount: data.StorageAccount.ValueString(),
+			ContainerName:  data.ContainerName.ValueString(),
+			BlobName:       data.BlobName.ValueString(),
+			Content:        body,
+			Headers:        blobHeaders(data),
+			Metadata:       metadata,
+			Owner:          data.Owner.ValueString(),
+			Tags:           tags,
+			AccessTier:     data.AccessTier.ValueString(),
+			BlobType:       data.BlobType.ValueString(),
+			LeaseID:        data.LeaseID.ValueString(),
+
+			EncryptionScope:   data.EncryptionScope.ValueString(),
+			ExtraHeaders:      headers,
+			TerraformMetadata: terraformMetadata,
+		}
+		if data.UseETagPrecondition.ValueBool() {
+			config.IfMatch = state.ETag.ValueString()
+		}
+
+		result, err := r.client.UpdateBlobContent(ctx, config)
+		if errors.Is(err, blobclient.ErrLeaseLost) {
+			resp.Diagnostics.AddAttributeError(path.Root("content"), "Lease No Longer Held", err.Error())
+			return
+		}
+		if errors.Is(err, blobclient.ErrBlobChanged) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("content"),
+				"Blob Changed Remotely",
+				fmt.Sprintf("%s. The blob was written by someone else since it was last refreshed, so it was not overwritten because use_etag_precondition is true. Run terraform plan again to refresh and review the current blob before applying.", err),
+			)
+			return
+		}
+		if errors.Is(err, blobclient.ErrEncryptionScopeDenied) {
+			resp.Diagnostics.AddAttributeError(path.Root("encryption_scope"), "Write Denied", err.Error())
+			return
+		}
+		if err != nil {
+			resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to update blob content, got error: %s", err))
+			return
+		}
+
+		data.ETag = types.StringValue(result.ETag)
+		data.ContentMD5 = types.StringValue(result.ContentMD5)
+		data.VersionID = stringOrNull(result.VersionID)
+	}
+	if lockInfo != "" {
+		data.LockInfoDocument = types.StringValue(lockInfo)
+	}
+
+	// New append_content records are appended under the lease. Writing the content again
+	// started the blob over, so then every record is.
+	appendFrom := len(state.AppendContent.Elements())
+	if writeContent {
+		appendFrom = 0
+	}
+	resp.Diagnostics.Append(r.appendRecords(ctx, &data, appendFrom)...)
+	if resp.Diagnostics.HasError() {
+		// Record the records that were appended, with the lease they were appended under
+		state.AppendContent = data.AppendContent
+		state.LeaseID = data.LeaseID
+		state.ETag = data.ETag
+		resp.Diagnostics.Append(storeLeaseID(ctx, &state, hideLeaseID, resp.Private)...)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		return
+	}
+
+	// Header-only changes are applied in place without rewriting the content
+	if blobHeaders(data) != blobHeaders(state) {
+		config := blobclient.BlobLeaseConfig{
+			StorageAccount: data.StorageAccount.ValueString(),
+			ContainerName:  data.ContainerName.ValueString(),
+			BlobName:       data.BlobName.ValueString(),
+			LeaseID:        data.LeaseID.ValueString(),
+			Headers:        blobHeaders(data),
+		}
+
+		etag, err := r.client.SetBlobHTTPHeaders(ctx, config)
+		if err != nil {
+			resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to set blob HTTP headers, got error: %s", err))
+			return
+		}
+		data.ETag = types.StringValue(etag)
+	}
+
+	// Metadata-only changes are applied in place under the lease as well
+	if !data.Metadata.Equal(state.Metadata) || !data.Owner.Equal(state.Owner) || !data.TerraformMetadata.Equal(state.TerraformMetadata) {
+		config := blobclient.BlobLeaseConfig{
+			StorageAccount: data.StorageAccount.ValueString(),
+			ContainerName:  data.ContainerName.ValueString(),
+			BlobName:       data.BlobName.ValueString(),
+			LeaseID:        data.LeaseID.ValueString(),
+			Metadata:       metadata,
+			Owner:          data.Owner.ValueString(),
+
+			TerraformMetadata: terraformMetadata,
+		}
+
+		etag, err := r.client.SetBlobMetadata(ctx, config)
+		if err != nil {
+			resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to set blob metadata, got error: %s", err))
+			return
+		}
+		data.ETag = types.StringValue(etag)
+	}
+
+	if !data.Tags.Equal(state.Tags) {
+		config := blobclient.BlobLeaseConfig{
+			StorageAccount: data.StorageAccount.ValueString(),
+			ContainerName:  data.ContainerName.ValueString(),
+			BlobName:       data.BlobName.ValueString(),
+			LeaseID:        data.LeaseID.ValueString(),
+			Tags:           tags,
+		}
+
+		if err := r.client.SetBlobTags(ctx, config); err != nil {
+			resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to set blob tags, got error: %s", err))
+			return
+		}
+	}
+
+	// Only a configured tier is applied, so tier changes made by lifecycle policies are not reverted
+	if !data.AccessTier.IsNull() && !data.AccessTier.IsUnknown() && !data.AccessTier.Equal(state.AccessTier) {
+		config := blobclient.BlobLeaseConfig{
+			StorageAccount: data.StorageAccount.ValueString(),
+			ContainerName:  data.ContainerName.ValueString(),
+			BlobName:       data.BlobName.ValueString(),
+			LeaseID:        data.LeaseID.ValueString(),
+			AccessTier:     data.AccessTier.ValueString(),
+		}
+
+		if err := r.client.SetBlobTier(ctx, config); err != nil {
+			if errors.Is(err, blobclient.ErrRehydrationPending) {
+				resp.Diagnostics.AddAttributeError(path.Root("access_tier"), "Rehydration In Progress", err.Error())
+				return
+			}
+			resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to set blob access tier, got error: %s", err))
+			return
+		}
+	}
+
+	// Update the access level of the existing container when it changes
+	if !data.ContainerAccess.IsNull() && !data.ContainerAccess.Equal(state.ContainerAccess) {
+		err := r.client.SetContainerAccess(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.ContainerAccess.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to update container access type, got error: %s", err))
+			return
+		}
 	}
 
+	// Apply a changed expiry; a removed expiry clears it on the blob
+	if !data.Expiry.Equal(state.Expiry) || !data.ExpiryDays.Equal(state.ExpiryDays) {
+		config := blobclient.BlobLeaseConfig{
+			StorageAccount: data.StorageAccount.ValueString(),
+			ContainerName:  data.ContainerName.ValueString(),
+			BlobName:       data.BlobName.ValueString(),
+			Expiry:         blobExpiry(data),
+		}
+
+		expiresOn, err := r.client.SetBlobExpiry(ctx, config)
+		if err != nil {
+			resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to set blob expiry, got error: %s", err))
+			return
+		}
+		data.ExpiresOn = timestampValue(expiresOn)
+	} else if data.ExpiresOn.IsUnknown() {
+		data.ExpiresOn = state.ExpiresOn
+	}
+
+	// A changed legal hold or immutability policy is applied in place
+	immutabilityConfig := blobclient.BlobLeaseConfig{
+		StorageAccount: data.StorageAccount.ValueString(),
+		ContainerName:  data.ContainerName.ValueString(),
+		BlobName:       data.BlobName.ValueString(),
+	}
+	resp.Diagnostics.Append(r.applyImmutability(ctx, &data, immutabilityConfig, state.LegalHold.ValueBool(), immutabilityPolicy(state.ImmutabilityPolicy))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(r.readBlobProperties(ctx, &data)...)
+
+	// A new lease ID, rotated or re-acquired, restarts the rotation period
+	if data.LeaseID.Equal(state.LeaseID) && !state.LeaseRotatedAt.IsNull() {
+		data.LeaseRotatedAt = state.LeaseRotatedAt
+	} else {
+		rotatedAt := r.now()
+		data.LeaseRotatedAt = timestampValue(&rotatedAt)
+	}
+	r.keepLeaseRenewed(ctx, data, data.LeaseID.ValueString())
+	resp.Diagnostics.Append(storeLeaseID(ctx, &data, hideLeaseID, resp.Private)...)
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -341,61 +3539,112 @@ func (r *BlobLeaseResource) Delete(ctx context.Context, req resource.DeleteReque
 
 	// Read Terraform prior state data into the model
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	leaseID, diags := heldLeaseID(ctx, data, req.Private)
+	resp.Diagnostics.Append(diags...)
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	if data.DeletionProtection.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("deletion_protection"),
+			"Deletion Protection Enabled",
+			fmt.Sprintf("Blob %s is protected by deletion_protection, so the resource was not destroyed and its lease is still held. Set deletion_protection to false and apply before destroying it.", data.BlobName.ValueString()),
+		)
+		return
+	}
+
+	timeout := operationTimeout(data.Timeouts, "delete", defaultDeleteTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "delete", data.BlobName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
 	// Release lease and delete blob
 	config := blobclient.BlobLeaseConfig{
 		StorageAccount: data.StorageAccount.ValueString(),
 		ContainerName:  data.ContainerName.ValueString(),
 		BlobName:       data.BlobName.ValueString(),
-		LeaseID:        data.LeaseID.ValueString(),
+		LeaseID:        leaseID,
 	}
 
-	err := r.client.ReleaseBlobLease(ctx, config, true) // true = delete blob
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to release lease and delete blob, got error: %s", err))
+	// A blob acquired with acquire_existing belongs to someone else and is left in place, and
+	// one with archive_on_destroy is archived instead
+	archiveBlob := data.ArchiveOnDestroy.ValueBool()
+	deleteBlob := !data.AcquireExisting.ValueBool() && !archiveBlob
+
+	// Fail before touching the blob when it is known to be undeletable
+	if deleteBlob && isImmutable(data) {
+		resp.Diagnostics.Append(immutableBlobError(data))
 		return
 	}
-}
 
-func (r *BlobLeaseResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Import format: storage_account/container_name/blob_name
-	id := req.ID
-
-	parts := []string{}
-	// Simple split by '/'
-	start := 0
-	for i, char := range id {
-		if char == '/' {
-			if start < i {
-				parts = append(parts, id[start:i])
-			}
-			start = i + 1
+	if data.SnapshotBeforeDestroy.ValueBool() {
+		_, diags := r.snapshot(ctx, data, "snapshot_before_destroy")
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
 		}
 	}
-	if start < len(id) {
-		parts = append(parts, id[start:])
+
+	// A background renewal would keep the lease alive, or fail, after it is released
+	keepalives.replace(blobclient.BlobTargetPath(config.StorageAccount, config.ContainerName, config.BlobName), nil)
+
+	err := r.client.ReleaseBlobLease(ctx, config, deleteBlob)
+	if errors.Is(err, blobclient.ErrBlobImmutable) {
+		resp.Diagnostics.AddError("Blob Is Immutable", fmt.Sprintf("%s. Refresh to see the current legal_hold and immutability_policy of the blob.", err))
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to release lease and delete blob, got error: %s", err))
+		return
+	}
+
+	if archiveBlob {
+		err := r.client.ArchiveBlob(ctx, config)
+		if errors.Is(err, blobclient.ErrTierUnsupported) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("archive_on_destroy"),
+				"Archive Tier Unsupported",
+				fmt.Sprintf("%s. The lease was released and the blob was left in place. Set archive_on_destroy to false and apply to delete the blob on destroy instead.", err),
+			)
+			return
+		}
+		if err != nil {
+			resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to archive blob, got error: %s", err))
+			return
+		}
 	}
+}
+
+func (r *BlobLeaseResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
 
-	if len(parts) != 3 {
+	// Import format: storage_account/container_name/blob_name, where the blob name may contain
+	// slashes, optionally followed by ;blob_endpoint for a blob reached through a custom endpoint
+	storageAccount, containerName, blobName, endpoint, err := parseImportID(req.ID)
+	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unexpected Import Identifier",
-			fmt.Sprintf("Expected import identifier with format: storage_account/container_name/blob_name. Got: %s", req.ID),
+			fmt.Sprintf("Expected import identifier with format: storage_account/container_name/blob_name or storage_account/container_name/blob_name;blob_endpoint. Got: %s (%s)", req.ID, err),
 		)
 		return
 	}
-
-	storageAccount := parts[0]
-	containerName := parts[1]
-	blobName := parts[2]
+	if endpoint != "" {
+		if err := r.client.CheckBlobEndpoint(endpoint); err != nil {
+			resp.Diagnostics.AddError("Invalid Blob Endpoint", err.Error())
+			return
+		}
+	}
+	ctx = blobclient.WithBlobEndpoint(ctx, endpoint)
+	id := blobLeaseID(storageAccount, containerName, blobName)
 
 	// Check if blob exists
 	exists, err := r.client.BlobExists(ctx, storageAccount, containerName, blobName)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to check blob existence during import, got error: %s", err))
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence during import, got error: %s", err))
 		return
 	}
 
@@ -410,21 +3659,122 @@ func (r *BlobLeaseResource) ImportState(ctx context.Context, req resource.Import
 	// Get current lease state
 	leaseResult, err := r.client.GetBlobLeaseState(ctx, storageAccount, containerName, blobName)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read blob lease state during import, got error: %s", err))
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read blob lease state during import, got error: %s", err))
 		return
 	}
 
 	// Set the state
 	var data BlobLeaseResourceModel
-	data.ID = types.StringValue(req.ID)
+	data.ID = types.StringValue(id)
 	data.StorageAccount = types.StringValue(storageAccount)
+	data.OldStorageAccount = data.StorageAccount
+	data.BlobEndpoint = stringOrNull(endpoint)
 	data.ContainerName = types.StringValue(containerName)
+	data.OldContainerName = data.ContainerName
 	data.BlobName = types.StringValue(blobName)
-	data.Content = types.StringValue("") // Cannot read blob content during import
+	data.OldBlobName = data.BlobName
+	data.BlobNamePrefix = types.StringNull()
+	data.Content = types.StringNull()
 	data.BlobURL = types.StringValue(leaseResult.BlobURL)
 	data.ETag = types.StringValue(leaseResult.ETag)
 	data.LeaseState = types.StringValue(leaseResult.LeaseState)
-	data.LeaseID = types.StringValue("") // Unknown lease ID during import
+	data.LeaseStatus = leaseStatusValue(leaseResult)
+	// The kind of a lease the service does not report follows from lease_duration, so it is set
+	// first
+	data.LeaseDuration = types.Int32Value(-1)
+	data.LeaseDurationKind = leaseDurationKindValue(leaseResult, data.LeaseDuration)
+	data.LeaseID = types.StringNull() // Unknown lease ID during import
+	data.ExposeLeaseID = types.BoolValue(false)
+	data.LeaseActionOnDrift = types.StringValue(leaseActionOnDriftReacquire)
+	data.RenewDuringApply = types.BoolValue(false)
+	data.AcquireExisting = types.BoolValue(false)
+	data.AppendContent = types.ListNull(types.StringType)
+	// Gzip encoded content is taken to be compressed by content_compression
+	data.ContentCompression = types.StringValue(contentCompressionNone)
+	if leaseResult.Headers.ContentEncoding == blobclient.GzipEncoding {
+		data.ContentCompression = types.StringValue(contentCompressionGzip)
+	}
+	data.AdoptMatching = types.BoolValue(false)
+	data.RestoreIfSoftDeleted = types.BoolValue(false)
+	data.Overwrite = types.BoolValue(false)
+	data.UseETagPrecondition = types.BoolValue(false)
+	data.ForceBreak = types.BoolValue(false)
+	data.StealIfOlderThan = types.StringNull()
+	data.CreateContainer = types.BoolValue(true)
+	data.Source = types.StringNull()
+	data.CopySource = types.StringNull()
+	data.SourceMD5 = types.StringNull()
+	data.ContentMD5 = types.StringValue(leaseResult.ContentMD5)
+	data.DetectDrift = types.BoolValue(true)
+	data.RenewOnRead = types.BoolValue(false)
+	data.VerifyOwnership = types.BoolValue(true)
+	data.DeletionProtection = types.BoolValue(false)
+	data.RotationTriggers = types.MapNull(types.StringType)
+	data.RotationDays = types.Int32Null()
+	data.LeaseRotatedAt = types.StringNull()
+	data.Keepers = types.MapNull(types.StringType)
+	data.Timeouts = types.ObjectNull(timeoutsAttrTypes)
+	data.SnapshotBeforeDestroy = types.BoolValue(false)
+	data.ArchiveOnDestroy = types.BoolValue(false)
+	data.SnapshotBeforeUpdate = types.BoolValue(false)
+	data.LastSnapshotID = types.StringNull()
+	data.Metadata = types.MapNull(types.StringType)
+	data.Owner = types.StringNull()
+	data.TerraformMetadata = types.BoolValue(false)
+	data.WriteLockInfo = types.BoolValue(false)
+	data.LockInfo = types.MapNull(types.StringType)
+	data.LockInfoDocument = types.StringNull()
+	data.Tags = types.MapNull(types.StringType)
+	data.ExtraHeaders = types.MapNull(types.StringType)
+	data.AccessTier = stringOrNull(leaseResult.AccessTier)
+	data.BlobType = types.StringValue(leaseResult.BlobType)
+	data.PageBlobSize = types.Int64Null()
+	if leaseResult.BlobType == blobclient.BlobTypePage {
+		data.PageBlobSize = types.Int64Value(leaseResult.Size)
+	}
+	refreshHeaders(&data, leaseResult.Headers)
+	resp.Diagnostics.Append(refreshMetadata(ctx, &data, leaseResult.Metadata, r.client.DefaultMetadata())...)
+	if leaseResult.TagCount > 0 {
+		resp.Diagnostics.Append(r.refreshTags(ctx, &data)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Expiry = types.StringNull()
+	data.ExpiryDays = types.Int32Null()
+	refreshExpiry(&data, leaseResult)
+	refreshBlobProperties(&data, leaseResult)
+	data.VersionID = stringOrNull(leaseResult.VersionID)
+	data.EncryptionScope = stringOrNull(leaseResult.EncryptionScope)
+	data.LegalHold = types.BoolValue(leaseResult.LegalHold)
+	data.ImmutabilityPolicy = immutabilityPolicyValue(leaseResult.ImmutabilityPolicy, types.ObjectNull(immutabilityPolicyAttrTypes))
+
+	// The content of a small text block blob is downloaded, and decompressed when it is gzip
+	// encoded, so that a configuration with the same content plans no rewrite. Other content, and
+	// content that does not decompress, stays null.
+	encoding := leaseResult.Headers.ContentEncoding
+	if leaseResult.BlobType == blobclient.BlobTypeBlock && (encoding == "" || encoding == blobclient.GzipEncoding) && leaseResult.Size <= defaultMaxContentSize {
+		content, err := r.client.DownloadBlobContent(ctx, blobclient.BlobLeaseConfig{
+			StorageAccount: storageAccount,
+			ContainerName:  containerName,
+			BlobName:       blobName,
+		}, defaultMaxContentSize)
+		switch {
+		case errors.Is(err, blobclient.ErrBlobTooLarge):
+		case err != nil:
+			resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to download blob content during import, got error: %s", err))
+			return
+		default:
+			decoded, err := blobclient.DecodeContent(content.Content, encoding, defaultMaxContentSize)
+			if err == nil && utf8.Valid(decoded) {
+				data.Content = types.StringValue(string(decoded))
+			}
+		}
+	}
+
+	// When and for how long the lease was acquired is not visible on the blob
+	data.LeaseExpiresAt = types.StringNull()
+	data.RenewThreshold = types.Int32Null()
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
-}
\ No newline at end of file
+}
@@ -0,0 +1,1489 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient/blobclienttest"
+)
+
+const blobLeaseType = "blobleas_blob_lease"
+
+// otherLeaseID is a lease held by someone other than the resource under test
+const otherLeaseID = "0b5a9a5e-9a43-4c64-8f3b-3c1f0f6b2a11"
+
+// blobLeaseConfig returns the configuration of a lease on env/app.lock in the test container,
+// with extra attributes set on top
+func blobLeaseConfig(extra map[string]any) map[string]any {
+	config := map[string]any{
+		"storage_account_name":   testAccount,
+		"storage_container_name": testContainer,
+		"name":                   "env/app.lock",
+	}
+	for name, value := range extra {
+		config[name] = value
+	}
+	return config
+}
+
+func TestBlobLeaseContentUpdateKeepsLease(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	state := p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"content": "v1"}))
+	created, _ := p.server.Blob(testAccount, testContainer, "env/app.lock")
+	if created.LeaseState != "leased" || created.LeaseID == "" {
+		t.Fatalf("expected the blob to be leased, got %+v", created)
+	}
+
+	state, diags := p.read(blobLeaseType, state)
+	requireNoErrors(t, "refresh", diags)
+	updated := p.mustApply(blobLeaseType, state, blobLeaseConfig(map[string]any{"content": "v2"}))
+
+	blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock")
+	if string(blob.Content) != "v2" {
+		t.Errorf("expected the content to be updated, got %q", blob.Content)
+	}
+	if blob.LeaseID != created.LeaseID || blob.LeaseState != "leased" {
+		t.Errorf("expected lease %s to be kept, got %s (%s)", created.LeaseID, blob.LeaseID, blob.LeaseState)
+	}
+	if got, want := stringAttr(t, updated.value, "lease_id"), stringAttr(t, state.value, "lease_id"); got != want {
+		t.Errorf("expected lease_id to stay %q, got %q", want, got)
+	}
+	if got := stringAttr(t, updated.value, "etag"); got != blob.ETag {
+		t.Errorf("expected etag %s, got %s", blob.ETag, got)
+	}
+	if got := stringAttr(t, updated.value, "content_md5"); got != blob.ContentMD5 {
+		t.Errorf("expected content_md5 %s, got %s", blob.ContentMD5, got)
+	}
+}
+
+func TestBlobLeaseContentUpdateLostLease(t *testing.T) {
+	for name, refresh := range map[string]bool{
+		"seen by refresh":        true,
+		"lost after the refresh": false,
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := newTestProvider(t, nil)
+			state := p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"content": "v1"}))
+
+			if refresh {
+				p.server.SetLease(testAccount, testContainer, "env/app.lock", otherLeaseID)
+				refreshed, readDiags := p.read(blobLeaseType, state)
+				requireNoErrors(t, "refresh", readDiags)
+				state = refreshed
+			}
+			plan, diags := p.plan(blobLeaseType, state, blobLeaseConfig(map[string]any{"content": "v2"}))
+			requireNoErrors(t, "plan", diags)
+			if !refresh {
+				p.server.SetLease(testAccount, testContainer, "env/app.lock", otherLeaseID)
+			}
+
+			_, diags = p.applyPlan(blobLeaseType, plan)
+			requireError(t, diags, "Lease No Longer Held")
+
+			blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock")
+			if string(blob.Content) != "v1" || blob.LeaseID != otherLeaseID {
+				t.Errorf("expected the blob and the other lease to be left alone, got %q under %s", blob.Content, blob.LeaseID)
+			}
+		})
+	}
+}
+
+func TestBlobLeaseEncryptionScopePlan(t *testing.T) {
+	for name, tc := range map[string]struct {
+		created, updated any
+		replace          bool
+		planned          string
+	}{
+		"account default kept":      {created: nil, updated: nil, planned: ""},
+		"configured scope kept":     {created: "scope-a", updated: "scope-a", planned: "scope-a"},
+		"scope removed from config": {created: "scope-a", updated: nil, planned: "scope-a"},
+		"scope changed":             {created: "scope-a", updated: "scope-b", replace: true, planned: "scope-b"},
+		"scope added":               {created: nil, updated: "scope-b", replace: true, planned: "scope-b"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := newTestProvider(t, nil)
+			state := p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{
+				"content":          "v1",
+				"encryption_scope": tc.created,
+			}))
+
+			plan, diags := p.plan(blobLeaseType, state, blobLeaseConfig(map[string]any{
+				"content":          "v2",
+				"encryption_scope": tc.updated,
+			}))
+			requireNoErrors(t, "plan", diags)
+			if replace := len(plan.requiresReplace) > 0; replace != tc.replace {
+				t.Errorf("expected replacement %t, got %v", tc.replace, plan.requiresReplace)
+			}
+			if got := stringAttr(t, plan.planned, "encryption_scope"); got != tc.planned {
+				t.Errorf("expected encryption_scope to be planned as %q, got %q", tc.planned, got)
+			}
+		})
+	}
+}
+
+func TestBlobLeaseKeepers(t *testing.T) {
+	unknownMap := tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, tftypes.UnknownValue)
+	unknownString := tftypes.NewValue(tftypes.String, tftypes.UnknownValue)
+
+	for name, tc := range map[string]struct {
+		keepers any
+		replace []string
+	}{
+		"unchanged": {
+			keepers: map[string]string{"cluster_id": "a", "region": "westeurope"},
+		},
+		"value changed": {
+			keepers: map[string]string{"cluster_id": "b", "region": "westeurope"},
+			replace: []string{`AttributeName("keepers")`, `AttributeName("keepers").ElementKeyString("cluster_id")`},
+		},
+		"key added and removed": {
+			keepers: map[string]string{"cluster_id": "a", "zone": "1"},
+			replace: []string{`AttributeName("keepers")`, `AttributeName("keepers").ElementKeyString("region")`, `AttributeName("keepers").ElementKeyString("zone")`},
+		},
+		"removed": {
+			keepers: nil,
+			replace: []string{`AttributeName("keepers")`, `AttributeName("keepers").ElementKeyString("cluster_id")`, `AttributeName("keepers").ElementKeyString("region")`},
+		},
+		"unknown value": {
+			keepers: map[string]any{"cluster_id": unknownString, "region": "westeurope"},
+			replace: []string{`AttributeName("keepers")`, `AttributeName("keepers").ElementKeyString("cluster_id")`},
+		},
+		"unknown map": {
+			keepers: unknownMap,
+			replace: []string{`AttributeName("keepers")`},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := newTestProvider(t, nil)
+			state := p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{
+				"keepers": map[string]string{"cluster_id": "a", "region": "westeurope"},
+			}))
+			if got := stringMapAttr(t, state.value, "keepers"); got["cluster_id"] != "a" || got["region"] != "westeurope" {
+				t.Fatalf("expected keepers to be stored in state, got %v", got)
+			}
+
+			plan, diags := p.plan(blobLeaseType, state, blobLeaseConfig(map[string]any{"keepers": tc.keepers}))
+			requireNoErrors(t, "plan", diags)
+			var replace []string
+			for _, p := range plan.requiresReplace {
+				replace = append(replace, p.String())
+			}
+			slices.Sort(replace)
+			if !slices.Equal(replace, tc.replace) {
+				t.Errorf("expected replacement for %v, got %v", tc.replace, replace)
+			}
+		})
+	}
+}
+
+// Until write-only content is available, source is the documented way to keep the payload out
+// of state
+func TestBlobLeaseSourceKeepsContentOutOfState(t *testing.T) {
+	p := newTestProvider(t, nil)
+	source := filepath.Join(t.TempDir(), "payload.json")
+	if err := os.WriteFile(source, []byte(`{"secret":"s3cr3t"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	state := p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"source": source}))
+
+	blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock")
+	if string(blob.Content) != `{"secret":"s3cr3t"}` {
+		t.Errorf("expected the file to be uploaded, got %q", blob.Content)
+	}
+	if got := stringAttr(t, state.value, "source_md5"); got != blobclient.ContentMD5(blob.Content) {
+		t.Errorf("expected source_md5 %s, got %s", blobclient.ContentMD5(blob.Content), got)
+	}
+	for name, value := range attributes(t, state.value) {
+		if strings.Contains(value.String(), "s3cr3t") {
+			t.Errorf("expected the content to stay out of state, found it in %s", name)
+		}
+	}
+}
+
+// stall holds every request to the fake service until its context ends
+func stall(req *http.Request) *http.Response {
+	<-req.Context().Done()
+	return nil
+}
+
+func TestBlobLeaseTimeouts(t *testing.T) {
+	timeouts := func(operation string) map[string]any {
+		return map[string]any{operation: "50ms"}
+	}
+
+	t.Run("create", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		p.server.Intercept(stall)
+		_, diags := p.apply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"timeouts": timeouts("create")}))
+		requireError(t, diags, "The create of blob env/app.lock did not complete within 50ms")
+	})
+
+	t.Run("read", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		state := p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"timeouts": timeouts("read")}))
+		p.server.Intercept(stall)
+		_, diags := p.read(blobLeaseType, state)
+		requireError(t, diags, "The read of blob env/app.lock did not complete within 50ms")
+	})
+
+	t.Run("update", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		state := p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"timeouts": timeouts("update")}))
+		p.server.Intercept(stall)
+		_, diags := p.apply(blobLeaseType, state, blobLeaseConfig(map[string]any{"timeouts": timeouts("update"), "content": "v2"}))
+		requireError(t, diags, "The update of blob env/app.lock did not complete within 50ms")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		state := p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"timeouts": timeouts("delete")}))
+		p.server.Intercept(stall)
+		diags := p.destroy(blobLeaseType, state)
+		requireError(t, diags, "The delete of blob env/app.lock did not complete within 50ms")
+	})
+}
+
+func TestBlobLeaseTimestamps(t *testing.T) {
+	p := newTestProvider(t, nil)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	p.server.SetNow(func() time.Time { return now })
+	config := blobLeaseConfig(map[string]any{"content": "v1"})
+
+	state := p.mustApply(blobLeaseType, nil, config)
+	created := now.Format(time.RFC3339)
+	if got := stringAttr(t, state.value, "creation_time"); got != created {
+		t.Errorf("expected creation_time %s, got %s", created, got)
+	}
+	if got := stringAttr(t, state.value, "last_modified"); got != created {
+		t.Errorf("expected last_modified %s, got %s", created, got)
+	}
+
+	// A write outside Terraform that changes nothing managed is only seen in the timestamps
+	now = now.Add(time.Hour)
+	p.server.Touch(testAccount, testContainer, "env/app.lock")
+	refreshed, diags := p.read(blobLeaseType, state)
+	requireNoErrors(t, "refresh", diags)
+	if got, want := stringAttr(t, refreshed.value, "last_modified"), now.Format(time.RFC3339); got != want {
+		t.Errorf("expected refresh to update last_modified to %s, got %s", want, got)
+	}
+	if got := stringAttr(t, refreshed.value, "creation_time"); got != created {
+		t.Errorf("expected creation_time to stay %s, got %s", created, got)
+	}
+
+	plan, diags := p.plan(blobLeaseType, refreshed, config)
+	requireNoErrors(t, "plan", diags)
+	if changed := changedAttributes(t, refreshed.value, plan.planned); len(changed) > 0 {
+		t.Errorf("expected an empty plan after the timestamps changed, got changes to %v", changed)
+	}
+
+	// A change written by the apply leaves last_modified to be known after apply
+	now = now.Add(time.Hour)
+	plan, diags = p.plan(blobLeaseType, refreshed, blobLeaseConfig(map[string]any{"content": "v2"}))
+	requireNoErrors(t, "plan", diags)
+	if attrValue(t, plan.planned, "last_modified").IsKnown() {
+		t.Error("expected last_modified to be unknown in the plan of a content change")
+	}
+	if got := stringAttr(t, plan.planned, "creation_time"); got != created {
+		t.Errorf("expected creation_time to be planned as %s, got %s", created, got)
+	}
+	updated, diags := p.applyPlan(blobLeaseType, plan)
+	requireNoErrors(t, "apply", diags)
+	if got, want := stringAttr(t, updated.value, "last_modified"), now.Format(time.RFC3339); got != want {
+		t.Errorf("expected last_modified %s after the update, got %s", want, got)
+	}
+}
+
+func TestBlobLeaseImportPlan(t *testing.T) {
+	// Gzip encoded content is decompressed on import, so it matches the configured content
+	for name, tc := range map[string]map[string]any{
+		"plain": {"content": `{"owner":"team-a"}`},
+		"gzip":  {"content": `{"owner":"team-a"}`, "content_compression": "gzip"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := newTestProvider(t, nil)
+			config := blobLeaseConfig(tc)
+			created := p.mustApply(blobLeaseType, nil, config)
+			// The lease of the resource that wrote the blob is released before it is imported
+			p.server.SetLease(testAccount, testContainer, "env/app.lock", "")
+
+			imported, diags := p.importState(blobLeaseType, "acct/locks/env/app.lock")
+			requireNoErrors(t, "import", diags)
+			if got, want := stringAttr(t, imported.value, "id"), stringAttr(t, created.value, "id"); got != want {
+				t.Errorf("expected the imported id %s, got %s", want, got)
+			}
+			if got := stringAttr(t, imported.value, "content"); got != `{"owner":"team-a"}` {
+				t.Errorf("expected import to download the content, got %q", got)
+			}
+
+			// The first apply only acquires the lease: the imported lease ID is not known
+			plan, diags := p.plan(blobLeaseType, imported, config)
+			requireNoErrors(t, "plan", diags)
+			if len(plan.requiresReplace) > 0 {
+				t.Fatalf("expected the imported blob not to be replaced, got replacement for %v", plan.requiresReplace)
+			}
+			leaseAttributes := []string{"blob_url", "content_length", "etag", "immutability_policy", "last_modified", "lease_duration_kind", "lease_expires_at", "lease_id", "lease_rotated_at", "lease_state", "lease_status"}
+			for _, name := range changedAttributes(t, imported.value, plan.planned) {
+				if !slices.Contains(leaseAttributes, name) {
+					t.Errorf("expected the plan after import to only acquire the lease, got a change to %s", name)
+				}
+			}
+
+			writes := func(req *http.Request) bool {
+				return req.Method == http.MethodPut && req.URL.Query().Get("comp") == ""
+			}
+			before := p.server.Count(writes)
+			applied, diags := p.applyPlan(blobLeaseType, plan)
+			requireNoErrors(t, "apply", diags)
+			if p.server.Count(writes) != before {
+				t.Error("expected the imported blob not to be rewritten")
+			}
+			if blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock"); blob.LeaseState != "leased" {
+				t.Errorf("expected the apply to lease the imported blob, got lease state %s", blob.LeaseState)
+			}
+
+			refreshed, diags := p.read(blobLeaseType, applied)
+			requireNoErrors(t, "refresh", diags)
+			plan, diags = p.plan(blobLeaseType, refreshed, config)
+			requireNoErrors(t, "plan", diags)
+			if changed := changedAttributes(t, refreshed.value, plan.planned); len(changed) > 0 {
+				t.Errorf("expected an empty plan once the imported lease is acquired, got changes to %v", changed)
+			}
+		})
+	}
+}
+
+func TestBlobLeaseDeletionProtection(t *testing.T) {
+	all := func(*http.Request) bool { return true }
+
+	t.Run("toggle sends no request", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		state := p.mustApply(blobLeaseType, nil, blobLeaseConfig(nil))
+		state, diags := p.read(blobLeaseType, state)
+		requireNoErrors(t, "refresh", diags)
+
+		plan, diags := p.plan(blobLeaseType, state, blobLeaseConfig(map[string]any{"deletion_protection": true}))
+		requireNoErrors(t, "plan", diags)
+		before := p.server.Count(all)
+		protected, diags := p.applyPlan(blobLeaseType, plan)
+		requireNoErrors(t, "apply", diags)
+		if n := p.server.Count(all) - before; n != 0 {
+			t.Errorf("expected toggling deletion_protection to send no request, sent %d", n)
+		}
+
+		before = p.server.Count(all)
+		requireError(t, p.destroy(blobLeaseType, protected), "Deletion Protection Enabled")
+		if n := p.server.Count(all) - before; n != 0 {
+			t.Errorf("expected a protected destroy to send no request, sent %d", n)
+		}
+		if _, ok := p.server.Blob(testAccount, testContainer, "env/app.lock"); !ok {
+			t.Error("expected the protected blob to be kept")
+		}
+	})
+
+	t.Run("toggle with a lost lease re-acquires it", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		state := p.mustApply(blobLeaseType, nil, blobLeaseConfig(nil))
+		p.server.SetLease(testAccount, testContainer, "env/app.lock", "")
+		state, diags := p.read(blobLeaseType, state)
+		requireNoErrors(t, "refresh", diags)
+
+		p.mustApply(blobLeaseType, state, blobLeaseConfig(map[string]any{"deletion_protection": true}))
+		if blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock"); blob.LeaseState != "leased" {
+			t.Errorf("expected the lost lease to be re-acquired, got lease state %s", blob.LeaseState)
+		}
+	})
+}
+
+func TestBlobLeaseArchiveOnDestroy(t *testing.T) {
+	t.Run("archived", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		state := p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"archive_on_destroy": true}))
+		requireNoErrors(t, "destroy", p.destroy(blobLeaseType, state))
+
+		blob, ok := p.server.Blob(testAccount, testContainer, "env/app.lock")
+		if !ok {
+			t.Fatal("expected the blob to still exist after destroy")
+		}
+		if blob.AccessTier != "Archive" || blob.LeaseState != "available" {
+			t.Errorf("expected the blob to be archived with its lease released, got tier %s and lease state %s", blob.AccessTier, blob.LeaseState)
+		}
+	})
+
+	t.Run("tier unsupported", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		state := p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"archive_on_destroy": true}))
+		p.server.Intercept(func(req *http.Request) *http.Response {
+			if req.URL.Query().Get("comp") == "tier" {
+				return blobclienttest.Error(req, http.StatusBadRequest, "InvalidBlobTier")
+			}
+			return nil
+		})
+
+		requireError(t, p.destroy(blobLeaseType, state), "Archive Tier Unsupported")
+		if blob, ok := p.server.Blob(testAccount, testContainer, "env/app.lock"); !ok || blob.LeaseState != "available" {
+			t.Errorf("expected the blob to be left in place with its lease released, got %+v", blob)
+		}
+	})
+
+	t.Run("conflicts with acquire_existing", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		_, diags := p.plan(blobLeaseType, nil, blobLeaseConfig(map[string]any{"archive_on_destroy": true, "acquire_existing": true}))
+		requireError(t, diags, "archive_on_destroy cannot be set when acquire_existing is true")
+	})
+}
+
+func TestBlobLeaseDestroy(t *testing.T) {
+	for name, tc := range map[string]struct {
+		lease   string // the lease on the blob at destroy, "" when it was released
+		keep    bool
+		wantErr string
+	}{
+		"held":               {lease: "held"},
+		"released elsewhere": {lease: ""},
+		"taken over":         {lease: otherLeaseID, keep: true, wantErr: "Unable to release lease and delete blob"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := newTestProvider(t, nil)
+			state := p.mustApply(blobLeaseType, nil, blobLeaseConfig(nil))
+			if tc.lease != "held" {
+				p.server.SetLease(testAccount, testContainer, "env/app.lock", tc.lease)
+			}
+
+			diags := p.destroy(blobLeaseType, state)
+			if tc.wantErr != "" {
+				requireError(t, diags, tc.wantErr)
+			} else {
+				requireNoErrors(t, "destroy", diags)
+			}
+			blob, ok := p.server.Blob(testAccount, testContainer, "env/app.lock")
+			if ok != tc.keep {
+				t.Fatalf("expected the blob to be kept %t, got %t", tc.keep, ok)
+			}
+			if tc.keep && blob.LeaseID != tc.lease {
+				t.Errorf("expected the other lease to be left alone, got %s", blob.LeaseID)
+			}
+		})
+	}
+}
+
+func TestBlobLeaseETagPrecondition(t *testing.T) {
+	t.Run("blob changed between refresh and apply", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		config := blobLeaseConfig(map[string]any{"content": "v1", "use_etag_precondition": true})
+		state := p.mustApply(blobLeaseType, nil, config)
+		state, diags := p.read(blobLeaseType, state)
+		requireNoErrors(t, "refresh", diags)
+
+		config["content"] = "v2"
+		plan, diags := p.plan(blobLeaseType, state, config)
+		requireNoErrors(t, "plan", diags)
+		p.server.PutBlob(testAccount, testContainer, "env/app.lock", []byte("written elsewhere"))
+
+		_, diags = p.applyPlan(blobLeaseType, plan)
+		requireError(t, diags, "Blob Changed Remotely")
+		requireError(t, diags, "Run terraform plan again to refresh")
+		if blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock"); string(blob.Content) != "written elsewhere" {
+			t.Errorf("expected the remote write to be kept, got %q", blob.Content)
+		}
+	})
+
+	t.Run("unchanged blob is updated", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		config := blobLeaseConfig(map[string]any{"content": "v1", "use_etag_precondition": true})
+		state := p.mustApply(blobLeaseType, nil, config)
+		state, diags := p.read(blobLeaseType, state)
+		requireNoErrors(t, "refresh", diags)
+
+		config["content"] = "v2"
+		p.mustApply(blobLeaseType, state, config)
+		if blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock"); string(blob.Content) != "v2" {
+			t.Errorf("expected the content to be updated, got %q", blob.Content)
+		}
+	})
+
+	t.Run("create does not overwrite an existing blob", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		p.server.PutBlob(testAccount, testContainer, "env/app.lock", []byte("written elsewhere"))
+
+		_, diags := p.apply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"content": "v1", "use_etag_precondition": true, "overwrite": true}))
+		requireNoErrors(t, "apply with overwrite", diags)
+		p.server.SetLease(testAccount, testContainer, "env/app.lock", "")
+
+		_, diags = p.apply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"content": "v2", "use_etag_precondition": true}))
+		requireError(t, diags, "Blob Already Exists")
+		if blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock"); string(blob.Content) != "v1" {
+			t.Errorf("expected the existing blob to be kept, got %q", blob.Content)
+		}
+	})
+}
+
+func TestBlobLeaseEmptyContent(t *testing.T) {
+	for name, tc := range map[string]struct {
+		content any
+		want    string
+	}{
+		"null writes the default content": {content: nil, want: defaultContent},
+		"empty string writes zero bytes":  {content: "", want: ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := newTestProvider(t, nil)
+			config := blobLeaseConfig(map[string]any{"content": tc.content})
+
+			plan, diags := p.plan(blobLeaseType, nil, config)
+			requireNoErrors(t, "plan", diags)
+			if got := stringAttr(t, plan.planned, "content"); got != tc.want {
+				t.Errorf("expected content to be planned as %q, got %q", tc.want, got)
+			}
+			state, diags := p.applyPlan(blobLeaseType, plan)
+			requireNoErrors(t, "apply", diags)
+
+			blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock")
+			if string(blob.Content) != tc.want {
+				t.Errorf("expected the blob to hold %q, got %q", tc.want, blob.Content)
+			}
+			if got := stringAttr(t, state.value, "content_md5"); got != blobclient.ContentMD5([]byte(tc.want)) {
+				t.Errorf("expected content_md5 %s, got %s", blobclient.ContentMD5([]byte(tc.want)), got)
+			}
+			var length big.Float
+			if err := attrValue(t, state.value, "content_length").As(&length); err != nil {
+				t.Fatal(err)
+			}
+			if n, _ := length.Int64(); n != int64(len(tc.want)) {
+				t.Errorf("expected content_length %d, got %d", len(tc.want), n)
+			}
+
+			// The content, empty or not, is not reported as drifted by a refresh
+			refreshed, diags := p.read(blobLeaseType, state)
+			requireNoErrors(t, "refresh", diags)
+			plan, diags = p.plan(blobLeaseType, refreshed, config)
+			requireNoErrors(t, "plan", diags)
+			if changed := changedAttributes(t, refreshed.value, plan.planned); len(changed) > 0 {
+				t.Errorf("expected an empty plan after refresh, got changes to %v", changed)
+			}
+		})
+	}
+}
+
+func TestBlobLeaseDefaultMetadata(t *testing.T) {
+	p := newTestProvider(t, map[string]any{
+		"default_metadata": map[string]string{"team": "platform", "env": "prod"},
+	})
+	config := blobLeaseConfig(map[string]any{
+		"metadata": map[string]string{"env": "dev", "app": "api"},
+	})
+	state := p.mustApply(blobLeaseType, nil, config)
+
+	// The resource metadata wins for env, and team is only in the defaults
+	requireBlobMetadata := func(want map[string]string) {
+		t.Helper()
+		blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock")
+		if len(blob.Metadata) != len(want) {
+			t.Errorf("expected blob metadata %v, got %v", want, blob.Metadata)
+		}
+		for key, value := range want {
+			if got, ok := lookupFold(blob.Metadata, key); !ok || blob.Metadata[got] != value {
+				t.Errorf("expected blob metadata %s=%q, got %v", key, value, blob.Metadata)
+			}
+		}
+	}
+	requireBlobMetadata(map[string]string{"team": "platform", "env": "dev", "app": "api"})
+
+	// State keeps only the resource metadata, so the merge shows no drift
+	refreshed, diags := p.read(blobLeaseType, state)
+	requireNoErrors(t, "refresh", diags)
+	if got := stringMapAttr(t, refreshed.value, "metadata"); !maps.Equal(got, map[string]string{"env": "dev", "app": "api"}) {
+		t.Errorf("expected only the resource metadata in state, got %v", got)
+	}
+	plan, diags := p.plan(blobLeaseType, refreshed, config)
+	requireNoErrors(t, "plan", diags)
+	if changed := changedAttributes(t, refreshed.value, plan.planned); len(changed) > 0 {
+		t.Errorf("expected an empty plan after refresh, got changes to %v", changed)
+	}
+
+	// Removing env from the resource falls back to the default, which is kept on update
+	config["metadata"] = map[string]string{"app": "api"}
+	p.mustApply(blobLeaseType, refreshed, config)
+	requireBlobMetadata(map[string]string{"team": "platform", "env": "prod", "app": "api"})
+}
+
+func TestBlobLeaseTerraformMetadata(t *testing.T) {
+	t.Setenv("TFC_WORKSPACE_NAME", "")
+	t.Setenv("TF_WORKSPACE", "staging")
+	t.Setenv("TFC_RUN_ID", "run-abc123")
+
+	p := newTestProvider(t, nil)
+	config := blobLeaseConfig(map[string]any{
+		"write_terraform_metadata": true,
+		"metadata":                 map[string]string{"team": "platform", "tf_run_id": "pinned"},
+	})
+	state := p.mustApply(blobLeaseType, nil, config)
+
+	// The Terraform metadata is merged below the configured metadata, which keeps its tf_run_id
+	blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock")
+	want := map[string]string{
+		"team":                "platform",
+		"tf_run_id":           "pinned",
+		"tf_workspace":        "staging",
+		"tf_provider_version": "test",
+	}
+	for key, value := range want {
+		if got, ok := lookupFold(blob.Metadata, key); !ok || blob.Metadata[got] != value {
+			t.Errorf("expected blob metadata %s=%q, got %v", key, value, blob.Metadata)
+		}
+	}
+	acquiredKey, ok := lookupFold(blob.Metadata, tfAcquiredAtMetadataKey)
+	if !ok {
+		t.Fatalf("expected blob metadata %s, got %v", tfAcquiredAtMetadataKey, blob.Metadata)
+	}
+	if _, err := time.Parse(time.RFC3339, blob.Metadata[acquiredKey]); err != nil {
+		t.Errorf("expected %s to be an RFC 3339 timestamp: %s", tfAcquiredAtMetadataKey, err)
+	}
+
+	// The provider-managed keys are not read into metadata, so a refresh shows no drift
+	refreshed, diags := p.read(blobLeaseType, state)
+	requireNoErrors(t, "refresh", diags)
+	metadata := stringMapAttr(t, refreshed.value, "metadata")
+	if len(metadata) != 2 || metadata["team"] != "platform" || metadata["tf_run_id"] != "pinned" {
+		t.Errorf("expected only the configured metadata in state, got %v", metadata)
+	}
+	plan, diags := p.plan(blobLeaseType, refreshed, config)
+	requireNoErrors(t, "plan", diags)
+	if changed := changedAttributes(t, refreshed.value, plan.planned); len(changed) > 0 {
+		t.Errorf("expected an empty plan after refresh, got changes to %v", changed)
+	}
+
+	// Turning write_terraform_metadata off removes the keys it wrote, but not the configured ones
+	config["write_terraform_metadata"] = false
+	p.mustApply(blobLeaseType, refreshed, config)
+	blob, _ = p.server.Blob(testAccount, testContainer, "env/app.lock")
+	for _, key := range terraformMetadataKeys {
+		if found, ok := lookupFold(blob.Metadata, key); ok && key != tfRunIDMetadataKey {
+			t.Errorf("expected %s to be removed, got %s=%q", key, found, blob.Metadata[found])
+		}
+	}
+	if key, ok := lookupFold(blob.Metadata, tfRunIDMetadataKey); !ok || blob.Metadata[key] != "pinned" {
+		t.Errorf("expected the configured %s to be kept, got %v", tfRunIDMetadataKey, blob.Metadata)
+	}
+}
+
+func TestBlobLeaseRestoreIfSoftDeleted(t *testing.T) {
+	undeletes := func(req *http.Request) bool { return req.URL.Query().Get("comp") == "undelete" }
+	writes := func(req *http.Request) bool {
+		return req.Method == http.MethodPut && req.URL.Query().Get("comp") == ""
+	}
+
+	for name, tc := range map[string]struct {
+		softDelete  bool
+		probeFails  bool
+		restore     bool
+		content     string
+		wantRestore bool
+		wantWrite   bool   // whether create writes the content
+		wantWarning string // part of the Restored Soft-Deleted Blob warning, "" for none
+	}{
+		"restored with matching content": {
+			softDelete: true, restore: true, content: "v1",
+			wantRestore: true, wantWarning: "was not rewritten",
+		},
+		"restored with differing content": {
+			softDelete: true, restore: true, content: "v2",
+			wantRestore: true, wantWrite: true, wantWarning: "was overwritten",
+		},
+		"flag off": {
+			softDelete: true, content: "v1", wantWrite: true,
+		},
+		"account without soft delete": {
+			restore: true, content: "v1", wantWrite: true,
+		},
+		"probe fails": {
+			softDelete: true, probeFails: true, restore: true, content: "v1", wantWrite: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := newTestProvider(t, nil)
+			p.server.SoftDelete = tc.softDelete
+			state := p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"content": "v1"}))
+			requireNoErrors(t, "destroy", p.destroy(blobLeaseType, state))
+
+			// Listing deleted blobs is denied without list permission on the container
+			if tc.probeFails {
+				p.server.Intercept(func(req *http.Request) *http.Response {
+					if req.URL.Query().Get("comp") == "list" {
+						return blobclienttest.Error(req, http.StatusForbidden, "AuthorizationPermissionMismatch")
+					}
+					return nil
+				})
+			}
+
+			beforeUndeletes, beforeWrites := p.server.Count(undeletes), p.server.Count(writes)
+			state, diags := p.apply(blobLeaseType, nil, blobLeaseConfig(map[string]any{
+				"content":                 tc.content,
+				"restore_if_soft_deleted": tc.restore,
+			}))
+			requireNoErrors(t, "create", diags)
+
+			if restored := p.server.Count(undeletes) > beforeUndeletes; restored != tc.wantRestore {
+				t.Errorf("expected undelete %t, got %t", tc.wantRestore, restored)
+			}
+			if wrote := p.server.Count(writes) > beforeWrites; wrote != tc.wantWrite {
+				t.Errorf("expected the content to be written %t, got %t", tc.wantWrite, wrote)
+			}
+			detail, warned := findWarning(diags, "Restored Soft-Deleted Blob")
+			switch {
+			case tc.wantWarning == "" && warned:
+				t.Errorf("expected no restore warning, got: %s", detail)
+			case tc.wantWarning != "" && !strings.Contains(detail, tc.wantWarning):
+				t.Errorf("expected a restore warning containing %q, got:%s", tc.wantWarning, formatDiagnostics(diags))
+			}
+			if _, ok := p.server.DeletedBlob(testAccount, testContainer, "env/app.lock"); ok == tc.wantRestore && tc.softDelete {
+				t.Errorf("expected the soft-deleted blob to be kept %t, got %t", !tc.wantRestore, ok)
+			}
+
+			blob, ok := p.server.Blob(testAccount, testContainer, "env/app.lock")
+			if !ok {
+				t.Fatal("expected a live blob")
+			}
+			if string(blob.Content) != tc.content {
+				t.Errorf("expected the blob to hold %q, got %q", tc.content, blob.Content)
+			}
+			if blob.LeaseID == "" || stringAttr(t, state.value, "lease_state") != "leased" {
+				t.Errorf("expected create to lease the blob, got lease %q", blob.LeaseID)
+			}
+		})
+	}
+}
+
+func TestRenewalDue(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	expiresIn := func(d time.Duration) types.String {
+		return types.StringValue(now.Add(d).Format(time.RFC3339))
+	}
+
+	for name, tc := range map[string]struct {
+		expiresAt types.String
+		threshold types.Int32
+		want      bool
+	}{
+		"infinite lease":    {expiresAt: types.StringNull(), threshold: types.Int32Value(30)},
+		"expiry unknown":    {expiresAt: types.StringUnknown(), threshold: types.Int32Value(30)},
+		"no threshold":      {expiresAt: expiresIn(5 * time.Second), threshold: types.Int32Null()},
+		"below threshold":   {expiresAt: expiresIn(10 * time.Second), threshold: types.Int32Value(30), want: true},
+		"already expired":   {expiresAt: expiresIn(-time.Minute), threshold: types.Int32Value(30), want: true},
+		"at threshold":      {expiresAt: expiresIn(30 * time.Second), threshold: types.Int32Value(30)},
+		"above threshold":   {expiresAt: expiresIn(45 * time.Second), threshold: types.Int32Value(30)},
+		"unparsable expiry": {expiresAt: types.StringValue("soon"), threshold: types.Int32Value(30)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := renewalDue(tc.expiresAt, tc.threshold, now); got != tc.want {
+				t.Errorf("expected renewalDue %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestBlobLeaseRenewThresholdPlan(t *testing.T) {
+	p := newTestProvider(t, nil)
+	config := blobLeaseConfig(map[string]any{
+		"lease_duration":          30,
+		"renew_threshold_seconds": 10,
+		"expose_lease_id":         true,
+	})
+	applied := p.mustApply(blobLeaseType, nil, config)
+
+	r := &BlobLeaseResource{}
+	var schemaResp resource.SchemaResponse
+	r.Schema(context.Background(), resource.SchemaRequest{}, &schemaResp)
+	configValue := objectValue(t, p.resourceSchema(blobLeaseType).ValueType(), config)
+	expiry := time.Date(2026, 3, 1, 12, 0, 30, 0, time.UTC)
+
+	for name, tc := range map[string]struct {
+		expiresAt any // lease_expires_at in state
+		now       time.Time
+		wantRenew bool
+	}{
+		"lapses after the threshold":  {expiresAt: expiry.Format(time.RFC3339), now: expiry.Add(-20 * time.Second)},
+		"lapses within the threshold": {expiresAt: expiry.Format(time.RFC3339), now: expiry.Add(-5 * time.Second), wantRenew: true},
+		"infinite lease":              {expiresAt: nil, now: expiry.Add(time.Hour)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			stateValue := withAttributes(t, applied.value, map[string]any{"lease_expires_at": tc.expiresAt})
+
+			r.now = func() time.Time { return tc.now }
+			req := resource.ModifyPlanRequest{
+				Config: tfsdk.Config{Raw: configValue, Schema: schemaResp.Schema},
+				State:  tfsdk.State{Raw: stateValue, Schema: schemaResp.Schema},
+				Plan:   tfsdk.Plan{Raw: stateValue.Copy(), Schema: schemaResp.Schema},
+			}
+			resp := resource.ModifyPlanResponse{Plan: req.Plan}
+			r.ModifyPlan(context.Background(), req, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("plan failed: %v", resp.Diagnostics)
+			}
+
+			// A due renewal leaves the attributes the apply changes unknown
+			for _, name := range []string{"lease_state", "etag", "lease_expires_at"} {
+				if unknown := !attrValue(t, resp.Plan.Raw, name).IsKnown(); unknown != tc.wantRenew {
+					t.Errorf("expected %s unknown %t, got %t", name, tc.wantRenew, unknown)
+				}
+			}
+			if !attrValue(t, resp.Plan.Raw, "lease_id").Equal(attrValue(t, stateValue, "lease_id")) {
+				t.Error("expected a renewal to keep the lease ID")
+			}
+		})
+	}
+}
+
+func TestJSONEqual(t *testing.T) {
+	for name, tc := range map[string]struct {
+		a, b string
+		want bool
+	}{
+		"identical":             {a: `{"holder":"ci","ttl":30}`, b: `{"holder":"ci","ttl":30}`, want: true},
+		"reordered keys":        {a: `{"holder":"ci","ttl":30}`, b: `{"ttl":30,"holder":"ci"}`, want: true},
+		"reordered nested keys": {a: `{"lock":{"a":1,"b":[true,null]}}`, b: `{"lock":{"b":[true,null],"a":1}}`, want: true},
+		"whitespace":            {a: `{"holder":"ci"}`, b: "{\n  \"holder\": \"ci\"\n}\n", want: true},
+		"trailing whitespace":   {a: `[1,2]`, b: "[1,2] \t\n", want: true},
+		"decimal point":         {a: `{"ttl":30}`, b: `{"ttl":30.0}`, want: true},
+		"exponent":              {a: `{"ttl":30}`, b: `{"ttl":3e1}`, want: true},
+		"negative zero":         {a: `0`, b: `-0`, want: true},
+		"escaped string":        {a: `"é"`, b: `"é"`, want: true},
+		"different value":       {a: `{"holder":"ci"}`, b: `{"holder":"cd"}`},
+		"different number":      {a: `{"ttl":30}`, b: `{"ttl":31}`},
+		"large integers":        {a: `{"run":12345678901234567890}`, b: `{"run":12345678901234567891}`},
+		"number and string":     {a: `{"ttl":30}`, b: `{"ttl":"30"}`},
+		"extra key":             {a: `{"holder":"ci"}`, b: `{"holder":"ci","ttl":30}`},
+		"reordered array":       {a: `[1,2]`, b: `[2,1]`},
+		"null and missing":      {a: `{"holder":null}`, b: `{}`},
+		"invalid":               {a: `{"holder":"ci"}`, b: `{"holder":`},
+		"both invalid":          {a: `not json`, b: `not json`},
+		"trailing data":         {a: `{"holder":"ci"}`, b: `{"holder":"ci"} {}`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := jsonEqual(tc.a, tc.b); got != tc.want {
+				t.Errorf("expected jsonEqual(%s, %s) %t, got %t", tc.a, tc.b, tc.want, got)
+			}
+			if got := jsonEqual(tc.b, tc.a); got != tc.want {
+				t.Errorf("expected jsonEqual(%s, %s) %t, got %t", tc.b, tc.a, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestBlobLeaseJSONContentPlan(t *testing.T) {
+	p := newTestProvider(t, nil)
+	config := blobLeaseConfig(map[string]any{"content_format": "json", "content": `{"holder":"ci","ttl":30}`})
+	state := p.mustApply(blobLeaseType, nil, config)
+
+	// A re-render with other key order, whitespace and number formatting is not a change
+	config["content"] = "{\n  \"ttl\": 30.0,\n  \"holder\": \"ci\"\n}\n"
+	plan, diags := p.plan(blobLeaseType, state, config)
+	requireNoErrors(t, "plan", diags)
+	if changed := changedAttributes(t, state.value, plan.planned); len(changed) > 0 {
+		t.Errorf("expected an empty plan for equal JSON, got changes to %v", changed)
+	}
+
+	config["content"] = `{"holder":"ci","ttl":60}`
+	plan, diags = p.plan(blobLeaseType, state, config)
+	requireNoErrors(t, "plan", diags)
+	if !slices.Contains(changedAttributes(t, state.value, plan.planned), "content") {
+		t.Error("expected a different value to change content")
+	}
+
+	config["content"] = `{"holder":`
+	_, diags = p.plan(blobLeaseType, state, config)
+	requireError(t, diags, "Invalid JSON Content")
+}
+
+func TestLeaseIDPlanModifier(t *testing.T) {
+	p := newTestProvider(t, nil)
+	config := blobLeaseConfig(map[string]any{"rotation_days": 90, "expose_lease_id": true})
+	applied := p.mustApply(blobLeaseType, nil, config)
+	leaseID := stringAttr(t, applied.value, "lease_id")
+
+	var schemaResp resource.SchemaResponse
+	(&BlobLeaseResource{}).Schema(context.Background(), resource.SchemaRequest{}, &schemaResp)
+	rotatedAt := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	for name, tc := range map[string]struct {
+		now        time.Time
+		state      map[string]any // attributes of state, on top of the applied one
+		plan       map[string]any // attributes of the plan, on top of state
+		config     map[string]any // attributes of the configuration
+		wantRotate bool
+	}{
+		"rotation not due": {now: rotatedAt.Add(89 * 24 * time.Hour)},
+		"rotation due":     {now: rotatedAt.Add(90 * 24 * time.Hour), wantRotate: true},
+		"rotation overdue": {now: rotatedAt.Add(400 * 24 * time.Hour), wantRotate: true},
+		"rotation_days unset": {
+			now:    rotatedAt.Add(400 * 24 * time.Hour),
+			state:  map[string]any{"rotation_days": nil},
+			plan:   map[string]any{"rotation_days": nil},
+			config: map[string]any{"rotation_days": nil},
+		},
+		"rotation time unknown": {
+			now:   rotatedAt.Add(400 * 24 * time.Hour),
+			state: map[string]any{"lease_rotated_at": nil},
+		},
+		"rotation trigger changed": {
+			now:        rotatedAt.Add(time.Hour),
+			plan:       map[string]any{"rotation_triggers": map[string]string{"credentials": "v2"}},
+			config:     map[string]any{"rotation_triggers": map[string]string{"credentials": "v2"}},
+			wantRotate: true,
+		},
+		"lease lost": {
+			now:        rotatedAt.Add(time.Hour),
+			state:      map[string]any{"lease_state": "broken"},
+			wantRotate: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			stateAttrs := map[string]any{"lease_rotated_at": rotatedAt.Format(time.RFC3339)}
+			for attribute, value := range tc.state {
+				stateAttrs[attribute] = value
+			}
+			stateValue := withAttributes(t, applied.value, stateAttrs)
+			planValue := withAttributes(t, stateValue, tc.plan)
+			configAttrs := maps.Clone(config)
+			for attribute, value := range tc.config {
+				configAttrs[attribute] = value
+			}
+			configValue := objectValue(t, p.resourceSchema(blobLeaseType).ValueType(), configAttrs)
+
+			// Terraform plans a computed attribute that is not configured as unknown when anything
+			// changed; the modifier decides whether the state value is kept
+			req := planmodifier.StringRequest{
+				Path:        path.Root("lease_id"),
+				Config:      tfsdk.Config{Raw: configValue, Schema: schemaResp.Schema},
+				ConfigValue: types.StringNull(),
+				State:       tfsdk.State{Raw: stateValue, Schema: schemaResp.Schema},
+				StateValue:  types.StringValue(leaseID),
+				Plan:        tfsdk.Plan{Raw: planValue, Schema: schemaResp.Schema},
+				PlanValue:   types.StringUnknown(),
+			}
+			resp := planmodifier.StringResponse{PlanValue: req.PlanValue}
+			leaseIDPlanModifier{now: func() time.Time { return tc.now }}.PlanModifyString(context.Background(), req, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("plan failed: %v", resp.Diagnostics)
+			}
+
+			switch {
+			case tc.wantRotate && !resp.PlanValue.IsUnknown():
+				t.Errorf("expected a new lease ID to be planned, got %s", resp.PlanValue)
+			case !tc.wantRotate && !resp.PlanValue.Equal(req.StateValue):
+				t.Errorf("expected the lease ID to be kept, got %s", resp.PlanValue)
+			}
+		})
+	}
+
+	// A configured lease ID is planned as configured
+	req := planmodifier.StringRequest{
+		ConfigValue: types.StringValue(otherLeaseID),
+		State:       tfsdk.State{Raw: applied.value, Schema: schemaResp.Schema},
+		StateValue:  types.StringValue(leaseID),
+		Plan:        tfsdk.Plan{Raw: applied.value, Schema: schemaResp.Schema},
+		PlanValue:   types.StringValue(otherLeaseID),
+	}
+	resp := planmodifier.StringResponse{PlanValue: req.PlanValue}
+	leaseIDPlanModifier{now: time.Now}.PlanModifyString(context.Background(), req, &resp)
+	if resp.PlanValue.ValueString() != otherLeaseID {
+		t.Errorf("expected the configured lease ID to be planned, got %s", resp.PlanValue)
+	}
+}
+
+func TestBlobLeaseRotationDays(t *testing.T) {
+	p := newTestProvider(t, nil)
+	config := blobLeaseConfig(map[string]any{"rotation_days": 90, "expose_lease_id": true})
+	state := p.mustApply(blobLeaseType, nil, config)
+	leaseID := stringAttr(t, state.value, "lease_id")
+
+	// Before rotation is due, a refresh and plan find nothing to do
+	refreshed, diags := p.read(blobLeaseType, state)
+	requireNoErrors(t, "refresh", diags)
+	plan, diags := p.plan(blobLeaseType, refreshed, config)
+	requireNoErrors(t, "plan", diags)
+	if changed := changedAttributes(t, refreshed.value, plan.planned); len(changed) > 0 {
+		t.Errorf("expected an empty plan while rotation is not due, got changes to %v", changed)
+	}
+
+	// Once the lease ID is 90 days old, the apply changes the lease to a new ID
+	old := time.Now().Add(-91 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	refreshed.value = withAttributes(t, refreshed.value, map[string]any{"lease_rotated_at": old})
+	plan, diags = p.plan(blobLeaseType, refreshed, config)
+	requireNoErrors(t, "plan", diags)
+	if attrValue(t, plan.planned, "lease_id").IsKnown() || attrValue(t, plan.planned, "lease_rotated_at").IsKnown() {
+		t.Fatal("expected a due rotation to plan a new lease_id and lease_rotated_at")
+	}
+	if len(plan.requiresReplace) > 0 {
+		t.Errorf("expected a rotation in place, got replacement for %v", plan.requiresReplace)
+	}
+	rotated, diags := p.applyPlan(blobLeaseType, plan)
+	requireNoErrors(t, "apply", diags)
+
+	newID := stringAttr(t, rotated.value, "lease_id")
+	if newID == leaseID {
+		t.Error("expected a new lease ID")
+	}
+	blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock")
+	if blob.LeaseID != newID {
+		t.Errorf("expected the blob to be leased with %s, got %s", newID, blob.LeaseID)
+	}
+	if stringAttr(t, rotated.value, "lease_rotated_at") == old {
+		t.Error("expected lease_rotated_at to be updated")
+	}
+}
+
+func TestBlobLeaseFixedDuration(t *testing.T) {
+	p := newTestProvider(t, nil)
+	now := time.Now().UTC().Truncate(time.Second)
+	p.server.SetNow(func() time.Time { return now })
+	config := blobLeaseConfig(map[string]any{"lease_duration": 15, "expose_lease_id": true})
+
+	// lease_expires_at is counted from the clock of the provider, not that of the service
+	requireExpiresIn15s := func(state *resourceState) {
+		t.Helper()
+		expiresAt, err := time.Parse(time.RFC3339, stringAttr(t, state.value, "lease_expires_at"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lapse := time.Until(expiresAt); lapse <= 0 || lapse > 15*time.Second {
+			t.Errorf("expected lease_expires_at within 15 seconds, got %s", expiresAt)
+		}
+	}
+
+	// The lease is acquired for 15 seconds, and state records when it lapses
+	created := p.mustApply(blobLeaseType, nil, config)
+	leaseID := stringAttr(t, created.value, "lease_id")
+	if got := stringAttr(t, created.value, "lease_duration_kind"); got != "fixed" {
+		t.Errorf("expected lease_duration_kind fixed, got %q", got)
+	}
+	requireExpiresIn15s(created)
+
+	// Once it lapses unrenewed, refresh reports the lease expired
+	now = now.Add(20 * time.Second)
+	refreshed, diags := p.read(blobLeaseType, created)
+	requireNoErrors(t, "refresh", diags)
+	for attribute, want := range map[string]string{"lease_state": "expired", "lease_status": "unlocked"} {
+		if got := stringAttr(t, refreshed.value, attribute); got != want {
+			t.Errorf("expected %s %q after the lease expired, got %q", attribute, want, got)
+		}
+	}
+
+	// The next apply warns and re-acquires it in place with the same ID and duration
+	plan, diags := p.plan(blobLeaseType, refreshed, config)
+	requireNoErrors(t, "plan", diags)
+	if _, warned := findWarning(diags, "Lease Not Held"); !warned {
+		t.Errorf("expected a Lease Not Held warning, got:%s", formatDiagnostics(diags))
+	}
+	if len(plan.requiresReplace) > 0 {
+		t.Fatalf("expected the expired lease to be re-acquired in place, got replacement for %v", plan.requiresReplace)
+	}
+	reacquired, diags := p.applyPlan(blobLeaseType, plan)
+	requireNoErrors(t, "apply", diags)
+	blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock")
+	if blob.LeaseState != "leased" || blob.LeaseID != leaseID {
+		t.Errorf("expected the blob to be leased again with %s, got %s (%s)", leaseID, blob.LeaseID, blob.LeaseState)
+	}
+	for attribute, want := range map[string]string{"lease_state": "leased", "lease_duration_kind": "fixed", "lease_id": leaseID} {
+		if got := stringAttr(t, reacquired.value, attribute); got != want {
+			t.Errorf("expected %s %q after the apply, got %q", attribute, want, got)
+		}
+	}
+	requireExpiresIn15s(reacquired)
+}
+
+func TestLeaseDurationKindValue(t *testing.T) {
+	for name, tc := range map[string]struct {
+		result   blobclient.BlobLeaseResult
+		duration types.Int32
+		want     string
+	}{
+		"reported by the service": {
+			result:   blobclient.BlobLeaseResult{LeaseState: "leased", LeaseStatus: "locked", LeaseDuration: "fixed"},
+			duration: types.Int32Value(-1),
+			want:     "fixed",
+		},
+		"not leased": {
+			result:   blobclient.BlobLeaseResult{LeaseState: "available", LeaseStatus: "unlocked"},
+			duration: types.Int32Value(-1),
+			want:     "",
+		},
+		"acquired infinite": {
+			result:   blobclient.BlobLeaseResult{LeaseState: "leased"},
+			duration: types.Int32Value(-1),
+			want:     "infinite",
+		},
+		"acquired fixed": {
+			result:   blobclient.BlobLeaseResult{LeaseState: "leased"},
+			duration: types.Int32Value(30),
+			want:     "fixed",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := leaseDurationKindValue(&tc.result, tc.duration); got.ValueString() != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got.ValueString())
+			}
+		})
+	}
+}
+
+func TestBlobLeaseImportLeasedBlob(t *testing.T) {
+	p := newTestProvider(t, nil)
+	p.server.PutBlob(testAccount, testContainer, "env/app.lock", []byte("locked"))
+	p.server.SetLease(testAccount, testContainer, "env/app.lock", otherLeaseID)
+
+	state, diags := p.importState(blobLeaseType, "acct/locks/env/app.lock")
+	requireNoErrors(t, "import", diags)
+	for attribute, want := range map[string]string{"lease_state": "leased", "lease_duration_kind": "infinite"} {
+		if got := stringAttr(t, state.value, attribute); got != want {
+			t.Errorf("expected %s %q, got %q", attribute, want, got)
+		}
+	}
+	var duration big.Float
+	if err := attrValue(t, state.value, "lease_duration").As(&duration); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := duration.Int64(); n != -1 {
+		t.Errorf("expected lease_duration -1, got %d", n)
+	}
+}
+
+func TestBlobLeaseImportNestedName(t *testing.T) {
+	for _, blobName := range []string{"env/prod/eu/app.lock", "env//app.lock"} {
+		t.Run(blobName, func(t *testing.T) {
+			p := newTestProvider(t, nil)
+			p.server.PutBlob(testAccount, testContainer, blobName, []byte("locked"))
+
+			state, diags := p.importState(blobLeaseType, "acct/locks/"+blobName)
+			requireNoErrors(t, "import", diags)
+			for attribute, want := range map[string]string{"name": blobName, "id": "acct/locks/" + blobName} {
+				if got := stringAttr(t, state.value, attribute); got != want {
+					t.Errorf("expected %s %q, got %q", attribute, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestBlobLeaseVerifyOwnership(t *testing.T) {
+	for name, tc := range map[string]struct {
+		verify    bool
+		wantState string
+	}{
+		"verified":     {verify: true, wantState: leaseStateLeasedByOther},
+		"not verified": {verify: false, wantState: "leased"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := newTestProvider(t, nil)
+			config := blobLeaseConfig(map[string]any{"verify_ownership": tc.verify})
+			state := p.mustApply(blobLeaseType, nil, config)
+
+			// Someone breaks the lease and acquires their own
+			p.server.SetLease(testAccount, testContainer, "env/app.lock", otherLeaseID)
+			renewals := func(req *http.Request) bool { return req.Header.Get("X-Ms-Lease-Action") == "renew" }
+			before := p.server.Count(renewals)
+			refreshed, diags := p.read(blobLeaseType, state)
+			requireNoErrors(t, "refresh", diags)
+			if got := stringAttr(t, refreshed.value, "lease_state"); got != tc.wantState {
+				t.Errorf("expected lease_state %s, got %s", tc.wantState, got)
+			}
+			if checked := p.server.Count(renewals) > before; checked != tc.verify {
+				t.Errorf("expected the ownership check to be made %t, got %t", tc.verify, checked)
+			}
+		})
+	}
+}
+
+func TestBlobLeaseActionOnDrift(t *testing.T) {
+	writes := func(req *http.Request) bool {
+		return req.Method == http.MethodPut && req.URL.Query().Get("comp") == ""
+	}
+	mutations := func(req *http.Request) bool { return req.Method != http.MethodGet && req.Method != http.MethodHead }
+
+	// breakLease breaks the lease out-of-band, as another client would
+	breakLease := func(t *testing.T, p *testProvider) {
+		t.Helper()
+		client, err := p.server.NewClient(blobclient.ClientOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = client.BreakBlobLease(context.Background(), blobclient.BlobLeaseConfig{
+			StorageAccount: testAccount,
+			ContainerName:  testContainer,
+			BlobName:       "env/app.lock",
+		}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		action      string
+		afterPlan   bool // whether the lease is broken after plan rather than before refresh
+		wantErr     string
+		wantLeased  bool
+		wantNoApply bool // whether the plan is empty, so Terraform does not apply
+	}{
+		"reacquire":            {action: "reacquire", wantLeased: true},
+		"fail":                 {action: "fail", wantErr: "Lease Lost Outside Terraform"},
+		"ignore":               {action: "ignore", wantNoApply: true},
+		"reacquire after plan": {action: "reacquire", afterPlan: true, wantLeased: true},
+		"fail after plan":      {action: "fail", afterPlan: true, wantErr: "Lease Lost Outside Terraform"},
+		"ignore after plan":    {action: "ignore", afterPlan: true, wantErr: "Lease No Longer Held"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := newTestProvider(t, nil)
+			config := blobLeaseConfig(map[string]any{"lease_action_on_drift": tc.action, "content": "locked"})
+			state := p.mustApply(blobLeaseType, nil, config)
+
+			if !tc.afterPlan {
+				breakLease(t, p)
+			}
+			refreshed, diags := p.read(blobLeaseType, state)
+			requireNoErrors(t, "refresh", diags)
+			if tc.afterPlan {
+				// A metadata change makes the plan apply while the lease is believed held
+				config["metadata"] = map[string]string{"team": "platform"}
+			}
+			plan, diags := p.plan(blobLeaseType, refreshed, config)
+			requireNoErrors(t, "plan", diags)
+			if tc.afterPlan {
+				breakLease(t, p)
+			}
+
+			if tc.wantNoApply {
+				if changed := changedAttributes(t, refreshed.value, plan.planned); len(changed) > 0 {
+					t.Fatalf("expected an empty plan, got changes to %v", changed)
+				}
+				if _, warned := findWarning(diags, "Lease Not Held"); !warned {
+					t.Errorf("expected a Lease Not Held warning, got:%s", formatDiagnostics(diags))
+				}
+				if got := stringAttr(t, refreshed.value, "lease_state"); got != "broken" {
+					t.Errorf("expected state to record the broken lease, got %s", got)
+				}
+				// The next refresh and plan stay empty
+				again, diags := p.read(blobLeaseType, refreshed)
+				requireNoErrors(t, "refresh", diags)
+				plan, diags = p.plan(blobLeaseType, again, config)
+				requireNoErrors(t, "plan", diags)
+				if changed := changedAttributes(t, again.value, plan.planned); len(changed) > 0 {
+					t.Errorf("expected the plan to stay empty, got changes to %v", changed)
+				}
+				return
+			}
+
+			beforeWrites, beforeMutations := p.server.Count(writes), p.server.Count(mutations)
+			applied, diags := p.applyPlan(blobLeaseType, plan)
+			if tc.wantErr != "" {
+				requireError(t, diags, tc.wantErr)
+				if n := p.server.Count(mutations) - beforeMutations; n != 0 {
+					t.Errorf("expected no request that changes the blob or lease, got %d", n)
+				}
+				return
+			}
+			requireNoErrors(t, "apply", diags)
+			if p.server.Count(writes) != beforeWrites {
+				t.Error("expected the lease to be re-acquired without rewriting the blob")
+			}
+			blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock")
+			if tc.wantLeased && (blob.LeaseID == "" || stringAttr(t, applied.value, "lease_state") != "leased") {
+				t.Errorf("expected the lease to be re-acquired, got lease %q and state %s", blob.LeaseID, stringAttr(t, applied.value, "lease_state"))
+			}
+		})
+	}
+}
+
+// leaseIDOf returns the lease ID recorded in state: lease_id when it is exposed, and the lease ID
+// kept in private state otherwise
+func leaseIDOf(t *testing.T, state *resourceState) string {
+	t.Helper()
+	if !attrValue(t, state.value, "lease_id").IsNull() {
+		return stringAttr(t, state.value, "lease_id")
+	}
+	var private map[string][]byte
+	var leaseID string
+	if err := json.Unmarshal(state.private, &private); err != nil {
+		t.Fatalf("invalid private state: %s", err)
+	}
+	if err := json.Unmarshal(private[leaseIDPrivateKey], &leaseID); err != nil {
+		t.Fatalf("no lease ID in private state: %s", err)
+	}
+	return leaseID
+}
+
+func TestBlobLeaseFailedUpdateRecordsNewLease(t *testing.T) {
+	const leaseA, leaseB = "3f2504e0-4f89-11d3-9a0c-0305e82c3301", "9b2c1f2e-6a3d-4e5f-8a7b-1c2d3e4f5a6b"
+	failMetadata := func(req *http.Request) *http.Response {
+		if req.URL.Query().Get("comp") == "metadata" {
+			return blobclienttest.Error(req, http.StatusBadRequest, "InvalidHeaderValue")
+		}
+		return nil
+	}
+
+	// Each case creates the lease with config and then changes config, the state or the blob, so
+	// that the update leases the blob with a new ID: wantID, or any new one when it is empty
+	for name, tc := range map[string]struct {
+		config  map[string]any
+		prepare func(t *testing.T, p *testProvider, state *resourceState, config map[string]any)
+		wantID  string
+	}{
+		"changed lease_id": {
+			config: map[string]any{"lease_id": leaseA},
+			prepare: func(t *testing.T, p *testProvider, state *resourceState, config map[string]any) {
+				config["lease_id"] = leaseB
+			},
+			wantID: leaseB,
+		},
+		"rotation trigger": {
+			config: map[string]any{"rotation_triggers": map[string]string{"version": "1"}},
+			prepare: func(t *testing.T, p *testProvider, state *resourceState, config map[string]any) {
+				config["rotation_triggers"] = map[string]string{"version": "2"}
+			},
+		},
+		"lease taken over": {
+			// The blob was last written two days ago, so a lease taken over since is stolen back
+			config: map[string]any{"steal_if_older_than": "1h"},
+			prepare: func(t *testing.T, p *testProvider, state *resourceState, config map[string]any) {
+				p.server.SetLease(testAccount, testContainer, "env/app.lock", otherLeaseID)
+			},
+		},
+		"rotation due": {
+			config: map[string]any{"rotation_days": 90},
+			prepare: func(t *testing.T, p *testProvider, state *resourceState, config map[string]any) {
+				old := time.Now().Add(-91 * 24 * time.Hour).UTC().Format(time.RFC3339)
+				state.value = withAttributes(t, state.value, map[string]any{"lease_rotated_at": old})
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := newTestProvider(t, nil)
+			created := time.Now().Add(-48 * time.Hour)
+			p.server.SetNow(func() time.Time { return created })
+			config := blobLeaseConfig(tc.config)
+			state := p.mustApply(blobLeaseType, nil, config)
+			oldID := leaseIDOf(t, state)
+
+			tc.prepare(t, p, state, config)
+			config["metadata"] = map[string]string{"team": "platform"}
+			p.server.Intercept(failMetadata)
+			failed, diags := p.apply(blobLeaseType, state, config)
+			requireError(t, diags, "Unable to set blob metadata")
+
+			blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock")
+			want := tc.wantID
+			if want == "" {
+				want = blob.LeaseID
+			}
+			if blob.LeaseID != want || want == oldID || want == "" {
+				t.Fatalf("expected the blob to be leased with a new lease ID %s, got %q", want, blob.LeaseID)
+			}
+			if failed == nil {
+				t.Fatal("expected the failed update to save state")
+			}
+			if got := leaseIDOf(t, failed); got != want {
+				t.Errorf("expected state to record lease ID %s, got %s", want, got)
+			}
+
+			// The next apply continues with the recorded lease
+			p.server.Intercept(nil)
+			applied := p.mustApply(blobLeaseType, failed, config)
+			if got := leaseIDOf(t, applied); got != want {
+				t.Errorf("expected lease ID %s after the next apply, got %s", want, got)
+			}
+			if got := stringMapAttr(t, applied.value, "metadata"); got["team"] != "platform" {
+				t.Errorf("expected the metadata to be applied, got %v", got)
+			}
+		})
+	}
+}
+
+func TestBlobLeaseFailedCreateRecordsLease(t *testing.T) {
+	// The fake service does not support legal holds, so applying one always fails
+	p := newTestProvider(t, nil)
+	failed, diags := p.apply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"legal_hold": true}))
+	requireError(t, diags, "Unable to set legal hold")
+	if failed == nil {
+		t.Fatal("expected the failed create to save state")
+	}
+	if !failed.value.IsFullyKnown() {
+		t.Errorf("expected the saved state to have no unknown values, got %v", failed.value)
+	}
+
+	blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock")
+	if got := leaseIDOf(t, failed); got == "" || got != blob.LeaseID {
+		t.Errorf("expected state to record the lease %q of the blob, got %q", blob.LeaseID, got)
+	}
+	var legalHold bool
+	if err := attrValue(t, failed.value, "legal_hold").As(&legalHold); err != nil {
+		t.Fatal(err)
+	}
+	if legalHold {
+		t.Error("expected state to record that the legal hold was not set")
+	}
+
+	// Destroying the tainted resource removes the blob with its lease
+	requireNoErrors(t, "destroy", p.destroy(blobLeaseType, failed))
+	if _, ok := p.server.Blob(testAccount, testContainer, "env/app.lock"); ok {
+		t.Error("expected the blob to be deleted")
+	}
+}
+
+func TestBlobLeaseFailedAcquireExistingRecordsLease(t *testing.T) {
+	p := newTestProvider(t, nil)
+	p.server.PutBlob(testAccount, testContainer, "env/app.lock", []byte("written elsewhere"))
+	p.server.Intercept(func(req *http.Request) *http.Response {
+		if req.URL.Query().Get("comp") == "metadata" {
+			return blobclienttest.Error(req, http.StatusBadRequest, "InvalidHeaderValue")
+		}
+		return nil
+	})
+
+	failed, diags := p.apply(blobLeaseType, nil, blobLeaseConfig(map[string]any{
+		"acquire_existing": true,
+		"metadata":         map[string]string{"env": "dev"},
+	}))
+	requireError(t, diags, "Unable to set blob metadata")
+	if failed == nil {
+		t.Fatal("expected the failed create to save state")
+	}
+	if !failed.value.IsFullyKnown() {
+		t.Errorf("expected the saved state to have no unknown values, got %v", failed.value)
+	}
+	blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock")
+	if blob.LeaseState != "leased" {
+		t.Fatalf("expected the blob to be leased, got %q", blob.LeaseState)
+	}
+	if got := leaseIDOf(t, failed); got != blob.LeaseID {
+		t.Errorf("expected state to record the lease %q of the blob, got %q", blob.LeaseID, got)
+	}
+
+	// Destroying the tainted resource releases the lease and leaves the acquired blob in place
+	p.server.Intercept(nil)
+	requireNoErrors(t, "destroy", p.destroy(blobLeaseType, failed))
+	blob, ok := p.server.Blob(testAccount, testContainer, "env/app.lock")
+	if !ok {
+		t.Fatal("expected the acquired blob to be kept")
+	}
+	if blob.LeaseState != "available" {
+		t.Errorf("expected the lease to be released, got lease state %q", blob.LeaseState)
+	}
+}
@@ -0,0 +1,630 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &BlobLeaseSetResource{}
+var _ resource.ResourceWithModifyPlan = &BlobLeaseSetResource{}
+
+// leaseStateBlobMissing is recorded in lease_states for a blob that no longer exists
+const leaseStateBlobMissing = "missing"
+
+// blobNamesValidator ensures a set holds at least one valid blob name
+type blobNamesValidator struct{}
+
+func (v blobNamesValidator) Description(ctx context.Context) string {
+	return "set must hold at least one valid blob name"
+}
+
+func (v blobNamesValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v blobNamesValidator) ValidateSet(ctx context.Context, req validator.SetRequest, resp *validator.SetResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	elements := req.ConfigValue.Elements()
+	if len(elements) == 0 {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid Blob Names", fmt.Sprintf("%s %s", req.Path, v.Description(ctx)))
+		return
+	}
+
+	nameValidator := validators.BlobName()
+	for _, element := range elements {
+		name, ok := element.(types.String)
+		if !ok || name.IsNull() || name.IsUnknown() {
+			continue
+		}
+		nameResp := &validator.StringResponse{}
+		nameValidator.ValidateString(ctx, validator.StringRequest{
+			Path:        req.Path.AtSetValue(element),
+			ConfigValue: name,
+			Config:      req.Config,
+		}, nameResp)
+		resp.Diagnostics.Append(nameResp.Diagnostics...)
+	}
+}
+
+func NewBlobLeaseSetResource() resource.Resource {
+	return &BlobLeaseSetResource{}
+}
+
+// BlobLeaseSetResource manages the leases on a set of existing blobs of one container, acquired
+// together. Like blobleas_lease, it never writes or deletes the blobs.
+type BlobLeaseSetResource struct {
+	client *blobclient.AzureBlobLeaseClient
+}
+
+// BlobLeaseSetResourceModel describes the resource data model.
+type BlobLeaseSetResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	StorageAccount types.String `tfsdk:"storage_account_name"`
+	BlobEndpoint   types.String `tfsdk:"blob_endpoint"`
+	ContainerName  types.String `tfsdk:"storage_container_name"`
+	BlobNames      types.Set    `tfsdk:"names"`
+	Atomic         types.Bool   `tfsdk:"atomic"`
+	LeaseDuration  types.Int32  `tfsdk:"lease_duration"`
+	AcquireTimeout types.String `tfsdk:"acquire_timeout"`
+	LeaseIDs       types.Map    `tfsdk:"lease_ids"`
+	LeaseStates    types.Map    `tfsdk:"lease_states"`
+	Timeouts       types.Object `tfsdk:"timeouts"`
+}
+
+func (r *BlobLeaseSetResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_blob_lease_set"
+}
+
+func (r *BlobLeaseSetResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Leases on a set of existing blobs in one Azure Storage container, acquired together. The blobs are never written or deleted",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name. Changing it forces a new resource",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, e.g. a private endpoint or sovereign cloud URL, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`. Changing it forces a new resource",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"storage_container_name": schema.StringAttribute{
+				MarkdownDescription: "The container of the blobs. Changing it forces a new resource",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"names": schema.SetAttribute{
+				MarkdownDescription: "The names of the existing blobs to lease. Blobs added to the set are leased and blobs removed from it are released in place",
+				Required:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.Set{
+					blobNamesValidator{},
+				},
+			},
+			"atomic": schema.BoolAttribute{
+				MarkdownDescription: "Whether the leases are acquired all or nothing: when any blob cannot be leased, the leases acquired on the others are released again and the apply fails. Otherwise the acquired leases are kept. Defaults to `true`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"lease_duration": schema.Int32Attribute{
+				MarkdownDescription: "The lease duration in seconds. Use -1 for infinite leases (default), or 15-60 for time-limited leases. Changing it forces a new resource",
+				Optional:            true,
+				Computed:            true,
+				Default:             int32default.StaticInt32(-1),
+				PlanModifiers: []planmodifier.Int32{
+					int32planmodifier.RequiresReplace(),
+				},
+				Validators: []validator.Int32{
+					leaseDurationValidator{},
+				},
+			},
+			"acquire_timeout": schema.StringAttribute{
+				MarkdownDescription: "How long to wait for a lease held by someone else to be released before failing, e.g. `5m`. Unset or `0s` fails immediately",
+				Optional:            true,
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"timeouts": timeoutsAttribute(),
+			"lease_ids": schema.MapAttribute{
+				MarkdownDescription: "The ID of the lease held on each blob, by blob name",
+				Computed:            true,
+				Sensitive:           true,
+				ElementType:         types.StringType,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"lease_states": schema.MapAttribute{
+				MarkdownDescription: "The current lease state of each blob, by blob name: a lease state of the service, `leased-by-other` when it is leased with a different lease ID, or `missing` when the blob no longer exists",
+				Computed:            true,
+				ElementType:         types.StringType,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *BlobLeaseSetResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// ModifyPlan plans new lease IDs and states when blobs are added to or removed from the set, and
+// when refresh shows a lease is no longer held, so a lost lease is taken back by the next apply
+func (r *BlobLeaseSetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	// Whether an http endpoint is allowed is only known once the provider is configured
+	var endpoint types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("blob_endpoint"), &endpoint)...)
+	if r.client != nil && !endpoint.IsNull() && !endpoint.IsUnknown() {
+		if err := r.client.CheckBlobEndpoint(endpoint.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+		}
+	}
+	if resp.Diagnostics.HasError() || req.State.Raw.IsNull() {
+		return
+	}
+
+	var plan, state BlobLeaseSetResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var lost []string
+	var leaseStates map[string]string
+	if !plan.BlobNames.IsUnknown() {
+		names, leaseIDs, states, diags := leaseSetValues(ctx, plan.BlobNames, state)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		leaseStates = states
+		for _, name := range names {
+			if _, ok := leaseIDs[name]; !ok || leaseStates[name] != "leased" {
+				lost = append(lost, name)
+			}
+		}
+		if plan.BlobNames.Equal(state.BlobNames) && len(lost) == 0 {
+			return
+		}
+	}
+
+	// The apply acquires or releases leases, which changes these attributes
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("lease_ids"), types.MapUnknown(types.StringType))...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("lease_states"), types.MapUnknown(types.StringType))...)
+
+	// Blobs newly added to the set are expected to be unleased
+	var held []string
+	for _, name := range lost {
+		if observed, ok := leaseStates[name]; ok {
+			held = append(held, fmt.Sprintf("%s (observed lease state: %s)", name, observed))
+		}
+	}
+	if len(held) > 0 {
+		resp.Diagnostics.AddWarning(
+			"Leases Not Held",
+			fmt.Sprintf("The leases on these blobs of container %s/%s are not held by this resource: %s. The apply will try to acquire them, without touching the blobs.",
+				state.StorageAccount.ValueString(), state.ContainerName.ValueString(), strings.Join(held, ", ")),
+		)
+	}
+}
+
+// leaseSetValues returns the sorted blob names of names together with the lease IDs and lease
+// states recorded in data
+func leaseSetValues(ctx context.Context, names types.Set, data BlobLeaseSetResourceModel) ([]string, map[string]string, map[string]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var blobNames []string
+	leaseIDs := map[string]string{}
+	leaseStates := map[string]string{}
+
+	diags.Append(names.ElementsAs(ctx, &blobNames, false)...)
+	if !data.LeaseIDs.IsNull() && !data.LeaseIDs.IsUnknown() {
+		diags.Append(data.LeaseIDs.ElementsAs(ctx, &leaseIDs, false)...)
+	}
+	if !data.LeaseStates.IsNull() && !data.LeaseStates.IsUnknown() {
+		diags.Append(data.LeaseStates.ElementsAs(ctx, &leaseStates, false)...)
+	}
+	slices.Sort(blobNames)
+	return blobNames, leaseIDs, leaseStates, diags
+}
+
+// setLeaseValues records leaseIDs and leaseStates in data
+func setLeaseValues(ctx context.Context, data *BlobLeaseSetResourceModel, leaseIDs, leaseStates map[string]string) diag.Diagnostics {
+	var diags, d diag.Diagnostics
+	data.LeaseIDs, d = types.MapValueFrom(ctx, types.StringType, leaseIDs)
+	diags.Append(d...)
+	data.LeaseStates, d = types.MapValueFrom(ctx, types.StringType, leaseStates)
+	diags.Append(d...)
+	return diags
+}
+
+// leaseSetConfig builds the client lease config of blobName in data, with the lease held or
+// proposed as leaseID
+func leaseSetConfig(data BlobLeaseSetResourceModel, blobName, leaseID string) blobclient.BlobLeaseConfig {
+	// The value was validated as a duration at plan time
+	timeout, _ := time.ParseDuration(data.AcquireTimeout.ValueString())
+	return blobclient.BlobLeaseConfig{
+		StorageAccount: data.StorageAccount.ValueString(),
+		ContainerName:  data.ContainerName.ValueString(),
+		BlobName:       blobName,
+		LeaseID:        leaseID,
+		LeaseDuration:  data.LeaseDuration.ValueInt32(),
+		AcquireTimeout: timeout,
+	}
+}
+
+// acquireLeases acquires the leases of configs and records them in leaseIDs and leaseStates. When
+// some leases cannot be acquired, the error lists the blobs that were contended and those that
+// failed otherwise, and tells what became of the leases that were acquired.
+func (r *BlobLeaseSetResource) acquireLeases(ctx context.Context, configs []blobclient.BlobLeaseConfig, atomic bool, leaseIDs, leaseStates map[string]string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(configs) == 0 {
+		return diags
+	}
+
+	results, err := r.client.AcquireBlobLeases(ctx, configs, atomic)
+	for name, result := range results {
+		leaseIDs[name] = result.LeaseID
+		leaseStates[name] = result.LeaseState
+	}
+
+	var bulkErr *blobclient.BulkLeaseError
+	if !errors.As(err, &bulkErr) {
+		if err != nil {
+			diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to acquire blob leases, got error: %s", err))
+		}
+		return diags
+	}
+
+	detail := fmt.Sprintf("The lease on %d of %d blobs could not be acquired.", len(bulkErr.Failed), len(configs))
+	if contended := bulkErr.Contended(); len(contended) > 0 {
+		detail += fmt.Sprintf(" Leased by someone else: %s.", strings.Join(contended, ", "))
+	}
+	var failed []string
+	for name, err := range bulkErr.Failed {
+		if !blobclient.IsLeaseContended(err) {
+			failed = append(failed, fmt.Sprintf("%s (%s)", name, err))
+		}
+	}
+	if len(failed) > 0 {
+		slices.Sort(failed)
+		detail += fmt.Sprintf(" Failed: %s.", strings.Join(failed, "; "))
+	}
+
+	switch {
+	case !atomic:
+		detail += " The leases acquired on the other blobs are kept."
+	case len(bulkErr.RollbackFailed) > 0:
+		var stuck []string
+		for name := range bulkErr.RollbackFailed {
+			stuck = append(stuck, name)
+		}
+		slices.Sort(stuck)
+		detail += fmt.Sprintf(" Releasing the leases acquired on the other blobs failed for %s; these blobs are still leased with the lease IDs recorded in lease_ids and must be released, e.g. by destroying this resource, or broken, e.g. with blobleas_lease_break.", strings.Join(stuck, ", "))
+	default:
+		detail += " The leases acquired on the other blobs were released again."
+	}
+	diags.AddAttributeError(path.Root("names"), "Blob Leases Not Acquired", detail)
+	return diags
+}
+
+// releaseLeases releases the leases of configs, leaving the blobs in place, and reports each lease
+// that could not be released
+func (r *BlobLeaseSetResource) releaseLeases(ctx context.Context, configs []blobclient.BlobLeaseConfig) diag.Diagnostics {
+	var diags diag.Diagnostics
+	failed := r.client.ReleaseBlobLeases(ctx, configs)
+	for _, config := range configs {
+		if err, ok := failed[config.BlobName]; ok {
+			diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to release lease on blob %s, got error: %s", config.BlobName, err))
+		}
+	}
+	return diags
+}
+
+func (r *BlobLeaseSetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data BlobLeaseSetResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := operationTimeout(data.Timeouts, "create", defaultCreateTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "create", data.ContainerName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
+	names, leaseIDs, leaseStates, diags := leaseSetValues(ctx, data.BlobNames, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	configs := make([]blobclient.BlobLeaseConfig, 0, len(names))
+	for _, name := range names {
+		configs = append(configs, leaseSetConfig(data, name, uuid.New().String()))
+	}
+	resp.Diagnostics.Append(r.acquireLeases(ctx, configs, data.Atomic.ValueBool(), leaseIDs, leaseStates)...)
+
+	// Leases kept after a partial acquire are recorded, so that destroying the tainted resource
+	// releases them
+	if len(leaseIDs) == 0 {
+		return
+	}
+	data.ID = types.StringValue(uuid.New().String())
+	resp.Diagnostics.Append(setLeaseValues(ctx, &data, leaseIDs, leaseStates)...)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BlobLeaseSetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data BlobLeaseSetResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := operationTimeout(data.Timeouts, "read", defaultReadTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "read", data.ContainerName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
+	names, leaseIDs, _, diags := leaseSetValues(ctx, data.BlobNames, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	leaseStates := map[string]string{}
+	for _, name := range names {
+		exists, err := r.client.BlobExists(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), name)
+		if err != nil {
+			resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check existence of blob %s, got error: %s", name, err))
+			return
+		}
+		if !exists {
+			// Blob was deleted outside of Terraform, which also ended its lease
+			leaseStates[name] = leaseStateBlobMissing
+			continue
+		}
+
+		leaseResult, err := r.client.GetBlobLeaseState(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), name)
+		if err != nil {
+			resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read lease state of blob %s, got error: %s", name, err))
+			return
+		}
+
+		// A read served by the secondary endpoint may lag the primary, so keep the prior state
+		if leaseResult.StaleRead {
+			resp.Diagnostics.AddWarning(
+				"Stale Read From Secondary Endpoint",
+				fmt.Sprintf("The primary endpoint of storage account %s is unavailable; lease states for the blobs of container %s were read from the secondary endpoint and may be out of date. Keeping the previously known state.",
+					data.StorageAccount.ValueString(), data.ContainerName.ValueString()),
+			)
+			resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+			return
+		}
+
+		// A leased blob may be leased by someone else, which only a renewal with the lease ID tells
+		leaseState := leaseResult.LeaseState
+		if leaseState == "leased" {
+			held := false
+			if leaseID, ok := leaseIDs[name]; ok {
+				held, err = r.client.ProbeBlobLease(ctx, leaseSetConfig(data, name, leaseID))
+				if err != nil {
+					resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check lease on blob %s, got error: %s", name, err))
+					return
+				}
+			}
+			if !held {
+				leaseState = leaseStateLeasedByOther
+			}
+		}
+		leaseStates[name] = leaseState
+	}
+	resp.Diagnostics.Append(setLeaseValues(ctx, &data, leaseIDs, leaseStates)...)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BlobLeaseSetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state BlobLeaseSetResourceModel
+
+	// Read Terraform plan and prior state data into the models
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := operationTimeout(plan.Timeouts, "update", defaultUpdateTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "update", plan.ContainerName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, plan.BlobEndpoint.ValueString())
+
+	names, heldIDs, heldStates, diags := leaseSetValues(ctx, plan.BlobNames, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Leases still held are kept; added blobs and lost leases are acquired, a lost lease with the
+	// ID it was held with
+	leaseIDs := map[string]string{}
+	leaseStates := map[string]string{}
+	var acquire []blobclient.BlobLeaseConfig
+	for _, name := range names {
+		leaseID, ok := heldIDs[name]
+		if ok && heldStates[name] == "leased" {
+			leaseIDs[name] = leaseID
+			leaseStates[name] = heldStates[name]
+			continue
+		}
+		if !ok {
+			leaseID = uuid.New().String()
+		}
+		acquire = append(acquire, leaseSetConfig(plan, name, leaseID))
+	}
+
+	// An atomic update that cannot acquire every lease changes nothing, so the prior state is kept.
+	// Leases that its rollback could not release are added to it, so that they are released with
+	// the resource.
+	diags = r.acquireLeases(ctx, acquire, plan.Atomic.ValueBool(), leaseIDs, leaseStates)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() && plan.Atomic.ValueBool() {
+		priorNames, _, _, d := leaseSetValues(ctx, state.BlobNames, state)
+		resp.Diagnostics.Append(d...)
+		unreleased := false
+		for _, config := range acquire {
+			if leaseID, ok := leaseIDs[config.BlobName]; ok {
+				unreleased = true
+				heldIDs[config.BlobName], heldStates[config.BlobName] = leaseID, leaseStates[config.BlobName]
+				if !slices.Contains(priorNames, config.BlobName) {
+					priorNames = append(priorNames, config.BlobName)
+				}
+			}
+		}
+		if !unreleased {
+			return
+		}
+		state.BlobNames, d = types.SetValueFrom(ctx, types.StringType, priorNames)
+		resp.Diagnostics.Append(d...)
+		resp.Diagnostics.Append(setLeaseValues(ctx, &state, heldIDs, heldStates)...)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		return
+	}
+
+	// Blobs removed from the set are released; leases that were lost are not someone else's to
+	// release
+	var release []blobclient.BlobLeaseConfig
+	for name, leaseID := range heldIDs {
+		if !slices.Contains(names, name) && heldStates[name] == "leased" {
+			release = append(release, leaseSetConfig(state, name, leaseID))
+		}
+	}
+	resp.Diagnostics.Append(r.releaseLeases(ctx, release)...)
+
+	resp.Diagnostics.Append(setLeaseValues(ctx, &plan, leaseIDs, leaseStates)...)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *BlobLeaseSetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data BlobLeaseSetResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := operationTimeout(data.Timeouts, "delete", defaultDeleteTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "delete", data.ContainerName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
+	_, leaseIDs, leaseStates, diags := leaseSetValues(ctx, data.BlobNames, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Release the leases only; the blobs are left in place. Missing blobs and leases someone else
+	// took over are skipped.
+	var release []blobclient.BlobLeaseConfig
+	for name, leaseID := range leaseIDs {
+		if leaseStates[name] == "leased" {
+			release = append(release, leaseSetConfig(data, name, leaseID))
+		}
+	}
+	resp.Diagnostics.Append(r.releaseLeases(ctx, release)...)
+}
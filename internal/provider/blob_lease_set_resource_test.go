@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient/blobclienttest"
+)
+
+const blobLeaseSetType = "blobleas_blob_lease_set"
+
+// leaseSetBlobs are the blobs leased by the set under test
+var leaseSetBlobs = []string{"env/a.lock", "env/b.lock", "env/c.lock"}
+
+// blobLeaseSetConfig returns the configuration of a lease set on names in the test container,
+// with extra attributes set on top
+func blobLeaseSetConfig(names []string, extra map[string]any) map[string]any {
+	config := map[string]any{
+		"storage_account_name":   testAccount,
+		"storage_container_name": testContainer,
+		"names":                  names,
+	}
+	for name, value := range extra {
+		config[name] = value
+	}
+	return config
+}
+
+// newLeaseSetProvider returns a test provider whose container holds the lease set blobs, with
+// env/c.lock leased by someone else when contended is set
+func newLeaseSetProvider(t *testing.T, contended bool) *testProvider {
+	t.Helper()
+	p := newTestProvider(t, nil)
+	for _, name := range leaseSetBlobs {
+		p.server.PutBlob(testAccount, testContainer, name, []byte("lock"))
+	}
+	if contended {
+		p.server.SetLease(testAccount, testContainer, "env/c.lock", otherLeaseID)
+	}
+	return p
+}
+
+// failRelease makes releasing the lease on blobName fail
+func failRelease(p *testProvider, blobName string) {
+	p.server.Intercept(func(req *http.Request) *http.Response {
+		if req.URL.Query().Get("comp") == "lease" && req.Header.Get("X-Ms-Lease-Action") == "release" && strings.HasSuffix(req.URL.Path, "/"+blobName) {
+			return blobclienttest.Error(req, http.StatusBadRequest, "InvalidHeaderValue")
+		}
+		return nil
+	})
+}
+
+// requireLeasedWith fails the test unless each blob of leaseIDs is leased with its lease ID, and
+// every other lease set blob but env/c.lock is not leased
+func requireLeasedWith(t *testing.T, p *testProvider, leaseIDs map[string]string) {
+	t.Helper()
+	for _, name := range leaseSetBlobs {
+		blob, _ := p.server.Blob(testAccount, testContainer, name)
+		leaseID, ok := leaseIDs[name]
+		switch {
+		case ok && (blob.LeaseState != "leased" || blob.LeaseID != leaseID):
+			t.Errorf("expected %s to be leased with %s, got %s %q", name, leaseID, blob.LeaseState, blob.LeaseID)
+		case !ok && name != "env/c.lock" && blob.LeaseState == "leased":
+			t.Errorf("expected %s not to be leased, got leased with %q", name, blob.LeaseID)
+		}
+	}
+}
+
+// leasedBlobs returns the sorted blob names of leaseIDs
+func leasedBlobs(leaseIDs map[string]string) []string {
+	names := make([]string, 0, len(leaseIDs))
+	for name := range leaseIDs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestBlobLeaseSetCreate(t *testing.T) {
+	t.Run("all leases acquired", func(t *testing.T) {
+		p := newLeaseSetProvider(t, false)
+		state := p.mustApply(blobLeaseSetType, nil, blobLeaseSetConfig(leaseSetBlobs, nil))
+
+		leaseIDs := stringMapAttr(t, state.value, "lease_ids")
+		if got := leasedBlobs(leaseIDs); !slices.Equal(got, leaseSetBlobs) {
+			t.Errorf("expected lease IDs for %v, got %v", leaseSetBlobs, got)
+		}
+		for name, leaseState := range stringMapAttr(t, state.value, "lease_states") {
+			if leaseState != "leased" {
+				t.Errorf("expected %s to be leased, got %s", name, leaseState)
+			}
+		}
+		requireLeasedWith(t, p, leaseIDs)
+
+		requireNoErrors(t, "destroy", p.destroy(blobLeaseSetType, state))
+		requireLeasedWith(t, p, nil)
+	})
+
+	t.Run("atomic partial failure rolls back", func(t *testing.T) {
+		p := newLeaseSetProvider(t, true)
+		state, diags := p.apply(blobLeaseSetType, nil, blobLeaseSetConfig(leaseSetBlobs, nil))
+		requireError(t, diags, "Leased by someone else: env/c.lock.")
+		requireError(t, diags, "The leases acquired on the other blobs were released again.")
+		if state != nil {
+			t.Errorf("expected no state, got %v", state.value)
+		}
+		requireLeasedWith(t, p, nil)
+
+		blob, _ := p.server.Blob(testAccount, testContainer, "env/c.lock")
+		if blob.LeaseID != otherLeaseID {
+			t.Errorf("expected the contended lease to be left alone, got %q", blob.LeaseID)
+		}
+	})
+
+	t.Run("non-atomic partial failure keeps the acquired leases", func(t *testing.T) {
+		p := newLeaseSetProvider(t, true)
+		state, diags := p.apply(blobLeaseSetType, nil, blobLeaseSetConfig(leaseSetBlobs, map[string]any{"atomic": false}))
+		requireError(t, diags, "Leased by someone else: env/c.lock.")
+		requireError(t, diags, "The leases acquired on the other blobs are kept.")
+		if state == nil {
+			t.Fatal("expected the kept leases to be saved in state")
+		}
+
+		leaseIDs := stringMapAttr(t, state.value, "lease_ids")
+		if got := leasedBlobs(leaseIDs); !slices.Equal(got, []string{"env/a.lock", "env/b.lock"}) {
+			t.Errorf("expected lease IDs for the acquired blobs, got %v", got)
+		}
+		requireLeasedWith(t, p, leaseIDs)
+
+		requireNoErrors(t, "destroy", p.destroy(blobLeaseSetType, state))
+		requireLeasedWith(t, p, nil)
+	})
+
+	t.Run("failed rollback records the leases still held", func(t *testing.T) {
+		p := newLeaseSetProvider(t, true)
+		failRelease(p, "env/b.lock")
+		state, diags := p.apply(blobLeaseSetType, nil, blobLeaseSetConfig(leaseSetBlobs, nil))
+		requireError(t, diags, "Releasing the leases acquired on the other blobs failed for env/b.lock")
+		if state == nil {
+			t.Fatal("expected the lease still held to be saved in state")
+		}
+
+		leaseIDs := stringMapAttr(t, state.value, "lease_ids")
+		if got := leasedBlobs(leaseIDs); !slices.Equal(got, []string{"env/b.lock"}) {
+			t.Errorf("expected the lease ID of env/b.lock only, got %v", got)
+		}
+		requireLeasedWith(t, p, leaseIDs)
+
+		p.server.Intercept(nil)
+		requireNoErrors(t, "destroy", p.destroy(blobLeaseSetType, state))
+		requireLeasedWith(t, p, nil)
+	})
+}
+
+func TestBlobLeaseSetAtomicUpdate(t *testing.T) {
+	p := newLeaseSetProvider(t, true)
+	config := blobLeaseSetConfig([]string{"env/a.lock"}, nil)
+	state := p.mustApply(blobLeaseSetType, nil, config)
+	prior := stringMapAttr(t, state.value, "lease_ids")
+
+	t.Run("failure keeps the prior state", func(t *testing.T) {
+		updated, diags := p.apply(blobLeaseSetType, state, blobLeaseSetConfig(leaseSetBlobs, nil))
+		requireError(t, diags, "Leased by someone else: env/c.lock.")
+		if updated == nil || !updated.value.Equal(state.value) {
+			t.Errorf("expected the prior state to be kept")
+		}
+		requireLeasedWith(t, p, prior)
+	})
+
+	t.Run("failed rollback adds the leases still held", func(t *testing.T) {
+		failRelease(p, "env/b.lock")
+		updated, diags := p.apply(blobLeaseSetType, state, blobLeaseSetConfig(leaseSetBlobs, nil))
+		requireError(t, diags, "Releasing the leases acquired on the other blobs failed for env/b.lock")
+		if updated == nil {
+			t.Fatal("expected the lease still held to be saved in state")
+		}
+
+		leaseIDs := stringMapAttr(t, updated.value, "lease_ids")
+		if got := leasedBlobs(leaseIDs); !slices.Equal(got, []string{"env/a.lock", "env/b.lock"}) {
+			t.Errorf("expected the lease IDs of env/a.lock and env/b.lock, got %v", got)
+		}
+		if leaseIDs["env/a.lock"] != prior["env/a.lock"] {
+			t.Errorf("expected the lease on env/a.lock to be kept")
+		}
+		requireLeasedWith(t, p, leaseIDs)
+
+		p.server.Intercept(nil)
+		requireNoErrors(t, "destroy", p.destroy(blobLeaseSetType, updated))
+		requireLeasedWith(t, p, nil)
+	})
+}
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &BlobNameFromURLFunction{}
+
+func NewBlobNameFromURLFunction() function.Function {
+	return &BlobNameFromURLFunction{}
+}
+
+// BlobNameFromURLFunction splits a blob URL into its storage account, container, blob name and
+// endpoint suffix, the inverse of blob_url
+type BlobNameFromURLFunction struct{}
+
+// parsedBlobURL is the object returned by blob_name_from_url
+type parsedBlobURL struct {
+	StorageAccount types.String `tfsdk:"storage_account"`
+	ContainerName  types.String `tfsdk:"container_name"`
+	BlobName       types.String `tfsdk:"blob_name"`
+	EndpointSuffix types.String `tfsdk:"endpoint_suffix"`
+}
+
+func (f *BlobNameFromURLFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "blob_name_from_url"
+}
+
+func (f *BlobNameFromURLFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Splits a blob URL into its storage account, container, blob name and endpoint suffix",
+		MarkdownDescription: "Splits an https URL of the form `https://<storage_account>.blob.<endpoint_suffix>/<container>/<blob>`, such as one copied from the Azure portal, with the blob name percent-decoded. A query string such as a SAS token is dropped: it is never returned and never appears in errors",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "url",
+				MarkdownDescription: "The URL of the blob",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"storage_account": types.StringType,
+				"container_name":  types.StringType,
+				"blob_name":       types.StringType,
+				"endpoint_suffix": types.StringType,
+			},
+		},
+	}
+}
+
+func (f *BlobNameFromURLFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var blobURL string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &blobURL))
+	if resp.Error != nil {
+		return
+	}
+
+	storageAccount, containerName, blobName, suffix, err := blobclient.ParseBlobURL(blobURL)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+	if err := validators.CheckStorageAccountName(storageAccount); err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("storage account of the blob URL %s", err))
+		return
+	}
+	if err := validators.CheckContainerName(containerName); err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("container of the blob URL %s", err))
+		return
+	}
+	if err := validators.CheckBlobName(blobName); err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("blob name of the blob URL %s", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, parsedBlobURL{
+		StorageAccount: types.StringValue(storageAccount),
+		ContainerName:  types.StringValue(containerName),
+		BlobName:       types.StringValue(blobName),
+		EndpointSuffix: types.StringValue(suffix),
+	}))
+}
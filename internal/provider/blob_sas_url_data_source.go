@@ -0,0 +1,277 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &BlobSASURLDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &BlobSASURLDataSource{}
+
+// Defaults of the SAS signed by blobleas_blob_sas_url
+const (
+	defaultSASPermissions = "r"
+	defaultSASExpiry      = time.Hour
+)
+
+// sasPermissionsValidator ensures a string attribute is a set of SAS permission flags
+type sasPermissionsValidator struct{}
+
+func (v sasPermissionsValidator) Description(ctx context.Context) string {
+	return "value must be one or more of the permission flags r, w and d, e.g. rw"
+}
+
+func (v sasPermissionsValidator) MarkdownDescription(ctx context.Context) string {
+	return "value must be one or more of the permission flags `r`, `w` and `d`, e.g. `rw`"
+}
+
+func (v sasPermissionsValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if err := blobclient.ValidBlobSASPermissions(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid SAS Permissions",
+			fmt.Sprintf("%s %s: %s", req.Path, v.Description(ctx), err),
+		)
+	}
+}
+
+func NewBlobSASURLDataSource() datasource.DataSource {
+	return &BlobSASURLDataSource{}
+}
+
+// BlobSASURLDataSource signs a short-lived SAS URL for a blob
+type BlobSASURLDataSource struct {
+	client *blobclient.AzureBlobLeaseClient
+}
+
+// BlobSASURLDataSourceModel describes the data source data model.
+type BlobSASURLDataSourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	StorageAccount types.String `tfsdk:"storage_account_name"`
+	BlobEndpoint   types.String `tfsdk:"blob_endpoint"`
+	ContainerName  types.String `tfsdk:"storage_container_name"`
+	BlobName       types.String `tfsdk:"name"`
+	Permissions    types.String `tfsdk:"permissions"`
+	Expiry         types.String `tfsdk:"expiry"`
+	SigningMethod  types.String `tfsdk:"signing_method"`
+	SASURL         types.String `tfsdk:"sas_url"`
+	SASToken       types.String `tfsdk:"sas_token"`
+	StartsAt       types.String `tfsdk:"starts_at"`
+	ExpiresAt      types.String `tfsdk:"expires_at"`
+}
+
+func (d *BlobSASURLDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_blob_sas_url"
+}
+
+func (d *BlobSASURLDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Signs a short-lived, HTTPS-only SAS URL for an Azure Storage blob",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Data source identifier, `storage_account_name/storage_container_name/name`",
+			},
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name",
+				Required:            true,
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, e.g. a private endpoint or sovereign cloud URL, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`",
+				Optional:            true,
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"storage_container_name": schema.StringAttribute{
+				MarkdownDescription: "The container of the blob",
+				Required:            true,
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The name of the blob. It does not have to exist yet",
+				Required:            true,
+				Validators: []validator.String{
+					validators.BlobName(),
+				},
+			},
+			"permissions": schema.StringAttribute{
+				MarkdownDescription: "What the SAS allows, any of `r` (read), `w` (write) and `d` (delete), e.g. `rw`. Defaults to `r`",
+				Optional:            true,
+				Validators: []validator.String{
+					sasPermissionsValidator{},
+				},
+			},
+			"expiry": schema.StringAttribute{
+				MarkdownDescription: "How long the SAS is valid from the time it is signed, e.g. `30m`; at most `168h` (7 days) with `user_delegation`. Defaults to `1h`",
+				Optional:            true,
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"signing_method": schema.StringAttribute{
+				MarkdownDescription: "How the SAS is signed: `user_delegation`, with a user delegation key requested with the Azure credential, or `account_key`, which requires `use_account_key_lookup` on the provider. Defaults to `user_delegation`",
+				Optional:            true,
+				Validators: []validator.String{
+					stringOneOfValidator{values: []string{blobclient.SASSigningUserDelegation, blobclient.SASSigningAccountKey}},
+				},
+			},
+			"sas_url": schema.StringAttribute{
+				MarkdownDescription: "The URL of the blob with the SAS token as its query",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"sas_token": schema.StringAttribute{
+				MarkdownDescription: "The SAS token, without a leading `?`",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"starts_at": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time the SAS becomes valid, backdated a few minutes to allow for clock skew",
+				Computed:            true,
+			},
+			"expires_at": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time the SAS expires",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *BlobSASURLDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var data BlobSASURLDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Expiry.IsNull() || data.Expiry.IsUnknown() || data.SigningMethod.IsUnknown() {
+		return
+	}
+	// The format was checked by the attribute validator
+	expiry, err := time.ParseDuration(data.Expiry.ValueString())
+	if err != nil {
+		return
+	}
+	switch {
+	case expiry == 0:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("expiry"),
+			"Invalid SAS Expiry",
+			"expiry must be longer than 0s; a SAS that expires as it is signed cannot be used.",
+		)
+	case expiry > blobclient.MaxUserDelegationExpiry && data.SigningMethod.ValueString() != blobclient.SASSigningAccountKey:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("expiry"),
+			"Invalid SAS Expiry",
+			fmt.Sprintf("A user delegation SAS expires at most %s after it is signed, got: %s. Shorten expiry or set signing_method to account_key.", blobclient.MaxUserDelegationExpiry, expiry),
+		)
+	}
+}
+
+func (d *BlobSASURLDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *BlobSASURLDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data BlobSASURLDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+
+	endpoint := data.BlobEndpoint.ValueString()
+	if endpoint != "" {
+		if err := d.client.CheckBlobEndpoint(endpoint); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+			return
+		}
+	}
+	ctx = blobclient.WithBlobEndpoint(ctx, endpoint)
+
+	// The values were validated at plan time
+	options := blobclient.BlobSASOptions{
+		Permissions:   defaultSASPermissions,
+		Expiry:        time.Now().Add(defaultSASExpiry),
+		SigningMethod: blobclient.SASSigningUserDelegation,
+	}
+	if !data.Permissions.IsNull() {
+		options.Permissions = data.Permissions.ValueString()
+	}
+	if !data.Expiry.IsNull() {
+		expiry, _ := time.ParseDuration(data.Expiry.ValueString())
+		options.Expiry = time.Now().Add(expiry)
+	}
+	if !data.SigningMethod.IsNull() {
+		options.SigningMethod = data.SigningMethod.ValueString()
+	}
+
+	storageAccount, containerName, blobName := data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString()
+	signed, err := d.client.SignBlobSAS(ctx, storageAccount, containerName, blobName, options)
+	switch {
+	case errors.Is(err, blobclient.ErrAccountKeyRequired):
+		resp.Diagnostics.AddAttributeError(path.Root("signing_method"), "Account Key Not Available", fmt.Sprintf("%s. Set use_account_key_lookup on the provider, or sign with user_delegation.", err))
+		return
+	case errors.Is(err, blobclient.ErrAuthorizationFailed):
+		resp.Diagnostics.AddAttributeError(path.Root("signing_method"), "SAS Signing Not Permitted", err.Error())
+		return
+	case err != nil:
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to sign SAS for blob, got error: %s", err))
+		return
+	}
+
+	data.ID = types.StringValue(blobLeaseID(storageAccount, containerName, blobName))
+	data.SASURL = types.StringValue(signed.URL)
+	data.SASToken = types.StringValue(signed.Token)
+	data.StartsAt = types.StringValue(signed.Start.UTC().Format(time.RFC3339))
+	data.ExpiresAt = types.StringValue(options.Expiry.UTC().Format(time.RFC3339))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,296 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &BlobSnapshotResource{}
+var _ resource.ResourceWithModifyPlan = &BlobSnapshotResource{}
+
+func NewBlobSnapshotResource() resource.Resource {
+	return &BlobSnapshotResource{}
+}
+
+// BlobSnapshotResource manages one read-only snapshot of a blob. The blob itself is never
+// modified; destroying the resource deletes only its snapshot.
+type BlobSnapshotResource struct {
+	client *blobclient.AzureBlobLeaseClient
+}
+
+// BlobSnapshotResourceModel describes the resource data model.
+type BlobSnapshotResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	StorageAccount types.String `tfsdk:"storage_account_name"`
+	BlobEndpoint   types.String `tfsdk:"blob_endpoint"`
+	ContainerName  types.String `tfsdk:"storage_container_name"`
+	BlobName       types.String `tfsdk:"name"`
+	LeaseID        types.String `tfsdk:"lease_id"`
+	Keepers        types.Map    `tfsdk:"keepers"`
+	SnapshotURL    types.String `tfsdk:"snapshot_url"`
+	Timeouts       types.Object `tfsdk:"timeouts"`
+}
+
+func (r *BlobSnapshotResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_blob_snapshot"
+}
+
+func (r *BlobSnapshotResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Point-in-time snapshot of an Azure Storage blob",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The snapshot timestamp, which addresses the snapshot with the `snapshot` query parameter",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name. Changing it forces a new resource",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`. Changing it forces a new resource",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"storage_container_name": schema.StringAttribute{
+				MarkdownDescription: "The container of the blob. Changing it forces a new resource",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The name of the blob to snapshot. Changing it forces a new resource",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.BlobName(),
+				},
+			},
+			"lease_id": schema.StringAttribute{
+				MarkdownDescription: "The lease ID the blob is leased with, e.g. the `lease_id` of a `blobleas_blob_lease`. When set, the snapshot is only taken while that lease holds the blob. Only used on create",
+				Optional:            true,
+				Sensitive:           true,
+				Validators: []validator.String{
+					uuidValidator{},
+				},
+			},
+			"keepers": schema.MapAttribute{
+				MarkdownDescription: "Arbitrary values, e.g. a release version, that take a new snapshot when they change",
+				ElementType:         types.StringType,
+				Optional:            true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"snapshot_url": schema.StringAttribute{
+				MarkdownDescription: "The URL of the snapshot",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"timeouts": timeoutsAttribute(),
+		},
+	}
+}
+
+func (r *BlobSnapshotResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// ModifyPlan rejects an http blob_endpoint the provider does not allow
+func (r *BlobSnapshotResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var endpoint types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("blob_endpoint"), &endpoint)...)
+	if !endpoint.IsNull() && !endpoint.IsUnknown() {
+		if err := r.client.CheckBlobEndpoint(endpoint.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+		}
+	}
+}
+
+func (r *BlobSnapshotResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data BlobSnapshotResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := operationTimeout(data.Timeouts, "create", defaultCreateTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "create", data.BlobName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
+	config := blobclient.BlobLeaseConfig{
+		StorageAccount: data.StorageAccount.ValueString(),
+		ContainerName:  data.ContainerName.ValueString(),
+		BlobName:       data.BlobName.ValueString(),
+		LeaseID:        data.LeaseID.ValueString(),
+	}
+
+	snapshot, err := r.client.SnapshotBlob(ctx, config)
+	if errors.Is(err, blobclient.ErrSnapshotRejected) {
+		resp.Diagnostics.AddError("Snapshot Rejected", err.Error())
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to snapshot blob, got error: %s", err))
+		return
+	}
+	tflog.Info(ctx, "Created blob snapshot", map[string]interface{}{
+		"blob":     blobLeaseID(config.StorageAccount, config.ContainerName, config.BlobName),
+		"snapshot": snapshot,
+	})
+
+	snapshotURL, err := r.client.GetBlobSnapshot(ctx, config.StorageAccount, config.ContainerName, config.BlobName, snapshot)
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read blob snapshot, got error: %s", err))
+		return
+	}
+
+	data.ID = types.StringValue(snapshot)
+	data.SnapshotURL = types.StringValue(snapshotURL)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BlobSnapshotResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data BlobSnapshotResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := operationTimeout(data.Timeouts, "read", defaultReadTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "read", data.BlobName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
+	snapshotURL, err := r.client.GetBlobSnapshot(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString(), data.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read blob snapshot, got error: %s", err))
+		return
+	}
+	if snapshotURL == "" {
+		// Snapshot was deleted outside of Terraform, or with the blob
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	data.SnapshotURL = types.StringValue(snapshotURL)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update only records lease_id and timeouts, which do not affect the snapshot taken
+func (r *BlobSnapshotResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data BlobSnapshotResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BlobSnapshotResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data BlobSnapshotResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := operationTimeout(data.Timeouts, "delete", defaultDeleteTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "delete", data.BlobName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
+	// Only the snapshot is deleted; the blob and its other snapshots stay
+	config := blobclient.BlobLeaseConfig{
+		StorageAccount: data.StorageAccount.ValueString(),
+		ContainerName:  data.ContainerName.ValueString(),
+		BlobName:       data.BlobName.ValueString(),
+	}
+	err := r.client.DeleteBlobSnapshot(ctx, config, data.ID.ValueString())
+	if errors.Is(err, blobclient.ErrBlobImmutable) {
+		resp.Diagnostics.AddError("Blob Is Immutable", err.Error())
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to delete blob snapshot, got error: %s", err))
+	}
+}
@@ -0,0 +1,200 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &BlobSnapshotsDataSource{}
+
+func NewBlobSnapshotsDataSource() datasource.DataSource {
+	return &BlobSnapshotsDataSource{}
+}
+
+// BlobSnapshotsDataSource lists the snapshots of a blob
+type BlobSnapshotsDataSource struct {
+	client *blobclient.AzureBlobLeaseClient
+}
+
+// BlobSnapshotsDataSourceModel describes the data source data model.
+type BlobSnapshotsDataSourceModel struct {
+	ID             types.String                  `tfsdk:"id"`
+	StorageAccount types.String                  `tfsdk:"storage_account_name"`
+	BlobEndpoint   types.String                  `tfsdk:"blob_endpoint"`
+	ContainerName  types.String                  `tfsdk:"storage_container_name"`
+	BlobName       types.String                  `tfsdk:"name"`
+	MaxResults     types.Int64                   `tfsdk:"max_results"`
+	Snapshots      []BlobSnapshotsDataSourceItem `tfsdk:"snapshots"`
+}
+
+// BlobSnapshotsDataSourceItem describes a listed snapshot.
+type BlobSnapshotsDataSourceItem struct {
+	Snapshot     types.String `tfsdk:"snapshot"`
+	ETag         types.String `tfsdk:"etag"`
+	Size         types.Int64  `tfsdk:"size"`
+	LastModified types.String `tfsdk:"last_modified"`
+}
+
+func (d *BlobSnapshotsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_blob_snapshots"
+}
+
+func (d *BlobSnapshotsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Lists the snapshots of an Azure Storage blob, newest first",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Data source identifier, `storage_account_name/storage_container_name/name`",
+			},
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name",
+				Required:            true,
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, e.g. a private endpoint or sovereign cloud URL, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`",
+				Optional:            true,
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"storage_container_name": schema.StringAttribute{
+				MarkdownDescription: "The container of the blob",
+				Required:            true,
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The name of the blob",
+				Required:            true,
+				Validators: []validator.String{
+					validators.BlobName(),
+				},
+			},
+			"max_results": schema.Int64Attribute{
+				MarkdownDescription: "How many snapshots the blob may have; a blob with more fails instead of being stored in state. Defaults to 1000",
+				Optional:            true,
+				Validators: []validator.Int64{
+					positiveInt64Validator{},
+				},
+			},
+			"snapshots": schema.ListNestedAttribute{
+				MarkdownDescription: "The snapshots of the blob, newest first. Empty when the blob has none or does not exist",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"snapshot": schema.StringAttribute{
+							MarkdownDescription: "The snapshot timestamp, which addresses the snapshot with the `snapshot` query parameter and is the `id` of a `blobleas_blob_snapshot`",
+							Computed:            true,
+						},
+						"etag": schema.StringAttribute{
+							MarkdownDescription: "The ETag of the snapshot",
+							Computed:            true,
+						},
+						"size": schema.Int64Attribute{
+							MarkdownDescription: "The size of the snapshot content in bytes",
+							Computed:            true,
+						},
+						"last_modified": schema.StringAttribute{
+							MarkdownDescription: "The RFC3339 time of the last write to the blob before the snapshot was taken",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *BlobSnapshotsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *BlobSnapshotsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data BlobSnapshotsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+
+	endpoint := data.BlobEndpoint.ValueString()
+	if endpoint != "" {
+		if err := d.client.CheckBlobEndpoint(endpoint); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+			return
+		}
+	}
+	ctx = blobclient.WithBlobEndpoint(ctx, endpoint)
+
+	maxResults := defaultMaxListResults
+	if !data.MaxResults.IsNull() {
+		maxResults = int(data.MaxResults.ValueInt64())
+	}
+
+	storageAccount, containerName, blobName := data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString()
+	items, err := d.client.ListBlobSnapshots(ctx, storageAccount, containerName, blobName, maxResults)
+	switch {
+	case errors.Is(err, blobclient.ErrTooManySnapshots):
+		resp.Diagnostics.AddAttributeError(path.Root("max_results"), "Too Many Snapshots", fmt.Sprintf("%s. Raise max_results to list them; every snapshot listed is stored in state.", err))
+		return
+	case errors.Is(err, blobclient.ErrContainerNotFound):
+		resp.Diagnostics.AddAttributeError(path.Root("storage_container_name"), "Container Not Found", err.Error())
+		return
+	case err != nil:
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to list blob snapshots, got error: %s", err))
+		return
+	}
+
+	data.ID = types.StringValue(blobLeaseID(storageAccount, containerName, blobName))
+	data.Snapshots = make([]BlobSnapshotsDataSourceItem, 0, len(items))
+	for _, item := range items {
+		data.Snapshots = append(data.Snapshots, BlobSnapshotsDataSourceItem{
+			Snapshot:     types.StringValue(item.Snapshot),
+			ETag:         stringOrNull(item.ETag),
+			Size:         types.Int64Value(item.Size),
+			LastModified: timestampValue(item.LastModified),
+		})
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
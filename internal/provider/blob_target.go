@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+)
+
+// blobTargetPrivateKey is the private state key recording which operation claimed the blob of a
+// resource. Terraform plans a resource it replaces a second time as a new one, passing the private
+// state of the first plan, and that plan must not count as a second resource.
+const blobTargetPrivateKey = "blob_target"
+
+// claimBlobTarget claims the planned blob for the resource of req during the current operation,
+// and reports an error, or a warning with allow_duplicate_blob_targets, when another resource of
+// the configuration already claimed it. Blobs whose path is not known yet are not checked.
+func claimBlobTarget(ctx context.Context, client *blobclient.AzureBlobLeaseClient, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if client == nil {
+		return
+	}
+
+	var storageAccount, containerName, blobName types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("storage_account_name"), &storageAccount)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("storage_container_name"), &containerName)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("name"), &blobName)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for _, value := range []types.String{storageAccount, containerName, blobName} {
+		if value.IsNull() || value.IsUnknown() {
+			return
+		}
+	}
+
+	target := blobclient.BlobTargetPath(storageAccount.ValueString(), containerName.ValueString(), blobName.ValueString())
+	claim, err := json.Marshal(client.BlobTargetSession() + "/" + target)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Private State", fmt.Sprintf("Unable to record the blob target in private state, got error: %s", err))
+		return
+	}
+	if req.State.Raw.IsNull() {
+		prior, diags := req.Private.GetKey(ctx, blobTargetPrivateKey)
+		resp.Diagnostics.Append(diags...)
+		if bytes.Equal(prior, claim) {
+			return
+		}
+	}
+
+	err = client.ClaimBlobTarget(target)
+	if errors.Is(err, blobclient.ErrDuplicateTarget) {
+		summary := "Duplicate Blob Target"
+		detail := fmt.Sprintf("Another blobleas_blob_lease or blobleas_lease resource in this configuration also manages blob %s/%s/%s, so the two would take the lease from each other during apply. "+
+			"Terraform shows the address of this resource with this diagnostic; the provider is not told resource addresses, so look for the other resource with the same storage_account_name, storage_container_name and name. "+
+			"Set allow_duplicate_blob_targets in the provider configuration if this is intended.",
+			storageAccount.ValueString(), containerName.ValueString(), blobName.ValueString())
+		if client.AllowDuplicateBlobTargets() {
+			resp.Diagnostics.AddAttributeWarning(path.Root("name"), summary, detail)
+		} else {
+			resp.Diagnostics.AddAttributeError(path.Root("name"), summary, detail)
+		}
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, blobTargetPrivateKey, claim)...)
+}
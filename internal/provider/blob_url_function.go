@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &BlobURLFunction{}
+
+// endpointSuffixParameter is the optional endpoint suffix of the URL functions
+var endpointSuffixParameter = function.StringParameter{
+	Name:                "endpoint_suffix",
+	MarkdownDescription: "The storage endpoint suffix, at most one. Defaults to `core.windows.net`",
+}
+
+// endpointSuffixArgument returns the endpoint suffix passed as the variadic argument at
+// position, empty when there is none
+func endpointSuffixArgument(suffixes []string, position int64) (string, *function.FuncError) {
+	switch len(suffixes) {
+	case 0:
+		return "", nil
+	case 1:
+		if err := blobclient.CheckEndpointSuffix(suffixes[0]); err != nil {
+			return "", function.NewArgumentFuncError(position, err.Error())
+		}
+		return suffixes[0], nil
+	}
+	return "", function.NewArgumentFuncError(position+1, fmt.Sprintf("at most one endpoint_suffix can be given, got %d", len(suffixes)))
+}
+
+func NewBlobURLFunction() function.Function {
+	return &BlobURLFunction{}
+}
+
+// BlobURLFunction builds the URL of a blob from its storage account, container and name
+type BlobURLFunction struct{}
+
+func (f *BlobURLFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "blob_url"
+}
+
+func (f *BlobURLFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Returns the https URL of an Azure Storage blob",
+		MarkdownDescription: "Returns the https URL of a blob, with the blob name percent-encoded the same way as the `blob_url` attributes of the provider. An optional fourth argument sets the endpoint suffix of a sovereign cloud, e.g. `core.chinacloudapi.cn`; it defaults to `core.windows.net`",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "storage_account",
+				MarkdownDescription: "The Azure Storage Account name",
+			},
+			function.StringParameter{
+				Name:                "container",
+				MarkdownDescription: "The container of the blob",
+			},
+			function.StringParameter{
+				Name:                "blob",
+				MarkdownDescription: "The name of the blob",
+			},
+		},
+		VariadicParameter: endpointSuffixParameter,
+		Return:            function.StringReturn{},
+	}
+}
+
+func (f *BlobURLFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var storageAccount, containerName, blobName string
+	var suffixes []string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &storageAccount, &containerName, &blobName, &suffixes))
+	if resp.Error != nil {
+		return
+	}
+
+	if err := validators.CheckStorageAccountName(storageAccount); err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("storage_account %s", err))
+		return
+	}
+	if err := validators.CheckContainerName(containerName); err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("container %s", err))
+		return
+	}
+	if err := validators.CheckBlobName(blobName); err != nil {
+		resp.Error = function.NewArgumentFuncError(2, fmt.Sprintf("blob %s", err))
+		return
+	}
+
+	suffix, funcErr := endpointSuffixArgument(suffixes, 3)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, blobclient.BlobURL(storageAccount, containerName, blobName, suffix)))
+}
@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBlobURLFunction(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	for name, tc := range map[string]struct {
+		blobName string
+		suffix   []any
+		want     string
+		wantErr  string
+	}{
+		"plain name": {
+			blobName: "app.lock",
+			want:     "https://acct.blob.core.windows.net/locks/app.lock",
+		},
+		"path": {
+			blobName: "env/app.lock",
+			want:     "https://acct.blob.core.windows.net/locks/env%2Fapp.lock",
+		},
+		"spaces": {
+			blobName: "my app.lock",
+			want:     "https://acct.blob.core.windows.net/locks/my%20app.lock",
+		},
+		"hash": {
+			blobName: "app#1.lock",
+			want:     "https://acct.blob.core.windows.net/locks/app%231.lock",
+		},
+		"question mark": {
+			blobName: "app?.lock",
+			want:     "https://acct.blob.core.windows.net/locks/app%3F.lock",
+		},
+		"percent sign": {
+			blobName: "100%.lock",
+			want:     "https://acct.blob.core.windows.net/locks/100%25.lock",
+		},
+		"non-ASCII": {
+			blobName: "équipe/ü.lock",
+			want:     "https://acct.blob.core.windows.net/locks/%C3%A9quipe%2F%C3%BC.lock",
+		},
+		"sovereign cloud": {
+			blobName: "env/app.lock",
+			suffix:   []any{"core.chinacloudapi.cn"},
+			want:     "https://acct.blob.core.chinacloudapi.cn/locks/env%2Fapp.lock",
+		},
+		"invalid blob name": {
+			blobName: "env/app/",
+			wantErr:  "blob ",
+		},
+		"two endpoint suffixes": {
+			blobName: "app.lock",
+			suffix:   []any{"core.windows.net", "core.chinacloudapi.cn"},
+			wantErr:  "at most one endpoint_suffix",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, funcErr := p.callFunction("blob_url", append([]any{testAccount, testContainer, tc.blobName}, tc.suffix...)...)
+			if tc.wantErr != "" {
+				if funcErr == nil || !strings.Contains(funcErr.Text, tc.wantErr) {
+					t.Fatalf("expected an error containing %q, got %v", tc.wantErr, funcErr)
+				}
+				return
+			}
+			if funcErr != nil {
+				t.Fatalf("unexpected error: %s", funcErr.Text)
+			}
+			var got string
+			if err := result.As(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+
+			// blob_name_from_url decodes the name again
+			if result, funcErr = p.callFunction("blob_name_from_url", got); funcErr != nil {
+				t.Fatalf("unexpected error: %s", funcErr.Text)
+			}
+			if blobName := stringAttr(t, result, "blob_name"); blobName != tc.blobName {
+				t.Errorf("expected blob_name_from_url to return %q, got %q", tc.blobName, blobName)
+			}
+		})
+	}
+}
+
+func TestBlobURLFunctionMatchesBlobURLAttribute(t *testing.T) {
+	for _, blobName := range []string{"env/app.lock", "my app#1?.lock", "100%.lock", "équipe/ü.lock"} {
+		t.Run(blobName, func(t *testing.T) {
+			p := newTestProvider(t, nil)
+			state := p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"name": blobName}))
+
+			result, funcErr := p.callFunction("blob_url", testAccount, testContainer, blobName)
+			if funcErr != nil {
+				t.Fatalf("unexpected error: %s", funcErr.Text)
+			}
+			var got string
+			if err := result.As(&got); err != nil {
+				t.Fatal(err)
+			}
+			if want := stringAttr(t, state.value, "blob_url"); got != want {
+				t.Errorf("expected the blob_url attribute %s, got %s", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package blobclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	armruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+)
+
+const (
+	armEndpoint          = "https://management.azure.com"
+	storageAPIVersion    = "2023-01-01"
+	armModuleName        = "terraform-provider-blobleas"
+	armModuleVersion     = "v0.1.0"
+	storageAccountsRType = "Microsoft.Storage/storageAccounts"
+)
+
+// accountKeyCache looks up storage account keys through the ARM management plane and caches
+// the resulting shared key credentials per account for the lifetime of the client.
+// Keys are held in memory only and are never logged.
+type accountKeyCache struct {
+	credential     azcore.TokenCredential
+	subscriptionID string
+	resourceGroups map[string]string
+
+	mu       sync.Mutex
+	pipeline *runtime.Pipeline
+	keys     map[string]*azblob.SharedKeyCredential
+}
+
+func newAccountKeyCache(credential azcore.TokenCredential, subscriptionID string, resourceGroups map[string]string) *accountKeyCache {
+	return &accountKeyCache{
+		credential:     credential,
+		subscriptionID: subscriptionID,
+		resourceGroups: resourceGroups,
+		keys:           map[string]*azblob.SharedKeyCredential{},
+	}
+}
+
+// sharedKeyCredential returns the cached shared key credential for the account, fetching it on first use
+func (k *accountKeyCache) sharedKeyCredential(ctx context.Context, storageAccount string) (*azblob.SharedKeyCredential, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if cred, ok := k.keys[storageAccount]; ok {
+		return cred, nil
+	}
+
+	if k.pipeline == nil {
+		pl, err := armruntime.NewPipeline(armModuleName, armModuleVersion, k.credential, runtime.PipelineOptions{}, &armpolicy.ClientOptions{
+			DisableRPRegistration: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create management client: %w", err)
+		}
+		k.pipeline = &pl
+	}
+
+	resourceID, err := k.resourceID(ctx, storageAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys struct {
+		Keys []struct {
+			KeyName     string `json:"keyName"`
+			Value       string `json:"value"`
+			Permissions string `json:"permissions"`
+		} `json:"keys"`
+	}
+	endpoint := fmt.Sprintf("%s%s/listKeys?api-version=%s", armEndpoint, resourceID, storageAPIVersion)
+	if err := k.do(ctx, http.MethodPost, endpoint, &keys); err != nil {
+		return nil, wrapError(err, "failed to list keys for storage account %s", storageAccount)
+	}
+
+	for _, key := range keys.Keys {
+		if !strings.EqualFold(key.Permissions, "FULL") || key.Value == "" {
+			continue
+		}
+		cred, err := azblob.NewSharedKeyCredential(storageAccount, key.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create shared key credential for %s: %w", storageAccount, err)
+		}
+		k.keys[storageAccount] = cred
+		return cred, nil
+	}
+
+	return nil, fmt.Errorf("storage account %s returned no key with full permissions", storageAccount)
+}
+
+// resourceID resolves the ARM resource ID of the storage account, either from the configured
+// resource group mapping or by searching the subscription for an account with that name
+func (k *accountKeyCache) resourceID(ctx context.Context, storageAccount string) (string, error) {
+	if k.subscriptionID == "" {
+		return "", fmt.Errorf("a subscription ID is required to look up keys for storage account %s", storageAccount)
+	}
+
+	if rg, ok := k.resourceGroups[storageAccount]; ok {
+		if strings.HasPrefix(rg, "/subscriptions/") {
+			// Full resource ID supplied
+			if _, err := arm.ParseResourceID(rg); err != nil {
+				return "", fmt.Errorf("invalid storage account resource ID %q: %w", rg, err)
+			}
+			return rg, nil
+		}
+		return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/%s/%s",
+			url.PathEscape(k.subscriptionID), url.PathEscape(rg), storageAccountsRType, url.PathEscape(storageAccount)), nil
+	}
+
+	endpoint := fmt.Sprintf("%s/subscriptions/%s/providers/%s?api-version=%s", armEndpoint, url.PathEscape(k.subscriptionID), storageAccountsRType, storageAPIVersion)
+	for endpoint != "" {
+		var page struct {
+			Value []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if err := k.do(ctx, http.MethodGet, endpoint, &page); err != nil {
+			return "", wrapError(err, "failed to find storage account %s in subscription %s", storageAccount, k.subscriptionID)
+		}
+		for _, account := range page.Value {
+			if strings.EqualFold(account.Name, storageAccount) {
+				return account.ID, nil
+			}
+		}
+		endpoint = page.NextLink
+	}
+
+	return "", fmt.Errorf("%w: %s is not visible in subscription %s; set its resource group in storage_account_resource_groups",
+		ErrAccountNotFound, storageAccount, k.subscriptionID)
+}
+
+// do sends a management-plane request and decodes the JSON response into v
+func (k *accountKeyCache) do(ctx context.Context, method, endpoint string, v any) error {
+	req, err := runtime.NewRequest(ctx, method, endpoint)
+	if err != nil {
+		return err
+	}
+	resp, err := k.pipeline.Do(req)
+	if err != nil {
+		return err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return runtime.NewResponseError(resp)
+	}
+	return runtime.UnmarshalAsJSON(resp, v)
+}
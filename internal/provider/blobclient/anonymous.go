@@ -0,0 +1,77 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+)
+
+// ErrCredentialRequired is returned when a write or lease operation is attempted without an Azure credential
+var ErrCredentialRequired = errors.New("an Azure credential is required for write and lease operations; anonymous access is read-only")
+
+// createAnonymousBlobClient creates a credential-less blob client for reading public containers
+func (c *AzureBlobLeaseClient) createAnonymousBlobClient(ctx context.Context, storageAccount string) (*azblob.Client, error) {
+	serviceURL, err := c.serviceURL(ctx, storageAccount)
+	if err != nil {
+		return nil, err
+	}
+	client, err := azblob.NewClientWithNoCredential(serviceURL, c.azblobOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create anonymous blob client for %s: %w", storageAccount, err)
+	}
+	return client, nil
+}
+
+// withReadFallback runs a read-only operation with the authenticated client. When anonymous reads
+// are allowed and the authenticated attempt fails authentication or authorization (or there is no
+// credential at all), the operation is retried anonymously. Anonymous failures other than a
+// genuine BlobNotFound are discarded in favour of the original error, since Azure reports private
+// containers as ResourceNotFound to anonymous callers.
+func (c *AzureBlobLeaseClient) withReadFallback(ctx context.Context, storageAccount string, read func(*azblob.Client) error) error {
+	var err error
+	if c.credential != nil {
+		var client *azblob.Client
+		client, err = c.CreateBlobClient(ctx, storageAccount)
+		if err != nil {
+			return fmt.Errorf("failed to create blob client: %w", err)
+		}
+		err = read(client)
+		if err == nil || !c.options.AllowAnonymousReads || !isAuthFailure(err) {
+			return err
+		}
+	}
+
+	anonClient, anonErr := c.createAnonymousBlobClient(ctx, storageAccount)
+	if anonErr != nil {
+		return anonErr
+	}
+	anonErr = read(anonClient)
+	if anonErr == nil || err == nil || bloberror.HasCode(anonErr, bloberror.BlobNotFound) {
+		return anonErr
+	}
+	return err
+}
+
+// isAuthFailure reports whether err is an authentication or authorization failure, either a
+// 401/403 from the service or a credential that could not produce a token
+func isAuthFailure(err error) bool {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusUnauthorized || respErr.StatusCode == http.StatusForbidden
+	}
+
+	var authErr *azidentity.AuthenticationFailedError
+	if errors.As(err, &authErr) {
+		return true
+	}
+
+	// Credential failures surface from the bearer token policy as non-retriable errors
+	var nonRetriable interface{ NonRetriable() }
+	return errors.As(err, &nonRetriable)
+}
@@ -0,0 +1,131 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// publicBlobTransport rejects authenticated requests and serves an available blob to anonymous ones,
+// like a public container the credential has no role on
+func publicBlobTransport() *fakeTransport {
+	return &fakeTransport{handler: func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Authorization") != "" {
+			return respondError(req, http.StatusForbidden, "AuthorizationPermissionMismatch"), nil
+		}
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			return respondError(req, http.StatusNotFound, "ResourceNotFound"), nil
+		}
+		return respond(req, http.StatusOK, "", "x-ms-lease-state", "available", "x-ms-lease-status", "unlocked", "ETag", `"0x1"`), nil
+	}}
+}
+
+// anonymousRequests counts the requests sent without a credential
+func anonymousRequests(transport *fakeTransport) int {
+	return transport.count(func(req *http.Request) bool { return req.Header.Get("Authorization") == "" })
+}
+
+func TestReadFallsBackToAnonymousAfterAuthFailure(t *testing.T) {
+	transport := publicBlobTransport()
+	client := newTestClient(transport, ClientOptions{AllowAnonymousReads: true})
+
+	exists, err := client.BlobExists(context.Background(), "acct", "public", "state.lock")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !exists {
+		t.Error("expected the anonymous read to find the blob")
+	}
+
+	state, err := client.GetBlobLeaseState(context.Background(), "acct", "public", "state.lock")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if state.LeaseState != "available" {
+		t.Errorf("expected lease state available, got %q", state.LeaseState)
+	}
+
+	if got := anonymousRequests(transport); got != 2 {
+		t.Errorf("expected 2 anonymous requests, got %d", got)
+	}
+}
+
+func TestReadDoesNotFallBackWhenDisabled(t *testing.T) {
+	transport := publicBlobTransport()
+	client := newTestClient(transport, ClientOptions{})
+
+	_, err := client.BlobExists(context.Background(), "acct", "public", "state.lock")
+	if !errors.Is(err, ErrAuthorizationFailed) {
+		t.Fatalf("expected ErrAuthorizationFailed, got: %v", err)
+	}
+	if got := anonymousRequests(transport); got != 0 {
+		t.Errorf("expected no anonymous requests, got %d", got)
+	}
+}
+
+func TestReadDoesNotFallBackOnOtherErrors(t *testing.T) {
+	transport := &fakeTransport{handler: func(req *http.Request) (*http.Response, error) {
+		return respondError(req, http.StatusNotFound, "BlobNotFound"), nil
+	}}
+	client := newTestClient(transport, ClientOptions{AllowAnonymousReads: true})
+
+	exists, err := client.BlobExists(context.Background(), "acct", "public", "state.lock")
+	if err != nil || exists {
+		t.Fatalf("expected a missing blob, got exists=%t, err=%v", exists, err)
+	}
+	if got := anonymousRequests(transport); got != 0 {
+		t.Errorf("expected no anonymous requests, got %d", got)
+	}
+}
+
+func TestLeaseAndWriteCallsNeverFallBack(t *testing.T) {
+	config := BlobLeaseConfig{
+		StorageAccount: "acct",
+		ContainerName:  "public",
+		BlobName:       "state.lock",
+		LeaseID:        "3f2504e0-4f89-11d3-9a0c-0305e82c3301",
+		LeaseDuration:  -1,
+		Metadata:       map[string]string{"team": "a"},
+	}
+
+	calls := map[string]func(*AzureBlobLeaseClient) error{
+		"AcquireBlobLease": func(c *AzureBlobLeaseClient) error {
+			_, err := c.AcquireBlobLease(context.Background(), config)
+			return err
+		},
+		"RenewBlobLease": func(c *AzureBlobLeaseClient) error {
+			_, err := c.RenewBlobLease(context.Background(), config)
+			return err
+		},
+		"ReleaseBlobLease": func(c *AzureBlobLeaseClient) error {
+			return c.ReleaseBlobLease(context.Background(), config, false)
+		},
+		"BreakBlobLease": func(c *AzureBlobLeaseClient) error {
+			_, err := c.BreakBlobLease(context.Background(), config, 0)
+			return err
+		},
+		"SetBlobMetadata": func(c *AzureBlobLeaseClient) error {
+			_, err := c.SetBlobMetadata(context.Background(), config)
+			return err
+		},
+		"CreateBlobWithLease": func(c *AzureBlobLeaseClient) error {
+			_, err := c.CreateBlobWithLease(context.Background(), config)
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			transport := publicBlobTransport()
+			client := newTestClient(transport, ClientOptions{AllowAnonymousReads: true, DisableAuthCircuitBreaker: true})
+
+			if err := call(client); err == nil {
+				t.Fatal("expected the authorization failure to be returned")
+			}
+			if got := anonymousRequests(transport); got != 0 {
+				t.Errorf("expected no anonymous requests, got %d", got)
+			}
+		})
+	}
+}
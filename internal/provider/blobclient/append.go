@@ -0,0 +1,51 @@
+package blobclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/appendblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+)
+
+// AppendBlobRecords appends records in order to the end of a leased append blob under
+// config.LeaseID, each as one or more Append Block calls. It returns how many records were
+// appended completely, also when it fails part way, and the ETag after the last append.
+func (c *AzureBlobLeaseClient) AppendBlobRecords(ctx context.Context, config BlobLeaseConfig, records []string) (int, string, error) {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
+	appendBlobClient := containerClient.NewAppendBlobClient(config.BlobName)
+
+	etag := ""
+	for i, record := range records {
+		for data := []byte(record); len(data) > 0; {
+			block := data[:min(len(data), maxAppendBlockBytes)]
+			data = data[len(block):]
+
+			blockSum := md5.Sum(block)
+			resp, err := appendBlobClient.AppendBlock(ctx, streaming.NopCloser(bytes.NewReader(block)), &appendblob.AppendBlockOptions{
+				TransactionalValidation: blob.TransferValidationTypeMD5(blockSum[:]),
+				AccessConditions:        leaseConditions(config.LeaseID),
+				CPKScopeInfo:            encryptionScope(config.EncryptionScope),
+			})
+			if err != nil {
+				if bloberror.HasCode(err, bloberror.LeaseIDMissing, bloberror.LeaseIDMismatchWithBlobOperation, bloberror.LeaseNotPresentWithBlobOperation, bloberror.LeaseLost) {
+					return i, etag, fmt.Errorf("%w: blob %s is no longer leased with the known lease ID: %w",
+						ErrLeaseLost, config.BlobName, wrapError(err, "append rejected"))
+				}
+				return i, etag, wrapError(err, "failed to append record %d to blob %s", i, config.BlobName)
+			}
+			etag = etagValue(resp.ETag)
+		}
+	}
+	return len(records), etag, nil
+}
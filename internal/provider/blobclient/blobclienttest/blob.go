@@ -0,0 +1,520 @@
+package blobclienttest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Blob is a copy of the state of a fake block blob
+type Blob struct {
+	Content            []byte
+	ContentType        string
+	ContentEncoding    string
+	ContentMD5         string // base64-encoded, as the service reports it
+	CacheControl       string
+	ContentDisposition string
+	ContentLanguage    string
+	Metadata           map[string]string
+	Tags               map[string]string
+	AccessTier         string
+	EncryptionScope    string
+	ETag               string
+	CreationTime       time.Time
+	LastModified       time.Time
+
+	// LeaseState is available, leased, expired, breaking or broken
+	LeaseState string
+	// LeaseID is the ID of the lease while it is leased or breaking
+	LeaseID string
+}
+
+// fakeBlob is a stored block blob and its lease
+type fakeBlob struct {
+	Blob
+
+	leaseState    string    // available, leased, breaking or broken as last set
+	leaseDuration int32     // seconds of a fixed lease, -1 for an infinite one
+	leaseExpiry   time.Time // when a fixed lease lapses, zero for an infinite one
+	breakEnd      time.Time // when a breaking lease is broken
+}
+
+// lease returns the lease state, status and duration of b at now
+func (b *fakeBlob) lease(now time.Time) (state, status, duration string) {
+	switch b.leaseState {
+	case "leased":
+		if !b.leaseExpiry.IsZero() && !now.Before(b.leaseExpiry) {
+			return "expired", "unlocked", ""
+		}
+		if b.leaseExpiry.IsZero() {
+			return "leased", "locked", "infinite"
+		}
+		return "leased", "locked", "fixed"
+	case "breaking":
+		if !now.Before(b.breakEnd) {
+			return "broken", "unlocked", ""
+		}
+		return "breaking", "locked", ""
+	case "broken":
+		return "broken", "unlocked", ""
+	}
+	return "available", "unlocked", ""
+}
+
+// active reports whether b is locked by a lease at now
+func (b *fakeBlob) active(now time.Time) bool {
+	_, status, _ := b.lease(now)
+	return status == "locked"
+}
+
+// snapshot returns a copy of b for callers outside the server
+func (b *fakeBlob) snapshot(now time.Time) Blob {
+	copied := b.Blob
+	copied.Content = bytes.Clone(b.Content)
+	copied.Metadata = cloneMap(b.Metadata)
+	copied.Tags = cloneMap(b.Tags)
+	copied.LeaseState, _, _ = b.lease(now)
+	copied.LeaseID = ""
+	if b.active(now) {
+		copied.LeaseID = b.LeaseID
+	}
+	return copied
+}
+
+func cloneMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+// Blob returns a copy of a live blob
+func (s *Server) Blob(account, container, name string) (Blob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.container(account, container)
+	if c == nil || c.blobs[name] == nil {
+		return Blob{}, false
+	}
+	return c.blobs[name].snapshot(s.now()), true
+}
+
+// DeletedBlob returns a copy of a soft-deleted blob
+func (s *Server) DeletedBlob(account, container, name string) (Blob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.container(account, container)
+	if c == nil || c.deleted[name] == nil {
+		return Blob{}, false
+	}
+	return c.deleted[name].snapshot(s.now()), true
+}
+
+// PutBlob writes a blob directly, as if someone else had written it, creating the container if
+// needed. The lease of an existing blob is kept.
+func (s *Server) PutBlob(account, container, name string, content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.container(account, container)
+	if c == nil {
+		c = s.createContainer(account, container, "")
+	}
+	b := c.blobs[name]
+	if b == nil {
+		b = &fakeBlob{Blob: Blob{CreationTime: s.now(), ContentType: "application/octet-stream", AccessTier: "Hot"}, leaseState: "available"}
+		c.blobs[name] = b
+	}
+	b.Content = bytes.Clone(content)
+	sum := md5.Sum(content)
+	b.ContentMD5 = base64.StdEncoding.EncodeToString(sum[:])
+	b.ETag, b.LastModified = s.nextETag(), s.now()
+}
+
+// Touch gives a live blob a new ETag and last modified time without changing anything else, as
+// someone setting its properties to the same values would
+func (s *Server) Touch(account, container, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c := s.container(account, container); c != nil && c.blobs[name] != nil {
+		c.blobs[name].ETag, c.blobs[name].LastModified = s.nextETag(), s.now()
+	}
+}
+
+// SetLease gives a live blob an infinite lease with leaseID, as if someone else had leased it.
+// An empty leaseID removes the lease.
+func (s *Server) SetLease(account, container, name, leaseID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.container(account, container)
+	if c == nil || c.blobs[name] == nil {
+		return
+	}
+	b := c.blobs[name]
+	b.LeaseID, b.leaseState, b.leaseExpiry = leaseID, "leased", time.Time{}
+	if leaseID == "" {
+		b.leaseState = "available"
+	}
+}
+
+// DeleteBlob removes a live blob directly, as if someone else had deleted it
+func (s *Server) DeleteBlob(account, container, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c := s.container(account, container); c != nil {
+		delete(c.blobs, name)
+	}
+}
+
+// handleBlob implements the blob operations
+func (s *Server) handleBlob(req *http.Request, account, containerName, name string) *http.Response {
+	c := s.container(account, containerName)
+	if c == nil {
+		return Error(req, http.StatusNotFound, "ContainerNotFound")
+	}
+	b := c.blobs[name]
+	comp := req.URL.Query().Get("comp")
+
+	if req.Method == http.MethodPut && comp == "" {
+		return s.putBlob(req, c, name)
+	}
+	if req.Method == http.MethodPut && comp == "undelete" {
+		deleted := c.deleted[name]
+		if deleted == nil {
+			return Error(req, http.StatusNotFound, "BlobNotFound")
+		}
+		if b == nil {
+			c.blobs[name] = deleted
+		}
+		delete(c.deleted, name)
+		return Response(req, http.StatusOK, "")
+	}
+	if b == nil {
+		return Error(req, http.StatusNotFound, "BlobNotFound")
+	}
+	if resp := checkConditions(req, b); resp != nil {
+		return resp
+	}
+
+	switch {
+	case req.Method == http.MethodHead && comp == "":
+		if resp := s.checkLease(req, b, true); resp != nil {
+			return resp
+		}
+		return s.properties(req, b, http.StatusOK, "")
+	case req.Method == http.MethodGet && comp == "":
+		if resp := s.checkLease(req, b, true); resp != nil {
+			return resp
+		}
+		return s.download(req, b)
+	case req.Method == http.MethodDelete && comp == "":
+		if resp := s.checkLease(req, b, false); resp != nil {
+			return resp
+		}
+		delete(c.blobs, name)
+		if s.SoftDelete {
+			b.LeaseID, b.leaseState = "", "available"
+			c.deleted[name] = b
+		}
+		return Response(req, http.StatusAccepted, "")
+	case req.Method == http.MethodPut && comp == "lease":
+		return s.leaseBlob(req, b)
+	case req.Method == http.MethodPut && comp == "metadata":
+		if resp := s.checkLease(req, b, false); resp != nil {
+			return resp
+		}
+		b.Metadata = metadataHeaders(req)
+		return s.modified(req, b)
+	case req.Method == http.MethodPut && comp == "properties":
+		if resp := s.checkLease(req, b, false); resp != nil {
+			return resp
+		}
+		setHTTPHeaders(req, b)
+		return s.modified(req, b)
+	case req.Method == http.MethodPut && comp == "tags":
+		if resp := s.checkLease(req, b, true); resp != nil {
+			return resp
+		}
+		tags, err := readTags(req.Body)
+		if err != nil {
+			return Error(req, http.StatusBadRequest, "InvalidXmlDocument")
+		}
+		b.Tags = tags
+		return Response(req, http.StatusNoContent, "")
+	case req.Method == http.MethodGet && comp == "tags":
+		return writeTags(req, b.Tags)
+	case req.Method == http.MethodPut && comp == "tier":
+		if resp := s.checkLease(req, b, true); resp != nil {
+			return resp
+		}
+		tier := req.Header.Get("x-ms-access-tier")
+		switch tier {
+		case "Hot", "Cool", "Cold", "Archive":
+			b.AccessTier = tier
+			return Response(req, http.StatusOK, "")
+		}
+		return Error(req, http.StatusBadRequest, "InvalidHeaderValue")
+	}
+	return unsupported(req)
+}
+
+// checkConditions applies If-Match and If-None-Match to a request for an existing blob
+func checkConditions(req *http.Request, b *fakeBlob) *http.Response {
+	if match := req.Header.Get("If-Match"); match != "" && match != "*" && match != b.ETag {
+		return Error(req, http.StatusPreconditionFailed, "ConditionNotMet")
+	}
+	if noneMatch := req.Header.Get("If-None-Match"); noneMatch == "*" || (noneMatch != "" && noneMatch == b.ETag) {
+		return Error(req, http.StatusPreconditionFailed, "ConditionNotMet")
+	}
+	return nil
+}
+
+// checkLease applies the lease rules to a request. A write to a leased blob needs its lease ID;
+// a read, or a write where optional is set, only has to match it when one is sent.
+func (s *Server) checkLease(req *http.Request, b *fakeBlob, optional bool) *http.Response {
+	leaseID := req.Header.Get("x-ms-lease-id")
+	if !b.active(s.now()) {
+		if leaseID != "" {
+			return Error(req, http.StatusPreconditionFailed, "LeaseNotPresentWithBlobOperation")
+		}
+		return nil
+	}
+	switch {
+	case leaseID == "" && optional:
+		return nil
+	case leaseID == "":
+		return Error(req, http.StatusPreconditionFailed, "LeaseIdMissing")
+	case leaseID != b.LeaseID:
+		return Error(req, http.StatusPreconditionFailed, "LeaseIdMismatchWithBlobOperation")
+	}
+	return nil
+}
+
+// putBlob implements Put Blob for block blobs
+func (s *Server) putBlob(req *http.Request, c *fakeContainer, name string) *http.Response {
+	if req.Header.Get("x-ms-blob-type") != "BlockBlob" || req.Header.Get("x-ms-copy-source") != "" {
+		return unsupported(req)
+	}
+
+	b := c.blobs[name]
+	if b != nil {
+		if req.Header.Get("If-None-Match") == "*" {
+			return Error(req, http.StatusConflict, "BlobAlreadyExists")
+		}
+		if resp := checkConditions(req, b); resp != nil {
+			return resp
+		}
+		if resp := s.checkLease(req, b, false); resp != nil {
+			return resp
+		}
+	} else if match := req.Header.Get("If-Match"); match != "" {
+		return Error(req, http.StatusPreconditionFailed, "ConditionNotMet")
+	} else if req.Header.Get("x-ms-lease-id") != "" {
+		return Error(req, http.StatusPreconditionFailed, "LeaseNotPresentWithBlobOperation")
+	}
+
+	var content []byte
+	if req.Body != nil {
+		var err error
+		if content, err = io.ReadAll(req.Body); err != nil {
+			return Error(req, http.StatusBadRequest, "InvalidInput")
+		}
+	}
+	sum := md5.Sum(content)
+	transactional := base64.StdEncoding.EncodeToString(sum[:])
+	if sent := req.Header.Get("Content-MD5"); sent != "" && sent != transactional {
+		return Error(req, http.StatusBadRequest, "Md5Mismatch")
+	}
+
+	if b == nil {
+		b = &fakeBlob{Blob: Blob{CreationTime: s.now()}, leaseState: "available"}
+		c.blobs[name] = b
+	}
+	b.Content = content
+	setHTTPHeaders(req, b)
+	if b.ContentMD5 == "" {
+		b.ContentMD5 = transactional
+	}
+	b.Metadata = metadataHeaders(req)
+	b.Tags = nil
+	if tags := req.Header.Get("x-ms-tags"); tags != "" {
+		values, err := url.ParseQuery(tags)
+		if err != nil {
+			return Error(req, http.StatusBadRequest, "InvalidHeaderValue")
+		}
+		b.Tags = map[string]string{}
+		for key := range values {
+			b.Tags[key] = values.Get(key)
+		}
+	}
+	b.EncryptionScope = req.Header.Get("x-ms-encryption-scope")
+	b.AccessTier = "Hot"
+	if tier := req.Header.Get("x-ms-access-tier"); tier != "" {
+		b.AccessTier = tier
+	}
+	b.ETag, b.LastModified = s.nextETag(), s.now()
+
+	return Response(req, http.StatusCreated, "",
+		"ETag", b.ETag,
+		"Last-Modified", httpTime(b.LastModified),
+		"Content-MD5", transactional,
+		"x-ms-request-server-encrypted", "true")
+}
+
+// setHTTPHeaders replaces the HTTP headers of b with those sent with req
+func setHTTPHeaders(req *http.Request, b *fakeBlob) {
+	b.ContentType = req.Header.Get("x-ms-blob-content-type")
+	if b.ContentType == "" {
+		b.ContentType = "application/octet-stream"
+	}
+	b.ContentEncoding = req.Header.Get("x-ms-blob-content-encoding")
+	b.ContentMD5 = req.Header.Get("x-ms-blob-content-md5")
+	b.CacheControl = req.Header.Get("x-ms-blob-cache-control")
+	b.ContentDisposition = req.Header.Get("x-ms-blob-content-disposition")
+	b.ContentLanguage = req.Header.Get("x-ms-blob-content-language")
+}
+
+// metadataHeaders returns the x-ms-meta-* headers of req, keeping the case of their names
+func metadataHeaders(req *http.Request) map[string]string {
+	metadata := map[string]string{}
+	for name, values := range req.Header {
+		if len(name) > len("x-ms-meta-") && strings.EqualFold(name[:len("x-ms-meta-")], "x-ms-meta-") && len(values) > 0 {
+			metadata[name[len("x-ms-meta-"):]] = values[0]
+		}
+	}
+	return metadata
+}
+
+// modified records a change to the properties of b and responds with its new ETag
+func (s *Server) modified(req *http.Request, b *fakeBlob) *http.Response {
+	b.ETag, b.LastModified = s.nextETag(), s.now()
+	return Response(req, http.StatusOK, "", "ETag", b.ETag, "Last-Modified", httpTime(b.LastModified))
+}
+
+// properties responds with the properties of b as headers, and body as the content
+func (s *Server) properties(req *http.Request, b *fakeBlob, status int, body string, extra ...string) *http.Response {
+	state, leaseStatus, duration := b.lease(s.now())
+	header := []string{
+		"Content-Length", strconv.Itoa(len(b.Content)),
+		"Content-Type", b.ContentType,
+		"ETag", b.ETag,
+		"Last-Modified", httpTime(b.LastModified),
+		"x-ms-creation-time", httpTime(b.CreationTime),
+		"x-ms-blob-type", "BlockBlob",
+		"x-ms-lease-state", state,
+		"x-ms-lease-status", leaseStatus,
+		"x-ms-access-tier", b.AccessTier,
+		"x-ms-server-encrypted", "true",
+		"Accept-Ranges", "bytes",
+	}
+	if duration != "" {
+		header = append(header, "x-ms-lease-duration", duration)
+	}
+	for name, value := range map[string]string{
+		"Content-MD5":         b.ContentMD5,
+		"Content-Encoding":    b.ContentEncoding,
+		"Cache-Control":       b.CacheControl,
+		"Content-Disposition": b.ContentDisposition,
+		"Content-Language":    b.ContentLanguage,
+	} {
+		if value != "" {
+			header = append(header, name, value)
+		}
+	}
+	if b.EncryptionScope != "" {
+		header = append(header, "x-ms-encryption-scope", b.EncryptionScope)
+	}
+	if len(b.Tags) > 0 {
+		header = append(header, "x-ms-tag-count", strconv.Itoa(len(b.Tags)))
+	}
+	for key, value := range b.Metadata {
+		header = append(header, "x-ms-meta-"+key, value)
+	}
+	header = append(header, extra...)
+
+	resp := Response(req, status, body, header...)
+	resp.ContentLength = int64(len(body))
+	return resp
+}
+
+// download responds with the content of b, or the byte range asked for with x-ms-range
+func (s *Server) download(req *http.Request, b *fakeBlob) *http.Response {
+	rangeHeader := req.Header.Get("x-ms-range")
+	if rangeHeader == "" {
+		rangeHeader = req.Header.Get("Range")
+	}
+	if rangeHeader == "" {
+		return s.properties(req, b, http.StatusOK, string(b.Content))
+	}
+
+	var start, end int
+	size := len(b.Content)
+	if n, _ := fmt.Sscanf(rangeHeader, "bytes=%d-%d", &start, &end); n < 1 || start > size {
+		return Error(req, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+	} else if n == 1 || end >= size {
+		end = size - 1
+	}
+	body := string(b.Content[start : end+1])
+	resp := s.properties(req, b, http.StatusPartialContent, body, "Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+	resp.Header["Content-Length"] = []string{strconv.Itoa(len(body))}
+	return resp
+}
+
+// blobTags is the XML document of Get and Set Blob Tags
+type blobTags struct {
+	XMLName xml.Name `xml:"Tags"`
+	Tags    []struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	} `xml:"TagSet>Tag"`
+}
+
+func readTags(body io.Reader) (map[string]string, error) {
+	var doc blobTags
+	if err := xml.NewDecoder(body).Decode(&doc); err != nil {
+		return nil, err
+	}
+	tags := map[string]string{}
+	for _, tag := range doc.Tags {
+		tags[tag.Key] = tag.Value
+	}
+	return tags, nil
+}
+
+func writeTags(req *http.Request, tags map[string]string) *http.Response {
+	var doc blobTags
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		doc.Tags = append(doc.Tags, struct {
+			Key   string `xml:"Key"`
+			Value string `xml:"Value"`
+		}{key, tags[key]})
+	}
+	body, err := xml.Marshal(doc)
+	if err != nil {
+		return Error(req, http.StatusInternalServerError, "InternalError")
+	}
+	return Response(req, http.StatusOK, xml.Header+string(body), "Content-Type", "application/xml")
+}
@@ -0,0 +1,114 @@
+package blobclienttest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// leaseBlob implements Lease Blob
+func (s *Server) leaseBlob(req *http.Request, b *fakeBlob) *http.Response {
+	now := s.now()
+	state, _, _ := b.lease(now)
+	leaseID := req.Header.Get("x-ms-lease-id")
+	proposedID := req.Header.Get("x-ms-proposed-lease-id")
+
+	switch req.Header.Get("x-ms-lease-action") {
+	case "acquire":
+		duration, err := strconv.Atoi(req.Header.Get("x-ms-lease-duration"))
+		if err != nil || (duration != -1 && (duration < 15 || duration > 60)) {
+			return Error(req, http.StatusBadRequest, "InvalidHeaderValue")
+		}
+		if proposedID == "" {
+			proposedID = uuid.New().String()
+		}
+		switch {
+		case state == "breaking":
+			return Error(req, http.StatusConflict, "LeaseIsBreakingAndCannotBeAcquired")
+		case state == "leased" && proposedID != b.LeaseID:
+			return Error(req, http.StatusConflict, "LeaseAlreadyPresent")
+		}
+		b.LeaseID, b.leaseState = proposedID, "leased"
+		s.startLease(b, int32(duration), now)
+		return Response(req, http.StatusCreated, "", "x-ms-lease-id", b.LeaseID, "ETag", b.ETag, "Last-Modified", httpTime(b.LastModified))
+
+	case "renew":
+		switch {
+		case state == "available":
+			return Error(req, http.StatusConflict, "LeaseNotPresentWithLeaseOperation")
+		case leaseID != b.LeaseID:
+			return Error(req, http.StatusConflict, "LeaseIdMismatchWithLeaseOperation")
+		case state == "breaking":
+			return Error(req, http.StatusConflict, "LeaseIsBreakingAndCannotBeChanged")
+		case state == "broken":
+			return Error(req, http.StatusConflict, "LeaseIsBrokenAndCannotBeRenewed")
+		}
+		b.leaseState = "leased"
+		s.startLease(b, b.leaseDuration, now)
+		return Response(req, http.StatusOK, "", "x-ms-lease-id", b.LeaseID, "ETag", b.ETag, "Last-Modified", httpTime(b.LastModified))
+
+	case "change":
+		switch {
+		case state == "available" || state == "expired" || state == "broken":
+			return Error(req, http.StatusConflict, "LeaseNotPresentWithLeaseOperation")
+		case state == "breaking":
+			return Error(req, http.StatusConflict, "LeaseIsBreakingAndCannotBeChanged")
+		case leaseID != b.LeaseID && proposedID != b.LeaseID:
+			return Error(req, http.StatusConflict, "LeaseIdMismatchWithLeaseOperation")
+		}
+		b.LeaseID = proposedID
+		return Response(req, http.StatusOK, "", "x-ms-lease-id", b.LeaseID, "ETag", b.ETag, "Last-Modified", httpTime(b.LastModified))
+
+	case "release":
+		switch {
+		case state == "available":
+			return Error(req, http.StatusConflict, "LeaseNotPresentWithLeaseOperation")
+		case leaseID != b.LeaseID:
+			return Error(req, http.StatusConflict, "LeaseIdMismatchWithLeaseOperation")
+		}
+		b.LeaseID, b.leaseState = "", "available"
+		return Response(req, http.StatusOK, "", "ETag", b.ETag, "Last-Modified", httpTime(b.LastModified))
+
+	case "break":
+		if state == "available" {
+			return Error(req, http.StatusConflict, "LeaseNotPresentWithLeaseOperation")
+		}
+		remaining := time.Duration(0)
+		switch state {
+		case "leased":
+			// An infinite lease breaks at once unless a period is given; a fixed one at the latest when it lapses
+			if !b.leaseExpiry.IsZero() {
+				remaining = b.leaseExpiry.Sub(now)
+			}
+			if period := req.Header.Get("x-ms-lease-break-period"); period != "" {
+				seconds, err := strconv.Atoi(period)
+				if err != nil || seconds < 0 || seconds > 60 {
+					return Error(req, http.StatusBadRequest, "InvalidHeaderValue")
+				}
+				if p := time.Duration(seconds) * time.Second; b.leaseExpiry.IsZero() || p < remaining {
+					remaining = p
+				}
+			}
+		case "breaking":
+			remaining = b.breakEnd.Sub(now)
+		}
+		if remaining > 0 {
+			b.leaseState, b.breakEnd = "breaking", now.Add(remaining)
+		} else {
+			b.leaseState = "broken"
+		}
+		return Response(req, http.StatusAccepted, "", "x-ms-lease-time", strconv.Itoa(int(remaining.Seconds())), "ETag", b.ETag, "Last-Modified", httpTime(b.LastModified))
+	}
+	return Error(req, http.StatusBadRequest, "InvalidHeaderValue")
+}
+
+// startLease starts a lease of duration seconds on b at now, -1 meaning infinite
+func (s *Server) startLease(b *fakeBlob, duration int32, now time.Time) {
+	b.leaseDuration = duration
+	b.leaseExpiry = time.Time{}
+	if duration > 0 {
+		b.leaseExpiry = now.Add(time.Duration(duration) * time.Second)
+	}
+}
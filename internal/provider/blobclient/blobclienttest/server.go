@@ -0,0 +1,364 @@
+// Package blobclienttest provides an in-memory imitation of the Azure Blob Storage service, so
+// code built on blobclient can be tested without a storage account. It implements the subset
+// of the REST API the provider uses for block blobs: containers, Put Blob, properties,
+// metadata, tags, tiers, leases, soft delete and listing. Other operations fail with a 400
+// UnsupportedByFake error.
+package blobclienttest
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+)
+
+// Credential hands out a static bearer token, which the fake service accepts
+type Credential struct{}
+
+func (Credential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "blobclienttest", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// Server is a fake storage service holding the containers and blobs of any number of storage
+// accounts. It implements policy.Transporter and is safe for concurrent use.
+type Server struct {
+	// SoftDelete keeps deleted blobs as soft-deleted, restorable with Undelete
+	SoftDelete bool
+
+	mu         sync.Mutex
+	now        func() time.Time
+	etags      int
+	containers map[string]*fakeContainer
+	intercept  func(*http.Request) *http.Response
+	requests   []*http.Request
+}
+
+// fakeContainer is a container and its blobs, both live and soft-deleted
+type fakeContainer struct {
+	publicAccess string
+	etag         string
+	lastModified time.Time
+	blobs        map[string]*fakeBlob
+	deleted      map[string]*fakeBlob
+}
+
+// NewServer returns an empty fake storage service
+func NewServer() *Server {
+	return &Server{now: time.Now, containers: map[string]*fakeContainer{}}
+}
+
+// NewClient returns a client that sends its requests to s with a static bearer token
+func (s *Server) NewClient(options blobclient.ClientOptions) (*blobclient.AzureBlobLeaseClient, error) {
+	options.Credential = Credential{}
+	options.Transport = s
+	return blobclient.NewAzureBlobLeaseClient(&options)
+}
+
+// SetNow replaces the clock used for timestamps and lease expiry
+func (s *Server) SetNow(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// Intercept makes every request go to fn first. When fn returns a response, it is sent instead
+// of the one the fake service would have sent. A request whose context ends while fn holds it
+// fails with the context error, as a request stalled on the network would. A nil fn removes the
+// interception.
+func (s *Server) Intercept(fn func(*http.Request) *http.Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.intercept = fn
+}
+
+// Count returns the number of requests received that match match
+func (s *Server) Count(match func(*http.Request) bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for _, req := range s.requests {
+		if match(req) {
+			n++
+		}
+	}
+	return n
+}
+
+// Do handles a request to any storage account as the service would
+func (s *Server) Do(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	canonicalizeHeaders(req.Header)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	intercept := s.intercept
+	s.mu.Unlock()
+
+	if intercept != nil {
+		if resp := intercept(req); resp != nil {
+			return resp, nil
+		}
+		if err := req.Context().Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.handle(req), nil
+}
+
+// canonicalizeHeaders rewrites the header names the SDK sets in lower case so Header.Get finds
+// them, leaving the x-ms-meta-* names alone because the case of a metadata name is kept
+func canonicalizeHeaders(header http.Header) {
+	for name, values := range header {
+		if strings.HasPrefix(strings.ToLower(name), "x-ms-meta-") {
+			continue
+		}
+		if canonical := http.CanonicalHeaderKey(name); canonical != name {
+			delete(header, name)
+			header[canonical] = append(header[canonical], values...)
+		}
+	}
+}
+
+// handle routes a request by its container, blob name and comp query value
+func (s *Server) handle(req *http.Request) *http.Response {
+	if req.Header.Get("Authorization") == "" {
+		return Error(req, http.StatusForbidden, "NoAuthenticationInformation")
+	}
+
+	account := strings.SplitN(req.URL.Host, ".", 2)[0]
+	containerName, blobName, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/"), "/")
+	query := req.URL.Query()
+
+	switch {
+	case containerName == "":
+		if req.Method == http.MethodGet && query.Get("comp") == "list" {
+			return s.listContainers(req, account)
+		}
+	case blobName == "" && query.Get("restype") == "container":
+		return s.handleContainer(req, account, containerName)
+	case blobName != "":
+		return s.handleBlob(req, account, containerName, blobName)
+	}
+	return unsupported(req)
+}
+
+// Response builds a response to req with the given status, body and headers, given as name,
+// value pairs. Header names are canonicalized as net/http does for a response read off the
+// wire, so metadata names come back as, e.g., "Team" rather than "team".
+func Response(req *http.Request, status int, body string, header ...string) *http.Response {
+	resp := &http.Response{
+		StatusCode:    status,
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:        http.Header{},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		resp.Header.Set(header[i], header[i+1])
+	}
+	resp.Header.Set("x-ms-request-id", "blobclienttest")
+	resp.Header.Set("x-ms-version", "2023-11-03")
+	return resp
+}
+
+// Error builds a storage service error response with the given error code
+func Error(req *http.Request, status int, code string) *http.Response {
+	body := fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?><Error><Code>%s</Code><Message>%s (blobclienttest)</Message></Error>`, code, code)
+	return Response(req, status, body, "x-ms-error-code", code, "Content-Type", "application/xml")
+}
+
+// unsupported rejects an operation the fake service does not implement
+func unsupported(req *http.Request) *http.Response {
+	return Error(req, http.StatusBadRequest, "UnsupportedByFake")
+}
+
+// httpTime formats a time as the service does in headers
+func httpTime(t time.Time) string {
+	return t.UTC().Format(http.TimeFormat)
+}
+
+// nextETag returns a new unique ETag
+func (s *Server) nextETag() string {
+	s.etags++
+	return fmt.Sprintf(`"0x8DC%013X"`, s.etags)
+}
+
+// container returns the container of account, or nil when it does not exist
+func (s *Server) container(account, name string) *fakeContainer {
+	return s.containers[account+"/"+name]
+}
+
+// CreateContainer creates a container directly, as if it had been created by someone else
+func (s *Server) CreateContainer(account, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.createContainer(account, name, "")
+}
+
+func (s *Server) createContainer(account, name, publicAccess string) *fakeContainer {
+	c := &fakeContainer{
+		publicAccess: publicAccess,
+		etag:         s.nextETag(),
+		lastModified: s.now(),
+		blobs:        map[string]*fakeBlob{},
+		deleted:      map[string]*fakeBlob{},
+	}
+	s.containers[account+"/"+name] = c
+	return c
+}
+
+// handleContainer implements the container operations
+func (s *Server) handleContainer(req *http.Request, account, name string) *http.Response {
+	c := s.container(account, name)
+	query := req.URL.Query()
+
+	switch comp := query.Get("comp"); {
+	case comp == "" && req.Method == http.MethodPut:
+		if c != nil {
+			return Error(req, http.StatusConflict, "ContainerAlreadyExists")
+		}
+		c = s.createContainer(account, name, req.Header.Get("x-ms-blob-public-access"))
+		return Response(req, http.StatusCreated, "", "ETag", c.etag, "Last-Modified", httpTime(c.lastModified))
+	case comp == "" && (req.Method == http.MethodGet || req.Method == http.MethodHead):
+		if c == nil {
+			return Error(req, http.StatusNotFound, "ContainerNotFound")
+		}
+		header := []string{"ETag", c.etag, "Last-Modified", httpTime(c.lastModified),
+			"x-ms-lease-state", "available", "x-ms-lease-status", "unlocked"}
+		if c.publicAccess != "" {
+			header = append(header, "x-ms-blob-public-access", c.publicAccess)
+		}
+		return Response(req, http.StatusOK, "", header...)
+	case comp == "acl" && req.Method == http.MethodPut:
+		if c == nil {
+			return Error(req, http.StatusNotFound, "ContainerNotFound")
+		}
+		c.publicAccess = req.Header.Get("x-ms-blob-public-access")
+		c.etag, c.lastModified = s.nextETag(), s.now()
+		return Response(req, http.StatusOK, "", "ETag", c.etag, "Last-Modified", httpTime(c.lastModified))
+	case comp == "acl" && req.Method == http.MethodGet:
+		if c == nil {
+			return Error(req, http.StatusNotFound, "ContainerNotFound")
+		}
+		header := []string{"ETag", c.etag, "Last-Modified", httpTime(c.lastModified), "Content-Type", "application/xml"}
+		if c.publicAccess != "" {
+			header = append(header, "x-ms-blob-public-access", c.publicAccess)
+		}
+		return Response(req, http.StatusOK, `<?xml version="1.0" encoding="utf-8"?><SignedIdentifiers />`, header...)
+	case comp == "list" && req.Method == http.MethodGet:
+		if c == nil {
+			return Error(req, http.StatusNotFound, "ContainerNotFound")
+		}
+		return s.listBlobs(req, c)
+	}
+	return unsupported(req)
+}
+
+// listContainers lists the live containers of account
+func (s *Server) listContainers(req *http.Request, account string) *http.Response {
+	prefix := req.URL.Query().Get("prefix")
+	type item struct {
+		Name string `xml:"Name"`
+	}
+	var items []item
+	for key := range s.containers {
+		name := strings.TrimPrefix(key, account+"/")
+		if name != key && strings.HasPrefix(name, prefix) {
+			items = append(items, item{Name: name})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	body, err := xml.Marshal(struct {
+		XMLName    xml.Name `xml:"EnumerationResults"`
+		Containers []item   `xml:"Containers>Container"`
+		NextMarker string   `xml:"NextMarker"`
+	}{Containers: items})
+	if err != nil {
+		return Error(req, http.StatusInternalServerError, "InternalError")
+	}
+	return Response(req, http.StatusOK, xml.Header+string(body), "Content-Type", "application/xml")
+}
+
+// listBlobs lists the blobs of a container, including soft-deleted ones when asked to
+func (s *Server) listBlobs(req *http.Request, c *fakeContainer) *http.Response {
+	query := req.URL.Query()
+	prefix := query.Get("prefix")
+	includeDeleted := strings.Contains(query.Get("include"), "deleted")
+
+	type properties struct {
+		ContentLength int64  `xml:"Content-Length"`
+		ContentMD5    string `xml:"Content-MD5,omitempty"`
+		ContentType   string `xml:"Content-Type,omitempty"`
+		ETag          string `xml:"Etag"`
+		LastModified  string `xml:"Last-Modified"`
+		CreationTime  string `xml:"Creation-Time"`
+		BlobType      string `xml:"BlobType"`
+		AccessTier    string `xml:"AccessTier,omitempty"`
+		LeaseState    string `xml:"LeaseState"`
+		LeaseStatus   string `xml:"LeaseStatus"`
+	}
+	type item struct {
+		Name       string     `xml:"Name"`
+		Deleted    bool       `xml:"Deleted,omitempty"`
+		Properties properties `xml:"Properties"`
+	}
+
+	var items []item
+	add := func(name string, b *fakeBlob, deleted bool) {
+		if !strings.HasPrefix(name, prefix) {
+			return
+		}
+		state, status, _ := b.lease(s.now())
+		items = append(items, item{Name: name, Deleted: deleted, Properties: properties{
+			ContentLength: int64(len(b.Content)),
+			ContentMD5:    b.ContentMD5,
+			ContentType:   b.ContentType,
+			ETag:          b.ETag,
+			LastModified:  httpTime(b.LastModified),
+			CreationTime:  httpTime(b.CreationTime),
+			BlobType:      "BlockBlob",
+			AccessTier:    b.AccessTier,
+			LeaseState:    state,
+			LeaseStatus:   status,
+		}})
+	}
+	for name, b := range c.blobs {
+		add(name, b, false)
+	}
+	if includeDeleted {
+		for name, b := range c.deleted {
+			add(name, b, true)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	body, err := xml.Marshal(struct {
+		XMLName    xml.Name `xml:"EnumerationResults"`
+		Prefix     string   `xml:"Prefix"`
+		Blobs      []item   `xml:"Blobs>Blob"`
+		NextMarker string   `xml:"NextMarker"`
+	}{Prefix: prefix, Blobs: items})
+	if err != nil {
+		return Error(req, http.StatusInternalServerError, "InternalError")
+	}
+	return Response(req, http.StatusOK, xml.Header+string(body), "Content-Type", "application/xml")
+}
@@ -0,0 +1,75 @@
+package blobclienttest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+)
+
+const testLeaseID = "3f2504e0-4f89-11d3-9a0c-0305e82c3301"
+
+func TestClientLifecycle(t *testing.T) {
+	server := NewServer()
+	client, err := server.NewClient(blobclient.ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	config := blobclient.BlobLeaseConfig{
+		StorageAccount: "acct",
+		ContainerName:  "locks",
+		BlobName:       "env/app.lock",
+		Content:        []byte("locked"),
+		Metadata:       map[string]string{"team": "a"},
+		Tags:           map[string]string{"env": "prod"},
+		LeaseID:        testLeaseID,
+		LeaseDuration:  -1,
+	}
+
+	result, err := client.CreateBlobWithLease(ctx, config)
+	if err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if result.LeaseID != testLeaseID || result.ContentMD5 != blobclient.ContentMD5(config.Content) {
+		t.Errorf("unexpected create result: %+v", result)
+	}
+
+	state, err := client.GetBlobLeaseState(ctx, "acct", "locks", "env/app.lock")
+	if err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if state.LeaseState != "leased" || state.LeaseDuration != "infinite" || state.Metadata["Team"] != "a" || state.TagCount != 1 {
+		t.Errorf("unexpected properties: %+v", state)
+	}
+
+	// A second holder is turned away while the lease is held
+	other := config
+	other.LeaseID = "6f19fd39-0c8e-5dde-b640-65f641d9d5dc"
+	other.Overwrite = true
+	if _, err := client.CreateBlobWithLease(ctx, other); err == nil {
+		t.Error("expected a second holder to be rejected")
+	}
+
+	config.Content = []byte("updated")
+	if _, err := client.UpdateBlobContent(ctx, config); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if blob, _ := server.Blob("acct", "locks", "env/app.lock"); string(blob.Content) != "updated" || blob.LeaseID != testLeaseID {
+		t.Errorf("expected the content to be updated under the lease, got %+v", blob)
+	}
+
+	server.SetLease("acct", "locks", "env/app.lock", other.LeaseID)
+	if _, err := client.UpdateBlobContent(ctx, config); !errors.Is(err, blobclient.ErrLeaseLost) {
+		t.Errorf("expected ErrLeaseLost once the lease is taken, got: %v", err)
+	}
+	server.SetLease("acct", "locks", "env/app.lock", testLeaseID)
+
+	if err := client.ReleaseBlobLease(ctx, config, true); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+	if _, ok := server.Blob("acct", "locks", "env/app.lock"); ok {
+		t.Error("expected the blob to be deleted")
+	}
+}
@@ -0,0 +1,147 @@
+package blobclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/appendblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/pageblob"
+)
+
+// Blob types that can be created
+const (
+	BlobTypeBlock  = "block"
+	BlobTypeAppend = "append"
+	BlobTypePage   = "page"
+)
+
+// PageBlobAlignment is the size in bytes page blob sizes must be a multiple of
+const PageBlobAlignment = pageblob.PageBytes
+
+// maxAppendBlockBytes is the largest block accepted by Append Block on every service version
+const maxAppendBlockBytes = 4 * 1024 * 1024
+
+// blobTypeName maps a blob type reported by the service to the names used in configuration
+func blobTypeName(blobType *blob.BlobType) string {
+	if blobType == nil {
+		return ""
+	}
+	switch *blobType {
+	case blob.BlobTypeAppendBlob:
+		return BlobTypeAppend
+	case blob.BlobTypePageBlob:
+		return BlobTypePage
+	default:
+		return BlobTypeBlock
+	}
+}
+
+// uploadBlob writes the content of config as a blob of config.BlobType, sending
+// config.ExtraHeaders with the requests that create it
+func (c *AzureBlobLeaseClient) uploadBlob(ctx context.Context, containerClient *container.Client, config BlobLeaseConfig, options uploadOptions) (*uploadResult, error) {
+	ctx = withExtraHeaders(ctx, config.ExtraHeaders)
+	switch config.BlobType {
+	case BlobTypeAppend:
+		return uploadAppendBlob(ctx, containerClient.NewAppendBlobClient(config.BlobName), config, options)
+	case BlobTypePage:
+		return createPageBlob(ctx, containerClient.NewPageBlobClient(config.BlobName), config.PageBlobSize, options)
+	default:
+		blockBlobClient := containerClient.NewBlockBlobClient(config.BlobName)
+		if config.CopySource != "" {
+			return copyBlockBlob(ctx, blockBlobClient, config, options)
+		}
+		if config.SourcePath != "" {
+			return uploadBlockBlobFile(ctx, blockBlobClient, config.SourcePath, options)
+		}
+		return uploadBlockBlob(ctx, blockBlobClient, config.Content, options)
+	}
+}
+
+// uploadAppendBlob creates an append blob and appends the content in blocks. Append blobs have no
+// whole-blob checksum, so the content MD5 is computed up front and stored as the Content-MD5 property.
+func uploadAppendBlob(ctx context.Context, client *appendblob.Client, config BlobLeaseConfig, options uploadOptions) (*uploadResult, error) {
+	var content io.ReadSeeker = bytes.NewReader(config.Content)
+	if config.SourcePath != "" {
+		file, err := os.Open(config.SourcePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open source file: %w", err)
+		}
+		defer file.Close()
+		content = file
+	}
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return nil, fmt.Errorf("failed to read blob content: %w", err)
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read blob content: %w", err)
+	}
+	sum := hash.Sum(nil)
+
+	resp, err := client.Create(ctx, &appendblob.CreateOptions{
+		HTTPHeaders: options.Headers.sdkHeaders(nil, sum),
+		Metadata:    options.Metadata,
+		Tags:        options.Tags,
+
+		AccessConditions: options.AccessConditions,
+		CPKScopeInfo:     options.EncryptionScope,
+	})
+	if err != nil {
+		return nil, err
+	}
+	etag, versionID, scope := resp.ETag, resp.VersionID, resp.EncryptionScope
+
+	block := make([]byte, maxAppendBlockBytes)
+	for {
+		n, err := io.ReadFull(content, block)
+		if n > 0 {
+			blockSum := md5.Sum(block[:n])
+			appendResp, err := client.AppendBlock(ctx, streaming.NopCloser(bytes.NewReader(block[:n])), &appendblob.AppendBlockOptions{
+				TransactionalValidation: blob.TransferValidationTypeMD5(blockSum[:]),
+				AccessConditions:        leaseConditions(options.LeaseID),
+				CPKScopeInfo:            options.EncryptionScope,
+			})
+			if err != nil {
+				return nil, err
+			}
+			etag = appendResp.ETag
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read blob content: %w", err)
+		}
+	}
+
+	return &uploadResult{ETag: etagValue(etag), ContentMD5: sum, VersionID: stringValue(versionID), EncryptionScope: stringValue(scope)}, nil
+}
+
+// createPageBlob creates an empty page blob of the given size. Page blob content is not written.
+func createPageBlob(ctx context.Context, client *pageblob.Client, size int64, options uploadOptions) (*uploadResult, error) {
+	if size <= 0 || size%PageBlobAlignment != 0 {
+		return nil, fmt.Errorf("page blob size must be a positive multiple of %d bytes, got: %d", PageBlobAlignment, size)
+	}
+
+	resp, err := client.Create(ctx, size, &pageblob.CreateOptions{
+		HTTPHeaders: options.Headers.sdkHeaders(nil, nil),
+		Metadata:    options.Metadata,
+		Tags:        options.Tags,
+
+		AccessConditions: options.AccessConditions,
+		CPKScopeInfo:     options.EncryptionScope,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &uploadResult{ETag: etagValue(resp.ETag), VersionID: stringValue(resp.VersionID), EncryptionScope: stringValue(resp.EncryptionScope)}, nil
+}
@@ -0,0 +1,157 @@
+package blobclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+const (
+	// breakerThreshold is the number of consecutive auth failures that opens the breaker
+	breakerThreshold = 5
+	// breakerWindow is the period within which failures count as consecutive, and how long an open
+	// breaker fails fast before letting a probe call through
+	breakerWindow = time.Minute
+)
+
+// ErrCircuitOpen is returned for calls short-circuited after repeated authentication failures
+var ErrCircuitOpen = errors.New("authentication circuit breaker open")
+
+// CircuitOpenError is returned instead of contacting a storage account whose recent calls all failed
+// authentication or authorization. Hint names the principal and the role it needs.
+type CircuitOpenError struct {
+	StorageAccount string
+	Failures       int
+	Hint           string
+	Err            error
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s for storage account %s after %d consecutive authentication failures, skipping calls for up to %s: %s (last error: %s)",
+		ErrCircuitOpen, e.StorageAccount, e.Failures, breakerWindow, e.Hint, e.Err)
+}
+
+func (e *CircuitOpenError) Unwrap() []error {
+	return []error{ErrCircuitOpen, e.Err}
+}
+
+// breakerState tracks consecutive auth failures for one storage account
+type breakerState struct {
+	failures     int
+	firstFailure time.Time
+	open         *CircuitOpenError
+	// openedAt is when the breaker last opened or a probe last failed
+	openedAt time.Time
+	// probing is set while the one call let through by an open breaker is in flight
+	probing bool
+}
+
+// authBreaker fails fast for storage accounts that keep rejecting the configured credential
+type authBreaker struct {
+	principal string
+	// now is injected so tests can move the clock past breakerWindow
+	now func() time.Time
+
+	mu       sync.Mutex
+	accounts map[string]*breakerState
+}
+
+func newAuthBreaker(principal string) *authBreaker {
+	return &authBreaker{principal: principal, now: time.Now, accounts: map[string]*breakerState{}}
+}
+
+// check returns the cached error when the breaker for host is open. Once breakerWindow has passed
+// since it opened, a single call is let through as a probe: its success closes the breaker and
+// another auth failure keeps it open for a further window.
+func (b *authBreaker) check(host string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, ok := b.accounts[host]
+	if !ok || state.open == nil {
+		return nil
+	}
+	if state.probing || b.now().Sub(state.openedAt) < breakerWindow {
+		return state.open
+	}
+	state.probing = true
+	return nil
+}
+
+// release ends a probe that failed for a reason other than authentication, so that the next
+// call probes again instead of the breaker deciding on an unrelated error
+func (b *authBreaker) release(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if state, ok := b.accounts[host]; ok {
+		state.probing = false
+	}
+}
+
+// record updates the breaker for host with the outcome of a call
+func (b *authBreaker) record(host string, authFailure bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !authFailure {
+		delete(b.accounts, host)
+		return
+	}
+
+	now := b.now()
+	state, ok := b.accounts[host]
+	if !ok || (state.open == nil && now.Sub(state.firstFailure) > breakerWindow) {
+		state = &breakerState{firstFailure: now}
+		b.accounts[host] = state
+	}
+	state.failures++
+
+	if state.failures >= breakerThreshold {
+		// The error is handed out to callers, so a failed probe replaces it rather than updating it
+		account := strings.SplitN(host, ".", 2)[0]
+		state.open = &CircuitOpenError{
+			StorageAccount: account,
+			Failures:       state.failures,
+			Hint: fmt.Sprintf("grant %s the 'Storage Blob Data Contributor' role on storage account %s, or fix the credential configuration",
+				b.principal, account),
+			Err: err,
+		}
+		state.openedAt = now
+		state.probing = false
+	}
+}
+
+// breakerPolicy consults the auth breaker before each call and records the outcome afterwards
+type breakerPolicy struct {
+	breaker *authBreaker
+}
+
+func (p breakerPolicy) Do(req *policy.Request) (*http.Response, error) {
+	host := req.Raw().URL.Host
+	if err := p.breaker.check(host); err != nil {
+		return nil, err
+	}
+
+	resp, err := req.Next()
+
+	switch {
+	case err != nil:
+		if isAuthFailure(err) {
+			p.breaker.record(host, true, err)
+		} else {
+			p.breaker.release(host)
+		}
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		p.breaker.record(host, true, fmt.Errorf("storage service returned %s", resp.Status))
+	default:
+		p.breaker.record(host, false, nil)
+	}
+
+	return resp, err
+}
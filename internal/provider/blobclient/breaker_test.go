@@ -0,0 +1,283 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// scriptedStatuses answers requests with the given statuses in turn, repeating the last one
+type scriptedStatuses struct {
+	mu       sync.Mutex
+	statuses []int
+}
+
+func (s *scriptedStatuses) set(statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses = statuses
+}
+
+func (s *scriptedStatuses) handle(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	status := s.statuses[0]
+	if len(s.statuses) > 1 {
+		s.statuses = s.statuses[1:]
+	}
+	s.mu.Unlock()
+
+	if status == 0 {
+		return nil, errors.New("connection reset by peer")
+	}
+	if status == http.StatusForbidden {
+		return respondError(req, status, "AuthorizationPermissionMismatch"), nil
+	}
+	return respond(req, status, ""), nil
+}
+
+// breakerFixture sends requests through breakerPolicy with a clock the test controls
+type breakerFixture struct {
+	breaker   *authBreaker
+	script    *scriptedStatuses
+	transport *fakeTransport
+	pl        runtime.Pipeline
+
+	mu    sync.Mutex
+	clock time.Time
+}
+
+func newBreakerFixture(statuses ...int) *breakerFixture {
+	f := &breakerFixture{
+		breaker: newAuthBreaker("client ID test"),
+		script:  &scriptedStatuses{statuses: statuses},
+		clock:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	f.breaker.now = func() time.Time {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return f.clock
+	}
+	f.transport = &fakeTransport{handler: f.script.handle}
+	f.pl = runtime.NewPipeline("blobclient-test", "v0.0.0", runtime.PipelineOptions{
+		PerCall: []policy.Policy{breakerPolicy{breaker: f.breaker}},
+	}, &policy.ClientOptions{
+		Transport: f.transport,
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	})
+	return f
+}
+
+func (f *breakerFixture) advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.clock = f.clock.Add(d)
+}
+
+// send makes n calls to host and returns the error of the last
+func (f *breakerFixture) send(t *testing.T, host string, n int) error {
+	t.Helper()
+	var err error
+	for range n {
+		var req *policy.Request
+		req, err = runtime.NewRequest(context.Background(), http.MethodGet, "https://"+host+"/locks/state.lock")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = f.pl.Do(req)
+	}
+	return err
+}
+
+// sent returns the number of calls that reached the transport
+func (f *breakerFixture) sent() int {
+	return f.transport.count(func(*http.Request) bool { return true })
+}
+
+const breakerHost = "acct.blob.core.windows.net"
+
+func TestBreakerOpensAfterConsecutiveAuthFailures(t *testing.T) {
+	f := newBreakerFixture(http.StatusForbidden)
+
+	if err := f.send(t, breakerHost, breakerThreshold); errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the breaker to stay closed until the threshold, got: %s", err)
+	}
+
+	err := f.send(t, breakerHost, 1)
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("expected a CircuitOpenError, got: %v", err)
+	}
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Error("expected the error to match ErrCircuitOpen")
+	}
+	if openErr.StorageAccount != "acct" || openErr.Failures != breakerThreshold {
+		t.Errorf("unexpected error details: %+v", openErr)
+	}
+	if !strings.Contains(openErr.Hint, "client ID test") {
+		t.Errorf("expected the hint to name the principal, got: %s", openErr.Hint)
+	}
+	if got := f.sent(); got != breakerThreshold {
+		t.Errorf("expected the short-circuited call not to be sent, %d calls were sent", got)
+	}
+}
+
+func TestBreakerCountsOnlyConsecutiveFailures(t *testing.T) {
+	tests := map[string]func(t *testing.T, f *breakerFixture){
+		"success in between": func(t *testing.T, f *breakerFixture) {
+			f.script.set(http.StatusForbidden, http.StatusForbidden, http.StatusForbidden, http.StatusForbidden, http.StatusOK, http.StatusForbidden)
+		},
+		"failures outside the window": func(t *testing.T, f *breakerFixture) {
+			f.script.set(http.StatusForbidden)
+			f.send(t, breakerHost, breakerThreshold-1)
+			f.advance(breakerWindow + time.Second)
+		},
+	}
+
+	for name, setup := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := newBreakerFixture(http.StatusForbidden)
+			setup(t, f)
+			if err := f.send(t, breakerHost, breakerThreshold); errors.Is(err, ErrCircuitOpen) {
+				t.Fatalf("expected the breaker to stay closed, got: %s", err)
+			}
+		})
+	}
+}
+
+func TestBreakerIsPerAccount(t *testing.T) {
+	f := newBreakerFixture(http.StatusForbidden)
+	f.send(t, breakerHost, breakerThreshold)
+
+	f.script.set(http.StatusOK)
+	if err := f.send(t, "other.blob.core.windows.net", 1); err != nil {
+		t.Fatalf("expected another account to be unaffected, got: %s", err)
+	}
+}
+
+func TestBreakerProbeClosesOnSuccess(t *testing.T) {
+	f := newBreakerFixture(http.StatusForbidden)
+	f.send(t, breakerHost, breakerThreshold)
+
+	f.script.set(http.StatusOK)
+	f.advance(breakerWindow - time.Second)
+	if err := f.send(t, breakerHost, 1); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the breaker to stay open within the window, got: %v", err)
+	}
+
+	f.advance(2 * time.Second)
+	if err := f.send(t, breakerHost, 1); err != nil {
+		t.Fatalf("expected the probe to go through, got: %s", err)
+	}
+	if err := f.send(t, breakerHost, 3); err != nil {
+		t.Fatalf("expected the breaker to be closed after a successful probe, got: %s", err)
+	}
+	if got := f.sent(); got != breakerThreshold+4 {
+		t.Errorf("expected %d calls to be sent, got %d", breakerThreshold+4, got)
+	}
+}
+
+func TestBreakerProbeReopensOnAuthFailure(t *testing.T) {
+	f := newBreakerFixture(http.StatusForbidden)
+	f.send(t, breakerHost, breakerThreshold)
+
+	f.advance(breakerWindow)
+	if err := f.send(t, breakerHost, 1); errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the probe to reach the service, got: %s", err)
+	}
+	if got := f.sent(); got != breakerThreshold+1 {
+		t.Fatalf("expected the probe to be sent, %d calls were sent", got)
+	}
+
+	err := f.send(t, breakerHost, 1)
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("expected the failed probe to reopen the breaker, got: %v", err)
+	}
+	if openErr.Failures != breakerThreshold+1 {
+		t.Errorf("expected the failed probe to be counted, got %d failures", openErr.Failures)
+	}
+
+	// A failed probe starts a new window before the next one
+	f.advance(breakerWindow - time.Second)
+	if err := f.send(t, breakerHost, 1); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the breaker to stay open for another window, got: %v", err)
+	}
+	f.script.set(http.StatusOK)
+	f.advance(time.Second)
+	if err := f.send(t, breakerHost, 1); err != nil {
+		t.Fatalf("expected the next probe to close the breaker, got: %s", err)
+	}
+}
+
+func TestBreakerProbesOneCallAtATime(t *testing.T) {
+	f := newBreakerFixture(http.StatusForbidden)
+	f.send(t, breakerHost, breakerThreshold)
+	f.advance(breakerWindow)
+
+	release := make(chan struct{})
+	f.transport.handler = func(req *http.Request) (*http.Response, error) {
+		<-release
+		return respond(req, http.StatusOK, ""), nil
+	}
+
+	probe := make(chan error)
+	go func() {
+		req, err := runtime.NewRequest(context.Background(), http.MethodGet, "https://"+breakerHost+"/locks/state.lock")
+		if err == nil {
+			_, err = f.pl.Do(req)
+		}
+		probe <- err
+	}()
+	f.transport.waitFor(t, breakerThreshold+1)
+
+	if err := f.send(t, breakerHost, 1); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected calls during the probe to be short-circuited, got: %v", err)
+	}
+	close(release)
+	if err := <-probe; err != nil {
+		t.Fatalf("unexpected probe error: %s", err)
+	}
+	if err := f.send(t, breakerHost, 1); err != nil {
+		t.Errorf("expected the breaker to close after the probe, got: %s", err)
+	}
+}
+
+func TestBreakerProbeReleasedOnOtherErrors(t *testing.T) {
+	f := newBreakerFixture(http.StatusForbidden)
+	f.send(t, breakerHost, breakerThreshold)
+	f.advance(breakerWindow)
+
+	// A connection error says nothing about the credential, so the next call probes again
+	f.script.set(0, http.StatusOK)
+	if err := f.send(t, breakerHost, 1); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the probe to fail with the connection error, got: %v", err)
+	}
+	if err := f.send(t, breakerHost, 1); err != nil {
+		t.Fatalf("expected another probe to go through and close the breaker, got: %s", err)
+	}
+}
+
+func TestCircuitOpenErrorThroughClient(t *testing.T) {
+	transport := &fakeTransport{handler: func(req *http.Request) (*http.Response, error) {
+		return respondError(req, http.StatusForbidden, "AuthorizationPermissionMismatch"), nil
+	}}
+	client := newTestClient(transport, ClientOptions{})
+
+	var err error
+	for range breakerThreshold + 1 {
+		_, err = client.GetBlobLeaseState(context.Background(), "acct", "locks", "state.lock")
+	}
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen from the client, got: %v", err)
+	}
+	if got := transport.count(func(*http.Request) bool { return true }); got != breakerThreshold {
+		t.Errorf("expected %d calls to be sent, got %d", breakerThreshold, got)
+	}
+}
@@ -0,0 +1,165 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bulkRollbackTimeout bounds releasing the leases of a failed atomic acquire, which runs even
+// when the context of the acquire has expired
+const bulkRollbackTimeout = time.Minute
+
+// ErrLeasesNotAcquired indicates the lease on some of the blobs of a bulk acquire could not be
+// acquired
+var ErrLeasesNotAcquired = errors.New("blob leases not acquired")
+
+// BulkLeaseError is returned by AcquireBlobLeases when the lease on some of the blobs could not be
+// acquired
+type BulkLeaseError struct {
+	// Failed holds why the lease on each blob that could not be leased was not acquired, by blob
+	// name
+	Failed map[string]error
+	// RolledBack lists the blobs whose lease was acquired and then released again because the
+	// acquire was atomic
+	RolledBack []string
+	// RollbackFailed holds why releasing the lease on a blob during a rollback failed, by blob
+	// name. These leases are still held.
+	RollbackFailed map[string]error
+}
+
+func (e *BulkLeaseError) Error() string {
+	failures := make([]string, 0, len(e.Failed))
+	for _, name := range sortedKeys(e.Failed) {
+		failures = append(failures, fmt.Sprintf("%s: %s", name, e.Failed[name]))
+	}
+	return fmt.Sprintf("%s on %d blobs: %s", ErrLeasesNotAcquired, len(e.Failed), strings.Join(failures, "; "))
+}
+
+func (e *BulkLeaseError) Unwrap() error {
+	return ErrLeasesNotAcquired
+}
+
+// Contended returns the sorted names of the blobs that could not be leased because someone else
+// held their lease
+func (e *BulkLeaseError) Contended() []string {
+	var names []string
+	for _, name := range sortedKeys(e.Failed) {
+		if IsLeaseContended(e.Failed[name]) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// IsLeaseContended reports whether err means a lease could not be acquired because someone else
+// held it, including after waiting for it to be given up
+func IsLeaseContended(err error) bool {
+	return isLeaseHeld(err) || isLeaseBreaking(err) || errors.Is(err, ErrLeaseWaitTimeout)
+}
+
+// AcquireBlobLeases acquires the lease on each blob of configs concurrently, with the lease ID,
+// duration and waiting of its config, and returns the results by blob name. When some leases
+// cannot be acquired, the error is a *BulkLeaseError. If atomic is set, the leases that were
+// acquired are then released again and only the results of the leases that could not be released
+// are returned, as they are still held; otherwise the results of the acquired leases are returned
+// with the error.
+func (c *AzureBlobLeaseClient) AcquireBlobLeases(ctx context.Context, configs []BlobLeaseConfig, atomic bool) (map[string]*BlobLeaseResult, error) {
+	results := make(map[string]*BlobLeaseResult, len(configs))
+	failed := map[string]error{}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, config := range configs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := c.AcquireBlobLease(ctx, config)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[config.BlobName] = err
+				return
+			}
+			results[config.BlobName] = result
+		}()
+	}
+	wg.Wait()
+
+	if len(failed) == 0 {
+		return results, nil
+	}
+	bulkErr := &BulkLeaseError{Failed: failed}
+	if !atomic {
+		return results, bulkErr
+	}
+
+	// Roll back with a context of its own, so that the leases are released even when the acquire
+	// failed because its context expired
+	rollbackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), bulkRollbackTimeout)
+	defer cancel()
+
+	var acquired []BlobLeaseConfig
+	for _, config := range configs {
+		if result, ok := results[config.BlobName]; ok {
+			config.LeaseID = result.LeaseID
+			acquired = append(acquired, config)
+		}
+	}
+	bulkErr.RollbackFailed = c.ReleaseBlobLeases(rollbackCtx, acquired)
+	for _, config := range acquired {
+		if _, ok := bulkErr.RollbackFailed[config.BlobName]; !ok {
+			bulkErr.RolledBack = append(bulkErr.RolledBack, config.BlobName)
+		}
+	}
+	sort.Strings(bulkErr.RolledBack)
+
+	var held map[string]*BlobLeaseResult
+	for name := range bulkErr.RollbackFailed {
+		if held == nil {
+			held = map[string]*BlobLeaseResult{}
+		}
+		held[name] = results[name]
+	}
+	return held, bulkErr
+}
+
+// ReleaseBlobLeases releases the lease on each blob of configs concurrently, leaving the blobs in
+// place, and returns why releasing failed by blob name; nil if every lease was released
+func (c *AzureBlobLeaseClient) ReleaseBlobLeases(ctx context.Context, configs []BlobLeaseConfig) map[string]error {
+	var failed map[string]error
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, config := range configs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.ReleaseBlobLease(ctx, config, false); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				if failed == nil {
+					failed = map[string]error{}
+				}
+				failed[config.BlobName] = err
+			}
+		}()
+	}
+	wg.Wait()
+	return failed
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,112 +1,387 @@
 package blobclient
 
 import (
+	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/lease"
 )
 
-// AzureBlobLeaseClient is the main client for Azure Blob Storage lease operations
+// storageScope is the AAD scope used for Azure Storage data-plane tokens
+const storageScope = "https://storage.azure.com/.default"
+
+// defaultConnectTimeout bounds CanConnect when no client timeout is configured
+const defaultConnectTimeout = 30 * time.Second
+
+// ClientOptions holds optional settings for the Azure Blob Storage lease client
+type ClientOptions struct {
+	// Timeout bounds individual health-check calls. Zero means defaultConnectTimeout.
+	Timeout time.Duration
+	// AllowAnonymousReads retries read-only operations without a credential when authenticated
+	// access is rejected, and lets the client start even if no credential can be constructed.
+	AllowAnonymousReads bool
+	// UseAccountKeyLookup fetches storage account keys through the ARM ListKeys API and uses
+	// shared key authentication for data-plane calls instead of AAD bearer tokens.
+	UseAccountKeyLookup bool
+	// SubscriptionID is the subscription searched for storage accounts when looking up keys
+	SubscriptionID string
+	// AccountResourceGroups maps storage account names to a resource group name or full
+	// resource ID, avoiding a subscription-wide search during key lookup.
+	AccountResourceGroups map[string]string
+	// MaxConcurrentOperations limits in-flight requests per storage account. Zero means unlimited.
+	MaxConcurrentOperations int
+	// ReadFromSecondaryOnFailure retries read-only operations against the RA-GRS secondary
+	// endpoint when the primary returns 5xx or is unreachable. Results are marked StaleRead.
+	ReadFromSecondaryOnFailure bool
+	// DisableAuthCircuitBreaker turns off failing fast after repeated authentication failures
+	DisableAuthCircuitBreaker bool
+	// DefaultMetadata is written to every blob; per-blob metadata wins per key
+	DefaultMetadata map[string]string
+	// ProviderVersion is the version of the provider, recorded in the Terraform metadata of blobs
+	ProviderVersion string
+	// AllowHTTPEndpoints accepts http blob endpoints set with WithBlobEndpoint, for emulators
+	// such as Azurite. Credentials are then sent unencrypted.
+	AllowHTTPEndpoints bool
+	// AllowDuplicateBlobTargets makes a blob claimed by two resources during one operation a
+	// warning rather than an error
+	AllowDuplicateBlobTargets bool
+	// Credential is used instead of the credential resolved from the ARM_* environment variables
+	// or the default Azure credential chain
+	Credential azcore.TokenCredential
+	// Transport sends the HTTP requests of the storage clients instead of the SDK default, for
+	// example to a fake storage service in tests
+	Transport policy.Transporter
+}
+
+// AzureBlobLeaseClient is the main client for Azure Blob Storage lease operations.
+// It is safe for concurrent use.
 type AzureBlobLeaseClient struct {
 	credential azcore.TokenCredential
+	options    ClientOptions
+	keys       *accountKeyCache
+	limiter    *accountLimiter
+	breaker    *authBreaker
+	targets    *targetRegistry
 }
 
 // NewAzureBlobLeaseClient creates a new Azure Blob Storage lease client with Azure authentication
-func NewAzureBlobLeaseClient() (*AzureBlobLeaseClient, error) {
-    clientID := os.Getenv("ARM_CLIENT_ID")
-    clientSecret := os.Getenv("ARM_CLIENT_SECRET")
-    tenantID := os.Getenv("ARM_TENANT_ID")
-    oidcToken := os.Getenv("ARM_OIDC_TOKEN")
-    useOIDC := os.Getenv("ARM_USE_OIDC")
-
-    var cred azcore.TokenCredential
-    var err error
-
-    if useOIDC == "true" && clientID != "" && tenantID != "" && oidcToken != "" {
-        // Use OIDC token authentication (Azure DevOps/GitHub Actions style)
-        cred, err = azidentity.NewClientAssertionCredential(tenantID, clientID, func(context.Context) (string, error) {
-            return oidcToken, nil
-        }, nil)
-        if err != nil {
-            return nil, fmt.Errorf("failed to create ClientAssertionCredential with OIDC: %w", err)
-        }
-    } else if clientID != "" && clientSecret != "" && tenantID != "" {
-        // Build credential from ARM_* variables (Terraform style)
-        cred, err = azidentity.NewClientSecretCredential(tenantID, clientID, clientSecret, nil)
-        if err != nil {
-            return nil, fmt.Errorf("failed to create ClientSecretCredential: %w", err)
-        }
-    } else {
-        // Fallback: standard Azure SDK auth chain
-        cred, err = azidentity.NewDefaultAzureCredential(nil)
-        if err != nil {
-            return nil, fmt.Errorf("failed to create DefaultAzureCredential: %w", err)
-        }
-    }
-
-    return &AzureBlobLeaseClient{
-        credential: cred,
-    }, nil
-}
-
-// CreateBlobClient creates a blob client for the specified storage account
-func (c *AzureBlobLeaseClient) CreateBlobClient(storageAccount string) (*azblob.Client, error) {
-	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", storageAccount)
-	client, err := azblob.NewClient(serviceURL, c.credential, nil)
+func NewAzureBlobLeaseClient(options *ClientOptions) (*AzureBlobLeaseClient, error) {
+	if options == nil {
+		options = &ClientOptions{}
+	}
+
+	clientID := os.Getenv("ARM_CLIENT_ID")
+	clientSecret := os.Getenv("ARM_CLIENT_SECRET")
+	tenantID := os.Getenv("ARM_TENANT_ID")
+	oidcToken := os.Getenv("ARM_OIDC_TOKEN")
+	useOIDC := os.Getenv("ARM_USE_OIDC")
+
+	var cred azcore.TokenCredential
+	var err error
+
+	if options.Credential != nil {
+		cred = options.Credential
+	} else if useOIDC == "true" && clientID != "" && tenantID != "" && oidcToken != "" {
+		// Use OIDC token authentication (Azure DevOps/GitHub Actions style)
+		cred, err = azidentity.NewClientAssertionCredential(tenantID, clientID, func(context.Context) (string, error) {
+			return oidcToken, nil
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ClientAssertionCredential with OIDC: %w", err)
+		}
+	} else if clientID != "" && clientSecret != "" && tenantID != "" {
+		// Build credential from ARM_* variables (Terraform style)
+		cred, err = azidentity.NewClientSecretCredential(tenantID, clientID, clientSecret, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ClientSecretCredential: %w", err)
+		}
+	} else {
+		// Fallback: standard Azure SDK auth chain
+		cred, err = azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			if !options.AllowAnonymousReads {
+				return nil, fmt.Errorf("failed to create DefaultAzureCredential: %w", err)
+			}
+			// Read-only client: every write or lease operation will return ErrCredentialRequired
+			cred = nil
+		}
+	}
+
+	client := &AzureBlobLeaseClient{
+		credential: cred,
+		options:    *options,
+		targets:    newTargetRegistry(),
+	}
+	if !options.DisableAuthCircuitBreaker {
+		principal := "the credential resolved by DefaultAzureCredential"
+		if options.Credential != nil {
+			principal = "the configured credential"
+		} else if clientID != "" {
+			principal = fmt.Sprintf("client ID %s", clientID)
+		}
+		client.breaker = newAuthBreaker(principal)
+	}
+	if options.MaxConcurrentOperations > 0 {
+		client.limiter = newAccountLimiter(options.MaxConcurrentOperations)
+	}
+	if options.UseAccountKeyLookup {
+		if cred == nil {
+			return nil, fmt.Errorf("account key lookup requires an Azure credential: %w", err)
+		}
+		client.keys = newAccountKeyCache(cred, options.SubscriptionID, options.AccountResourceGroups)
+	}
+
+	return client, nil
+}
+
+// CreateBlobClient creates a blob client for the specified storage account, against the blob
+// endpoint set on ctx with WithBlobEndpoint if any
+func (c *AzureBlobLeaseClient) CreateBlobClient(ctx context.Context, storageAccount string) (*azblob.Client, error) {
+	serviceURL, err := c.serviceURL(ctx, storageAccount)
+	if err != nil {
+		return nil, err
+	}
+	return c.newBlobClient(ctx, storageAccount, serviceURL)
+}
+
+// newBlobClient creates an authenticated blob client for the given service URL of a storage account
+func (c *AzureBlobLeaseClient) newBlobClient(ctx context.Context, storageAccount, serviceURL string) (*azblob.Client, error) {
+	if c.credential == nil {
+		return nil, ErrCredentialRequired
+	}
+
+	if c.keys != nil {
+		sharedKey, err := c.keys.sharedKeyCredential(ctx, storageAccount)
+		if err != nil {
+			return nil, err
+		}
+		client, err := azblob.NewClientWithSharedKeyCredential(serviceURL, sharedKey, c.azblobOptions())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create blob client for %s: %w", storageAccount, err)
+		}
+		return client, nil
+	}
+
+	client, err := azblob.NewClient(serviceURL, c.credential, c.azblobOptions())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create blob client for %s: %w", storageAccount, err)
 	}
 	return client, nil
 }
 
+// azblobOptions returns the SDK client options shared by every blob client
+func (c *AzureBlobLeaseClient) azblobOptions() *azblob.ClientOptions {
+	// Stamp every call with a client request ID so failures can be correlated with service logs
+	perCall := []policy.Policy{runtime.NewRequestIDPolicy(), extraHeadersPolicy{}}
+	if c.breaker != nil {
+		perCall = append(perCall, breakerPolicy{breaker: c.breaker})
+	}
+	if c.limiter != nil {
+		perCall = append(perCall, concurrencyPolicy{limiter: c.limiter})
+	}
+
+	return &azblob.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			PerCallPolicies:  perCall,
+			PerRetryPolicies: []policy.Policy{requestIDPolicy{}},
+
+			InsecureAllowCredentialWithHTTP: c.options.AllowHTTPEndpoints,
+			Transport:                       c.options.Transport,
+		},
+	}
+}
+
+// CanConnect verifies that the credential can obtain a storage token and reach the given
+// storage account without creating anything. Failures are returned as *ConnectivityError.
+func (c *AzureBlobLeaseClient) CanConnect(ctx context.Context, storageAccount string) error {
+	timeout := c.options.Timeout
+	if timeout <= 0 {
+		timeout = defaultConnectTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if c.credential == nil {
+		return newAuthenticationError(storageAccount, ErrCredentialRequired)
+	}
+
+	// Request a token first so credential problems are reported separately from network problems
+	if _, err := c.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{storageScope}}); err != nil {
+		return newAuthenticationError(storageAccount, err)
+	}
+
+	blobClient, err := c.CreateBlobClient(ctx, storageAccount)
+	if err != nil {
+		return fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	if _, err := blobClient.ServiceClient().GetAccountInfo(ctx, nil); err != nil {
+		return classifyConnectivityError(storageAccount, err)
+	}
+
+	return nil
+}
+
 // BlobLeaseConfig holds configuration for blob lease operations
 type BlobLeaseConfig struct {
 	StorageAccount string
 	ContainerName  string
 	BlobName       string
 	Content        []byte
+	SourcePath     string // local file streamed as the blob content instead of Content, if set
+	Headers        BlobHTTPHeaders
+	Metadata       map[string]string // merged over ClientOptions.DefaultMetadata when written
+	Owner          string            // written as LeaseOwnerMetadataKey, winning over Metadata; empty writes none
+	Tags           map[string]string // blob index tags
+	AccessTier     string            // access tier set at upload; empty uses the account default
+	BlobType       string            // BlobTypeBlock (default), BlobTypeAppend or BlobTypePage
+	PageBlobSize   int64             // size of a page blob in bytes, a multiple of PageBlobAlignment
 	LeaseID        string
-	LeaseDuration  int32 // -1 for infinite, 15-60 for seconds (default: -1)
+	LeaseDuration  int32       // -1 for infinite, 15-60 for seconds (default: -1)
+	Expiry         *BlobExpiry // expiry applied after upload; nil leaves the blob without one
+
+	// ExtraHeaders are sent with the requests that upload the content, for service features
+	// without an option of their own. Names are checked with ValidateExtraHeader.
+	ExtraHeaders map[string]string
+	// TerraformMetadata describes the Terraform run writing the blob. It is merged below the
+	// default and per-blob metadata, so it never replaces a key set by either.
+	TerraformMetadata map[string]string
+	// EncryptionScope encrypts the content with a named encryption scope; empty uses the
+	// container or account default
+	EncryptionScope string
+	// CopySource is the URL of a blob, optionally with a SAS token, that a block blob is created
+	// from with a server-side copy instead of uploading Content
+	CopySource string
+
+	// SkipContainerCreate assumes the container exists instead of creating it when missing
+	SkipContainerCreate bool
+	// AcquireTimeout is how long creating or acquiring waits for a lease held by someone else
+	// to be given up; zero fails immediately
+	AcquireTimeout time.Duration
+	// BreakExistingLease breaks a lease held by someone else, once any AcquireTimeout has
+	// expired, so that creating or acquiring can take the blob over
+	BreakExistingLease bool
+	// StealIfOlderThan breaks a lease held by someone else without waiting when it was acquired
+	// at least this long ago, judged by the AcquiredAtMetadataKey metadata of the blob or else
+	// its last modification; zero never steals
+	StealIfOlderThan time.Duration
+	// OwnLeaseID is a lease ID held by the caller before, such as the one in state. A lease held
+	// with it or with LeaseID is never stolen.
+	OwnLeaseID string
+	// Overwrite replaces an existing blob on upload instead of failing with a BlobExistsError
+	Overwrite bool
+	// IfMatch is the ETag the blob must still have for UpdateBlobContent to rewrite it; empty
+	// skips the check
+	IfMatch string
+	// ContainerAccess is the public access level used if the container has to be created
+	ContainerAccess string
 }
 
 // BlobLeaseResult represents the result of blob lease operations
 type BlobLeaseResult struct {
-	LeaseID    string
-	BlobURL    string
-	ETag       string
-	LeaseState string
+	LeaseID      string
+	BlobURL      string
+	ETag         string
+	LeaseState   string
+	LeaseStatus  string     // locked or unlocked, set by property reads
+	ContentMD5   string     // base64-encoded MD5 of the content written, or stored on the blob for property reads
+	ContentType  string     // Content-Type of the blob, set by property reads
+	StaleRead    bool       // true when the result was read from the secondary endpoint and may lag the primary
+	ExpiresOn    *time.Time // when the service will delete the blob, nil if it never expires
+	CreatedOn    *time.Time // blob creation time, set by property reads
+	LastModified *time.Time // time of the last write to the blob, set by property reads
+	Headers      BlobHTTPHeaders
+	Metadata     map[string]string // metadata as stored on the blob, including defaults
+	TagCount     int64             // number of index tags on the blob, set by property reads
+	AccessTier   string            // current access tier, possibly inferred from the account default
+	BlobType     string            // BlobTypeBlock, BlobTypeAppend or BlobTypePage, set by property reads
+	Size         int64             // content length in bytes, set by property reads
+	VersionID    string            // current version of the blob, empty without blob versioning
+	BrokeLease   bool              // true when a lease held by someone else was broken to acquire this one
+	StolenFrom   string            // the holder of a lease broken by StealIfOlderThan, empty otherwise
+
+	// EncryptionScope is the encryption scope of the content, empty when encrypted with the account key
+	EncryptionScope string
+	// LegalHold and ImmutabilityPolicy are set by property reads; ImmutabilityPolicy is nil when
+	// the blob has none
+	LegalHold          bool
+	ImmutabilityPolicy *ImmutabilityPolicy
+	// LeaseExpiresOn is when a finite lease acquired or renewed by the call lapses, measured from
+	// just before the request; nil for infinite leases and property reads
+	LeaseExpiresOn *time.Time
+	// LeaseDuration is fixed or infinite while the blob is leased, set by property reads
+	LeaseDuration string
 }
 
-// CreateBlobWithLease creates a blob and immediately leases it
+// CreateBlobWithLease creates a blob and immediately leases it, waiting up to
+// config.AcquireTimeout while the blob is leased by someone else
 func (c *AzureBlobLeaseClient) CreateBlobWithLease(ctx context.Context, config BlobLeaseConfig) (*BlobLeaseResult, error) {
+	return c.withLeaseWait(ctx, config, func(ctx context.Context) (*BlobLeaseResult, error) {
+		return c.createBlobWithLease(ctx, config)
+	})
+}
+
+// createBlobWithLease makes a single attempt at CreateBlobWithLease
+func (c *AzureBlobLeaseClient) createBlobWithLease(ctx context.Context, config BlobLeaseConfig) (*BlobLeaseResult, error) {
 	// Create blob client
-	blobClient, err := c.CreateBlobClient(config.StorageAccount)
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create blob client: %w", err)
 	}
 
 	// Create container if it doesn't exist
-	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
-	_, err = containerClient.Create(ctx, nil)
-	if err != nil {
-		// Container might already exist, continue
-		if !strings.Contains(err.Error(), "ContainerAlreadyExists") {
-			return nil, fmt.Errorf("failed to create container %s: %w", config.ContainerName, err)
+	if !config.SkipContainerCreate {
+		if err := c.EnsureContainer(ctx, config.StorageAccount, config.ContainerName, config.ContainerAccess); err != nil {
+			return nil, err
 		}
 	}
+	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
 
 	// Upload blob
 	blobClientRef := containerClient.NewBlockBlobClient(config.BlobName)
-	uploadResp, err := blobClientRef.UploadBuffer(ctx, config.Content, nil)
+	upload, err := c.uploadBlob(ctx, containerClient, config, c.uploadOptions(config))
 	if err != nil {
-		return nil, fmt.Errorf("failed to upload blob %s: %w", config.BlobName, err)
+		if config.SkipContainerCreate && bloberror.HasCode(err, bloberror.ContainerNotFound) {
+			return nil, fmt.Errorf("%w: container %s does not exist in storage account %s and create_container is false: %w",
+				ErrContainerNotFound, config.ContainerName, config.StorageAccount, wrapError(err, "upload rejected"))
+		}
+		if !config.Overwrite && bloberror.HasCode(err, bloberror.BlobAlreadyExists, bloberror.ConditionNotMet) {
+			return nil, existingBlobError(ctx, blobClientRef, err)
+		}
+		if isEncryptionScopeDenied(err) {
+			return nil, fmt.Errorf("%w: upload of blob %s with encryption scope %q rejected: %w",
+				ErrEncryptionScopeDenied, config.BlobName, config.EncryptionScope, wrapError(err, "upload rejected"))
+		}
+		if isCopyError(err) {
+			return nil, err
+		}
+		return nil, wrapError(err, "failed to upload blob %s", config.BlobName)
+	}
+
+	// Set expiry before leasing so an unsupported account fails without leaving a held lease
+	var expiresOn *time.Time
+	if config.Expiry != nil {
+		expiresOn, err = setExpiry(ctx, blobClientRef, config.StorageAccount, config.Expiry)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Acquire lease
@@ -121,23 +396,255 @@ func (c *AzureBlobLeaseClient) CreateBlobWithLease(ctx context.Context, config B
 	if leaseDuration == 0 {
 		leaseDuration = -1 // Default to infinite
 	}
+	acquiredAt := time.Now()
 	acquireResp, err := leaseClient.AcquireLease(ctx, leaseDuration, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to acquire lease on blob %s: %w", config.BlobName, err)
+		return nil, wrapError(err, "failed to acquire lease on blob %s", config.BlobName)
 	}
 
 	return &BlobLeaseResult{
 		LeaseID:    *acquireResp.LeaseID,
 		BlobURL:    blobClientRef.URL(),
-		ETag:       string(*uploadResp.ETag),
+		ETag:       upload.ETag,
 		LeaseState: "leased",
+		ContentMD5: base64.StdEncoding.EncodeToString(upload.ContentMD5),
+		ExpiresOn:  expiresOn,
+		AccessTier: upload.AccessTier,
+		VersionID:  upload.VersionID,
+
+		EncryptionScope: upload.EncryptionScope,
+		LeaseExpiresOn:  leaseExpiry(acquiredAt, leaseDuration),
 	}, nil
 }
 
-// RenewBlobLease renews an existing blob lease
+// leaseExpiry returns when a lease of duration seconds acquired or renewed at start lapses, nil
+// for infinite leases
+func leaseExpiry(start time.Time, duration int32) *time.Time {
+	if duration <= 0 {
+		return nil
+	}
+	expiresOn := start.Add(time.Duration(duration) * time.Second)
+	return &expiresOn
+}
+
+// leaseConditions returns access conditions for writing under leaseID, or nil when there is none
+func leaseConditions(leaseID string) *blob.AccessConditions {
+	if leaseID == "" {
+		return nil
+	}
+	return &blob.AccessConditions{
+		LeaseAccessConditions: &blob.LeaseAccessConditions{LeaseID: &leaseID},
+	}
+}
+
+// etagValue dereferences an optional ETag
+func etagValue(etag *azcore.ETag) string {
+	if etag == nil {
+		return ""
+	}
+	return string(*etag)
+}
+
+// uploadOptions holds the blob properties written together with the content
+type uploadOptions struct {
+	Headers  BlobHTTPHeaders
+	Metadata map[string]*string
+	Tags     map[string]string
+	Tier     *blob.AccessTier
+
+	// AccessConditions guard the write, for example against overwriting an existing blob
+	AccessConditions *blob.AccessConditions
+	// LeaseID is sent with writes that follow the initial one, when the blob is already leased
+	LeaseID string
+	// EncryptionScope encrypts the content with a named scope instead of the default
+	EncryptionScope *blob.CPKScopeInfo
+}
+
+// uploadOptions returns the properties to write with the content of config
+func (c *AzureBlobLeaseClient) uploadOptions(config BlobLeaseConfig) uploadOptions {
+	return uploadOptions{
+		Headers:  config.Headers,
+		Metadata: c.blobMetadata(config),
+		Tags:     config.Tags,
+		Tier:     accessTier(config.AccessTier),
+
+		AccessConditions: overwriteConditions(config.Overwrite),
+		EncryptionScope:  encryptionScope(config.EncryptionScope),
+	}
+}
+
+// overwriteConditions returns access conditions that make an upload fail if the blob already
+// exists, or nil when overwrite is allowed
+func overwriteConditions(overwrite bool) *blob.AccessConditions {
+	if overwrite {
+		return nil
+	}
+	etagAny := azcore.ETagAny
+	return &blob.AccessConditions{
+		ModifiedAccessConditions: &blob.ModifiedAccessConditions{IfNoneMatch: &etagAny},
+	}
+}
+
+// uploadResult holds the outcome of a verified block blob upload
+type uploadResult struct {
+	ETag       string
+	ContentMD5 []byte
+	AccessTier string
+	VersionID  string // version created by the upload, empty without blob versioning
+
+	// EncryptionScope is the scope the service encrypted the content with, empty for the account key
+	EncryptionScope string
+}
+
+// uploadBlockBlob uploads content with its Content-MD5 and verifies the MD5 the service stored.
+// Content that fits in a single Put Blob is sent with a transactional MD5; larger content is
+// staged in blocks validated individually with CRC64, with the whole-blob MD5 set on commit.
+func uploadBlockBlob(ctx context.Context, client *blockblob.Client, content []byte, options uploadOptions) (*uploadResult, error) {
+	sum := md5.Sum(content)
+	headers := options.Headers.sdkHeaders(nil, sum[:])
+
+	var etag *azcore.ETag
+	var versionID *string
+	if int64(len(content)) <= blockblob.MaxUploadBlobBytes {
+		resp, err := client.Upload(ctx, streaming.NopCloser(bytes.NewReader(content)), &blockblob.UploadOptions{
+			HTTPHeaders:             headers,
+			Metadata:                options.Metadata,
+			Tags:                    options.Tags,
+			Tier:                    options.Tier,
+			AccessConditions:        options.AccessConditions,
+			CPKScopeInfo:            options.EncryptionScope,
+			TransactionalValidation: blob.TransferValidationTypeMD5(sum[:]),
+		})
+		if err != nil {
+			return nil, err
+		}
+		etag, versionID = resp.ETag, resp.VersionID
+	} else {
+		resp, err := client.UploadBuffer(ctx, content, &blockblob.UploadBufferOptions{
+			HTTPHeaders:             headers,
+			Metadata:                options.Metadata,
+			Tags:                    options.Tags,
+			AccessTier:              options.Tier,
+			AccessConditions:        options.AccessConditions,
+			CPKScopeInfo:            options.EncryptionScope,
+			TransactionalValidation: blob.TransferValidationTypeComputeCRC64(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		etag, versionID = resp.ETag, resp.VersionID
+	}
+
+	return verifyUpload(ctx, client, sum[:], etag, versionID)
+}
+
+// uploadBlockBlobFile streams a local file into a block blob without loading it into memory,
+// using the same MD5 handling and verification as uploadBlockBlob
+func uploadBlockBlobFile(ctx context.Context, client *blockblob.Client, path string, options uploadOptions) (*uploadResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source file %s: %w", path, err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read source file %s: %w", path, err)
+	}
+	sum := hash.Sum(nil)
+	headers := options.Headers.sdkHeaders(nil, sum)
+
+	var etag *azcore.ETag
+	var versionID *string
+	if size <= blockblob.MaxUploadBlobBytes {
+		resp, err := client.Upload(ctx, streaming.NopCloser(file), &blockblob.UploadOptions{
+			HTTPHeaders:             headers,
+			Metadata:                options.Metadata,
+			Tags:                    options.Tags,
+			Tier:                    options.Tier,
+			AccessConditions:        options.AccessConditions,
+			CPKScopeInfo:            options.EncryptionScope,
+			TransactionalValidation: blob.TransferValidationTypeMD5(sum),
+		})
+		if err != nil {
+			return nil, err
+		}
+		etag, versionID = resp.ETag, resp.VersionID
+	} else {
+		resp, err := client.UploadFile(ctx, file, &blockblob.UploadFileOptions{
+			HTTPHeaders:             headers,
+			Metadata:                options.Metadata,
+			Tags:                    options.Tags,
+			AccessTier:              options.Tier,
+			AccessConditions:        options.AccessConditions,
+			CPKScopeInfo:            options.EncryptionScope,
+			TransactionalValidation: blob.TransferValidationTypeComputeCRC64(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		etag, versionID = resp.ETag, resp.VersionID
+	}
+
+	return verifyUpload(ctx, client, sum, etag, versionID)
+}
+
+// verifyUpload checks that the Content-MD5 stored by the service matches the uploaded content
+func verifyUpload(ctx context.Context, client *blockblob.Client, sum []byte, etag *azcore.ETag, versionID *string) (*uploadResult, error) {
+	props, err := client.GetProperties(ctx, nil)
+	if err != nil {
+		return nil, wrapError(err, "failed to verify uploaded content")
+	}
+	if !bytes.Equal(props.ContentMD5, sum) {
+		return nil, &ChecksumMismatchError{
+			BlobURL:  client.URL(),
+			Expected: base64.StdEncoding.EncodeToString(sum),
+			Actual:   base64.StdEncoding.EncodeToString(props.ContentMD5),
+		}
+	}
+
+	result := &uploadResult{
+		ContentMD5:      sum,
+		AccessTier:      stringValue(props.AccessTier),
+		VersionID:       stringValue(versionID),
+		EncryptionScope: stringValue(props.EncryptionScope),
+	}
+	if etag != nil {
+		result.ETag = string(*etag)
+	}
+	return result, nil
+}
+
+// ContentMD5 returns the base64-encoded MD5 of content, in the form the service reports it
+func ContentMD5(content []byte) string {
+	sum := md5.Sum(content)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// SourceFileMD5 returns the base64-encoded MD5 of a local file, in the same form as ContentMD5
+func SourceFileMD5(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil)), nil
+}
+
+// RenewBlobLease renews an existing blob lease. config.LeaseDuration must be the duration the
+// lease was acquired with for the result to report when it lapses.
 func (c *AzureBlobLeaseClient) RenewBlobLease(ctx context.Context, config BlobLeaseConfig) (*BlobLeaseResult, error) {
 	// Create blob client
-	blobClient, err := c.CreateBlobClient(config.StorageAccount)
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create blob client: %w", err)
 	}
@@ -148,7 +655,7 @@ func (c *AzureBlobLeaseClient) RenewBlobLease(ctx context.Context, config BlobLe
 	// Check if lease exists and is active
 	props, err := blobClientRef.GetProperties(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get blob properties: %w", err)
+		return nil, wrapError(err, "failed to get blob properties")
 	}
 
 	if props.LeaseState == nil || *props.LeaseState != "leased" {
@@ -161,12 +668,13 @@ func (c *AzureBlobLeaseClient) RenewBlobLease(ctx context.Context, config BlobLe
 		}
 
 		leaseDuration := config.LeaseDuration
-	if leaseDuration == 0 {
-		leaseDuration = -1 // Default to infinite
-	}
-	acquireResp, err := leaseClient.AcquireLease(ctx, leaseDuration, nil)
+		if leaseDuration == 0 {
+			leaseDuration = -1 // Default to infinite
+		}
+		acquiredAt := time.Now()
+		acquireResp, err := leaseClient.AcquireLease(ctx, leaseDuration, nil)
 		if err != nil {
-			return nil, fmt.Errorf("failed to re-acquire lease on blob %s: %w", config.BlobName, err)
+			return nil, wrapError(err, "failed to re-acquire lease on blob %s", config.BlobName)
 		}
 
 		return &BlobLeaseResult{
@@ -174,6 +682,8 @@ func (c *AzureBlobLeaseClient) RenewBlobLease(ctx context.Context, config BlobLe
 			BlobURL:    blobClientRef.URL(),
 			ETag:       string(*props.ETag),
 			LeaseState: "leased",
+
+			LeaseExpiresOn: leaseExpiry(acquiredAt, leaseDuration),
 		}, nil
 	}
 
@@ -185,9 +695,10 @@ func (c *AzureBlobLeaseClient) RenewBlobLease(ctx context.Context, config BlobLe
 		return nil, fmt.Errorf("failed to create lease client: %w", err)
 	}
 
+	renewedAt := time.Now()
 	renewResp, err := leaseClient.RenewLease(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to renew lease on blob %s: %w", config.BlobName, err)
+		return nil, wrapError(err, "failed to renew lease on blob %s", config.BlobName)
 	}
 
 	return &BlobLeaseResult{
@@ -195,13 +706,163 @@ func (c *AzureBlobLeaseClient) RenewBlobLease(ctx context.Context, config BlobLe
 		BlobURL:    blobClientRef.URL(),
 		ETag:       string(*props.ETag),
 		LeaseState: "leased",
+
+		LeaseExpiresOn: leaseExpiry(renewedAt, config.LeaseDuration),
 	}, nil
 }
 
-// ReleaseBlobLease releases a blob lease and optionally deletes the blob
+// RenewHeldBlobLease renews the lease config.LeaseID on a blob without ever acquiring one, unlike
+// RenewBlobLease. A blob that is no longer leased with config.LeaseID returns ErrLeaseLost.
+// config.LeaseDuration must be the duration the lease was acquired with for the result to report
+// when it lapses.
+func (c *AzureBlobLeaseClient) RenewHeldBlobLease(ctx context.Context, config BlobLeaseConfig) (*BlobLeaseResult, error) {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
+	blobClientRef := containerClient.NewBlockBlobClient(config.BlobName)
+	leaseClient, err := lease.NewBlobClient(blobClientRef, &lease.BlobClientOptions{
+		LeaseID: &config.LeaseID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lease client: %w", err)
+	}
+
+	renewedAt := time.Now()
+	renewResp, err := leaseClient.RenewLease(ctx, nil)
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.LeaseIDMismatchWithLeaseOperation, bloberror.LeaseNotPresentWithLeaseOperation, bloberror.LeaseIsBrokenAndCannotBeRenewed, bloberror.LeaseLost) {
+			return nil, fmt.Errorf("%w: blob %s is no longer leased with the known lease ID: %w",
+				ErrLeaseLost, config.BlobName, wrapError(err, "renewal rejected"))
+		}
+		return nil, wrapError(err, "failed to renew lease on blob %s", config.BlobName)
+	}
+
+	return &BlobLeaseResult{
+		LeaseID:    *renewResp.LeaseID,
+		BlobURL:    blobClientRef.URL(),
+		ETag:       etagValue(renewResp.ETag),
+		LeaseState: "leased",
+
+		LeaseExpiresOn: leaseExpiry(renewedAt, config.LeaseDuration),
+	}, nil
+}
+
+// ProbeBlobLease reports whether config.LeaseID still holds the lease on the blob. The service
+// has no read-only check, so it renews the lease, which only extends a lease that is held.
+func (c *AzureBlobLeaseClient) ProbeBlobLease(ctx context.Context, config BlobLeaseConfig) (bool, error) {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
+	if err != nil {
+		return false, fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
+	leaseClient, err := lease.NewBlobClient(containerClient.NewBlockBlobClient(config.BlobName), &lease.BlobClientOptions{
+		LeaseID: &config.LeaseID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create lease client: %w", err)
+	}
+
+	_, err = leaseClient.RenewLease(ctx, nil)
+	if err == nil {
+		return true, nil
+	}
+	if bloberror.HasCode(err, bloberror.LeaseIDMismatchWithLeaseOperation, bloberror.LeaseNotPresentWithLeaseOperation, bloberror.LeaseIsBrokenAndCannotBeRenewed, bloberror.LeaseLost) {
+		return false, nil
+	}
+	return false, wrapError(err, "failed to probe lease on blob %s", config.BlobName)
+}
+
+// AcquireBlobLease acquires a lease on an existing blob without writing content. If the blob is
+// already leased with config.LeaseID, the lease is re-acquired in place with the new duration.
+// A lease held by someone else is waited for up to config.AcquireTimeout.
+func (c *AzureBlobLeaseClient) AcquireBlobLease(ctx context.Context, config BlobLeaseConfig) (*BlobLeaseResult, error) {
+	return c.withLeaseWait(ctx, config, func(ctx context.Context) (*BlobLeaseResult, error) {
+		return c.acquireBlobLease(ctx, config)
+	})
+}
+
+// acquireBlobLease makes a single attempt at AcquireBlobLease
+func (c *AzureBlobLeaseClient) acquireBlobLease(ctx context.Context, config BlobLeaseConfig) (*BlobLeaseResult, error) {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
+	blobClientRef := containerClient.NewBlockBlobClient(config.BlobName)
+
+	leaseClient, err := lease.NewBlobClient(blobClientRef, &lease.BlobClientOptions{
+		LeaseID: &config.LeaseID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lease client: %w", err)
+	}
+
+	leaseDuration := config.LeaseDuration
+	if leaseDuration == 0 {
+		leaseDuration = -1 // Default to infinite
+	}
+	acquiredAt := time.Now()
+	acquireResp, err := leaseClient.AcquireLease(ctx, leaseDuration, nil)
+	if err != nil {
+		return nil, wrapError(err, "failed to acquire lease on blob %s", config.BlobName)
+	}
+
+	return &BlobLeaseResult{
+		LeaseID:    *acquireResp.LeaseID,
+		BlobURL:    blobClientRef.URL(),
+		ETag:       string(*acquireResp.ETag),
+		LeaseState: "leased",
+
+		LeaseExpiresOn: leaseExpiry(acquiredAt, leaseDuration),
+	}, nil
+}
+
+// ChangeBlobLease changes the ID of the active lease held with config.LeaseID to proposedID
+func (c *AzureBlobLeaseClient) ChangeBlobLease(ctx context.Context, config BlobLeaseConfig, proposedID string) (*BlobLeaseResult, error) {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
+	blobClientRef := containerClient.NewBlockBlobClient(config.BlobName)
+
+	leaseClient, err := lease.NewBlobClient(blobClientRef, &lease.BlobClientOptions{
+		LeaseID: &config.LeaseID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lease client: %w", err)
+	}
+
+	changeResp, err := leaseClient.ChangeLease(ctx, proposedID, nil)
+	if err != nil {
+		return nil, wrapError(err, "failed to change lease ID on blob %s", config.BlobName)
+	}
+
+	return &BlobLeaseResult{
+		LeaseID:    *changeResp.LeaseID,
+		BlobURL:    blobClientRef.URL(),
+		ETag:       etagValue(changeResp.ETag),
+		LeaseState: "leased",
+	}, nil
+}
+
+// ReleaseBlobLease releases a blob lease and optionally deletes the blob. A blob is deleted
+// under the held lease, so no one else can lease or rewrite it in between; only when that is
+// rejected because the lease is no longer held, the lease is released before an unconditional
+// delete.
 func (c *AzureBlobLeaseClient) ReleaseBlobLease(ctx context.Context, config BlobLeaseConfig, deleteBlob bool) error {
 	// Create blob client
-	blobClient, err := c.CreateBlobClient(config.StorageAccount)
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
 	if err != nil {
 		return fmt.Errorf("failed to create blob client: %w", err)
 	}
@@ -209,6 +870,14 @@ func (c *AzureBlobLeaseClient) ReleaseBlobLease(ctx context.Context, config Blob
 	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
 	blobClientRef := containerClient.NewBlockBlobClient(config.BlobName)
 
+	// Delete under the lease, which also ends it
+	if deleteBlob && config.LeaseID != "" {
+		err := deleteBlobWithSnapshots(ctx, blobClientRef, config, leaseConditions(config.LeaseID))
+		if err == nil || !isLeaseNotHeld(err) {
+			return err
+		}
+	}
+
 	// Release lease
 	leaseClient, err := lease.NewBlobClient(blobClientRef, &lease.BlobClientOptions{
 		LeaseID: &config.LeaseID,
@@ -219,42 +888,90 @@ func (c *AzureBlobLeaseClient) ReleaseBlobLease(ctx context.Context, config Blob
 
 	_, err = leaseClient.ReleaseLease(ctx, nil)
 	if err != nil {
-		// If lease doesn't exist or is already broken, continue to deletion
-		if !strings.Contains(err.Error(), "LeaseNotPresentWithBlobOperation") &&
-			!strings.Contains(err.Error(), "LeaseIdMismatchWithBlobOperation") {
-			return fmt.Errorf("failed to release lease on blob %s: %w", config.BlobName, err)
+		// If lease doesn't exist or is already broken, continue to deletion. Release Lease reports
+		// them with the lease operation codes.
+		if !bloberror.HasCode(err, bloberror.LeaseNotPresentWithLeaseOperation, bloberror.LeaseIDMismatchWithLeaseOperation) && !isLeaseNotHeld(err) {
+			return wrapError(err, "failed to release lease on blob %s", config.BlobName)
 		}
 	}
 
-	// Delete blob if requested
 	if deleteBlob {
-		_, err = blobClientRef.Delete(ctx, nil)
-		if err != nil {
-			return fmt.Errorf("failed to delete blob %s: %w", config.BlobName, err)
-		}
+		return deleteBlobWithSnapshots(ctx, blobClientRef, config, nil)
 	}
+	return nil
+}
 
+// deleteBlobWithSnapshots deletes a blob together with its snapshots, which would otherwise block
+// the delete, under the given access conditions
+func deleteBlobWithSnapshots(ctx context.Context, client *blockblob.Client, config BlobLeaseConfig, conditions *blob.AccessConditions) error {
+	includeSnapshots := blob.DeleteSnapshotsOptionTypeInclude
+	_, err := client.Delete(ctx, &blob.DeleteOptions{DeleteSnapshots: &includeSnapshots, AccessConditions: conditions})
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.BlobImmutableDueToPolicy) {
+			return fmt.Errorf("%w: blob %s has a legal hold or an unexpired immutability policy: %w",
+				ErrBlobImmutable, config.BlobName, wrapError(err, "delete rejected"))
+		}
+		return wrapError(err, "failed to delete blob %s", config.BlobName)
+	}
 	return nil
 }
 
-// BlobExists checks if a blob exists
-func (c *AzureBlobLeaseClient) BlobExists(ctx context.Context, storageAccount, containerName, blobName string) (bool, error) {
+// isLeaseNotHeld reports whether err means a write under a lease ID was rejected because that
+// lease is not, or no longer, the lease on the blob
+func isLeaseNotHeld(err error) bool {
+	return bloberror.HasCode(err, bloberror.LeaseIDMismatchWithBlobOperation, bloberror.LeaseNotPresentWithBlobOperation, bloberror.LeaseLost)
+}
+
+// BreakBlobLease breaks the lease on a blob, whoever holds it. The lease ends after at most
+// breakPeriod seconds; the returned duration is the time left until it is broken.
+func (c *AzureBlobLeaseClient) BreakBlobLease(ctx context.Context, config BlobLeaseConfig, breakPeriod int32) (time.Duration, error) {
 	// Create blob client
-	blobClient, err := c.CreateBlobClient(storageAccount)
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
 	if err != nil {
-		return false, fmt.Errorf("failed to create blob client: %w", err)
+		return 0, fmt.Errorf("failed to create blob client: %w", err)
 	}
 
-	containerClient := blobClient.ServiceClient().NewContainerClient(containerName)
-	blobClientRef := containerClient.NewBlockBlobClient(blobName)
+	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
+	blobClientRef := containerClient.NewBlockBlobClient(config.BlobName)
+
+	leaseClient, err := lease.NewBlobClient(blobClientRef, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create lease client: %w", err)
+	}
 
-	_, err = blobClientRef.GetProperties(ctx, nil)
+	breakResp, err := leaseClient.BreakLease(ctx, &lease.BlobBreakOptions{
+		BreakPeriod: &breakPeriod,
+	})
 	if err != nil {
-		// Check if it's a "not found" error
-		if strings.Contains(err.Error(), "404") || strings.Contains(err.Error(), "BlobNotFound") {
+		return 0, wrapError(err, "failed to break lease on blob %s", config.BlobName)
+	}
+
+	var remaining time.Duration
+	if breakResp.LeaseTime != nil {
+		remaining = time.Duration(*breakResp.LeaseTime) * time.Second
+	}
+	return remaining, nil
+}
+
+// BlobExists checks if a blob exists with a single Get Blob Properties request. Only a 404,
+// including one for a missing container, reports false; a principal that may not read the blob
+// gets an error wrapping ErrAuthorizationFailed, never false.
+func (c *AzureBlobLeaseClient) BlobExists(ctx context.Context, storageAccount, containerName, blobName string) (bool, error) {
+	_, err := c.readWithFallbacks(ctx, storageAccount, func(blobClient *azblob.Client) error {
+		containerClient := blobClient.ServiceClient().NewContainerClient(containerName)
+		_, err := containerClient.NewBlockBlobClient(blobName).GetProperties(ctx, nil)
+		return err
+	})
+	if err != nil {
+		if isNotFound(err) {
 			return false, nil
 		}
-		return false, fmt.Errorf("failed to check blob existence: %w", err)
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusForbidden {
+			return false, fmt.Errorf("%w: the principal may not read blob %s in container %s of storage account %s; it needs Microsoft.Storage/storageAccounts/blobServices/containers/blobs/read, e.g. through 'Storage Blob Data Reader': %w",
+				ErrAuthorizationFailed, blobName, containerName, storageAccount, wrapError(err, "blob properties read rejected"))
+		}
+		return false, wrapError(err, "failed to check blob existence")
 	}
 
 	return true, nil
@@ -262,46 +979,60 @@ func (c *AzureBlobLeaseClient) BlobExists(ctx context.Context, storageAccount, c
 
 // GetBlobLeaseState gets the current lease state of a blob
 func (c *AzureBlobLeaseClient) GetBlobLeaseState(ctx context.Context, storageAccount, containerName, blobName string) (*BlobLeaseResult, error) {
-	// Create blob client
-	blobClient, err := c.CreateBlobClient(storageAccount)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create blob client: %w", err)
-	}
-
-	containerClient := blobClient.ServiceClient().NewContainerClient(containerName)
-	blobClientRef := containerClient.NewBlockBlobClient(blobName)
+	var blobURL string
+	var props blob.GetPropertiesResponse
+	stale, err := c.readWithFallbacks(ctx, storageAccount, func(blobClient *azblob.Client) error {
+		containerClient := blobClient.ServiceClient().NewContainerClient(containerName)
+		blobClientRef := containerClient.NewBlockBlobClient(blobName)
+		if blobURL == "" {
+			blobURL = blobClientRef.URL()
+		}
 
-	props, err := blobClientRef.GetProperties(ctx, nil)
+		var err error
+		props, err = blobClientRef.GetProperties(ctx, nil)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get blob properties: %w", err)
+		return nil, wrapError(err, "failed to get blob properties")
 	}
 
 	leaseState := "available"
 	if props.LeaseState != nil {
 		leaseState = string(*props.LeaseState)
 	}
+	leaseStatus := "unlocked"
+	if props.LeaseStatus != nil {
+		leaseStatus = string(*props.LeaseStatus)
+	}
+	leaseDuration := ""
+	if props.LeaseDuration != nil && leaseState == string(lease.StateTypeLeased) {
+		leaseDuration = string(*props.LeaseDuration)
+	}
 
 	return &BlobLeaseResult{
-		BlobURL:    blobClientRef.URL(),
-		ETag:       string(*props.ETag),
-		LeaseState: leaseState,
-	}, nil
-}
+		BlobURL:      blobURL,
+		ETag:         etagValue(props.ETag),
+		LeaseState:   leaseState,
+		LeaseStatus:  leaseStatus,
+		StaleRead:    stale,
+		ContentMD5:   base64.StdEncoding.EncodeToString(props.ContentMD5),
+		ContentType:  stringValue(props.ContentType),
+		ExpiresOn:    props.ExpiresOn,
+		CreatedOn:    props.CreationTime,
+		LastModified: props.LastModified,
+		VersionID:    stringValue(props.VersionID),
+		Headers:      headersFromProperties(props),
+		Metadata:     metadataFromProperties(props.Metadata),
+		TagCount:     int64Value(props.TagCount),
+		AccessTier:   stringValue(props.AccessTier),
+		BlobType:     blobTypeName(props.BlobType),
+		Size:         int64Value(props.ContentLength),
 
-// StartLeaseRenewal starts a background process to automatically renew the lease
-func (c *AzureBlobLeaseClient) StartLeaseRenewal(ctx context.Context, config BlobLeaseConfig, renewInterval time.Duration) error {
-	ticker := time.NewTicker(renewInterval)
-	defer ticker.Stop()
+		EncryptionScope: stringValue(props.EncryptionScope),
 
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			_, err := c.RenewBlobLease(ctx, config)
-			if err != nil {
-				return fmt.Errorf("failed to renew lease during background renewal: %w", err)
-			}
-		}
-	}
-}
\ No newline at end of file
+		LegalHold:          props.LegalHold != nil && *props.LegalHold,
+		ImmutabilityPolicy: immutabilityPolicyFromProperties(props.ImmutabilityPolicyExpiresOn, props.ImmutabilityPolicyMode),
+
+		LeaseDuration: leaseDuration,
+	}, nil
+}
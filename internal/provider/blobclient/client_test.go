@@ -0,0 +1,102 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+)
+
+func TestReleaseBlobLease(t *testing.T) {
+	const leaseID = "3f2504e0-4f89-11d3-9a0c-0305e82c3301"
+
+	// Each request is answered with the status and error code scripted for its operation: a
+	// delete under the lease, a delete without one, or a release
+	type reply struct {
+		status int
+		code   bloberror.Code
+	}
+	tests := map[string]struct {
+		deleteBlob                    bool
+		leasedDelete, release, delete reply
+		wantOps                       []string
+		wantErr                       error
+		wantAnyErr                    bool
+	}{
+		"deleted under the lease": {
+			deleteBlob:   true,
+			leasedDelete: reply{status: http.StatusAccepted},
+			wantOps:      []string{"delete under lease"},
+		},
+		"lease no longer present": {
+			deleteBlob:   true,
+			leasedDelete: reply{http.StatusPreconditionFailed, bloberror.LeaseNotPresentWithBlobOperation},
+			release:      reply{http.StatusConflict, bloberror.LeaseNotPresentWithLeaseOperation},
+			delete:       reply{status: http.StatusAccepted},
+			wantOps:      []string{"delete under lease", "release", "delete"},
+		},
+		"lease taken over": {
+			deleteBlob:   true,
+			leasedDelete: reply{http.StatusPreconditionFailed, bloberror.LeaseIDMismatchWithBlobOperation},
+			release:      reply{http.StatusConflict, bloberror.LeaseIDMismatchWithLeaseOperation},
+			delete:       reply{http.StatusPreconditionFailed, bloberror.LeaseIDMissing},
+			wantOps:      []string{"delete under lease", "release", "delete"},
+			wantAnyErr:   true,
+		},
+		"immutable blob": {
+			deleteBlob:   true,
+			leasedDelete: reply{http.StatusConflict, bloberror.BlobImmutableDueToPolicy},
+			wantOps:      []string{"delete under lease"},
+			wantErr:      ErrBlobImmutable,
+		},
+		"release only": {
+			release: reply{status: http.StatusOK},
+			wantOps: []string{"release"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var ops []string
+			transport := &fakeTransport{handler: func(req *http.Request) (*http.Response, error) {
+				var r reply
+				switch {
+				case req.Method == http.MethodDelete && header(req, "x-ms-lease-id") == leaseID:
+					ops, r = append(ops, "delete under lease"), tt.leasedDelete
+				case req.Method == http.MethodDelete:
+					ops, r = append(ops, "delete"), tt.delete
+				case req.URL.Query().Get("comp") == "lease" && header(req, "x-ms-lease-action") == "release":
+					ops, r = append(ops, "release"), tt.release
+				default:
+					t.Fatalf("unexpected request %s %s", req.Method, req.URL)
+				}
+				if r.code != "" {
+					return respondError(req, r.status, string(r.code)), nil
+				}
+				return respond(req, r.status, ""), nil
+			}}
+			client := newTestClient(transport, ClientOptions{})
+
+			err := client.ReleaseBlobLease(context.Background(), BlobLeaseConfig{
+				StorageAccount: "acct",
+				ContainerName:  "locks",
+				BlobName:       "env/app.lock",
+				LeaseID:        leaseID,
+			}, tt.deleteBlob)
+			switch {
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Errorf("expected %v, got: %v", tt.wantErr, err)
+			case tt.wantErr == nil && tt.wantAnyErr && err == nil:
+				t.Error("expected an error")
+			case tt.wantErr == nil && !tt.wantAnyErr && err != nil:
+				t.Errorf("unexpected error: %s", err)
+			}
+			if !slices.Equal(ops, tt.wantOps) {
+				t.Errorf("expected requests %v, got %v", tt.wantOps, ops)
+			}
+		})
+	}
+}
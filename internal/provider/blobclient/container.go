@@ -0,0 +1,277 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/lease"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/service"
+)
+
+// ContainerProperties describes the current state of a container
+type ContainerProperties struct {
+	Exists       bool
+	LeaseState   string
+	LeaseStatus  string
+	PublicAccess string // "" for private, "blob" or "container"
+	ETag         string
+	// LeaseDuration is fixed or infinite while the container is leased
+	LeaseDuration string
+	LastModified  *time.Time
+
+	// BeingDeleted is true when the service reports the container is still being deleted
+	BeingDeleted bool
+	// SoftDeleted is true when a soft-deleted container with the same name is pending purge
+	SoftDeleted bool
+	// RemainingRetentionDays is the retention left on the soft-deleted container, if reported
+	RemainingRetentionDays int32
+}
+
+// GetContainerProperties gets the properties of a container, including soft-delete state when it does not exist
+func (c *AzureBlobLeaseClient) GetContainerProperties(ctx context.Context, storageAccount, containerName string) (*ContainerProperties, error) {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, storageAccount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(containerName)
+	props, err := containerClient.GetProperties(ctx, nil)
+	if err != nil {
+		if !bloberror.HasCode(err, bloberror.ContainerNotFound, bloberror.ContainerBeingDeleted) {
+			return nil, wrapError(err, "failed to get container properties for %s", containerName)
+		}
+
+		result := &ContainerProperties{
+			BeingDeleted: bloberror.HasCode(err, bloberror.ContainerBeingDeleted),
+		}
+		c.lookupDeletedContainer(ctx, blobClient.ServiceClient(), containerName, result)
+		return result, nil
+	}
+
+	result := &ContainerProperties{
+		Exists:     true,
+		LeaseState: "available",
+	}
+	if props.LeaseState != nil {
+		result.LeaseState = string(*props.LeaseState)
+	}
+	if props.LeaseStatus != nil {
+		result.LeaseStatus = string(*props.LeaseStatus)
+	}
+	if props.LeaseDuration != nil && result.LeaseState == string(lease.StateTypeLeased) {
+		result.LeaseDuration = string(*props.LeaseDuration)
+	}
+	result.LastModified = props.LastModified
+	if props.BlobPublicAccess != nil {
+		result.PublicAccess = string(*props.BlobPublicAccess)
+	}
+	if props.ETag != nil {
+		result.ETag = string(*props.ETag)
+	}
+
+	return result, nil
+}
+
+// lookupDeletedContainer fills in soft-delete details for a missing container. Listing deleted
+// containers needs account-level list permission, so failures are ignored and leave the details unset.
+func (c *AzureBlobLeaseClient) lookupDeletedContainer(ctx context.Context, serviceClient *service.Client, containerName string, result *ContainerProperties) {
+	pager := serviceClient.NewListContainersPager(&service.ListContainersOptions{
+		Prefix:  &containerName,
+		Include: service.ListContainersInclude{Deleted: true},
+	})
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return
+		}
+		for _, item := range page.ContainerItems {
+			if item.Name == nil || *item.Name != containerName || item.Deleted == nil || !*item.Deleted {
+				continue
+			}
+			result.SoftDeleted = true
+			if item.Properties != nil && item.Properties.RemainingRetentionDays != nil {
+				result.RemainingRetentionDays = *item.Properties.RemainingRetentionDays
+			}
+			return
+		}
+	}
+}
+
+// Public access levels accepted for containers
+const (
+	ContainerAccessPrivate   = "private"
+	ContainerAccessBlob      = "blob"
+	ContainerAccessContainer = "container"
+)
+
+// publicAccessType converts a container access level into the SDK option, nil meaning private
+func publicAccessType(access string) *container.PublicAccessType {
+	switch access {
+	case ContainerAccessBlob:
+		return to.Ptr(container.PublicAccessTypeBlob)
+	case ContainerAccessContainer:
+		return to.Ptr(container.PublicAccessTypeContainer)
+	default:
+		return nil
+	}
+}
+
+// EnsureContainer creates the container with the given public access level ("" or "private",
+// "blob", "container") if it does not already exist. The access level of an existing container
+// is left untouched. A container that is being deleted or soft-deleted is reported as
+// ErrContainerSoftDeleted instead of a bare 409.
+func (c *AzureBlobLeaseClient) EnsureContainer(ctx context.Context, storageAccount, containerName, access string) error {
+	props, err := c.GetContainerProperties(ctx, storageAccount, containerName)
+	if err == nil && props.Exists {
+		return nil
+	}
+
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, storageAccount)
+	if err != nil {
+		return fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(containerName)
+	_, err = containerClient.Create(ctx, &container.CreateOptions{Access: publicAccessType(access)})
+	if err == nil || bloberror.HasCode(err, bloberror.ContainerAlreadyExists) {
+		return nil
+	}
+
+	if bloberror.HasCode(err, bloberror.ContainerBeingDeleted) {
+		err = wrapError(err, "container create rejected")
+		props, propsErr := c.GetContainerProperties(ctx, storageAccount, containerName)
+		if propsErr == nil && props.SoftDeleted && props.RemainingRetentionDays > 0 {
+			return fmt.Errorf("%w: container %s is soft-deleted with %d retention day(s) remaining, retry after the retention period or restore/purge it: %w",
+				ErrContainerSoftDeleted, containerName, props.RemainingRetentionDays, err)
+		}
+		return fmt.Errorf("%w: container %s is being deleted or is soft-deleted, retry after the retention period or purge it: %w",
+			ErrContainerSoftDeleted, containerName, err)
+	}
+
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) && respErr.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: the principal may not create container %s in storage account %s; it needs Microsoft.Storage/storageAccounts/blobServices/containers/write (e.g. 'Storage Blob Data Contributor'), or create the container separately and set create_container = false: %w",
+			ErrAuthorizationFailed, containerName, storageAccount, wrapError(err, "container create rejected"))
+	}
+
+	return wrapError(err, "failed to create container %s", containerName)
+}
+
+// SetContainerAccess changes the public access level of an existing container, keeping its
+// stored access policies
+func (c *AzureBlobLeaseClient) SetContainerAccess(ctx context.Context, storageAccount, containerName, access string) error {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, storageAccount)
+	if err != nil {
+		return fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(containerName)
+
+	// Set Container ACL replaces the stored access policies, so send the current ones back
+	policy, err := containerClient.GetAccessPolicy(ctx, nil)
+	if err != nil {
+		return wrapError(err, "failed to get access policy for container %s", containerName)
+	}
+
+	_, err = containerClient.SetAccessPolicy(ctx, &container.SetAccessPolicyOptions{
+		Access:       publicAccessType(access),
+		ContainerACL: policy.SignedIdentifiers,
+	})
+	if err != nil {
+		return wrapError(err, "failed to set access level of container %s", containerName)
+	}
+
+	return nil
+}
+
+// ErrTooManyContainers indicates a listing matched more containers than allowed
+var ErrTooManyContainers = errors.New("too many containers")
+
+// ContainerItem describes a container of a storage account listing
+type ContainerItem struct {
+	Name         string
+	PublicAccess string // "" for private, "blob" or "container"
+	LeaseState   string
+	LastModified *time.Time
+	Metadata     map[string]string
+}
+
+// ListContainers lists the containers of a storage account whose name starts with prefix, in name
+// order, page by page. A listing with more than maxResults containers returns
+// ErrTooManyContainers; zero allows any number. A principal that may not list the containers of
+// the account gets an error wrapping ErrAuthorizationFailed rather than an empty list.
+func (c *AzureBlobLeaseClient) ListContainers(ctx context.Context, storageAccount, prefix string, maxResults int) ([]ContainerItem, error) {
+	var items []ContainerItem
+	var tooMany bool
+	_, err := c.readWithFallbacks(ctx, storageAccount, func(blobClient *azblob.Client) error {
+		// A read retried against another endpoint starts over
+		items, tooMany = nil, false
+
+		pager := blobClient.ServiceClient().NewListContainersPager(&service.ListContainersOptions{
+			Prefix:  optionalString(prefix),
+			Include: service.ListContainersInclude{Metadata: true},
+		})
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return err
+			}
+			for _, containerItem := range page.ContainerItems {
+				if containerItem == nil || containerItem.Name == nil {
+					continue
+				}
+				if maxResults > 0 && len(items) == maxResults {
+					tooMany = true
+					return nil
+				}
+				items = append(items, listedContainer(containerItem))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusForbidden {
+			return nil, fmt.Errorf("%w: the principal may not list the containers of storage account %s; it needs Microsoft.Storage/storageAccounts/blobServices/containers/read on the account (e.g. 'Storage Blob Data Reader'), a role on a single container is not enough: %w",
+				ErrAuthorizationFailed, storageAccount, wrapError(err, "container listing rejected"))
+		}
+		return nil, wrapError(err, "failed to list containers of storage account %s", storageAccount)
+	}
+	if tooMany {
+		return nil, fmt.Errorf("%w: storage account %s has more than %d matching containers", ErrTooManyContainers, storageAccount, maxResults)
+	}
+
+	return items, nil
+}
+
+// listedContainer converts a container of a listing
+func listedContainer(containerItem *service.ContainerItem) ContainerItem {
+	item := ContainerItem{
+		Name:       *containerItem.Name,
+		LeaseState: "available",
+		Metadata:   metadataFromProperties(containerItem.Metadata),
+	}
+	if props := containerItem.Properties; props != nil {
+		item.LastModified = props.LastModified
+		if props.LeaseState != nil {
+			item.LeaseState = string(*props.LeaseState)
+		}
+		if props.PublicAccess != nil {
+			item.PublicAccess = string(*props.PublicAccess)
+		}
+	}
+	return item
+}
@@ -0,0 +1,94 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// deletedContainerList is a List Containers page holding a soft-deleted "locks" container
+const deletedContainerList = `<?xml version="1.0" encoding="utf-8"?>
+<EnumerationResults ServiceEndpoint="https://acct.blob.core.windows.net/">
+  <Prefix>locks</Prefix>
+  <Containers>
+    <Container>
+      <Name>locks</Name>
+      <Deleted>true</Deleted>
+      <Version>01D60F8BB59A4652</Version>
+      <Properties>
+        <RemainingRetentionDays>5</RemainingRetentionDays>
+      </Properties>
+    </Container>
+  </Containers>
+  <NextMarker />
+</EnumerationResults>`
+
+func TestEnsureContainerBeingDeleted(t *testing.T) {
+	tests := map[string]struct {
+		listStatus int
+		wantMsg    string
+	}{
+		"soft-deleted with retention":   {listStatus: http.StatusOK, wantMsg: "soft-deleted with 5 retention day(s) remaining"},
+		"deleted containers not listed": {listStatus: http.StatusForbidden, wantMsg: "is being deleted or is soft-deleted"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			transport := &fakeTransport{handler: func(req *http.Request) (*http.Response, error) {
+				query := req.URL.Query()
+				switch {
+				case query.Get("comp") == "list":
+					if tt.listStatus != http.StatusOK {
+						return respondError(req, tt.listStatus, "AuthorizationPermissionMismatch"), nil
+					}
+					return respond(req, http.StatusOK, deletedContainerList, "Content-Type", "application/xml"), nil
+				case query.Get("restype") == "container":
+					// Both the properties lookup and the create are rejected while the delete is pending
+					return respondError(req, http.StatusConflict, "ContainerBeingDeleted"), nil
+				default:
+					t.Fatalf("unexpected request %s %s", req.Method, req.URL)
+					return nil, nil
+				}
+			}}
+			client := newTestClient(transport, ClientOptions{})
+
+			err := client.EnsureContainer(context.Background(), "acct", "locks", ContainerAccessPrivate)
+			if !errors.Is(err, ErrContainerSoftDeleted) {
+				t.Fatalf("expected ErrContainerSoftDeleted, got: %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("expected error to contain %q, got: %s", tt.wantMsg, err)
+			}
+			if !strings.Contains(err.Error(), "test-request-id") {
+				t.Errorf("expected error to carry the request ID, got: %s", err)
+			}
+
+			creates := transport.count(func(req *http.Request) bool {
+				return req.Method == http.MethodPut && req.URL.Query().Get("restype") == "container"
+			})
+			if creates != 1 {
+				t.Errorf("expected one create attempt, got %d", creates)
+			}
+		})
+	}
+}
+
+func TestGetContainerPropertiesBeingDeleted(t *testing.T) {
+	transport := &fakeTransport{handler: func(req *http.Request) (*http.Response, error) {
+		if req.URL.Query().Get("comp") == "list" {
+			return respond(req, http.StatusOK, deletedContainerList, "Content-Type", "application/xml"), nil
+		}
+		return respondError(req, http.StatusConflict, "ContainerBeingDeleted"), nil
+	}}
+	client := newTestClient(transport, ClientOptions{})
+
+	props, err := client.GetContainerProperties(context.Background(), "acct", "locks")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if props.Exists || !props.BeingDeleted || !props.SoftDeleted || props.RemainingRetentionDays != 5 {
+		t.Errorf("unexpected properties: %+v", props)
+	}
+}
@@ -0,0 +1,129 @@
+package blobclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+)
+
+// ErrLeaseLost indicates a write under the lease was rejected because the lease is no longer held
+var ErrLeaseLost = errors.New("lease no longer held")
+
+// ErrBlobChanged indicates a content update was rejected because the blob no longer has the
+// ETag in config.IfMatch, so it was written by someone else since it was last read
+var ErrBlobChanged = errors.New("blob changed since it was last read")
+
+// ErrUnsupportedEncoding indicates content has a Content-Encoding that DecodeContent cannot decode
+var ErrUnsupportedEncoding = errors.New("unsupported content encoding")
+
+// GzipEncoding is the Content-Encoding of blob content compressed with GzipContent
+const GzipEncoding = "gzip"
+
+// GzipContent compresses content with gzip. The header carries no name or time, so the same
+// content always compresses to the same bytes and keeps its Content-MD5.
+func GzipContent(content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(content); err != nil {
+		return nil, fmt.Errorf("failed to compress content: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress content: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeContent returns content as it was before it was encoded with encoding, the
+// Content-Encoding of the blob. Content without an encoding is returned as it is and gzip content
+// is decompressed; other encodings return ErrUnsupportedEncoding. Content that decompresses to
+// more than maxSize bytes returns ErrBlobTooLarge.
+func DecodeContent(content []byte, encoding string, maxSize int64) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		return content, nil
+	case GzipEncoding:
+	default:
+		return nil, fmt.Errorf("%w %q: only gzip content can be decompressed", ErrUnsupportedEncoding, encoding)
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress content: %w", err)
+	}
+	defer r.Close()
+	decoded, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress content: %w", err)
+	}
+	if int64(len(decoded)) > maxSize {
+		return nil, fmt.Errorf("%w: content decompresses to more than %d bytes", ErrBlobTooLarge, maxSize)
+	}
+	return decoded, nil
+}
+
+// UpdateBlobContent rewrites the content of a leased block or append blob in place under
+// config.LeaseID, so the lease is kept. The properties in config are written with the content
+// as on upload. With config.IfMatch set, the update is only applied while the blob still has
+// that ETag, and returns ErrBlobChanged otherwise.
+func (c *AzureBlobLeaseClient) UpdateBlobContent(ctx context.Context, config BlobLeaseConfig) (*BlobLeaseResult, error) {
+	if config.BlobType == BlobTypePage {
+		return nil, fmt.Errorf("page blob %s has no content to update", config.BlobName)
+	}
+
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
+
+	options := c.uploadOptions(config)
+	options.AccessConditions = leaseConditions(config.LeaseID)
+	options.LeaseID = config.LeaseID
+	if config.IfMatch != "" {
+		if options.AccessConditions == nil {
+			options.AccessConditions = &blob.AccessConditions{}
+		}
+		etag := azcore.ETag(config.IfMatch)
+		options.AccessConditions.ModifiedAccessConditions = &blob.ModifiedAccessConditions{IfMatch: &etag}
+	}
+
+	upload, err := c.uploadBlob(ctx, containerClient, config, options)
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.LeaseIDMissing, bloberror.LeaseIDMismatchWithBlobOperation, bloberror.LeaseNotPresentWithBlobOperation, bloberror.LeaseLost) {
+			return nil, fmt.Errorf("%w: blob %s is no longer leased with the known lease ID: %w",
+				ErrLeaseLost, config.BlobName, wrapError(err, "write rejected"))
+		}
+		if config.IfMatch != "" && bloberror.HasCode(err, bloberror.ConditionNotMet) {
+			return nil, fmt.Errorf("%w: blob %s no longer has ETag %s: %w",
+				ErrBlobChanged, config.BlobName, config.IfMatch, wrapError(err, "write rejected"))
+		}
+		if isEncryptionScopeDenied(err) {
+			return nil, fmt.Errorf("%w: write to blob %s with encryption scope %q rejected: %w",
+				ErrEncryptionScopeDenied, config.BlobName, config.EncryptionScope, wrapError(err, "write rejected"))
+		}
+		return nil, wrapError(err, "failed to update content of blob %s", config.BlobName)
+	}
+
+	return &BlobLeaseResult{
+		LeaseID:    config.LeaseID,
+		BlobURL:    containerClient.NewBlobClient(config.BlobName).URL(),
+		ETag:       upload.ETag,
+		LeaseState: "leased",
+		ContentMD5: base64.StdEncoding.EncodeToString(upload.ContentMD5),
+		AccessTier: upload.AccessTier,
+		VersionID:  upload.VersionID,
+
+		EncryptionScope: upload.EncryptionScope,
+	}, nil
+}
@@ -0,0 +1,148 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+)
+
+// Sentinel errors for a failed server-side copy. Use errors.Is to test for them.
+var (
+	ErrCopySourceNotFound = errors.New("copy source not found")
+	ErrCopySourceDenied   = errors.New("copy source access denied")
+	ErrCopyFailed         = errors.New("copy failed")
+)
+
+// Bounds of the interval between copy status checks
+const (
+	copyPollInitialInterval = time.Second
+	copyPollMaxInterval     = 10 * time.Second
+)
+
+// isCopyError reports whether err is one of the copy sentinel errors, which are already
+// descriptive and need no further wrapping
+func isCopyError(err error) bool {
+	return errors.Is(err, ErrCopySourceNotFound) || errors.Is(err, ErrCopySourceDenied) || errors.Is(err, ErrCopyFailed)
+}
+
+// copyBlockBlob creates a blob from config.CopySource with a server-side copy and waits for the
+// copy to complete. Metadata, tags and the tier of options are set on the copy; the managed
+// headers are written afterwards, since a copy takes them from the source. The source must be
+// readable by the storage service, for example through a SAS token in its URL.
+func copyBlockBlob(ctx context.Context, client *blockblob.Client, config BlobLeaseConfig, options uploadOptions) (*uploadResult, error) {
+	resp, err := client.StartCopyFromURL(ctx, config.CopySource, &blob.StartCopyFromURLOptions{
+		Metadata:         options.Metadata,
+		BlobTags:         options.Tags,
+		Tier:             options.Tier,
+		AccessConditions: options.AccessConditions,
+	})
+	if err != nil {
+		if sourceErr := copySourceError(err, config.BlobName); sourceErr != nil {
+			return nil, sourceErr
+		}
+		return nil, err
+	}
+
+	props, err := waitForCopy(ctx, client, stringValue(resp.CopyID), config.BlobName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without configured metadata a copy keeps the metadata of the source
+	if options.Metadata == nil && len(props.Metadata) > 0 {
+		if _, err := client.SetMetadata(ctx, nil, nil); err != nil {
+			return nil, wrapError(err, "failed to clear metadata copied to blob %s", config.BlobName)
+		}
+	}
+
+	headersResp, err := client.SetHTTPHeaders(ctx, *options.Headers.sdkHeaders(props.ContentType, props.ContentMD5), nil)
+	if err != nil {
+		return nil, wrapError(err, "failed to set HTTP headers on blob %s", config.BlobName)
+	}
+
+	return &uploadResult{
+		ETag:            etagValue(headersResp.ETag),
+		ContentMD5:      props.ContentMD5,
+		AccessTier:      stringValue(props.AccessTier),
+		VersionID:       stringValue(props.VersionID),
+		EncryptionScope: stringValue(props.EncryptionScope),
+	}, nil
+}
+
+// waitForCopy polls the destination blob until the copy with copyID has completed. When ctx
+// ends first, the copy is aborted so no partial blob is left behind.
+func waitForCopy(ctx context.Context, client *blockblob.Client, copyID, blobName string) (blob.GetPropertiesResponse, error) {
+	interval := copyPollInitialInterval
+	for {
+		props, err := client.GetProperties(ctx, nil)
+		if err != nil {
+			return props, wrapError(err, "failed to check copy status of blob %s", blobName)
+		}
+
+		status := blob.CopyStatusTypeSuccess
+		if props.CopyStatus != nil {
+			status = *props.CopyStatus
+		}
+		switch status {
+		case blob.CopyStatusTypeSuccess:
+			return props, nil
+		case blob.CopyStatusTypePending:
+		default:
+			return props, copyStatusError(status, stringValue(props.CopyStatusDescription), blobName)
+		}
+
+		select {
+		case <-ctx.Done():
+			// The operation context is already done, so the abort gets a short one of its own
+			abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), defaultConnectTimeout)
+			_, abortErr := client.AbortCopyFromURL(abortCtx, copyID, nil)
+			cancel()
+			if abortErr != nil {
+				return props, fmt.Errorf("copy to blob %s did not complete in time and could not be aborted: %w", blobName, errors.Join(ctx.Err(), abortErr))
+			}
+			return props, fmt.Errorf("copy to blob %s did not complete in time and was aborted: %w", blobName, ctx.Err())
+		case <-time.After(interval):
+		}
+		interval = min(2*interval, copyPollMaxInterval)
+	}
+}
+
+// copySourceError classifies a rejected copy request whose source could not be read, returning
+// nil for other failures
+func copySourceError(err error, blobName string) error {
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) || !bloberror.HasCode(err, bloberror.CannotVerifyCopySource, bloberror.BlobNotFound) {
+		return nil
+	}
+
+	switch respErr.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: the copy_source of blob %s does not exist: %w", ErrCopySourceNotFound, blobName, wrapError(err, "copy rejected"))
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w: the copy_source of blob %s cannot be read by the storage service; check that its SAS token is valid and grants read access: %w",
+			ErrCopySourceDenied, blobName, wrapError(err, "copy rejected"))
+	}
+	return nil
+}
+
+// copyStatusError describes a copy that failed or was aborted after it started. The status
+// description starts with the HTTP status the source returned, e.g. "404 NotFound".
+func copyStatusError(status blob.CopyStatusType, description, blobName string) error {
+	code, _, _ := strings.Cut(description, " ")
+	switch statusCode, _ := strconv.Atoi(code); statusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: the copy_source of blob %s was not found during the copy: %s", ErrCopySourceNotFound, blobName, description)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w: the copy_source of blob %s could not be read during the copy: %s", ErrCopySourceDenied, blobName, description)
+	}
+	return fmt.Errorf("%w: copy to blob %s ended with status %s: %s", ErrCopyFailed, blobName, status, description)
+}
@@ -0,0 +1,76 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+)
+
+// ErrBlobNotFound indicates the blob to download does not exist
+var ErrBlobNotFound = errors.New("blob not found")
+
+// ErrBlobTooLarge indicates a blob is larger than the size allowed for a download
+var ErrBlobTooLarge = errors.New("blob too large")
+
+// BlobContent is the content of a downloaded blob
+type BlobContent struct {
+	Content         []byte // the content as stored, still compressed when ContentEncoding is set; see DecodeContent
+	ContentType     string
+	ContentEncoding string
+	ETag            string
+	StaleRead       bool // true when the content was read from the secondary endpoint and may lag the primary
+}
+
+// DownloadBlobContent downloads the content of a blob, under config.LeaseID when it is set. A
+// blob larger than maxSize bytes is not read and returns ErrBlobTooLarge, and a missing blob
+// returns ErrBlobNotFound.
+func (c *AzureBlobLeaseClient) DownloadBlobContent(ctx context.Context, config BlobLeaseConfig, maxSize int64) (*BlobContent, error) {
+	var result BlobContent
+	var size int64
+	stale, err := c.readWithFallbacks(ctx, config.StorageAccount, func(blobClient *azblob.Client) error {
+		containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
+		resp, err := containerClient.NewBlobClient(config.BlobName).DownloadStream(ctx, &blob.DownloadStreamOptions{
+			AccessConditions: leaseConditions(config.LeaseID),
+		})
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		// The size is checked before reading, and the read is bounded in case it was not reported
+		size = int64Value(resp.ContentLength)
+		if size > maxSize {
+			return nil
+		}
+		content, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+		if err != nil {
+			return err
+		}
+		size = int64(len(content))
+
+		result = BlobContent{
+			Content:         content,
+			ContentType:     stringValue(resp.ContentType),
+			ContentEncoding: stringValue(resp.ContentEncoding),
+			ETag:            etagValue(resp.ETag),
+		}
+		return nil
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("%w: blob %s does not exist in container %s of storage account %s",
+				ErrBlobNotFound, config.BlobName, config.ContainerName, config.StorageAccount)
+		}
+		return nil, wrapError(err, "failed to download blob %s", config.BlobName)
+	}
+	if size > maxSize {
+		return nil, fmt.Errorf("%w: blob %s has more than %d bytes", ErrBlobTooLarge, config.BlobName, maxSize)
+	}
+
+	result.StaleRead = stale
+	return &result, nil
+}
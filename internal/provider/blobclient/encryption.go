@@ -0,0 +1,38 @@
+package blobclient
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+)
+
+// ErrEncryptionScopeDenied indicates a write was rejected because it did not use the encryption
+// scope a container or policy requires
+var ErrEncryptionScopeDenied = errors.New("write denied: encryption scope required/mismatch")
+
+// encryptionScope converts an encryption scope name into the SDK option, nil meaning the
+// container or account default
+func encryptionScope(scope string) *blob.CPKScopeInfo {
+	if scope == "" {
+		return nil
+	}
+	return &blob.CPKScopeInfo{EncryptionScope: &scope}
+}
+
+// isEncryptionScopeDenied reports whether a write failed because of its encryption scope. The
+// service uses several codes for this, all of which name the encryption scope.
+func isEncryptionScopeDenied(err error) bool {
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) {
+		return false
+	}
+	switch respErr.StatusCode {
+	case http.StatusBadRequest, http.StatusForbidden, http.StatusConflict:
+	default:
+		return false
+	}
+	return strings.Contains(respErr.ErrorCode, "EncryptionScope") || strings.Contains(strings.ToLower(respErr.Error()), "encryption scope")
+}
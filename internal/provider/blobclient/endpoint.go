@@ -0,0 +1,143 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// ErrInsecureEndpoint indicates a blob endpoint that uses http while http endpoints are not allowed
+var ErrInsecureEndpoint = errors.New("blob endpoint must use https")
+
+// DefaultEndpointSuffix is the storage endpoint suffix of the Azure public cloud
+const DefaultEndpointSuffix = "core.windows.net"
+
+// endpointSuffixPattern matches a DNS name such as core.chinacloudapi.cn
+var endpointSuffixPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)+$`)
+
+// CheckEndpointSuffix verifies that suffix is a storage endpoint suffix, a DNS name such as
+// core.windows.net without a scheme or path
+func CheckEndpointSuffix(suffix string) error {
+	if !endpointSuffixPattern.MatchString(suffix) {
+		return fmt.Errorf("endpoint suffix must be a lowercase DNS name such as %s, without a scheme or path, got: %q", DefaultEndpointSuffix, suffix)
+	}
+	return nil
+}
+
+// ContainerURL returns the https URL of a container of a storage account under endpointSuffix,
+// empty for DefaultEndpointSuffix, without a trailing slash
+func ContainerURL(storageAccount, containerName, endpointSuffix string) string {
+	if endpointSuffix == "" {
+		endpointSuffix = DefaultEndpointSuffix
+	}
+	serviceURL := fmt.Sprintf("https://%s.blob.%s/", storageAccount, endpointSuffix)
+	return runtime.JoinPaths(serviceURL, containerName)
+}
+
+// BlobURL returns the https URL of a blob of a storage account under endpointSuffix, empty for
+// DefaultEndpointSuffix: the ContainerURL, a slash and the blob name percent-encoded the way
+// blob clients encode it, so the URL matches the blob_url the provider computes.
+func BlobURL(storageAccount, containerName, blobName, endpointSuffix string) string {
+	return runtime.JoinPaths(ContainerURL(storageAccount, containerName, endpointSuffix), url.PathEscape(blobName))
+}
+
+// ParseBlobURL splits a blob URL of the form https://<account>.blob.<suffix>/<container>/<blob>
+// into its storage account, container, percent-decoded blob name and endpoint suffix. The query
+// string, such as a SAS token, is dropped and never appears in the returned error.
+func ParseBlobURL(rawURL string) (storageAccount, containerName, blobName, endpointSuffix string, err error) {
+	shown, _, hasQuery := strings.Cut(rawURL, "?")
+	if hasQuery {
+		shown += "?..."
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", "", "", fmt.Errorf("blob URL %q is not a valid URL", shown)
+	}
+	switch {
+	case u.Scheme != "https":
+		return "", "", "", "", fmt.Errorf("blob URL %q must use https, got scheme %q", shown, u.Scheme)
+	case u.User != nil:
+		return "", "", "", "", errors.New("blob URL must not contain user credentials")
+	case u.Fragment != "":
+		return "", "", "", "", fmt.Errorf("blob URL %q has a fragment; a '#' in a blob name must be encoded as %%23", shown)
+	case u.Port() != "":
+		return "", "", "", "", fmt.Errorf("blob URL %q must not have a port; path-style emulator URLs are not supported", shown)
+	}
+
+	storageAccount, endpointSuffix, ok := strings.Cut(strings.ToLower(u.Hostname()), ".blob.")
+	if !ok {
+		return "", "", "", "", fmt.Errorf("blob URL %q is not a blob service URL: the host must be <storage_account>.blob.<endpoint_suffix>", shown)
+	}
+	if err := CheckEndpointSuffix(endpointSuffix); err != nil {
+		return "", "", "", "", fmt.Errorf("blob URL %q: %w", shown, err)
+	}
+
+	containerName, blobName, _ = strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	if containerName == "" {
+		return "", "", "", "", fmt.Errorf("blob URL %q has no container", shown)
+	}
+	if blobName == "" {
+		return "", "", "", "", fmt.Errorf("blob URL %q has no blob name after container %q", shown, containerName)
+	}
+	return storageAccount, containerName, blobName, endpointSuffix, nil
+}
+
+// blobEndpointKey is the context key of the blob endpoint set by WithBlobEndpoint
+type blobEndpointKey struct{}
+
+// WithBlobEndpoint returns a context in which blob clients are created against endpoint, used
+// verbatim as the blob service URL, instead of https://<account>.blob.core.windows.net/. Use it
+// for private endpoints, sovereign clouds and emulators. An empty endpoint returns ctx unchanged.
+func WithBlobEndpoint(ctx context.Context, endpoint string) context.Context {
+	if endpoint == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, blobEndpointKey{}, endpoint)
+}
+
+// blobEndpoint returns the blob endpoint set on ctx, empty when there is none
+func blobEndpoint(ctx context.Context) string {
+	endpoint, _ := ctx.Value(blobEndpointKey{}).(string)
+	return endpoint
+}
+
+// CheckBlobEndpoint verifies that endpoint is an absolute https URL, or an http URL when
+// AllowHTTPEndpoints is set. An http endpoint otherwise returns ErrInsecureEndpoint.
+func (c *AzureBlobLeaseClient) CheckBlobEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("blob endpoint %q is not an absolute URL", endpoint)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("blob endpoint %q must not have a query or fragment", endpoint)
+	}
+
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if c.options.AllowHTTPEndpoints {
+			return nil
+		}
+		return fmt.Errorf("%w: %s uses http, which is only allowed when allow_http_endpoints is set", ErrInsecureEndpoint, endpoint)
+	}
+	return fmt.Errorf("blob endpoint %q must use https, got scheme %q", endpoint, u.Scheme)
+}
+
+// serviceURL returns the blob service URL of a storage account for operations under ctx
+func (c *AzureBlobLeaseClient) serviceURL(ctx context.Context, storageAccount string) (string, error) {
+	endpoint := blobEndpoint(ctx)
+	if endpoint == "" {
+		return fmt.Sprintf("https://%s.blob.core.windows.net/", storageAccount), nil
+	}
+	if err := c.CheckBlobEndpoint(endpoint); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(endpoint, "/") + "/", nil
+}
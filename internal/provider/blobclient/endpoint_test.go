@@ -0,0 +1,73 @@
+package blobclient
+
+import (
+	"strings"
+	"testing"
+)
+
+// endpointSuffixes are the endpoint suffixes of the public and sovereign clouds, and of a custom
+// DNS zone
+var endpointSuffixes = map[string]string{
+	"public cloud":        "core.windows.net",
+	"US government cloud": "core.usgovcloudapi.net",
+	"China cloud":         "core.chinacloudapi.cn",
+	"custom zone":         "storage.contoso.internal",
+}
+
+func TestContainerURL(t *testing.T) {
+	for name, suffix := range endpointSuffixes {
+		t.Run(name, func(t *testing.T) {
+			want := "https://acct.blob." + suffix + "/locks"
+			if got := ContainerURL("acct", "locks", suffix); got != want {
+				t.Errorf("expected %s, got %s", want, got)
+			}
+
+			// blob_url is the container URL, a slash and the encoded blob name
+			if got, want := BlobURL("acct", "locks", "env/my app.lock", suffix), want+"/env%2Fmy%20app.lock"; got != want {
+				t.Errorf("expected blob URL %s, got %s", want, got)
+			}
+		})
+	}
+
+	if got, want := ContainerURL("acct", "locks", ""), "https://acct.blob.core.windows.net/locks"; got != want {
+		t.Errorf("expected the default endpoint suffix %s, got %s", want, got)
+	}
+}
+
+func TestParseBlobURL(t *testing.T) {
+	for name, suffix := range endpointSuffixes {
+		t.Run(name, func(t *testing.T) {
+			blobName := "env/my app#1?.lock"
+			account, container, gotName, gotSuffix, err := ParseBlobURL(BlobURL("acct", "locks", blobName, suffix) + "?sv=2024-05-04&sig=secret")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if account != "acct" || container != "locks" || gotName != blobName || gotSuffix != suffix {
+				t.Errorf("expected acct, locks, %q, %s, got %s, %s, %q, %s", blobName, suffix, account, container, gotName, gotSuffix)
+			}
+		})
+	}
+
+	for name, tc := range map[string]struct {
+		rawURL  string
+		wantErr string
+	}{
+		"http":             {rawURL: "http://acct.blob.core.windows.net/locks/app.lock", wantErr: "must use https"},
+		"not blob service": {rawURL: "https://acct.queue.core.windows.net/locks/app.lock", wantErr: "not a blob service URL"},
+		"no blob name":     {rawURL: "https://acct.blob.core.usgovcloudapi.net/locks/", wantErr: "no blob name"},
+		"no container":     {rawURL: "https://acct.blob.core.chinacloudapi.cn/", wantErr: "no container"},
+		"port":             {rawURL: "https://acct.blob.core.windows.net:10000/locks/app.lock", wantErr: "must not have a port"},
+		"fragment":         {rawURL: "https://acct.blob.core.windows.net/locks/app#1.lock", wantErr: "has a fragment"},
+		"sas token hidden": {rawURL: "https://acct.blob.core.windows.net/locks/?sig=secret", wantErr: "?..."},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, _, _, err := ParseBlobURL(tc.rawURL)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected an error containing %q, got %v", tc.wantErr, err)
+			}
+			if strings.Contains(err.Error(), "secret") {
+				t.Errorf("expected the error not to show the query string, got: %s", err)
+			}
+		})
+	}
+}
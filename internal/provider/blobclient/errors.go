@@ -0,0 +1,208 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+)
+
+// Sentinel errors used to classify connectivity failures. Use errors.Is to test for them.
+var (
+	ErrAuthenticationFailed = errors.New("authentication failed")
+	ErrAuthorizationFailed  = errors.New("authorization failed")
+	ErrNetworkAccessDenied  = errors.New("network access denied")
+	ErrAccountNotFound      = errors.New("storage account not found")
+	ErrEndpointUnreachable  = errors.New("storage endpoint unreachable")
+)
+
+// ErrContainerSoftDeleted indicates the container cannot be created because a deleted container with the same name still exists
+var ErrContainerSoftDeleted = errors.New("container is soft-deleted")
+
+// ErrContainerNotFound indicates the target container does not exist and the client was told not to create it
+var ErrContainerNotFound = errors.New("container not found")
+
+// BlobExistsError is returned when an upload that must not overwrite finds the blob already exists
+type BlobExistsError struct {
+	BlobURL      string
+	ETag         string
+	LastModified *time.Time
+	Err          error
+}
+
+func (e *BlobExistsError) Error() string {
+	lastModified := "unknown"
+	if e.LastModified != nil {
+		lastModified = e.LastModified.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprintf("blob %s already exists (ETag: %s, last modified: %s)", e.BlobURL, e.ETag, lastModified)
+}
+
+func (e *BlobExistsError) Unwrap() error {
+	return e.Err
+}
+
+// existingBlobError describes the blob that blocked an upload, reading its properties if possible
+func existingBlobError(ctx context.Context, client *blockblob.Client, err error) error {
+	existsErr := &BlobExistsError{BlobURL: client.URL(), Err: wrapError(err, "upload rejected")}
+	if props, propsErr := client.GetProperties(ctx, nil); propsErr == nil {
+		existsErr.ETag = etagValue(props.ETag)
+		existsErr.LastModified = props.LastModified
+	}
+	return existsErr
+}
+
+// BlobLeaseError wraps a failed storage request with the identifiers Azure support asks for
+type BlobLeaseError struct {
+	Message         string
+	StatusCode      int
+	ErrorCode       string
+	RequestID       string
+	ClientRequestID string
+	Err             error
+}
+
+func (e *BlobLeaseError) Error() string {
+	return fmt.Sprintf("%s (x-ms-request-id: %s, x-ms-client-request-id: %s): %s", e.Message, e.RequestID, e.ClientRequestID, e.Err)
+}
+
+func (e *BlobLeaseError) Unwrap() error {
+	return e.Err
+}
+
+// wrapError annotates err with a message and, for service errors, the request IDs of the failed response
+func wrapError(err error, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+
+	var existing *BlobLeaseError
+	var respErr *azcore.ResponseError
+	if errors.As(err, &existing) || !errors.As(err, &respErr) {
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+
+	leaseErr := &BlobLeaseError{
+		Message:    msg,
+		StatusCode: respErr.StatusCode,
+		ErrorCode:  respErr.ErrorCode,
+		Err:        err,
+	}
+	if respErr.RawResponse != nil {
+		leaseErr.RequestID = respErr.RawResponse.Header.Get(headerRequestID)
+		leaseErr.ClientRequestID = respErr.RawResponse.Header.Get(headerClientRequestID)
+		if leaseErr.ClientRequestID == "" && respErr.RawResponse.Request != nil {
+			leaseErr.ClientRequestID = respErr.RawResponse.Request.Header.Get(headerClientRequestID)
+		}
+	}
+	return leaseErr
+}
+
+// ChecksumMismatchError is returned when the Content-MD5 stored by Azure differs from the uploaded content
+type ChecksumMismatchError struct {
+	BlobURL  string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("content MD5 mismatch for %s: expected %s, service reported %s", e.BlobURL, e.Expected, e.Actual)
+}
+
+// ConnectivityError describes why a storage account could not be reached, along with a remediation hint
+type ConnectivityError struct {
+	StorageAccount string
+	Kind           error
+	Hint           string
+	Err            error
+}
+
+func (e *ConnectivityError) Error() string {
+	msg := fmt.Sprintf("%s for storage account %s", e.Kind, e.StorageAccount)
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %s", msg, e.Err)
+	}
+	if e.Hint != "" {
+		msg = fmt.Sprintf("%s (hint: %s)", msg, e.Hint)
+	}
+	return msg
+}
+
+// Unwrap allows errors.Is to match both the Kind sentinel and the underlying error
+func (e *ConnectivityError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// Code returns a stable identifier of the Kind of the failure, such as "authorization_failed"
+func (e *ConnectivityError) Code() string {
+	switch e.Kind {
+	case ErrAuthenticationFailed:
+		return "authentication_failed"
+	case ErrAuthorizationFailed:
+		return "authorization_failed"
+	case ErrNetworkAccessDenied:
+		return "network_access_denied"
+	case ErrAccountNotFound:
+		return "account_not_found"
+	case ErrEndpointUnreachable:
+		return "endpoint_unreachable"
+	}
+	return "unknown"
+}
+
+// newAuthenticationError wraps a failure to obtain a token from the configured credential
+func newAuthenticationError(storageAccount string, err error) *ConnectivityError {
+	return &ConnectivityError{
+		StorageAccount: storageAccount,
+		Kind:           ErrAuthenticationFailed,
+		Hint:           "check the ARM_* / AZURE_* credential environment variables or run 'az login'",
+		Err:            err,
+	}
+}
+
+// classifyConnectivityError translates a data-plane failure into a ConnectivityError
+func classifyConnectivityError(storageAccount string, err error) *ConnectivityError {
+	connErr := &ConnectivityError{StorageAccount: storageAccount, Err: err}
+
+	var respErr *azcore.ResponseError
+	var dnsErr *net.DNSError
+	switch {
+	case bloberror.HasCode(err, bloberror.AuthorizationSourceIPMismatch, bloberror.AuthorizationFailure):
+		connErr.Kind = ErrNetworkAccessDenied
+		connErr.Hint = "the storage account firewall rejected the request; add this client's IP to the network rules or connect through a private endpoint"
+	case bloberror.HasCode(err, bloberror.AuthorizationPermissionMismatch):
+		connErr.Kind = ErrAuthorizationFailed
+		connErr.Hint = "the principal has no data-plane role on the account; assign 'Storage Blob Data Contributor' on the storage account or container"
+	case bloberror.HasCode(err, bloberror.InvalidAuthenticationInfo, bloberror.NoAuthenticationInformation):
+		connErr.Kind = ErrAuthenticationFailed
+		connErr.Hint = "the token was rejected by the storage service; check that the credential targets the correct tenant"
+	case bloberror.HasCode(err, bloberror.AccountIsDisabled):
+		connErr.Kind = ErrAccountNotFound
+		connErr.Hint = "the storage account is disabled"
+	case errors.As(err, &respErr) && respErr.StatusCode == http.StatusForbidden:
+		connErr.Kind = ErrAuthorizationFailed
+		connErr.Hint = "assign 'Storage Blob Data Contributor' on the storage account or container"
+	case errors.As(err, &respErr) && respErr.StatusCode == http.StatusUnauthorized:
+		connErr.Kind = ErrAuthenticationFailed
+		connErr.Hint = "the token was rejected by the storage service; check that the credential targets the correct tenant"
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		connErr.Kind = ErrAccountNotFound
+		connErr.Hint = "verify the storage account name; for private endpoints make sure the privatelink.blob.core.windows.net DNS zone is linked to this network"
+	case errors.Is(err, context.DeadlineExceeded) || isNetError(err):
+		connErr.Kind = ErrEndpointUnreachable
+		connErr.Hint = "the endpoint did not answer; check private endpoint DNS resolution, proxies and outbound firewall rules"
+	default:
+		connErr.Kind = ErrEndpointUnreachable
+	}
+
+	return connErr
+}
+
+func isNetError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
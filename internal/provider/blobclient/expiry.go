@@ -0,0 +1,77 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+)
+
+// ErrExpiryUnsupported indicates the storage account rejected Set Blob Expiry, which is only
+// available on accounts with hierarchical namespace enabled
+var ErrExpiryUnsupported = errors.New("blob expiry is not supported by this storage account")
+
+// BlobExpiry describes when the service deletes a blob on its own
+type BlobExpiry struct {
+	// At is an absolute expiry time. It takes precedence over Days.
+	At time.Time
+	// Days is the number of days after blob creation at which the blob expires
+	Days int32
+}
+
+// expiryType converts the expiry into the SDK option. A nil expiry removes any existing expiry.
+func (e *BlobExpiry) expiryType() blockblob.ExpiryType {
+	switch {
+	case e == nil:
+		return blockblob.ExpiryTypeNever{}
+	case !e.At.IsZero():
+		return blockblob.ExpiryTypeAbsolute(e.At)
+	default:
+		return blockblob.ExpiryTypeRelativeToCreation(time.Duration(e.Days) * 24 * time.Hour)
+	}
+}
+
+// SetBlobExpiry sets or, when config.Expiry is nil, clears the expiry of a blob and returns the
+// expiry time reported by the service afterwards (nil when the blob never expires)
+func (c *AzureBlobLeaseClient) SetBlobExpiry(ctx context.Context, config BlobLeaseConfig) (*time.Time, error) {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
+	return setExpiry(ctx, containerClient.NewBlockBlobClient(config.BlobName), config.StorageAccount, config.Expiry)
+}
+
+// setExpiry applies expiry to the blob and reads back the resulting expiry time
+func setExpiry(ctx context.Context, client *blockblob.Client, storageAccount string, expiry *BlobExpiry) (*time.Time, error) {
+	if _, err := client.SetExpiry(ctx, expiry.expiryType(), nil); err != nil {
+		if isExpiryUnsupported(err) {
+			return nil, fmt.Errorf("%w: storage account %s must have hierarchical namespace (Data Lake Storage Gen2) enabled to set an expiry: %w",
+				ErrExpiryUnsupported, storageAccount, wrapError(err, "set blob expiry rejected"))
+		}
+		return nil, wrapError(err, "failed to set expiry on blob %s", client.URL())
+	}
+
+	props, err := client.GetProperties(ctx, nil)
+	if err != nil {
+		return nil, wrapError(err, "failed to read blob expiry")
+	}
+	return props.ExpiresOn, nil
+}
+
+// isExpiryUnsupported reports whether a Set Blob Expiry failure means the account lacks the
+// feature. Flat-namespace accounts reject the call with a 400 or 409 rather than a specific code.
+func isExpiryUnsupported(err error) bool {
+	if bloberror.HasCode(err, bloberror.LeaseIDMissing, bloberror.LeaseIDMismatchWithBlobOperation, bloberror.BlobNotFound) {
+		return false
+	}
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && (respErr.StatusCode == http.StatusBadRequest || respErr.StatusCode == http.StatusConflict)
+}
@@ -0,0 +1,77 @@
+package blobclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// reservedHeaders are set by the client itself, for authentication, request signing, content
+// validation or leasing, and cannot be sent as extra headers
+var reservedHeaders = []string{
+	"authorization",
+	"content-length",
+	"content-md5",
+	"x-ms-date",
+	"x-ms-version",
+	"x-ms-client-request-id",
+	"x-ms-content-crc64",
+	"x-ms-blob-content-md5",
+	"x-ms-lease-id",
+}
+
+// ValidateExtraHeader reports whether name and value can be sent as an extra upload header:
+// Content-Type or an x-ms-* header that the client does not set itself, and a value without
+// line breaks. Customer-provided encryption keys are rejected too, since later reads could not
+// decrypt the blob.
+func ValidateExtraHeader(name, value string) error {
+	lower := strings.ToLower(name)
+	if lower != "content-type" && !strings.HasPrefix(lower, "x-ms-") {
+		return fmt.Errorf("header %q must be Content-Type or start with x-ms-", name)
+	}
+	for _, reserved := range reservedHeaders {
+		if lower == reserved {
+			return fmt.Errorf("header %q is set by the provider and cannot be overridden", name)
+		}
+	}
+	if strings.HasPrefix(lower, "x-ms-encryption-key") || lower == "x-ms-encryption-algorithm" {
+		return fmt.Errorf("header %q would encrypt the blob with a key the provider cannot read it with", name)
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("value of header %q must not contain line breaks", name)
+	}
+	return nil
+}
+
+// extraHeadersKey is the context key for the extra headers of an upload
+type extraHeadersKey struct{}
+
+// withExtraHeaders returns a context in which upload requests carry headers. Empty headers
+// return ctx unchanged.
+func withExtraHeaders(ctx context.Context, headers map[string]string) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, extraHeadersKey{}, headers)
+}
+
+// extraHeadersPolicy adds the extra headers of the request context to the requests that create
+// or replace a blob: Put Blob, Copy Blob and Put Block List. Other calls of an upload, such as
+// staged or appended blocks and property reads, are sent as they are.
+type extraHeadersPolicy struct{}
+
+func (extraHeadersPolicy) Do(req *policy.Request) (*http.Response, error) {
+	raw := req.Raw()
+	headers, _ := raw.Context().Value(extraHeadersKey{}).(map[string]string)
+	if len(headers) > 0 && raw.Method == http.MethodPut {
+		if comp := raw.URL.Query().Get("comp"); comp == "" || comp == "blocklist" {
+			for name, value := range headers {
+				raw.Header.Set(name, value)
+			}
+		}
+	}
+	return req.Next()
+}
@@ -0,0 +1,90 @@
+package blobclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+)
+
+// BlobHTTPHeaders holds the HTTP headers managed on a blob. An empty value means the header is not set.
+type BlobHTTPHeaders struct {
+	CacheControl       string
+	ContentEncoding    string
+	ContentLanguage    string
+	ContentDisposition string
+}
+
+// sdkHeaders builds the SDK headers. Set Blob Properties replaces every header, so the content
+// type and MD5 that are not managed here must be passed through to keep them.
+func (h BlobHTTPHeaders) sdkHeaders(contentType *string, contentMD5 []byte) *blob.HTTPHeaders {
+	return &blob.HTTPHeaders{
+		BlobCacheControl:       optionalString(h.CacheControl),
+		BlobContentEncoding:    optionalString(h.ContentEncoding),
+		BlobContentLanguage:    optionalString(h.ContentLanguage),
+		BlobContentDisposition: optionalString(h.ContentDisposition),
+		BlobContentType:        contentType,
+		BlobContentMD5:         contentMD5,
+	}
+}
+
+// headersFromProperties extracts the managed headers from a blob properties response
+func headersFromProperties(props blob.GetPropertiesResponse) BlobHTTPHeaders {
+	return BlobHTTPHeaders{
+		CacheControl:       stringValue(props.CacheControl),
+		ContentEncoding:    stringValue(props.ContentEncoding),
+		ContentLanguage:    stringValue(props.ContentLanguage),
+		ContentDisposition: stringValue(props.ContentDisposition),
+	}
+}
+
+// stringValue dereferences an optional header value
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// int64Value dereferences an optional count reported in blob properties
+func int64Value(count *int64) int64 {
+	if count == nil {
+		return 0
+	}
+	return *count
+}
+
+// optionalString returns nil for an empty string so the header is omitted
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// SetBlobHTTPHeaders replaces the managed HTTP headers of an existing blob under its lease without
+// rewriting the content. Headers left empty in config.Headers are cleared. Returns the new ETag.
+func (c *AzureBlobLeaseClient) SetBlobHTTPHeaders(ctx context.Context, config BlobLeaseConfig) (string, error) {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
+	if err != nil {
+		return "", fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
+	blobClientRef := containerClient.NewBlockBlobClient(config.BlobName)
+
+	props, err := blobClientRef.GetProperties(ctx, nil)
+	if err != nil {
+		return "", wrapError(err, "failed to get blob properties")
+	}
+
+	resp, err := blobClientRef.SetHTTPHeaders(ctx, *config.Headers.sdkHeaders(props.ContentType, props.ContentMD5), &blob.SetHTTPHeadersOptions{
+		AccessConditions: leaseConditions(config.LeaseID),
+	})
+	if err != nil {
+		return "", wrapError(err, "failed to set HTTP headers on blob %s", config.BlobName)
+	}
+
+	return etagValue(resp.ETag), nil
+}
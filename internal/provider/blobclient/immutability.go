@@ -0,0 +1,117 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+)
+
+// Immutability policy modes that can be set on a blob
+const (
+	ImmutabilityModeUnlocked = string(blob.ImmutabilityPolicySettingUnlocked)
+	ImmutabilityModeLocked   = string(blob.ImmutabilityPolicySettingLocked)
+)
+
+// ErrImmutabilityPolicyLocked indicates a change to a locked immutability policy other than
+// extending its expiry
+var ErrImmutabilityPolicyLocked = errors.New("immutability policy is locked")
+
+// ErrBlobImmutable indicates a blob cannot be deleted or overwritten because of a legal hold or
+// an immutability policy that has not expired
+var ErrBlobImmutable = errors.New("blob is immutable")
+
+// ImmutabilityPolicy is a time-based retention policy that keeps a blob from being modified or
+// deleted until ExpiresOn
+type ImmutabilityPolicy struct {
+	ExpiresOn time.Time
+	// Mode is ImmutabilityModeUnlocked, which still allows the policy to be shortened or deleted,
+	// or ImmutabilityModeLocked, which only allows extending it
+	Mode string
+}
+
+// Active reports whether the policy still protects the blob at now
+func (p *ImmutabilityPolicy) Active(now time.Time) bool {
+	return p != nil && p.ExpiresOn.After(now)
+}
+
+// immutabilityPolicyFromProperties returns the policy of a blob, nil when it has none
+func immutabilityPolicyFromProperties(expiresOn *time.Time, mode *blob.ImmutabilityPolicyMode) *ImmutabilityPolicy {
+	if expiresOn == nil || expiresOn.IsZero() {
+		return nil
+	}
+	policy := &ImmutabilityPolicy{ExpiresOn: *expiresOn, Mode: ImmutabilityModeUnlocked}
+	if mode != nil && *mode == blob.ImmutabilityPolicyModeLocked {
+		policy.Mode = ImmutabilityModeLocked
+	}
+	return policy
+}
+
+// SetBlobLegalHold places or clears a legal hold on a blob. The container must have version-level
+// immutability support enabled.
+func (c *AzureBlobLeaseClient) SetBlobLegalHold(ctx context.Context, config BlobLeaseConfig, hold bool) error {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
+	if err != nil {
+		return fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
+	blobClientRef := containerClient.NewBlobClient(config.BlobName)
+
+	if _, err := blobClientRef.SetLegalHold(ctx, hold, nil); err != nil {
+		return wrapError(err, "failed to set legal hold on blob %s", config.BlobName)
+	}
+	return nil
+}
+
+// SetBlobImmutabilityPolicy sets the immutability policy of a blob or, when policy is nil, deletes
+// it. A locked policy can only be extended; any other change returns ErrImmutabilityPolicyLocked,
+// as the service rejects it.
+func (c *AzureBlobLeaseClient) SetBlobImmutabilityPolicy(ctx context.Context, config BlobLeaseConfig, policy *ImmutabilityPolicy) error {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
+	if err != nil {
+		return fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
+	blobClientRef := containerClient.NewBlobClient(config.BlobName)
+
+	if policy == nil {
+		if _, err := blobClientRef.DeleteImmutabilityPolicy(ctx, nil); err != nil {
+			if bloberror.HasCode(err, bloberror.ImmutabilityPolicyDeleteOnLockedPolicy) {
+				return fmt.Errorf("%w: the policy on blob %s cannot be deleted, only extended: %w",
+					ErrImmutabilityPolicyLocked, config.BlobName, wrapError(err, "delete immutability policy rejected"))
+			}
+			return wrapError(err, "failed to delete immutability policy of blob %s", config.BlobName)
+		}
+		return nil
+	}
+
+	mode := blob.ImmutabilityPolicySetting(policy.Mode)
+	_, err = blobClientRef.SetImmutabilityPolicy(ctx, policy.ExpiresOn, &blob.SetImmutabilityPolicyOptions{
+		Mode: &mode,
+	})
+	if err == nil {
+		return nil
+	}
+
+	// The service does not say why a change was rejected, so compare with the current policy
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) && (respErr.StatusCode == http.StatusBadRequest || respErr.StatusCode == http.StatusConflict) {
+		props, propsErr := blobClientRef.GetProperties(ctx, nil)
+		current := immutabilityPolicyFromProperties(props.ImmutabilityPolicyExpiresOn, props.ImmutabilityPolicyMode)
+		if propsErr == nil && current != nil && current.Mode == ImmutabilityModeLocked &&
+			(policy.ExpiresOn.Before(current.ExpiresOn) || policy.Mode != ImmutabilityModeLocked) {
+			return fmt.Errorf("%w: the policy on blob %s protects it until %s and can only be extended: %w",
+				ErrImmutabilityPolicyLocked, config.BlobName, current.ExpiresOn.UTC().Format(time.RFC3339), wrapError(err, "set immutability policy rejected"))
+		}
+	}
+	return wrapError(err, "failed to set immutability policy of blob %s", config.BlobName)
+}
@@ -0,0 +1,120 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/lease"
+)
+
+// ErrTooManyBlobs indicates a listing matched more blobs than ListBlobsOptions.MaxResults allows
+var ErrTooManyBlobs = errors.New("too many blobs")
+
+// ListBlobsOptions select the blobs of a container returned by ListBlobs
+type ListBlobsOptions struct {
+	// Prefix only lists blobs whose name starts with it; empty lists every blob
+	Prefix string
+	// LeaseState only returns blobs in this lease state; empty returns any
+	LeaseState string
+	// Metadata only returns blobs that have every key, matched ignoring case, with its value
+	Metadata map[string]string
+	// MaxResults is how many blobs may match; a listing that matches more returns
+	// ErrTooManyBlobs. Zero allows any number.
+	MaxResults int
+}
+
+// BlobItem describes a blob of a container listing
+type BlobItem struct {
+	Name         string
+	ETag         string
+	Size         int64
+	LeaseState   string
+	LeaseStatus  string
+	LastModified *time.Time
+}
+
+// ListBlobs lists the blobs of a container that match options, in name order, page by page. An
+// empty container returns no blobs and a missing one ErrContainerNotFound.
+func (c *AzureBlobLeaseClient) ListBlobs(ctx context.Context, storageAccount, containerName string, options ListBlobsOptions) ([]BlobItem, error) {
+	var items []BlobItem
+	var tooMany bool
+	_, err := c.readWithFallbacks(ctx, storageAccount, func(blobClient *azblob.Client) error {
+		// A read retried against another endpoint starts over
+		items, tooMany = nil, false
+
+		containerClient := blobClient.ServiceClient().NewContainerClient(containerName)
+		pager := containerClient.NewListBlobsFlatPager(&container.ListBlobsFlatOptions{
+			Prefix:  optionalString(options.Prefix),
+			Include: container.ListBlobsInclude{Metadata: len(options.Metadata) > 0},
+		})
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return err
+			}
+			for _, blobItem := range page.Segment.BlobItems {
+				item, ok := listedBlob(blobItem, options)
+				if !ok {
+					continue
+				}
+				if options.MaxResults > 0 && len(items) == options.MaxResults {
+					tooMany = true
+					return nil
+				}
+				items = append(items, item)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.ContainerNotFound) {
+			return nil, fmt.Errorf("%w: container %s does not exist in storage account %s", ErrContainerNotFound, containerName, storageAccount)
+		}
+		return nil, wrapError(err, "failed to list blobs in container %s", containerName)
+	}
+	if tooMany {
+		return nil, fmt.Errorf("%w: more than %d blobs in container %s match", ErrTooManyBlobs, options.MaxResults, containerName)
+	}
+
+	return items, nil
+}
+
+// listedBlob converts a blob of a listing, and reports whether it matches options
+func listedBlob(blobItem *container.BlobItem, options ListBlobsOptions) (BlobItem, bool) {
+	if blobItem == nil || blobItem.Name == nil {
+		return BlobItem{}, false
+	}
+
+	item := BlobItem{
+		Name:        *blobItem.Name,
+		LeaseState:  string(lease.StateTypeAvailable),
+		LeaseStatus: string(lease.StatusTypeUnlocked),
+	}
+	if props := blobItem.Properties; props != nil {
+		item.ETag = etagValue(props.ETag)
+		item.Size = int64Value(props.ContentLength)
+		item.LastModified = props.LastModified
+		if props.LeaseState != nil {
+			item.LeaseState = string(*props.LeaseState)
+		}
+		if props.LeaseStatus != nil {
+			item.LeaseStatus = string(*props.LeaseStatus)
+		}
+	}
+
+	if options.LeaseState != "" && item.LeaseState != options.LeaseState {
+		return item, false
+	}
+	for key, value := range options.Metadata {
+		stored, ok := blobItem.Metadata[findKey(blobItem.Metadata, key)]
+		if !ok || stringValue(stored) != value {
+			return item, false
+		}
+	}
+	return item, true
+}
@@ -0,0 +1,102 @@
+package blobclient
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+)
+
+// LeaseOwnerMetadataKey is the metadata key an owner is written under, so that someone blocked
+// by the lease can find out who holds it
+const LeaseOwnerMetadataKey = "lease_owner"
+
+// AcquiredAtMetadataKey is the metadata key holding the RFC3339 time a lease was acquired, which
+// StealIfOlderThan judges the age of a lease by
+const AcquiredAtMetadataKey = "tf_acquired_at"
+
+// metadataKeyPattern matches the C# identifier rules Azure applies to metadata names
+var metadataKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateMetadataKey reports whether key is a valid blob metadata name
+func ValidateMetadataKey(key string) error {
+	if !metadataKeyPattern.MatchString(key) {
+		return fmt.Errorf("metadata key %q must start with a letter or underscore and contain only letters, digits and underscores", key)
+	}
+	return nil
+}
+
+// DefaultMetadata returns the metadata written to every blob in addition to the per-blob metadata
+func (c *AzureBlobLeaseClient) DefaultMetadata() map[string]string {
+	return c.options.DefaultMetadata
+}
+
+// ProviderVersion returns the version of the provider the client was created for
+func (c *AzureBlobLeaseClient) ProviderVersion() string {
+	return c.options.ProviderVersion
+}
+
+// blobMetadata merges the Terraform metadata of config, the default metadata and the per-blob
+// metadata, each winning per key over the ones before, and a non-empty owner, which wins over
+// all. Metadata names are case-insensitive, so a key replaces one differing only in case.
+func (c *AzureBlobLeaseClient) blobMetadata(config BlobLeaseConfig) map[string]*string {
+	sources := []map[string]string{config.TerraformMetadata, c.options.DefaultMetadata, config.Metadata}
+	if config.Owner != "" {
+		sources = append(sources, map[string]string{LeaseOwnerMetadataKey: config.Owner})
+	}
+
+	merged := map[string]*string{}
+	for _, source := range sources {
+		for key, value := range source {
+			delete(merged, findKey(merged, key))
+			merged[key] = &value
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// findKey returns the key of m equal to key ignoring case, or key itself if there is none
+func findKey[V any](m map[string]V, key string) string {
+	for existing := range m {
+		if strings.EqualFold(existing, key) {
+			return existing
+		}
+	}
+	return key
+}
+
+// metadataFromProperties converts the metadata of a blob properties response
+func metadataFromProperties(metadata map[string]*string) map[string]string {
+	result := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		result[key] = stringValue(value)
+	}
+	return result
+}
+
+// SetBlobMetadata replaces the metadata of an existing blob under its lease without rewriting the
+// content. Terraform and default metadata and the owner are merged in as on upload. Returns the new ETag.
+func (c *AzureBlobLeaseClient) SetBlobMetadata(ctx context.Context, config BlobLeaseConfig) (string, error) {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
+	if err != nil {
+		return "", fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
+	blobClientRef := containerClient.NewBlockBlobClient(config.BlobName)
+
+	resp, err := blobClientRef.SetMetadata(ctx, c.blobMetadata(config), &blob.SetMetadataOptions{
+		AccessConditions: leaseConditions(config.LeaseID),
+	})
+	if err != nil {
+		return "", wrapError(err, "failed to set metadata on blob %s", config.BlobName)
+	}
+
+	return etagValue(resp.ETag), nil
+}
@@ -0,0 +1,35 @@
+package blobclient
+
+import (
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	headerRequestID       = "x-ms-request-id"
+	headerClientRequestID = "x-ms-client-request-id"
+)
+
+// requestIDPolicy logs the service and client request IDs of every storage response at DEBUG level
+type requestIDPolicy struct{}
+
+func (requestIDPolicy) Do(req *policy.Request) (*http.Response, error) {
+	resp, err := req.Next()
+	if resp == nil {
+		return resp, err
+	}
+
+	raw := req.Raw()
+	tflog.Debug(raw.Context(), "Azure Storage response", map[string]interface{}{
+		"method":              raw.Method,
+		"host":                raw.URL.Host,
+		"path":                raw.URL.Path,
+		"status":              resp.StatusCode,
+		headerRequestID:       resp.Header.Get(headerRequestID),
+		headerClientRequestID: raw.Header.Get(headerClientRequestID),
+	})
+
+	return resp, err
+}
@@ -0,0 +1,139 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// finalRenewalTimeout bounds the best-effort renewal performed when a renewer stops
+const finalRenewalTimeout = 10 * time.Second
+
+// defaultRenewalInterval is used when no interval is set: half the shortest lease Azure grants (15s)
+const defaultRenewalInterval = 7500 * time.Millisecond
+
+// LeaseRenewalOptions configures background lease renewal
+type LeaseRenewalOptions struct {
+	// Interval between renewals. Should be well under the lease duration. Zero or negative means
+	// defaultRenewalInterval, which is short enough for any time-limited lease.
+	Interval time.Duration
+	// Jitter randomizes each interval by up to this fraction (0-1) so many renewers don't synchronize.
+	Jitter float64
+	// MaxRetries is the number of retries after a failed renewal before the error is reported (default 3).
+	MaxRetries int
+	// RetryBackoff is the initial delay between retries, doubled on each attempt (default 1s).
+	RetryBackoff time.Duration
+	// OnError is called when a renewal still fails after all retries. Renewal continues afterwards,
+	// unless the lease is no longer held: that is reported as ErrLeaseLost, without retries, and
+	// ends the renewal.
+	OnError func(error)
+}
+
+// LeaseRenewer is a handle to a background renewal started by StartLeaseRenewal
+type LeaseRenewer struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Stop cancels the renewal and waits for the goroutine, including its final renewal, to exit.
+// It is safe to call more than once.
+func (r *LeaseRenewer) Stop() {
+	r.once.Do(r.cancel)
+	<-r.done
+}
+
+// Done is closed once the renewal goroutine has exited
+func (r *LeaseRenewer) Done() <-chan struct{} {
+	return r.done
+}
+
+// StartLeaseRenewal starts a background process to automatically renew the lease.
+// Failed renewals are retried with backoff and reported through options.OnError; the renewal
+// stops when ctx is cancelled or Stop is called, after one final best-effort renewal. It only
+// renews a lease that is still held: a lease broken, released or taken over in the meantime is
+// never acquired again behind the back of whoever broke it.
+func (c *AzureBlobLeaseClient) StartLeaseRenewal(ctx context.Context, config BlobLeaseConfig, options LeaseRenewalOptions) *LeaseRenewer {
+	renew := func(ctx context.Context) error {
+		held, err := c.ProbeBlobLease(ctx, config)
+		if err == nil && !held {
+			return fmt.Errorf("%w: the lease on blob %s was broken, released or taken over", ErrLeaseLost, config.BlobName)
+		}
+		return err
+	}
+	return startRenewer(ctx, renew, options, time.After)
+}
+
+// startRenewer runs renew on a jittered interval. after is injected so the schedule can be driven by a fake clock.
+func startRenewer(ctx context.Context, renew func(context.Context) error, options LeaseRenewalOptions, after func(time.Duration) <-chan time.Time) *LeaseRenewer {
+	if options.Interval <= 0 {
+		options.Interval = defaultRenewalInterval
+	}
+	if options.MaxRetries <= 0 {
+		options.MaxRetries = 3
+	}
+	if options.RetryBackoff <= 0 {
+		options.RetryBackoff = time.Second
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	r := &LeaseRenewer{cancel: cancel, done: make(chan struct{})}
+
+	go func() {
+		defer close(r.done)
+
+		for {
+			select {
+			case <-ctx.Done():
+				finalCtx, finalCancel := context.WithTimeout(context.WithoutCancel(ctx), finalRenewalTimeout)
+				_ = renew(finalCtx)
+				finalCancel()
+				return
+			case <-after(jitter(options.Interval, options.Jitter)):
+			}
+
+			err := renewWithRetry(ctx, renew, options, after)
+			if err != nil && ctx.Err() == nil && options.OnError != nil {
+				options.OnError(fmt.Errorf("failed to renew lease during background renewal: %w", err))
+			}
+			// There is nothing left to renew, not even on stop
+			if errors.Is(err, ErrLeaseLost) {
+				return
+			}
+		}
+	}()
+
+	return r
+}
+
+// renewWithRetry calls renew, retrying with exponential backoff up to options.MaxRetries times. A
+// lost lease is not retried.
+func renewWithRetry(ctx context.Context, renew func(context.Context) error, options LeaseRenewalOptions, after func(time.Duration) <-chan time.Time) error {
+	backoff := options.RetryBackoff
+	err := renew(ctx)
+	for attempt := 0; err != nil && !errors.Is(err, ErrLeaseLost) && attempt < options.MaxRetries; attempt++ {
+		select {
+		case <-ctx.Done():
+			return err
+		case <-after(backoff):
+		}
+		backoff *= 2
+		err = renew(ctx)
+	}
+	return err
+}
+
+// jitter returns d shifted randomly by up to +/- fraction*d
+func jitter(d time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 || d <= 0 {
+		return d
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	delta := time.Duration(float64(d) * fraction * (2*rand.Float64() - 1))
+	return d + delta
+}
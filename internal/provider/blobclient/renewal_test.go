@@ -0,0 +1,285 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock stands in for time.After: every wait is handed to the test, which fires it
+type fakeClock struct {
+	waits chan fakeWait
+}
+
+type fakeWait struct {
+	d    time.Duration
+	fire chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{waits: make(chan fakeWait)}
+}
+
+func (c *fakeClock) after(d time.Duration) <-chan time.Time {
+	fire := make(chan time.Time, 1)
+	c.waits <- fakeWait{d: d, fire: fire}
+	return fire
+}
+
+// next returns the next wait started by the renewer, failing if it does not start one
+func (c *fakeClock) next(t *testing.T) fakeWait {
+	t.Helper()
+	select {
+	case w := <-c.waits:
+		return w
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the renewer to wait")
+		return fakeWait{}
+	}
+}
+
+// expect fires the next wait after checking its duration
+func (c *fakeClock) expect(t *testing.T, d time.Duration) {
+	t.Helper()
+	w := c.next(t)
+	if w.d != d {
+		t.Fatalf("expected a wait of %s, got %s", d, w.d)
+	}
+	w.fire <- time.Time{}
+}
+
+// scriptedRenew fails the calls whose index is in failures and records every call
+type scriptedRenew struct {
+	mu       sync.Mutex
+	calls    int
+	failures map[int]bool
+	ctxErrs  []error
+}
+
+var errRenew = errors.New("renew failed")
+
+func (s *scriptedRenew) renew(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+	s.ctxErrs = append(s.ctxErrs, ctx.Err())
+	if s.failures[s.calls] {
+		return errRenew
+	}
+	return nil
+}
+
+func (s *scriptedRenew) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+// errorRecorder collects the errors passed to OnError
+type errorRecorder struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *errorRecorder) onError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+func (r *errorRecorder) get() []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]error(nil), r.errs...)
+}
+
+func TestRenewerRetriesWithBackoff(t *testing.T) {
+	clock := newFakeClock()
+	renew := &scriptedRenew{failures: map[int]bool{1: true, 2: true}}
+	errs := &errorRecorder{}
+
+	r := startRenewer(context.Background(), renew.renew, LeaseRenewalOptions{
+		Interval:     10 * time.Second,
+		MaxRetries:   3,
+		RetryBackoff: time.Second,
+		OnError:      errs.onError,
+	}, clock.after)
+
+	clock.expect(t, 10*time.Second)
+	clock.expect(t, time.Second)
+	clock.expect(t, 2*time.Second)
+	// Recovered on the second retry, so the next wait is a regular interval
+	clock.next(t)
+
+	if got := renew.count(); got != 3 {
+		t.Errorf("expected 3 renewals, got %d", got)
+	}
+	if got := errs.get(); len(got) != 0 {
+		t.Errorf("expected no errors after recovery, got %v", got)
+	}
+
+	r.Stop()
+}
+
+func TestRenewerReportsErrorAfterMaxRetries(t *testing.T) {
+	clock := newFakeClock()
+	renew := &scriptedRenew{failures: map[int]bool{1: true, 2: true, 3: true}}
+	errs := &errorRecorder{}
+
+	r := startRenewer(context.Background(), renew.renew, LeaseRenewalOptions{
+		Interval:     10 * time.Second,
+		MaxRetries:   2,
+		RetryBackoff: time.Second,
+		OnError:      errs.onError,
+	}, clock.after)
+
+	clock.expect(t, 10*time.Second)
+	clock.expect(t, time.Second)
+	clock.expect(t, 2*time.Second)
+	// Renewal carries on after reporting the error
+	clock.expect(t, 10*time.Second)
+	clock.next(t)
+
+	got := errs.get()
+	if len(got) != 1 {
+		t.Fatalf("expected one error, got %v", got)
+	}
+	if !errors.Is(got[0], errRenew) {
+		t.Errorf("expected the renew error to be wrapped, got %v", got[0])
+	}
+	if got := renew.count(); got != 4 {
+		t.Errorf("expected 4 renewals, got %d", got)
+	}
+
+	r.Stop()
+}
+
+func TestRenewerFinalRenewalOnStop(t *testing.T) {
+	clock := newFakeClock()
+	renew := &scriptedRenew{}
+
+	r := startRenewer(context.Background(), renew.renew, LeaseRenewalOptions{Interval: 10 * time.Second}, clock.after)
+	clock.next(t)
+	r.Stop()
+
+	if got := renew.count(); got != 1 {
+		t.Fatalf("expected one final renewal, got %d", got)
+	}
+	if renew.ctxErrs[0] != nil {
+		t.Errorf("expected the final renewal to run with a live context, got %v", renew.ctxErrs[0])
+	}
+}
+
+func TestRenewerStopIsIdempotent(t *testing.T) {
+	clock := newFakeClock()
+	renew := &scriptedRenew{}
+
+	r := startRenewer(context.Background(), renew.renew, LeaseRenewalOptions{Interval: 10 * time.Second}, clock.after)
+	clock.next(t)
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Stop()
+		}()
+	}
+	wg.Wait()
+	r.Stop()
+
+	select {
+	case <-r.Done():
+	default:
+		t.Fatal("expected Done to be closed after Stop")
+	}
+	if got := renew.count(); got != 1 {
+		t.Errorf("expected a single final renewal, got %d", got)
+	}
+}
+
+func TestRenewerStopsWithContext(t *testing.T) {
+	clock := newFakeClock()
+	renew := &scriptedRenew{}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := startRenewer(ctx, renew.renew, LeaseRenewalOptions{Interval: 10 * time.Second}, clock.after)
+	clock.next(t)
+	cancel()
+
+	select {
+	case <-r.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the renewer to exit when its context is cancelled")
+	}
+	if got := renew.count(); got != 1 {
+		t.Errorf("expected one final renewal, got %d", got)
+	}
+}
+
+func TestRenewerDefaultsNonPositiveInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		clock := newFakeClock()
+		renew := &scriptedRenew{}
+
+		r := startRenewer(context.Background(), renew.renew, LeaseRenewalOptions{Interval: interval, Jitter: 0.5}, clock.after)
+		w := clock.next(t)
+		r.Stop()
+
+		if w.d < defaultRenewalInterval/2 || w.d > defaultRenewalInterval*3/2 {
+			t.Errorf("interval %s: expected a wait around %s, got %s", interval, defaultRenewalInterval, w.d)
+		}
+	}
+}
+
+func TestJitter(t *testing.T) {
+	d := 10 * time.Second
+	for range 100 {
+		if got := jitter(d, 0.1); got < 9*time.Second || got > 11*time.Second {
+			t.Fatalf("expected jitter within 10%% of %s, got %s", d, got)
+		}
+	}
+	if got := jitter(d, 0); got != d {
+		t.Errorf("expected no jitter, got %s", got)
+	}
+	if got := jitter(d, 5); got < 0 || got > 2*d {
+		t.Errorf("expected the fraction to be capped at 1, got %s", got)
+	}
+}
+
+func TestRenewerStopsWhenLeaseLost(t *testing.T) {
+	clock := newFakeClock()
+	errs := &errorRecorder{}
+	var calls int
+	renew := func(ctx context.Context) error {
+		calls++
+		return ErrLeaseLost
+	}
+
+	r := startRenewer(context.Background(), renew, LeaseRenewalOptions{
+		Interval:     10 * time.Second,
+		MaxRetries:   3,
+		RetryBackoff: time.Second,
+		OnError:      errs.onError,
+	}, clock.after)
+	clock.expect(t, 10*time.Second)
+
+	select {
+	case <-r.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the renewer to exit once the lease is lost")
+	}
+	r.Stop()
+
+	// Neither retried nor renewed a final time on stop
+	if calls != 1 {
+		t.Errorf("expected a single renewal, got %d", calls)
+	}
+	got := errs.get()
+	if len(got) != 1 || !errors.Is(got[0], ErrLeaseLost) {
+		t.Errorf("expected ErrLeaseLost to be reported once, got %v", got)
+	}
+}
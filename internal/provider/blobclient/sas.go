@@ -0,0 +1,237 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/service"
+)
+
+// SAS signing methods accepted by SignBlobSAS and SignContainerSAS
+const (
+	SASSigningUserDelegation = "user_delegation"
+	SASSigningAccountKey     = "account_key"
+)
+
+// MaxUserDelegationExpiry is how far ahead a user delegation key, and so a SAS signed with it, may expire
+const MaxUserDelegationExpiry = 7 * 24 * time.Hour
+
+// sasClockSkew backdates the start of a SAS so clocks running behind the service still accept it
+const sasClockSkew = 5 * time.Minute
+
+// ErrAccountKeyRequired indicates a SAS was to be signed with an account key while account key lookup is off
+var ErrAccountKeyRequired = errors.New("account key signing requires use_account_key_lookup")
+
+// BlobSASOptions describe the SAS signed by SignBlobSAS or SignContainerSAS
+type BlobSASOptions struct {
+	// Permissions are any of the flags r (read), w (write) and d (delete), and l (list) for a
+	// container SAS
+	Permissions string
+	// Expiry is when the SAS stops being accepted
+	Expiry time.Time
+	// SigningMethod is SASSigningUserDelegation or SASSigningAccountKey
+	SigningMethod string
+}
+
+// BlobSAS is a signed SAS for a blob or container
+type BlobSAS struct {
+	URL   string
+	Token string
+	Start time.Time
+}
+
+// GetUserDelegationCredential requests a user delegation key for the storage account, valid from
+// start until expiry, with the Azure credential of the client. The principal needs the
+// generateUserDelegationKey action on the account, e.g. through 'Storage Blob Delegator'.
+func (c *AzureBlobLeaseClient) GetUserDelegationCredential(ctx context.Context, storageAccount string, start, expiry time.Time) (*service.UserDelegationCredential, error) {
+	if c.credential == nil {
+		return nil, ErrCredentialRequired
+	}
+	// The service counts the limit from the current time, not from start
+	if validity := time.Until(expiry); validity > MaxUserDelegationExpiry {
+		return nil, fmt.Errorf("a user delegation key expires at most %s from now, requested %s", MaxUserDelegationExpiry, validity.Round(time.Second))
+	}
+
+	serviceURL, err := c.serviceURL(ctx, storageAccount)
+	if err != nil {
+		return nil, err
+	}
+	// A user delegation key is only issued to an Azure AD principal, even when account key
+	// lookup is on
+	serviceClient, err := service.NewClient(serviceURL, c.credential, (*service.ClientOptions)(c.azblobOptions()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service client for %s: %w", storageAccount, err)
+	}
+
+	udc, err := serviceClient.GetUserDelegationCredential(ctx, service.KeyInfo{
+		Start:  to.Ptr(start.UTC().Format(sas.TimeFormat)),
+		Expiry: to.Ptr(expiry.UTC().Format(sas.TimeFormat)),
+	}, nil)
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusForbidden {
+			return nil, fmt.Errorf("%w: the principal may not get a user delegation key for storage account %s; it needs Microsoft.Storage/storageAccounts/blobServices/generateUserDelegationKey/action, e.g. through 'Storage Blob Delegator' or a 'Storage Blob Data' role: %w",
+				ErrAuthorizationFailed, storageAccount, wrapError(err, "user delegation key request rejected"))
+		}
+		return nil, wrapError(err, "failed to get user delegation key for storage account %s", storageAccount)
+	}
+	return udc, nil
+}
+
+// SignBlobSAS signs an HTTPS-only SAS for a blob. The blob is not contacted, so the SAS may be
+// signed for a blob that does not exist yet. A user delegation SAS grants no more than the
+// principal itself holds on the blob, however many permissions it names.
+func (c *AzureBlobLeaseClient) SignBlobSAS(ctx context.Context, storageAccount, containerName, blobName string, options BlobSASOptions) (*BlobSAS, error) {
+	permissions, err := blobSASPermissions(options.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	return c.signSAS(ctx, storageAccount, containerName, blobName, permissions.String(), options)
+}
+
+// SignContainerSAS signs an HTTPS-only SAS for a container, which grants its permissions on
+// every blob of the container and, with l, listing them. Like SignBlobSAS, the container is not
+// contacted.
+func (c *AzureBlobLeaseClient) SignContainerSAS(ctx context.Context, storageAccount, containerName string, options BlobSASOptions) (*BlobSAS, error) {
+	permissions, err := containerSASPermissions(options.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	return c.signSAS(ctx, storageAccount, containerName, "", permissions.String(), options)
+}
+
+// signSAS signs a SAS with permissions for a blob, or for the container when blobName is empty
+func (c *AzureBlobLeaseClient) signSAS(ctx context.Context, storageAccount, containerName, blobName, permissions string, options BlobSASOptions) (*BlobSAS, error) {
+	now := time.Now()
+	if !options.Expiry.After(now) {
+		return nil, fmt.Errorf("SAS expiry %s is not in the future", options.Expiry.UTC().Format(time.RFC3339))
+	}
+
+	start := now.Add(-sasClockSkew)
+	values := sas.BlobSignatureValues{
+		Protocol:      sas.ProtocolHTTPS,
+		StartTime:     start,
+		ExpiryTime:    options.Expiry,
+		Permissions:   permissions,
+		ContainerName: containerName,
+		BlobName:      blobName,
+	}
+	target := "blob " + blobName
+	if blobName == "" {
+		target = "container " + containerName
+	}
+
+	var params sas.QueryParameters
+	switch options.SigningMethod {
+	case SASSigningAccountKey:
+		if c.keys == nil {
+			return nil, ErrAccountKeyRequired
+		}
+		sharedKey, err := c.keys.sharedKeyCredential(ctx, storageAccount)
+		if err != nil {
+			return nil, err
+		}
+		params, err = values.SignWithSharedKey(sharedKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign SAS for %s: %w", target, err)
+		}
+	case SASSigningUserDelegation, "":
+		udc, err := c.GetUserDelegationCredential(ctx, storageAccount, start, options.Expiry)
+		if err != nil {
+			return nil, err
+		}
+		params, err = values.SignWithUserDelegation(udc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign SAS for %s: %w", target, err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown SAS signing method %q", options.SigningMethod)
+	}
+
+	serviceURL, err := c.serviceURL(ctx, storageAccount)
+	if err != nil {
+		return nil, err
+	}
+	parts, err := blob.ParseURL(serviceURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse blob endpoint %s: %w", serviceURL, err)
+	}
+	parts.ContainerName = containerName
+	parts.BlobName = blobName
+	parts.SAS = params
+
+	return &BlobSAS{
+		URL:   parts.String(),
+		Token: params.Encode(),
+		Start: start,
+	}, nil
+}
+
+// parseSASPermissions sets the permission of each of flags in granted, each at most once.
+// expected lists the accepted flags for the error message.
+func parseSASPermissions(flags, expected string, granted map[rune]*bool) error {
+	if flags == "" {
+		return errors.New("a SAS needs at least one permission")
+	}
+	for _, flag := range flags {
+		permission, ok := granted[flag]
+		if !ok {
+			return fmt.Errorf("unknown SAS permission %q in %q, expected %s", flag, flags, expected)
+		}
+		if *permission {
+			return fmt.Errorf("SAS permission %q repeated in %q", flag, flags)
+		}
+		*permission = true
+	}
+	return nil
+}
+
+// blobSASPermissions parses blob SAS permission flags, any of r, w and d, each at most once
+func blobSASPermissions(flags string) (*sas.BlobPermissions, error) {
+	permissions := &sas.BlobPermissions{}
+	err := parseSASPermissions(flags, "r, w or d", map[rune]*bool{
+		'r': &permissions.Read,
+		'w': &permissions.Write,
+		'd': &permissions.Delete,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// containerSASPermissions parses container SAS permission flags, any of r, w, d and l, each at
+// most once
+func containerSASPermissions(flags string) (*sas.ContainerPermissions, error) {
+	permissions := &sas.ContainerPermissions{}
+	err := parseSASPermissions(flags, "r, w, d or l", map[rune]*bool{
+		'r': &permissions.Read,
+		'w': &permissions.Write,
+		'd': &permissions.Delete,
+		'l': &permissions.List,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// ValidBlobSASPermissions reports why flags are not valid SAS permissions, or nil if they are
+func ValidBlobSASPermissions(flags string) error {
+	_, err := blobSASPermissions(flags)
+	return err
+}
+
+// ValidContainerSASPermissions reports why flags are not valid container SAS permissions, or nil
+// if they are
+func ValidContainerSASPermissions(flags string) error {
+	_, err := containerSASPermissions(flags)
+	return err
+}
@@ -0,0 +1,55 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+)
+
+// createSecondaryBlobClient creates a blob client for the read-only RA-GRS secondary endpoint.
+// It must only be used for reads; the secondary rejects writes and lease operations. A custom blob
+// endpoint set on ctx has no known secondary, so none is created for it.
+func (c *AzureBlobLeaseClient) createSecondaryBlobClient(ctx context.Context, storageAccount string) (*azblob.Client, error) {
+	if endpoint := blobEndpoint(ctx); endpoint != "" {
+		return nil, fmt.Errorf("no secondary endpoint is known for blob endpoint %s", endpoint)
+	}
+	serviceURL := fmt.Sprintf("https://%s-secondary.blob.core.windows.net/", storageAccount)
+	if c.credential == nil {
+		return azblob.NewClientWithNoCredential(serviceURL, c.azblobOptions())
+	}
+	return c.newBlobClient(ctx, storageAccount, serviceURL)
+}
+
+// readWithFallbacks runs a read-only operation against the primary endpoint (with the anonymous
+// fallback) and, when enabled and the primary is failing, against the secondary endpoint.
+// It reports whether the successful read came from the secondary and may therefore be stale.
+// A failed secondary read returns the primary's error, so "not found" on a lagging secondary is
+// never mistaken for a deleted blob.
+func (c *AzureBlobLeaseClient) readWithFallbacks(ctx context.Context, storageAccount string, read func(*azblob.Client) error) (bool, error) {
+	err := c.withReadFallback(ctx, storageAccount, read)
+	if err == nil || !c.options.ReadFromSecondaryOnFailure || !isPrimaryOutage(err) {
+		return false, err
+	}
+
+	secondary, secErr := c.createSecondaryBlobClient(ctx, storageAccount)
+	if secErr != nil {
+		return false, err
+	}
+	if secErr := read(secondary); secErr != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// isPrimaryOutage reports whether err looks like a primary endpoint failure worth retrying on the secondary
+func isPrimaryOutage(err error) bool {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode >= http.StatusInternalServerError
+	}
+	return isNetError(err)
+}
@@ -0,0 +1,186 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+)
+
+// ErrTooManySnapshots indicates a blob has more snapshots than ListBlobSnapshots may return
+var ErrTooManySnapshots = errors.New("too many snapshots")
+
+// ErrSnapshotRejected indicates the storage account or a policy on it does not allow the blob
+// to be snapshotted
+var ErrSnapshotRejected = errors.New("blob snapshot rejected")
+
+// SnapshotBlob creates a read-only snapshot of the blob under its lease and returns the snapshot
+// ID, the timestamp that addresses the snapshot with the snapshot query parameter
+func (c *AzureBlobLeaseClient) SnapshotBlob(ctx context.Context, config BlobLeaseConfig) (string, error) {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
+	if err != nil {
+		return "", fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
+	blobClientRef := containerClient.NewBlobClient(config.BlobName)
+
+	resp, err := blobClientRef.CreateSnapshot(ctx, &blob.CreateSnapshotOptions{
+		AccessConditions: leaseConditions(config.LeaseID),
+	})
+	if err != nil {
+		if isSnapshotRejected(err) {
+			return "", fmt.Errorf("%w: storage account %s does not allow snapshots of blob %s: %w",
+				ErrSnapshotRejected, config.StorageAccount, config.BlobName, wrapError(err, "create snapshot rejected"))
+		}
+		return "", wrapError(err, "failed to snapshot blob %s", config.BlobName)
+	}
+
+	return stringValue(resp.Snapshot), nil
+}
+
+// isSnapshotRejected reports whether a Snapshot Blob failure comes from the account or its
+// policies rather than from the lease or a missing blob
+func isSnapshotRejected(err error) bool {
+	if bloberror.HasCode(err, bloberror.LeaseIDMissing, bloberror.LeaseIDMismatchWithBlobOperation, bloberror.LeaseNotPresentWithBlobOperation, bloberror.BlobNotFound) {
+		return false
+	}
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) {
+		return false
+	}
+	switch respErr.StatusCode {
+	case http.StatusBadRequest, http.StatusForbidden, http.StatusConflict:
+		return true
+	}
+	return false
+}
+
+// GetBlobSnapshot returns the URL of a snapshot of the blob, addressed by its snapshot ID, or ""
+// when the snapshot does not exist
+func (c *AzureBlobLeaseClient) GetBlobSnapshot(ctx context.Context, storageAccount, containerName, blobName, snapshot string) (string, error) {
+	var snapshotURL string
+	_, err := c.readWithFallbacks(ctx, storageAccount, func(blobClient *azblob.Client) error {
+		snapshotClient, err := blobClient.ServiceClient().NewContainerClient(containerName).NewBlobClient(blobName).WithSnapshot(snapshot)
+		if err != nil {
+			return err
+		}
+		if _, err := snapshotClient.GetProperties(ctx, nil); err != nil {
+			return err
+		}
+		snapshotURL = snapshotClient.URL()
+		return nil
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return "", nil
+		}
+		return "", wrapError(err, "failed to get snapshot %s of blob %s", snapshot, blobName)
+	}
+	return snapshotURL, nil
+}
+
+// DeleteBlobSnapshot deletes a snapshot of the blob, addressed by its snapshot ID, leaving the
+// blob and its other snapshots in place. A snapshot that no longer exists is not an error.
+func (c *AzureBlobLeaseClient) DeleteBlobSnapshot(ctx context.Context, config BlobLeaseConfig, snapshot string) error {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
+	if err != nil {
+		return fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	snapshotClient, err := blobClient.ServiceClient().NewContainerClient(config.ContainerName).NewBlobClient(config.BlobName).WithSnapshot(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot client: %w", err)
+	}
+
+	if _, err := snapshotClient.Delete(ctx, nil); err != nil && !isNotFound(err) {
+		if bloberror.HasCode(err, bloberror.BlobImmutableDueToPolicy) {
+			return fmt.Errorf("%w: snapshot %s of blob %s has a legal hold or an unexpired immutability policy: %w",
+				ErrBlobImmutable, snapshot, config.BlobName, wrapError(err, "delete rejected"))
+		}
+		return wrapError(err, "failed to delete snapshot %s of blob %s", snapshot, config.BlobName)
+	}
+	return nil
+}
+
+// SnapshotItem describes a snapshot of a blob
+type SnapshotItem struct {
+	Snapshot     string
+	ETag         string
+	Size         int64
+	LastModified *time.Time
+}
+
+// ListBlobSnapshots lists the snapshots of a blob, newest first. A blob without snapshots, or
+// one that does not exist, returns none; a missing container returns ErrContainerNotFound. A
+// blob with more than maxResults snapshots returns ErrTooManySnapshots; zero allows any number.
+func (c *AzureBlobLeaseClient) ListBlobSnapshots(ctx context.Context, storageAccount, containerName, blobName string, maxResults int) ([]SnapshotItem, error) {
+	var items []SnapshotItem
+	var tooMany bool
+	_, err := c.readWithFallbacks(ctx, storageAccount, func(blobClient *azblob.Client) error {
+		// A read retried against another endpoint starts over
+		items, tooMany = nil, false
+
+		// The prefix also matches blobs whose name extends this one, which are skipped
+		containerClient := blobClient.ServiceClient().NewContainerClient(containerName)
+		pager := containerClient.NewListBlobsFlatPager(&container.ListBlobsFlatOptions{
+			Prefix:  &blobName,
+			Include: container.ListBlobsInclude{Snapshots: true},
+		})
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return err
+			}
+			for _, blobItem := range page.Segment.BlobItems {
+				if blobItem == nil || stringValue(blobItem.Name) != blobName || stringValue(blobItem.Snapshot) == "" {
+					continue
+				}
+				if maxResults > 0 && len(items) == maxResults {
+					tooMany = true
+					return nil
+				}
+				item := SnapshotItem{Snapshot: *blobItem.Snapshot}
+				if props := blobItem.Properties; props != nil {
+					item.ETag = etagValue(props.ETag)
+					item.Size = int64Value(props.ContentLength)
+					item.LastModified = props.LastModified
+				}
+				items = append(items, item)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.ContainerNotFound) {
+			return nil, fmt.Errorf("%w: container %s does not exist in storage account %s", ErrContainerNotFound, containerName, storageAccount)
+		}
+		return nil, wrapError(err, "failed to list snapshots of blob %s", blobName)
+	}
+	if tooMany {
+		return nil, fmt.Errorf("%w: blob %s has more than %d snapshots", ErrTooManySnapshots, blobName, maxResults)
+	}
+
+	// Snapshot IDs are fixed-width UTC timestamps, so they sort as strings
+	slices.SortFunc(items, func(a, b SnapshotItem) int {
+		return strings.Compare(b.Snapshot, a.Snapshot)
+	})
+	return items, nil
+}
+
+// isNotFound reports whether err means the requested blob or snapshot does not exist
+func isNotFound(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound
+}
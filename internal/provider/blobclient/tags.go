@@ -0,0 +1,88 @@
+package blobclient
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+)
+
+// Limits Azure imposes on blob index tags
+const (
+	MaxBlobTags        = 10
+	maxTagKeyLength    = 128
+	maxTagValueLength  = 256
+	tagAllowedCharsMsg = "letters, digits, spaces and + - . / : = _"
+)
+
+// tagPattern matches the characters allowed in blob index tag keys and values
+var tagPattern = regexp.MustCompile(`^[A-Za-z0-9 +\-./:=_]*$`)
+
+// ValidateTag reports whether key and value are a valid blob index tag
+func ValidateTag(key, value string) error {
+	if len(key) == 0 || len(key) > maxTagKeyLength {
+		return fmt.Errorf("tag key %q must be between 1 and %d characters", key, maxTagKeyLength)
+	}
+	if !tagPattern.MatchString(key) {
+		return fmt.Errorf("tag key %q may only contain %s", key, tagAllowedCharsMsg)
+	}
+	if len(value) > maxTagValueLength {
+		return fmt.Errorf("value of tag %q must be at most %d characters", key, maxTagValueLength)
+	}
+	if !tagPattern.MatchString(value) {
+		return fmt.Errorf("value of tag %q may only contain %s", key, tagAllowedCharsMsg)
+	}
+	return nil
+}
+
+// SetBlobTags replaces the index tags of an existing blob under its lease. An empty
+// config.Tags removes all tags.
+func (c *AzureBlobLeaseClient) SetBlobTags(ctx context.Context, config BlobLeaseConfig) error {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
+	if err != nil {
+		return fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
+	blobClientRef := containerClient.NewBlockBlobClient(config.BlobName)
+
+	tags := config.Tags
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	_, err = blobClientRef.SetTags(ctx, tags, &blob.SetTagsOptions{
+		AccessConditions: leaseConditions(config.LeaseID),
+	})
+	if err != nil {
+		return wrapError(err, "failed to set tags on blob %s", config.BlobName)
+	}
+
+	return nil
+}
+
+// GetBlobTags returns the index tags of a blob. Reading tags needs the
+// Microsoft.Storage/storageAccounts/blobServices/containers/blobs/tags/read permission.
+func (c *AzureBlobLeaseClient) GetBlobTags(ctx context.Context, storageAccount, containerName, blobName string) (map[string]string, error) {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, storageAccount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(containerName)
+	resp, err := containerClient.NewBlockBlobClient(blobName).GetTags(ctx, nil)
+	if err != nil {
+		return nil, wrapError(err, "failed to get tags of blob %s", blobName)
+	}
+
+	tags := make(map[string]string, len(resp.BlobTagSet))
+	for _, tag := range resp.BlobTagSet {
+		if tag == nil || tag.Key == nil {
+			continue
+		}
+		tags[*tag.Key] = stringValue(tag.Value)
+	}
+	return tags, nil
+}
@@ -0,0 +1,71 @@
+package blobclient
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// ErrDuplicateTarget is returned when a second resource claims a blob during one operation
+var ErrDuplicateTarget = errors.New("blob already targeted by another resource")
+
+// DuplicateTargetError is returned by ClaimBlobTarget for a blob that was already claimed
+type DuplicateTargetError struct {
+	Path string
+}
+
+func (e *DuplicateTargetError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrDuplicateTarget, e.Path)
+}
+
+func (e *DuplicateTargetError) Unwrap() error {
+	return ErrDuplicateTarget
+}
+
+// targetRegistry records the blobs claimed by resources. Terraform starts the provider anew for
+// every plan and apply, so the registry covers exactly one operation.
+type targetRegistry struct {
+	// session identifies the registry, so claims recorded in private state are only recognized
+	// within the same operation
+	session string
+
+	mu      sync.Mutex
+	claimed map[string]bool
+}
+
+func newTargetRegistry() *targetRegistry {
+	return &targetRegistry{session: uuid.New().String(), claimed: map[string]bool{}}
+}
+
+// BlobTargetPath returns the normalized path of a blob. Storage account and container names are
+// case-insensitive, blob names are not.
+func BlobTargetPath(storageAccount, containerName, blobName string) string {
+	return fmt.Sprintf("%s/%s/%s", strings.ToLower(storageAccount), strings.ToLower(containerName), blobName)
+}
+
+// ClaimBlobTarget records that a resource manages the blob at path, as returned by
+// BlobTargetPath. It returns a *DuplicateTargetError when the blob was already claimed during
+// this operation. It is safe for concurrent use.
+func (c *AzureBlobLeaseClient) ClaimBlobTarget(path string) error {
+	c.targets.mu.Lock()
+	defer c.targets.mu.Unlock()
+
+	if c.targets.claimed[path] {
+		return &DuplicateTargetError{Path: path}
+	}
+	c.targets.claimed[path] = true
+	return nil
+}
+
+// BlobTargetSession returns a value that is unique to the current operation
+func (c *AzureBlobLeaseClient) BlobTargetSession() string {
+	return c.targets.session
+}
+
+// AllowDuplicateBlobTargets reports whether blobs claimed twice are only warned about
+func (c *AzureBlobLeaseClient) AllowDuplicateBlobTargets() bool {
+	return c.options.AllowDuplicateBlobTargets
+}
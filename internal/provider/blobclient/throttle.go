@@ -0,0 +1,91 @@
+package blobclient
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// maxRetryAfter caps how long a throttled call holds its slot waiting out Retry-After
+const maxRetryAfter = time.Minute
+
+// accountLimiter bounds the number of in-flight requests per storage account endpoint
+type accountLimiter struct {
+	limit int
+
+	mu   sync.Mutex
+	sems map[string]chan struct{}
+}
+
+func newAccountLimiter(limit int) *accountLimiter {
+	return &accountLimiter{limit: limit, sems: map[string]chan struct{}{}}
+}
+
+func (l *accountLimiter) semaphore(host string) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sem, ok := l.sems[host]
+	if !ok {
+		sem = make(chan struct{}, l.limit)
+		l.sems[host] = sem
+	}
+	return sem
+}
+
+// concurrencyPolicy holds a per-account slot for the whole call, including SDK retries. When the
+// final response is 503 with Retry-After, the slot is kept until the server's back-off has elapsed
+// so queued calls don't immediately hit the same busy account.
+type concurrencyPolicy struct {
+	limiter *accountLimiter
+}
+
+func (p concurrencyPolicy) Do(req *policy.Request) (*http.Response, error) {
+	ctx := req.Raw().Context()
+	sem := p.limiter.semaphore(req.Raw().URL.Host)
+
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-sem }()
+
+	resp, err := req.Next()
+	if resp != nil && resp.StatusCode == http.StatusServiceUnavailable {
+		if wait := retryAfter(resp); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+			}
+		}
+	}
+
+	return resp, err
+}
+
+// retryAfter parses the Retry-After family of headers, returning zero when absent
+func retryAfter(resp *http.Response) time.Duration {
+	var wait time.Duration
+	if v := resp.Header.Get("x-ms-retry-after-ms"); v != "" {
+		if ms, err := strconv.Atoi(v); err == nil {
+			wait = time.Duration(ms) * time.Millisecond
+		}
+	} else if v := resp.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			wait = time.Duration(secs) * time.Second
+		} else if at, err := http.ParseTime(v); err == nil {
+			wait = time.Until(at)
+		}
+	}
+
+	if wait > maxRetryAfter {
+		wait = maxRetryAfter
+	}
+	return wait
+}
@@ -0,0 +1,178 @@
+package blobclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+func TestConcurrencyLimitPerAccount(t *testing.T) {
+	const limit = 3
+
+	transport := &fakeTransport{handler: func(req *http.Request) (*http.Response, error) {
+		time.Sleep(20 * time.Millisecond)
+		return respond(req, http.StatusOK, "", "x-ms-lease-state", "available"), nil
+	}}
+	client := newTestClient(transport, ClientOptions{MaxConcurrentOperations: limit})
+
+	var wg sync.WaitGroup
+	for range 4 * limit {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetBlobLeaseState(context.Background(), "acct", "locks", "state.lock"); err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := transport.peak(); got > limit {
+		t.Errorf("expected at most %d requests in flight, got %d", limit, got)
+	}
+	if got := transport.peak(); got < 2 {
+		t.Errorf("expected requests to run concurrently up to the limit, got a peak of %d", got)
+	}
+}
+
+func TestConcurrencyLimitSeparatesAccounts(t *testing.T) {
+	release := make(chan struct{})
+	transport := &fakeTransport{handler: func(req *http.Request) (*http.Response, error) {
+		<-release
+		return respond(req, http.StatusOK, ""), nil
+	}}
+	pl := throttledPipeline(transport, newAccountLimiter(1))
+
+	var wg sync.WaitGroup
+	for _, host := range []string{"one.blob.core.windows.net", "two.blob.core.windows.net"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := sendTo(pl, host); err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		}()
+	}
+
+	// Both accounts get their own slot, so both requests reach the transport
+	transport.waitFor(t, 2)
+	close(release)
+	wg.Wait()
+
+	if got := transport.peak(); got != 2 {
+		t.Errorf("expected one request in flight per account, got a peak of %d", got)
+	}
+}
+
+func TestConcurrencySlotHeldThroughRetryAfter(t *testing.T) {
+	const retryAfterMs = 150
+
+	var mu sync.Mutex
+	var throttledAt, secondAt time.Time
+	transport := &fakeTransport{handler: func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if throttledAt.IsZero() {
+			throttledAt = time.Now()
+			return respond(req, http.StatusServiceUnavailable, "", "x-ms-retry-after-ms", "150"), nil
+		}
+		secondAt = time.Now()
+		return respond(req, http.StatusOK, ""), nil
+	}}
+	pl := throttledPipeline(transport, newAccountLimiter(1))
+
+	first := make(chan struct{})
+	go func() {
+		defer close(first)
+		resp, err := sendTo(pl, "acct.blob.core.windows.net")
+		if err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("expected the throttled response, got %v, %v", resp, err)
+		}
+	}()
+	transport.waitFor(t, 1)
+
+	if _, err := sendTo(pl, "acct.blob.core.windows.net"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	<-first
+
+	if waited := secondAt.Sub(throttledAt); waited < retryAfterMs*time.Millisecond {
+		t.Errorf("expected the queued call to wait out x-ms-retry-after-ms, it was sent after %s", waited)
+	}
+}
+
+func TestConcurrencySlotReleasedOnCancel(t *testing.T) {
+	transport := &fakeTransport{handler: func(req *http.Request) (*http.Response, error) {
+		return respond(req, http.StatusServiceUnavailable, "", "Retry-After", "60"), nil
+	}}
+	limiter := newAccountLimiter(1)
+	pl := throttledPipeline(transport, limiter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := runtime.NewRequest(ctx, http.MethodGet, "https://acct.blob.core.windows.net/locks/state.lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := pl.Do(req); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the Retry-After wait to end with the context, took %s", elapsed)
+	}
+	if got := len(limiter.semaphore("acct.blob.core.windows.net")); got != 0 {
+		t.Errorf("expected the slot to be released, %d still held", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := map[string]struct {
+		header []string
+		want   time.Duration
+	}{
+		"absent":           {want: 0},
+		"milliseconds":     {header: []string{"x-ms-retry-after-ms", "250"}, want: 250 * time.Millisecond},
+		"seconds":          {header: []string{"Retry-After", "3"}, want: 3 * time.Second},
+		"milliseconds win": {header: []string{"x-ms-retry-after-ms", "100", "Retry-After", "3"}, want: 100 * time.Millisecond},
+		"capped":           {header: []string{"Retry-After", "3600"}, want: maxRetryAfter},
+		"invalid":          {header: []string{"Retry-After", "soon"}, want: 0},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := retryAfter(respond(nil, http.StatusServiceUnavailable, "", tt.header...)); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	at := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	if got := retryAfter(respond(nil, http.StatusServiceUnavailable, "", "Retry-After", at)); got <= 0 || got > 10*time.Second {
+		t.Errorf("expected an HTTP date to give a wait of up to 10s, got %s", got)
+	}
+}
+
+// throttledPipeline sends requests through concurrencyPolicy to transport without SDK retries
+func throttledPipeline(transport policy.Transporter, limiter *accountLimiter) runtime.Pipeline {
+	return runtime.NewPipeline("blobclient-test", "v0.0.0", runtime.PipelineOptions{
+		PerCall: []policy.Policy{concurrencyPolicy{limiter: limiter}},
+	}, &policy.ClientOptions{
+		Transport: transport,
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	})
+}
+
+// sendTo sends a GET for a blob on host through pl
+func sendTo(pl runtime.Pipeline, host string) (*http.Response, error) {
+	req, err := runtime.NewRequest(context.Background(), http.MethodGet, "https://"+host+"/locks/state.lock")
+	if err != nil {
+		return nil, err
+	}
+	return pl.Do(req)
+}
@@ -0,0 +1,129 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+)
+
+// Access tiers accepted for block blobs
+const (
+	AccessTierHot     = string(blob.AccessTierHot)
+	AccessTierCool    = string(blob.AccessTierCool)
+	AccessTierCold    = string(blob.AccessTierCold)
+	AccessTierArchive = string(blob.AccessTierArchive)
+)
+
+// rehydrationPollInterval is how often the archive status is checked while a blob is rehydrated
+const rehydrationPollInterval = 30 * time.Second
+
+// defaultRehydrationWait bounds waiting for rehydration when the context has no deadline
+const defaultRehydrationWait = 30 * time.Minute
+
+// ErrRehydrationPending indicates a blob moving out of the Archive tier has not finished rehydrating
+var ErrRehydrationPending = errors.New("rehydration in progress")
+
+// ErrTierUnsupported indicates a blob that cannot be moved to an access tier, such as a page or
+// append blob, or a blob in a premium account
+var ErrTierUnsupported = errors.New("access tier not supported for blob")
+
+// accessTier converts an access tier name into the SDK option, nil meaning the account default
+func accessTier(tier string) *blob.AccessTier {
+	if tier == "" {
+		return nil
+	}
+	value := blob.AccessTier(tier)
+	return &value
+}
+
+// SetBlobTier moves an existing blob to config.AccessTier under its lease. Moving out of Archive
+// is asynchronous: the call waits for rehydration until ctx is done (or defaultRehydrationWait
+// if ctx has no deadline) and then returns ErrRehydrationPending.
+func (c *AzureBlobLeaseClient) SetBlobTier(ctx context.Context, config BlobLeaseConfig) error {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
+	if err != nil {
+		return fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
+	blobClientRef := containerClient.NewBlockBlobClient(config.BlobName)
+
+	props, err := blobClientRef.GetProperties(ctx, nil)
+	if err != nil {
+		return wrapError(err, "failed to get blob properties")
+	}
+
+	// A rehydration to the requested tier that is already running only needs waiting for
+	pending := stringValue(props.ArchiveStatus)
+	if !strings.EqualFold(pending, "rehydrate-pending-to-"+config.AccessTier) {
+		if strings.EqualFold(stringValue(props.AccessTier), config.AccessTier) && pending == "" {
+			return nil
+		}
+
+		_, err = blobClientRef.SetTier(ctx, blob.AccessTier(config.AccessTier), &blob.SetTierOptions{
+			AccessConditions: leaseConditions(config.LeaseID),
+		})
+		if err != nil {
+			return wrapError(err, "failed to set access tier of blob %s to %s", config.BlobName, config.AccessTier)
+		}
+
+		if !strings.EqualFold(stringValue(props.AccessTier), AccessTierArchive) || strings.EqualFold(config.AccessTier, AccessTierArchive) {
+			return nil
+		}
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultRehydrationWait)
+		defer cancel()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: blob %s is still being rehydrated from Archive to %s, which can take hours; run apply again once it has completed",
+				ErrRehydrationPending, config.BlobName, config.AccessTier)
+		case <-time.After(rehydrationPollInterval):
+		}
+
+		props, err := blobClientRef.GetProperties(ctx, nil)
+		if err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			return wrapError(err, "failed to get blob properties")
+		}
+		if props.ArchiveStatus == nil {
+			return nil
+		}
+	}
+}
+
+// ArchiveBlob moves a blob to the Archive tier. It does not use a lease, so release the lease
+// first. A blob that cannot be archived returns ErrTierUnsupported.
+func (c *AzureBlobLeaseClient) ArchiveBlob(ctx context.Context, config BlobLeaseConfig) error {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, config.StorageAccount)
+	if err != nil {
+		return fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(config.ContainerName)
+	blobClientRef := containerClient.NewBlockBlobClient(config.BlobName)
+
+	_, err = blobClientRef.SetTier(ctx, blob.AccessTierArchive, nil)
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.InvalidBlobTier, bloberror.InvalidBlobType, bloberror.BlobTierInadequateForContentLength) {
+			return fmt.Errorf("%w: blob %s in storage account %s cannot be moved to the Archive tier, which is only available for block blobs in standard accounts: %w",
+				ErrTierUnsupported, config.BlobName, config.StorageAccount, wrapError(err, "set access tier rejected"))
+		}
+		return wrapError(err, "failed to archive blob %s", config.BlobName)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package blobclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// fakeCredential hands out a static bearer token
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "test-token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// fakeTransport answers every request with handler instead of contacting Azure, recording the
+// requests it was sent and the most calls it saw in flight at once
+type fakeTransport struct {
+	handler func(*http.Request) (*http.Response, error)
+
+	mu          sync.Mutex
+	requests    []*http.Request
+	inFlight    int
+	maxInFlight int
+}
+
+func (t *fakeTransport) Do(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.requests = append(t.requests, req)
+	t.inFlight++
+	if t.inFlight > t.maxInFlight {
+		t.maxInFlight = t.inFlight
+	}
+	t.mu.Unlock()
+
+	defer func() {
+		t.mu.Lock()
+		t.inFlight--
+		t.mu.Unlock()
+	}()
+
+	return t.handler(req)
+}
+
+// count returns the number of recorded requests matching match
+func (t *fakeTransport) count(match func(*http.Request) bool) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := 0
+	for _, req := range t.requests {
+		if match(req) {
+			n++
+		}
+	}
+	return n
+}
+
+// waitFor waits until the transport has been sent n requests
+func (t *fakeTransport) waitFor(tb testing.TB, n int) {
+	tb.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for t.count(func(*http.Request) bool { return true }) < n {
+		if time.Now().After(deadline) {
+			tb.Fatalf("timed out waiting for %d requests", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// peak returns the most requests that were in flight at once
+func (t *fakeTransport) peak() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.maxInFlight
+}
+
+// newTestClient returns a client with a fake credential that sends its requests to transport
+func newTestClient(transport policy.Transporter, options ClientOptions) *AzureBlobLeaseClient {
+	options.Transport = transport
+	client := &AzureBlobLeaseClient{
+		credential: fakeCredential{},
+		options:    options,
+		targets:    newTargetRegistry(),
+	}
+	if !options.DisableAuthCircuitBreaker {
+		client.breaker = newAuthBreaker("client ID test")
+	}
+	if options.MaxConcurrentOperations > 0 {
+		client.limiter = newAccountLimiter(options.MaxConcurrentOperations)
+	}
+	return client
+}
+
+// respond builds a response to req with the given status, headers (as name, value pairs) and body
+func respond(req *http.Request, status int, body string, header ...string) *http.Response {
+	resp := &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		resp.Header.Set(header[i], header[i+1])
+	}
+	resp.Header.Set("x-ms-request-id", "test-request-id")
+	return resp
+}
+
+// respondError builds a storage service error response carrying code in x-ms-error-code
+func respondError(req *http.Request, status int, code string) *http.Response {
+	return respond(req, status, "", "x-ms-error-code", code)
+}
+
+// header returns a request header by name, also when the SDK set it under its lower-case name,
+// which Header.Get does not look up
+func header(req *http.Request, name string) string {
+	if value := req.Header.Get(name); value != "" {
+		return value
+	}
+	if values := req.Header[strings.ToLower(name)]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
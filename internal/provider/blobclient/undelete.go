@@ -0,0 +1,53 @@
+package blobclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+)
+
+// RestoreDeletedBlob undeletes a soft-deleted blob named blobName and reports whether a live blob
+// was restored. Finding deleted blobs needs list permission on the container and blob soft delete
+// on the account, so a failed lookup is treated as no deleted blob and returns false.
+func (c *AzureBlobLeaseClient) RestoreDeletedBlob(ctx context.Context, storageAccount, containerName, blobName string) (bool, error) {
+	// Create blob client
+	blobClient, err := c.CreateBlobClient(ctx, storageAccount)
+	if err != nil {
+		return false, fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(containerName)
+	if !c.hasDeletedBlob(ctx, containerClient, blobName) {
+		return false, nil
+	}
+
+	if _, err := containerClient.NewBlobClient(blobName).Undelete(ctx, nil); err != nil {
+		return false, wrapError(err, "failed to undelete blob %s", blobName)
+	}
+
+	// With blob versioning, undelete restores no current version, and the blob has to be
+	// created anew
+	return c.BlobExists(ctx, storageAccount, containerName, blobName)
+}
+
+// hasDeletedBlob reports whether the container lists a soft-deleted blob named blobName
+func (c *AzureBlobLeaseClient) hasDeletedBlob(ctx context.Context, containerClient *container.Client, blobName string) bool {
+	pager := containerClient.NewListBlobsFlatPager(&container.ListBlobsFlatOptions{
+		Prefix:  &blobName,
+		Include: container.ListBlobsInclude{Deleted: true},
+	})
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return false
+		}
+		for _, item := range page.Segment.BlobItems {
+			if item.Name != nil && *item.Name == blobName && item.Deleted != nil && *item.Deleted {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,270 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/lease"
+)
+
+// leaseBreakPollInterval is how often the lease state is checked while a broken lease ends. It is
+// a variable so tests can poll faster.
+var leaseBreakPollInterval = time.Second
+
+// Backoff between attempts while waiting for another holder to give up a lease
+const (
+	initialLeaseWaitBackoff = 2 * time.Second
+	maxLeaseWaitBackoff     = 30 * time.Second
+)
+
+// ErrLeaseWaitTimeout indicates the blob was still leased by another holder when
+// config.AcquireTimeout expired
+var ErrLeaseWaitTimeout = errors.New("timed out waiting for lease")
+
+// isLeaseHeld reports whether err means the blob is currently leased by someone else
+func isLeaseHeld(err error) bool {
+	return bloberror.HasCode(err, bloberror.LeaseAlreadyPresent, bloberror.LeaseIDMissing)
+}
+
+// isLeaseBreaking reports whether err means the lease on the blob is being broken
+func isLeaseBreaking(err error) bool {
+	return bloberror.HasCode(err, bloberror.LeaseIsBreakingAndCannotBeAcquired)
+}
+
+// withLeaseWait runs op and, while it fails because the blob is leased by someone else, retries
+// it with backoff until config.AcquireTimeout has passed. A zero timeout fails fast. A lease that
+// is being broken is waited for until the break has finished, and a lease older than
+// config.StealIfOlderThan is broken right away. If the blob is still leased
+// after waiting and config.BreakExistingLease is set, the lease is broken and op is run once
+// more; otherwise the error names the lease owner recorded on the blob, if any.
+func (c *AzureBlobLeaseClient) withLeaseWait(ctx context.Context, config BlobLeaseConfig, op func(context.Context) (*BlobLeaseResult, error)) (*BlobLeaseResult, error) {
+	result, err := op(ctx)
+	if isLeaseHeld(err) || isLeaseBreaking(err) {
+		result, err = c.waitForBreakingLease(ctx, config, op, err)
+	}
+	if isLeaseHeld(err) && config.StealIfOlderThan > 0 {
+		result, err = c.stealStaleLease(ctx, config, op, err)
+	}
+	if isLeaseHeld(err) {
+		result, err = c.waitForLease(ctx, config, op, err)
+	}
+	if !isLeaseHeld(err) {
+		return result, err
+	}
+	if !config.BreakExistingLease {
+		if errors.Is(err, ErrLeaseWaitTimeout) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("blob %s is leased by another holder (%s): %w", config.BlobName, c.leaseHolder(ctx, config), err)
+	}
+
+	if err := c.breakLease(ctx, config); err != nil {
+		return nil, err
+	}
+	result, err = op(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.BrokeLease = true
+	return result, nil
+}
+
+// stealStaleLease breaks the lease of someone else that blocked op with heldErr when it is older
+// than config.StealIfOlderThan, and runs op again. A lease that is not stale, or that is held with
+// config.LeaseID or config.OwnLeaseID, is left alone and heldErr is returned.
+func (c *AzureBlobLeaseClient) stealStaleLease(ctx context.Context, config BlobLeaseConfig, op func(context.Context) (*BlobLeaseResult, error), heldErr error) (*BlobLeaseResult, error) {
+	state, err := c.GetBlobLeaseState(ctx, config.StorageAccount, config.ContainerName, config.BlobName)
+	if err != nil {
+		return nil, err
+	}
+	acquiredAt := state.LastModified
+	if value := state.Metadata[findKey(state.Metadata, AcquiredAtMetadataKey)]; value != "" {
+		if at, err := time.Parse(time.RFC3339, value); err == nil {
+			acquiredAt = &at
+		}
+	}
+	if acquiredAt == nil || time.Since(*acquiredAt) < config.StealIfOlderThan {
+		return nil, heldErr
+	}
+
+	for _, own := range []string{config.LeaseID, config.OwnLeaseID} {
+		if own == "" {
+			continue
+		}
+		probe := config
+		probe.LeaseID = own
+		held, err := c.ProbeBlobLease(ctx, probe)
+		if err != nil {
+			return nil, err
+		}
+		if held {
+			return nil, heldErr
+		}
+	}
+
+	holder := c.leaseHolder(ctx, config)
+	if err := c.breakLease(ctx, config); err != nil {
+		return nil, err
+	}
+	result, err := op(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.BrokeLease = true
+	result.StolenFrom = fmt.Sprintf("acquired %s; %s", acquiredAt.UTC().Format(time.RFC3339), holder)
+	return result, nil
+}
+
+// waitForBreakingLease runs op again once a lease that is being broken, which made op fail with
+// err, has been broken. The wait is bounded by config.AcquireTimeout when it is set, and by ctx
+// otherwise. When the lease is not being broken, err is returned as is.
+func (c *AzureBlobLeaseClient) waitForBreakingLease(ctx context.Context, config BlobLeaseConfig, op func(context.Context) (*BlobLeaseResult, error), err error) (*BlobLeaseResult, error) {
+	state, stateErr := c.GetBlobLeaseState(ctx, config.StorageAccount, config.ContainerName, config.BlobName)
+	if stateErr != nil {
+		return nil, err
+	}
+	if state.LeaseState != string(lease.StateTypeBreaking) && !isLeaseBreaking(err) {
+		return nil, err
+	}
+
+	waitCtx := ctx
+	if config.AcquireTimeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, config.AcquireTimeout)
+		defer cancel()
+	}
+	if waitErr := c.waitForBreak(waitCtx, config, leaseBreakPollInterval); waitErr != nil {
+		if ctx.Err() == nil && waitCtx.Err() != nil {
+			return nil, fmt.Errorf("%w: the lease on blob %s was still breaking after waiting %s: %w",
+				ErrLeaseWaitTimeout, config.BlobName, config.AcquireTimeout, err)
+		}
+		return nil, waitErr
+	}
+	return op(ctx)
+}
+
+// breakLease breaks the lease on the blob immediately and waits until it has ended
+func (c *AzureBlobLeaseClient) breakLease(ctx context.Context, config BlobLeaseConfig) error {
+	return c.BreakBlobLeaseAndWait(ctx, config, 0)
+}
+
+// BreakBlobLeaseAndWait breaks the lease on a blob, whoever holds it, like BreakBlobLease, and
+// waits until the lease has ended after at most breakPeriod seconds
+func (c *AzureBlobLeaseClient) BreakBlobLeaseAndWait(ctx context.Context, config BlobLeaseConfig, breakPeriod int32) error {
+	remaining, err := c.BreakBlobLease(ctx, config, breakPeriod)
+	if err != nil {
+		return err
+	}
+	return c.waitForBreak(ctx, config, remaining)
+}
+
+// waitForBreak polls the lease state of the blob, first after wait, until it is no longer
+// breaking
+func (c *AzureBlobLeaseClient) waitForBreak(ctx context.Context, config BlobLeaseConfig, wait time.Duration) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stopped waiting for the broken lease on blob %s to end: %w", config.BlobName, ctx.Err())
+		case <-time.After(wait):
+		}
+
+		state, err := c.GetBlobLeaseState(ctx, config.StorageAccount, config.ContainerName, config.BlobName)
+		if err != nil {
+			return err
+		}
+		if state.LeaseState != string(lease.StateTypeBreaking) {
+			return nil
+		}
+		wait = leaseBreakPollInterval
+	}
+}
+
+// waitForLease retries op, whose first attempt failed with err because the blob is leased by
+// someone else, for up to config.AcquireTimeout while that is still the case
+func (c *AzureBlobLeaseClient) waitForLease(ctx context.Context, config BlobLeaseConfig, op func(context.Context) (*BlobLeaseResult, error), err error) (*BlobLeaseResult, error) {
+	if config.AcquireTimeout <= 0 {
+		return nil, err
+	}
+
+	deadline := time.NewTimer(config.AcquireTimeout)
+	defer deadline.Stop()
+
+	backoff := initialLeaseWaitBackoff
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("stopped waiting for the lease on blob %s: %w", config.BlobName, ctx.Err())
+		case <-deadline.C:
+			return nil, fmt.Errorf("%w: blob %s was still leased by another holder after waiting %s (last observed %s): %w",
+				ErrLeaseWaitTimeout, config.BlobName, config.AcquireTimeout, c.leaseHolder(ctx, config), err)
+		case <-time.After(jitter(backoff, 0.2)):
+		}
+
+		backoff = min(backoff*2, maxLeaseWaitBackoff)
+		var result *BlobLeaseResult
+		result, err = op(ctx)
+		if !isLeaseHeld(err) {
+			return result, err
+		}
+	}
+}
+
+// leaseHolder describes the lease state of a blob leased by someone else for an error message,
+// together with the owner written to its metadata when there is one
+func (c *AzureBlobLeaseClient) leaseHolder(ctx context.Context, config BlobLeaseConfig) string {
+	state, err := c.GetBlobLeaseState(ctx, config.StorageAccount, config.ContainerName, config.BlobName)
+	if err != nil {
+		return "lease state: unknown"
+	}
+	return describeLeaseHolder(state)
+}
+
+// describeLeaseHolder describes the lease state of a property read for an error message, together
+// with the owner written to the blob metadata when there is one
+func describeLeaseHolder(state *BlobLeaseResult) string {
+	description := "lease state: " + state.LeaseState
+	if owner := state.Metadata[findKey(state.Metadata, LeaseOwnerMetadataKey)]; owner != "" {
+		description += fmt.Sprintf("; metadata %s=%s", LeaseOwnerMetadataKey, owner)
+	}
+	return description
+}
+
+// isLeaseAvailable reports whether a blob in leaseState can be leased right away
+func isLeaseAvailable(leaseState string) bool {
+	switch lease.StateType(leaseState) {
+	case lease.StateTypeAvailable, lease.StateTypeExpired, lease.StateTypeBroken:
+		return true
+	}
+	return false
+}
+
+// WaitForLeaseAvailable polls the lease state of a blob every pollInterval until it can be
+// leased, that is until it is available, expired or broken, and returns the last property read.
+// It does not lease the blob. When the blob is still leased after timeout, it returns an error
+// wrapping ErrLeaseWaitTimeout that describes the holder; a cancelled ctx stops the wait at once.
+func (c *AzureBlobLeaseClient) WaitForLeaseAvailable(ctx context.Context, storageAccount, containerName, blobName string, timeout, pollInterval time.Duration) (*BlobLeaseResult, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		state, err := c.GetBlobLeaseState(ctx, storageAccount, containerName, blobName)
+		if err != nil {
+			return nil, err
+		}
+		if isLeaseAvailable(state.LeaseState) {
+			return state, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("stopped waiting for the lease on blob %s to become available: %w", blobName, ctx.Err())
+		case <-deadline.C:
+			return nil, fmt.Errorf("%w: blob %s was still leased after waiting %s (last observed %s)",
+				ErrLeaseWaitTimeout, blobName, timeout, describeLeaseHolder(state))
+		case <-time.After(pollInterval):
+		}
+	}
+}
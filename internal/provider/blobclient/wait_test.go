@@ -0,0 +1,189 @@
+package blobclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+)
+
+// shortenLeaseBreakPoll makes waits for a breaking lease poll every millisecond for the rest of
+// the test
+func shortenLeaseBreakPoll(t *testing.T) {
+	t.Helper()
+	interval := leaseBreakPollInterval
+	leaseBreakPollInterval = time.Millisecond
+	t.Cleanup(func() { leaseBreakPollInterval = interval })
+}
+
+// isAcquire reports whether req acquires a lease
+func isAcquire(req *http.Request) bool {
+	return req.URL.Query().Get("comp") == "lease" && header(req, "x-ms-lease-action") == "acquire"
+}
+
+// isHead reports whether req reads the blob properties
+func isHead(req *http.Request) bool {
+	return req.Method == http.MethodHead
+}
+
+func TestAcquireBlobLeaseWaitsForBreakingLease(t *testing.T) {
+	const leaseID = "3f2504e0-4f89-11d3-9a0c-0305e82c3301"
+	shortenLeaseBreakPoll(t)
+
+	// The lease reads as breaking for the first breakingReads property reads and broken after
+	// that; a negative count keeps it breaking. Acquiring fails while the lease is breaking.
+	tests := map[string]struct {
+		breakingReads  int
+		acquireTimeout time.Duration
+		wantErr        error
+		wantReads      int
+		wantAcquires   int
+	}{
+		"broken while waiting": {
+			breakingReads:  3,
+			acquireTimeout: 5 * time.Second,
+			wantReads:      4,
+			wantAcquires:   2,
+		},
+		"broken without a timeout": {
+			breakingReads: 2,
+			wantReads:     3,
+			wantAcquires:  2,
+		},
+		"still breaking at the timeout": {
+			breakingReads:  -1,
+			acquireTimeout: 50 * time.Millisecond,
+			wantErr:        ErrLeaseWaitTimeout,
+			wantAcquires:   1,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var transport *fakeTransport
+			transport = &fakeTransport{handler: func(req *http.Request) (*http.Response, error) {
+				breaking := tt.breakingReads < 0 || transport.count(isHead) <= tt.breakingReads
+				switch {
+				case isHead(req) && breaking:
+					return respond(req, http.StatusOK, "", "x-ms-lease-state", "breaking", "x-ms-lease-status", "locked"), nil
+				case isHead(req):
+					return respond(req, http.StatusOK, "", "x-ms-lease-state", "broken", "x-ms-lease-status", "unlocked"), nil
+				case isAcquire(req) && breaking:
+					return respondError(req, http.StatusConflict, string(bloberror.LeaseIsBreakingAndCannotBeAcquired)), nil
+				case isAcquire(req):
+					return respond(req, http.StatusCreated, "", "x-ms-lease-id", leaseID, "ETag", `"0x8D0"`), nil
+				}
+				t.Fatalf("unexpected request %s %s", req.Method, req.URL)
+				return nil, nil
+			}}
+			client := newTestClient(transport, ClientOptions{})
+
+			result, err := client.AcquireBlobLease(context.Background(), BlobLeaseConfig{
+				StorageAccount: "acct",
+				ContainerName:  "locks",
+				BlobName:       "env/app.lock",
+				LeaseID:        leaseID,
+				LeaseDuration:  -1,
+				AcquireTimeout: tt.acquireTimeout,
+			})
+			switch {
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Fatalf("expected %v, got: %v", tt.wantErr, err)
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("unexpected error: %s", err)
+			case tt.wantErr == nil && result.LeaseID != leaseID:
+				t.Errorf("expected lease ID %s, got %s", leaseID, result.LeaseID)
+			}
+
+			if got := transport.count(isAcquire); got != tt.wantAcquires {
+				t.Errorf("expected %d acquire attempts, got %d", tt.wantAcquires, got)
+			}
+			if tt.wantReads > 0 {
+				if got := transport.count(isHead); got != tt.wantReads {
+					t.Errorf("expected the lease state to be read %d times, got %d", tt.wantReads, got)
+				}
+			}
+		})
+	}
+}
+
+func TestWaitForLeaseAvailable(t *testing.T) {
+	// The blob reads as leased by the recorded owner for the first leasedReads property reads
+	// and as lease state available after that; a negative count keeps it leased
+	tests := map[string]struct {
+		leasedReads int
+		timeout     time.Duration
+		cancel      bool
+		wantState   string
+		wantErr     error
+		wantErrText string
+	}{
+		"becomes available": {
+			leasedReads: 2,
+			timeout:     5 * time.Second,
+			wantState:   "available",
+		},
+		"available at once": {
+			timeout:   5 * time.Second,
+			wantState: "available",
+		},
+		"still leased at the deadline": {
+			leasedReads: -1,
+			timeout:     50 * time.Millisecond,
+			wantErr:     ErrLeaseWaitTimeout,
+			wantErrText: "lease state: leased; metadata " + LeaseOwnerMetadataKey + "=ci-runner-7",
+		},
+		"cancelled": {
+			leasedReads: -1,
+			timeout:     5 * time.Second,
+			cancel:      true,
+			wantErr:     context.Canceled,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var transport *fakeTransport
+			transport = &fakeTransport{handler: func(req *http.Request) (*http.Response, error) {
+				if !isHead(req) {
+					t.Fatalf("unexpected request %s %s", req.Method, req.URL)
+				}
+				reads := transport.count(isHead)
+				if tt.cancel && reads == 2 {
+					cancel()
+				}
+				if tt.leasedReads < 0 || reads <= tt.leasedReads {
+					return respond(req, http.StatusOK, "", "x-ms-lease-state", "leased", "x-ms-lease-status", "locked",
+						"x-ms-meta-"+LeaseOwnerMetadataKey, "ci-runner-7"), nil
+				}
+				return respond(req, http.StatusOK, "", "x-ms-lease-state", "available", "x-ms-lease-status", "unlocked"), nil
+			}}
+			client := newTestClient(transport, ClientOptions{})
+
+			state, err := client.WaitForLeaseAvailable(ctx, "acct", "locks", "env/app.lock", tt.timeout, time.Millisecond)
+			switch {
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Fatalf("expected %v, got: %v", tt.wantErr, err)
+			case tt.wantErr != nil && !strings.Contains(err.Error(), tt.wantErrText):
+				t.Errorf("expected the error to contain %q, got: %s", tt.wantErrText, err)
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("unexpected error: %s", err)
+			case tt.wantErr == nil && state.LeaseState != tt.wantState:
+				t.Errorf("expected lease state %s, got %s", tt.wantState, state.LeaseState)
+			}
+
+			if tt.leasedReads >= 0 {
+				if got := transport.count(isHead); got != tt.leasedReads+1 {
+					t.Errorf("expected %d property reads, got %d", tt.leasedReads+1, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &BlobsDataSource{}
+
+// defaultMaxListResults is how many blobs a listing may return without max_results
+const defaultMaxListResults = 1000
+
+func NewBlobsDataSource() datasource.DataSource {
+	return &BlobsDataSource{}
+}
+
+// BlobsDataSource lists the blobs of a container
+type BlobsDataSource struct {
+	client *blobclient.AzureBlobLeaseClient
+}
+
+// BlobsDataSourceModel describes the data source data model.
+type BlobsDataSourceModel struct {
+	ID             types.String          `tfsdk:"id"`
+	StorageAccount types.String          `tfsdk:"storage_account_name"`
+	BlobEndpoint   types.String          `tfsdk:"blob_endpoint"`
+	ContainerName  types.String          `tfsdk:"storage_container_name"`
+	Prefix         types.String          `tfsdk:"prefix"`
+	LeaseState     types.String          `tfsdk:"lease_state"`
+	Metadata       types.Map             `tfsdk:"metadata"`
+	MaxResults     types.Int64           `tfsdk:"max_results"`
+	Blobs          []BlobsDataSourceItem `tfsdk:"blobs"`
+}
+
+// BlobsDataSourceItem describes a listed blob.
+type BlobsDataSourceItem struct {
+	Name         types.String `tfsdk:"name"`
+	ETag         types.String `tfsdk:"etag"`
+	Size         types.Int64  `tfsdk:"size"`
+	LeaseState   types.String `tfsdk:"lease_state"`
+	LeaseStatus  types.String `tfsdk:"lease_status"`
+	LastModified types.String `tfsdk:"last_modified"`
+}
+
+func (d *BlobsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_blobs"
+}
+
+func (d *BlobsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Lists the blobs of an Azure Storage container, optionally filtered by name prefix, lease state and metadata",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Data source identifier, `storage_account_name/storage_container_name`",
+			},
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name",
+				Required:            true,
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, e.g. a private endpoint or sovereign cloud URL, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`",
+				Optional:            true,
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"storage_container_name": schema.StringAttribute{
+				MarkdownDescription: "The container to list",
+				Required:            true,
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"prefix": schema.StringAttribute{
+				MarkdownDescription: "Only list blobs whose name starts with this prefix",
+				Optional:            true,
+			},
+			"lease_state": schema.StringAttribute{
+				MarkdownDescription: "Only return blobs in this lease state: `available`, `leased`, `expired`, `breaking` or `broken`",
+				Optional:            true,
+				Validators: []validator.String{
+					stringOneOfValidator{values: []string{"available", "leased", "expired", "breaking", "broken"}},
+				},
+			},
+			"metadata": schema.MapAttribute{
+				MarkdownDescription: "Only return blobs that have all of these metadata values. Keys are matched ignoring case",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"max_results": schema.Int64Attribute{
+				MarkdownDescription: "How many blobs may match; a listing that matches more fails instead of being stored in state. Defaults to 1000",
+				Optional:            true,
+				Validators: []validator.Int64{
+					positiveInt64Validator{},
+				},
+			},
+			"blobs": schema.ListNestedAttribute{
+				MarkdownDescription: "The matching blobs, in name order",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "The name of the blob",
+							Computed:            true,
+						},
+						"etag": schema.StringAttribute{
+							MarkdownDescription: "The ETag of the blob",
+							Computed:            true,
+						},
+						"size": schema.Int64Attribute{
+							MarkdownDescription: "The size of the blob content in bytes",
+							Computed:            true,
+						},
+						"lease_state": schema.StringAttribute{
+							MarkdownDescription: "The lease state of the blob",
+							Computed:            true,
+						},
+						"lease_status": schema.StringAttribute{
+							MarkdownDescription: "The lease status of the blob, `locked` or `unlocked`",
+							Computed:            true,
+						},
+						"last_modified": schema.StringAttribute{
+							MarkdownDescription: "The RFC3339 time of the last write to the blob",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *BlobsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *BlobsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data BlobsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+
+	endpoint := data.BlobEndpoint.ValueString()
+	if endpoint != "" {
+		if err := d.client.CheckBlobEndpoint(endpoint); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+			return
+		}
+	}
+	ctx = blobclient.WithBlobEndpoint(ctx, endpoint)
+
+	options := blobclient.ListBlobsOptions{
+		Prefix:     data.Prefix.ValueString(),
+		LeaseState: data.LeaseState.ValueString(),
+		MaxResults: defaultMaxListResults,
+	}
+	if !data.MaxResults.IsNull() {
+		options.MaxResults = int(data.MaxResults.ValueInt64())
+	}
+	if !data.Metadata.IsNull() {
+		resp.Diagnostics.Append(data.Metadata.ElementsAs(ctx, &options.Metadata, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	storageAccount, containerName := data.StorageAccount.ValueString(), data.ContainerName.ValueString()
+	items, err := d.client.ListBlobs(ctx, storageAccount, containerName, options)
+	switch {
+	case errors.Is(err, blobclient.ErrTooManyBlobs):
+		resp.Diagnostics.AddAttributeError(path.Root("max_results"), "Too Many Blobs", fmt.Sprintf("%s. Narrow the listing with prefix, lease_state or metadata, or raise max_results; every blob listed is stored in state.", err))
+		return
+	case errors.Is(err, blobclient.ErrContainerNotFound):
+		resp.Diagnostics.AddAttributeError(path.Root("storage_container_name"), "Container Not Found", err.Error())
+		return
+	case err != nil:
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to list blobs, got error: %s", err))
+		return
+	}
+
+	data.ID = types.StringValue(storageAccount + "/" + containerName)
+	data.Blobs = make([]BlobsDataSourceItem, 0, len(items))
+	for _, item := range items {
+		data.Blobs = append(data.Blobs, BlobsDataSourceItem{
+			Name:         types.StringValue(item.Name),
+			ETag:         stringOrNull(item.ETag),
+			Size:         types.Int64Value(item.Size),
+			LeaseState:   types.StringValue(item.LeaseState),
+			LeaseStatus:  types.StringValue(item.LeaseStatus),
+			LastModified: timestampValue(item.LastModified),
+		})
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
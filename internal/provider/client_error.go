@@ -0,0 +1,17 @@
+package provider
+
+import (
+	"errors"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+)
+
+// clientErrorSummary is the summary of the diagnostic for a failed client call. Calls the
+// authentication circuit breaker skipped get a summary of their own, so the single credential
+// problem behind them is not lost among generic client errors.
+func clientErrorSummary(err error) string {
+	if errors.Is(err, blobclient.ErrCircuitOpen) {
+		return "Storage Account Authentication Failing"
+	}
+	return "Client Error"
+}
@@ -0,0 +1,30 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+)
+
+func TestClientErrorSummary(t *testing.T) {
+	openErr := &blobclient.CircuitOpenError{StorageAccount: "acct", Failures: 5, Err: errors.New("storage service returned 403 Forbidden")}
+
+	tests := map[string]struct {
+		err  error
+		want string
+	}{
+		"circuit open":         {err: openErr, want: "Storage Account Authentication Failing"},
+		"wrapped circuit open": {err: fmt.Errorf("failed to get blob properties: %w", openErr), want: "Storage Account Authentication Failing"},
+		"other error":          {err: errors.New("connection reset by peer"), want: "Client Error"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := clientErrorSummary(tt.err); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
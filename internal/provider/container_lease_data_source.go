@@ -0,0 +1,195 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ContainerLeaseDataSource{}
+
+func NewContainerLeaseDataSource() datasource.DataSource {
+	return &ContainerLeaseDataSource{}
+}
+
+// ContainerLeaseDataSource reads the lease state of a container without leasing it
+type ContainerLeaseDataSource struct {
+	client *blobclient.AzureBlobLeaseClient
+}
+
+// ContainerLeaseDataSourceModel describes the data source data model.
+type ContainerLeaseDataSourceModel struct {
+	ID                types.String `tfsdk:"id"`
+	StorageAccount    types.String `tfsdk:"storage_account_name"`
+	BlobEndpoint      types.String `tfsdk:"blob_endpoint"`
+	ContainerName     types.String `tfsdk:"storage_container_name"`
+	AllowMissing      types.Bool   `tfsdk:"allow_missing"`
+	Exists            types.Bool   `tfsdk:"exists"`
+	LeaseState        types.String `tfsdk:"lease_state"`
+	LeaseStatus       types.String `tfsdk:"lease_status"`
+	LeaseDurationKind types.String `tfsdk:"lease_duration_kind"`
+	ETag              types.String `tfsdk:"etag"`
+	LastModified      types.String `tfsdk:"last_modified"`
+}
+
+func (d *ContainerLeaseDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_container_lease"
+}
+
+func (d *ContainerLeaseDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Reads the lease state of an Azure Storage container without leasing it",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Data source identifier, `storage_account_name/storage_container_name`",
+			},
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name",
+				Required:            true,
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, e.g. a private endpoint or sovereign cloud URL, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`",
+				Optional:            true,
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"storage_container_name": schema.StringAttribute{
+				MarkdownDescription: "The name of the container",
+				Required:            true,
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"allow_missing": schema.BoolAttribute{
+				MarkdownDescription: "Whether a container that does not exist sets `exists` to false instead of failing. Defaults to `false`",
+				Optional:            true,
+			},
+			"exists": schema.BoolAttribute{
+				MarkdownDescription: "Whether the container exists. Always true unless `allow_missing` is set",
+				Computed:            true,
+			},
+			"lease_state": schema.StringAttribute{
+				MarkdownDescription: "The lease state of the container: `available`, `leased`, `expired`, `breaking` or `broken`. Null when the container does not exist",
+				Computed:            true,
+			},
+			"lease_status": schema.StringAttribute{
+				MarkdownDescription: "The lease status of the container, `locked` or `unlocked`. Null when the container does not exist",
+				Computed:            true,
+			},
+			"lease_duration_kind": schema.StringAttribute{
+				MarkdownDescription: "Whether the lease on the container is `fixed` or `infinite`, empty when the container is not leased. Null when the container does not exist",
+				Computed:            true,
+			},
+			"etag": schema.StringAttribute{
+				MarkdownDescription: "The ETag of the container. Null when the container does not exist",
+				Computed:            true,
+			},
+			"last_modified": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time the container or its properties last changed. Null when the container does not exist",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *ContainerLeaseDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *ContainerLeaseDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ContainerLeaseDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+
+	endpoint := data.BlobEndpoint.ValueString()
+	if endpoint != "" {
+		if err := d.client.CheckBlobEndpoint(endpoint); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+			return
+		}
+	}
+	ctx = blobclient.WithBlobEndpoint(ctx, endpoint)
+
+	storageAccount, containerName := data.StorageAccount.ValueString(), data.ContainerName.ValueString()
+	data.ID = types.StringValue(storageAccount + "/" + containerName)
+
+	props, err := d.client.GetContainerProperties(ctx, storageAccount, containerName)
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read container properties, got error: %s", err))
+		return
+	}
+	data.Exists = types.BoolValue(props.Exists)
+	if !props.Exists {
+		if !data.AllowMissing.ValueBool() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("storage_container_name"),
+				"Container Not Found",
+				fmt.Sprintf("Container %s does not exist in storage account %s. Set allow_missing to read a missing container as exists = false.",
+					containerName, storageAccount),
+			)
+			return
+		}
+		data.LeaseState = types.StringNull()
+		data.LeaseStatus = types.StringNull()
+		data.LeaseDurationKind = types.StringNull()
+		data.ETag = types.StringNull()
+		data.LastModified = types.StringNull()
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	// The lease properties of a container match those of a blob, so the blob helpers apply
+	leaseResult := &blobclient.BlobLeaseResult{
+		LeaseState:    props.LeaseState,
+		LeaseStatus:   props.LeaseStatus,
+		LeaseDuration: props.LeaseDuration,
+	}
+	data.LeaseState = types.StringValue(props.LeaseState)
+	data.LeaseStatus = leaseStatusValue(leaseResult)
+	data.LeaseDurationKind = leaseDurationKindValue(leaseResult, types.Int32Null())
+	data.ETag = types.StringValue(props.ETag)
+	data.LastModified = timestampValue(props.LastModified)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
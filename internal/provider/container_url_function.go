@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ContainerURLFunction{}
+
+func NewContainerURLFunction() function.Function {
+	return &ContainerURLFunction{}
+}
+
+// ContainerURLFunction builds the URL of a container from its storage account and name
+type ContainerURLFunction struct{}
+
+func (f *ContainerURLFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "container_url"
+}
+
+func (f *ContainerURLFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Returns the https URL of an Azure Storage container",
+		MarkdownDescription: "Returns the https URL of a container, without a trailing slash, such that `blob_url` is this URL, a slash and the encoded blob name. An optional third argument sets the endpoint suffix of a sovereign cloud, e.g. `core.usgovcloudapi.net`; it defaults to `core.windows.net`",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "storage_account",
+				MarkdownDescription: "The Azure Storage Account name",
+			},
+			function.StringParameter{
+				Name:                "container",
+				MarkdownDescription: "The name of the container",
+			},
+		},
+		VariadicParameter: endpointSuffixParameter,
+		Return:            function.StringReturn{},
+	}
+}
+
+func (f *ContainerURLFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var storageAccount, containerName string
+	var suffixes []string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &storageAccount, &containerName, &suffixes))
+	if resp.Error != nil {
+		return
+	}
+
+	if err := validators.CheckStorageAccountName(storageAccount); err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("storage_account %s", err))
+		return
+	}
+	if err := validators.CheckContainerName(containerName); err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("container %s", err))
+		return
+	}
+	suffix, funcErr := endpointSuffixArgument(suffixes, 2)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, blobclient.ContainerURL(storageAccount, containerName, suffix)))
+}
@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContainerURLFunction(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	for name, tc := range map[string]struct {
+		containerName string
+		suffix        []any
+		want          string
+		wantErr       string
+	}{
+		"default cloud": {
+			containerName: "locks",
+			want:          "https://acct.blob.core.windows.net/locks",
+		},
+		"US government cloud": {
+			containerName: "locks",
+			suffix:        []any{"core.usgovcloudapi.net"},
+			want:          "https://acct.blob.core.usgovcloudapi.net/locks",
+		},
+		"China cloud": {
+			containerName: "locks",
+			suffix:        []any{"core.chinacloudapi.cn"},
+			want:          "https://acct.blob.core.chinacloudapi.cn/locks",
+		},
+		"invalid container name": {
+			containerName: "Locks",
+			wantErr:       "container ",
+		},
+		"two endpoint suffixes": {
+			containerName: "locks",
+			suffix:        []any{"core.windows.net", "core.chinacloudapi.cn"},
+			wantErr:       "at most one endpoint_suffix",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, funcErr := p.callFunction("container_url", append([]any{testAccount, tc.containerName}, tc.suffix...)...)
+			if tc.wantErr != "" {
+				if funcErr == nil || !strings.Contains(funcErr.Text, tc.wantErr) {
+					t.Fatalf("expected an error containing %q, got %v", tc.wantErr, funcErr)
+				}
+				return
+			}
+			if funcErr != nil {
+				t.Fatalf("unexpected error: %s", funcErr.Text)
+			}
+			var got string
+			if err := result.As(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+
+			// blob_url composes with it
+			result, funcErr = p.callFunction("blob_url", append([]any{testAccount, tc.containerName, "env/my app.lock"}, tc.suffix...)...)
+			if funcErr != nil {
+				t.Fatalf("unexpected error: %s", funcErr.Text)
+			}
+			var blobURL string
+			if err := result.As(&blobURL); err != nil {
+				t.Fatal(err)
+			}
+			if want := got + "/env%2Fmy%20app.lock"; blobURL != want {
+				t.Errorf("expected blob_url %s, got %s", want, blobURL)
+			}
+		})
+	}
+}
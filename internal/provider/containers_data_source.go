@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ContainersDataSource{}
+
+func NewContainersDataSource() datasource.DataSource {
+	return &ContainersDataSource{}
+}
+
+// ContainersDataSource lists the containers of a storage account
+type ContainersDataSource struct {
+	client *blobclient.AzureBlobLeaseClient
+}
+
+// ContainersDataSourceModel describes the data source data model.
+type ContainersDataSourceModel struct {
+	ID             types.String               `tfsdk:"id"`
+	StorageAccount types.String               `tfsdk:"storage_account_name"`
+	BlobEndpoint   types.String               `tfsdk:"blob_endpoint"`
+	Prefix         types.String               `tfsdk:"prefix"`
+	MaxResults     types.Int64                `tfsdk:"max_results"`
+	Containers     []ContainersDataSourceItem `tfsdk:"containers"`
+}
+
+// ContainersDataSourceItem describes a listed container.
+type ContainersDataSourceItem struct {
+	Name             types.String `tfsdk:"name"`
+	PublicAccessType types.String `tfsdk:"public_access_type"`
+	LeaseState       types.String `tfsdk:"lease_state"`
+	LastModified     types.String `tfsdk:"last_modified"`
+	Metadata         types.Map    `tfsdk:"metadata"`
+}
+
+func (d *ContainersDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_containers"
+}
+
+func (d *ContainersDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Lists the containers of an Azure Storage Account, optionally by name prefix",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Data source identifier, the storage account name",
+			},
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name",
+				Required:            true,
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, e.g. a private endpoint or sovereign cloud URL, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`",
+				Optional:            true,
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"prefix": schema.StringAttribute{
+				MarkdownDescription: "Only list containers whose name starts with this prefix",
+				Optional:            true,
+			},
+			"max_results": schema.Int64Attribute{
+				MarkdownDescription: "How many containers may match; a listing that matches more fails instead of being stored in state. Defaults to 1000",
+				Optional:            true,
+				Validators: []validator.Int64{
+					positiveInt64Validator{},
+				},
+			},
+			"containers": schema.ListNestedAttribute{
+				MarkdownDescription: "The matching containers, in name order",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "The name of the container",
+							Computed:            true,
+						},
+						"public_access_type": schema.StringAttribute{
+							MarkdownDescription: "The public access level of the container: `private`, `blob` or `container`",
+							Computed:            true,
+						},
+						"lease_state": schema.StringAttribute{
+							MarkdownDescription: "The lease state of the container",
+							Computed:            true,
+						},
+						"last_modified": schema.StringAttribute{
+							MarkdownDescription: "The RFC3339 time the container or its properties last changed",
+							Computed:            true,
+						},
+						"metadata": schema.MapAttribute{
+							MarkdownDescription: "The metadata of the container",
+							Computed:            true,
+							ElementType:         types.StringType,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *ContainersDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *ContainersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ContainersDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+
+	endpoint := data.BlobEndpoint.ValueString()
+	if endpoint != "" {
+		if err := d.client.CheckBlobEndpoint(endpoint); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+			return
+		}
+	}
+	ctx = blobclient.WithBlobEndpoint(ctx, endpoint)
+
+	maxResults := defaultMaxListResults
+	if !data.MaxResults.IsNull() {
+		maxResults = int(data.MaxResults.ValueInt64())
+	}
+
+	storageAccount := data.StorageAccount.ValueString()
+	items, err := d.client.ListContainers(ctx, storageAccount, data.Prefix.ValueString(), maxResults)
+	switch {
+	case errors.Is(err, blobclient.ErrTooManyContainers):
+		resp.Diagnostics.AddAttributeError(path.Root("max_results"), "Too Many Containers", fmt.Sprintf("%s. Narrow the listing with prefix or raise max_results; every container listed is stored in state.", err))
+		return
+	case errors.Is(err, blobclient.ErrAuthorizationFailed):
+		resp.Diagnostics.AddAttributeError(path.Root("storage_account_name"), "Container Listing Not Permitted", err.Error())
+		return
+	case err != nil:
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to list containers, got error: %s", err))
+		return
+	}
+
+	data.ID = types.StringValue(storageAccount)
+	data.Containers = make([]ContainersDataSourceItem, 0, len(items))
+	for _, item := range items {
+		metadata, diags := types.MapValueFrom(ctx, types.StringType, item.Metadata)
+		resp.Diagnostics.Append(diags...)
+		data.Containers = append(data.Containers, ContainersDataSourceItem{
+			Name:             types.StringValue(item.Name),
+			PublicAccessType: types.StringValue(containerAccessValue(item.PublicAccess)),
+			LeaseState:       types.StringValue(item.LeaseState),
+			LastModified:     timestampValue(item.LastModified),
+			Metadata:         metadata,
+		})
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
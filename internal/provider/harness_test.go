@@ -0,0 +1,636 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient/blobclienttest"
+)
+
+// The storage account and container the provider tests work in
+const (
+	testAccount   = "acct"
+	testContainer = "locks"
+)
+
+// testProvider drives the provider over the plugin protocol the way Terraform does, with its
+// storage requests sent to a fake storage service. Like Terraform, it starts the provider anew
+// for every plan, apply and import; refreshes run in the running instance.
+type testProvider struct {
+	t       *testing.T
+	server  *blobclienttest.Server
+	config  map[string]any
+	proto   tfprotov6.ProviderServer
+	schemas *tfprotov6.GetProviderSchemaResponse
+}
+
+// resourceState is the state Terraform keeps for a resource instance
+type resourceState struct {
+	value   tftypes.Value
+	private []byte
+}
+
+// planResult is the outcome of planning a change to a resource instance
+type planResult struct {
+	prior           *resourceState
+	config          tftypes.Value
+	planned         tftypes.Value
+	private         []byte
+	requiresReplace []*tftypes.AttributePath
+}
+
+// newTestProvider returns a configured provider whose client talks to a fake storage service
+// holding the test container. config sets provider attributes, as for toValue.
+func newTestProvider(t *testing.T, config map[string]any) *testProvider {
+	t.Helper()
+
+	p := &testProvider{t: t, server: blobclienttest.NewServer(), config: config}
+	p.server.CreateContainer(testAccount, testContainer)
+	p.restart()
+	return p
+}
+
+// restart starts and configures a new provider instance talking to the same fake service
+func (p *testProvider) restart() {
+	t := p.t
+	t.Helper()
+
+	proto, err := providerserver.NewProtocol6WithError(&blobLeaseProvider{
+		version: "test",
+		configureClient: func(options *blobclient.ClientOptions) {
+			options.Credential = blobclienttest.Credential{}
+			options.Transport = p.server
+		},
+	})()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.proto = proto
+
+	ctx := context.Background()
+	p.schemas, err = proto.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireNoErrors(t, "GetProviderSchema", p.schemas.Diagnostics)
+
+	configValue := objectValue(t, p.schemas.Provider.ValueType(), p.config)
+	resp, err := proto.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{
+		TerraformVersion: "1.10.0",
+		Config:           dynamicValue(t, configValue),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireNoErrors(t, "ConfigureProvider", resp.Diagnostics)
+}
+
+// resourceSchema returns the schema of a resource type
+func (p *testProvider) resourceSchema(typeName string) *tfprotov6.Schema {
+	p.t.Helper()
+	schema, ok := p.schemas.ResourceSchemas[typeName]
+	if !ok {
+		p.t.Fatalf("no resource type %s", typeName)
+	}
+	return schema
+}
+
+// plan validates config and plans the change from prior, nil when creating the resource
+func (p *testProvider) plan(typeName string, prior *resourceState, config map[string]any) (*planResult, []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+	p.restart()
+	ctx := context.Background()
+	schema := p.resourceSchema(typeName)
+	typ := schema.ValueType()
+	configValue := objectValue(p.t, typ, config)
+
+	validated, err := p.proto.ValidateResourceConfig(ctx, &tfprotov6.ValidateResourceConfigRequest{
+		TypeName: typeName,
+		Config:   dynamicValue(p.t, configValue),
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	if hasErrors(validated.Diagnostics) {
+		return nil, validated.Diagnostics
+	}
+
+	priorValue, priorPrivate := tftypes.NewValue(typ, nil), []byte(nil)
+	if prior != nil {
+		priorValue, priorPrivate = prior.value, prior.private
+	}
+	proposed := proposedNewState(p.t, schema, priorValue, configValue)
+
+	resp, err := p.proto.PlanResourceChange(ctx, &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       dynamicValue(p.t, priorValue),
+		ProposedNewState: dynamicValue(p.t, proposed),
+		Config:           dynamicValue(p.t, configValue),
+		PriorPrivate:     priorPrivate,
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	diags := append(validated.Diagnostics, resp.Diagnostics...)
+	if hasErrors(resp.Diagnostics) {
+		return nil, diags
+	}
+
+	return &planResult{
+		prior:           prior,
+		config:          configValue,
+		planned:         unmarshal(p.t, resp.PlannedState, typ),
+		private:         resp.PlannedPrivate,
+		requiresReplace: resp.RequiresReplace,
+	}, diags
+}
+
+// applyPlan applies a planned change, checking the new state keeps every value the plan knew. A
+// failed apply returns the state the provider saved anyway, if any, without checking it.
+func (p *testProvider) applyPlan(typeName string, plan *planResult) (*resourceState, []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+	p.restart()
+	typ := p.resourceSchema(typeName).ValueType()
+
+	priorValue := tftypes.NewValue(typ, nil)
+	if plan.prior != nil {
+		priorValue = plan.prior.value
+	}
+	resp, err := p.proto.ApplyResourceChange(context.Background(), &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:       typeName,
+		PriorState:     dynamicValue(p.t, priorValue),
+		PlannedState:   dynamicValue(p.t, plan.planned),
+		Config:         dynamicValue(p.t, plan.config),
+		PlannedPrivate: plan.private,
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	state := &resourceState{value: unmarshal(p.t, resp.NewState, typ), private: resp.Private}
+	if hasErrors(resp.Diagnostics) {
+		if state.value.IsNull() {
+			return nil, resp.Diagnostics
+		}
+		return state, resp.Diagnostics
+	}
+
+	if !plan.planned.IsNull() {
+		checkConsistent(p.t, plan.planned, state.value)
+	}
+	return state, resp.Diagnostics
+}
+
+// apply plans and applies config; a nil prior creates the resource
+func (p *testProvider) apply(typeName string, prior *resourceState, config map[string]any) (*resourceState, []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+	plan, diags := p.plan(typeName, prior, config)
+	if hasErrors(diags) {
+		return nil, diags
+	}
+	if len(plan.requiresReplace) > 0 && prior != nil {
+		p.t.Fatalf("plan for %s requires replacement: %v", typeName, plan.requiresReplace)
+	}
+	return p.applyPlan(typeName, plan)
+}
+
+// mustApply is apply failing the test on error diagnostics
+func (p *testProvider) mustApply(typeName string, prior *resourceState, config map[string]any) *resourceState {
+	p.t.Helper()
+	state, diags := p.apply(typeName, prior, config)
+	requireNoErrors(p.t, "apply "+typeName, diags)
+	return state
+}
+
+// destroy plans and applies the deletion of a resource instance
+func (p *testProvider) destroy(typeName string, prior *resourceState) []*tfprotov6.Diagnostic {
+	p.t.Helper()
+	p.restart()
+	ctx := context.Background()
+	typ := p.resourceSchema(typeName).ValueType()
+	null := tftypes.NewValue(typ, nil)
+
+	planned, err := p.proto.PlanResourceChange(ctx, &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       dynamicValue(p.t, prior.value),
+		ProposedNewState: dynamicValue(p.t, null),
+		Config:           dynamicValue(p.t, null),
+		PriorPrivate:     prior.private,
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	if hasErrors(planned.Diagnostics) {
+		return planned.Diagnostics
+	}
+
+	p.restart()
+	resp, err := p.proto.ApplyResourceChange(ctx, &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:       typeName,
+		PriorState:     dynamicValue(p.t, prior.value),
+		PlannedState:   dynamicValue(p.t, null),
+		Config:         dynamicValue(p.t, null),
+		PlannedPrivate: planned.PlannedPrivate,
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return append(planned.Diagnostics, resp.Diagnostics...)
+}
+
+// read refreshes a resource instance, returning nil state when the provider removed it
+func (p *testProvider) read(typeName string, state *resourceState) (*resourceState, []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+	typ := p.resourceSchema(typeName).ValueType()
+
+	resp, err := p.proto.ReadResource(context.Background(), &tfprotov6.ReadResourceRequest{
+		TypeName:     typeName,
+		CurrentState: dynamicValue(p.t, state.value),
+		Private:      state.private,
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	if hasErrors(resp.Diagnostics) {
+		return nil, resp.Diagnostics
+	}
+	value := unmarshal(p.t, resp.NewState, typ)
+	if value.IsNull() {
+		return nil, resp.Diagnostics
+	}
+	return &resourceState{value: value, private: resp.Private}, resp.Diagnostics
+}
+
+// importState imports a resource by ID and refreshes it, as terraform import does
+func (p *testProvider) importState(typeName, id string) (*resourceState, []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+	p.restart()
+	typ := p.resourceSchema(typeName).ValueType()
+
+	resp, err := p.proto.ImportResourceState(context.Background(), &tfprotov6.ImportResourceStateRequest{
+		TypeName: typeName,
+		ID:       id,
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	if hasErrors(resp.Diagnostics) {
+		return nil, resp.Diagnostics
+	}
+	if len(resp.ImportedResources) != 1 {
+		p.t.Fatalf("expected one imported resource, got %d", len(resp.ImportedResources))
+	}
+	imported := resp.ImportedResources[0]
+	state, diags := p.read(typeName, &resourceState{value: unmarshal(p.t, imported.State, typ), private: imported.Private})
+	return state, append(resp.Diagnostics, diags...)
+}
+
+// upgradeState upgrades a state of an earlier schema version, stored as rawJSON, as Terraform does
+// before the first refresh with a newer provider
+func (p *testProvider) upgradeState(typeName string, version int64, rawJSON string) (*resourceState, []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+	p.restart()
+	typ := p.resourceSchema(typeName).ValueType()
+
+	resp, err := p.proto.UpgradeResourceState(context.Background(), &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: typeName,
+		Version:  version,
+		RawState: &tfprotov6.RawState{JSON: []byte(rawJSON)},
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	if hasErrors(resp.Diagnostics) {
+		return nil, resp.Diagnostics
+	}
+	return &resourceState{value: unmarshal(p.t, resp.UpgradedState, typ)}, resp.Diagnostics
+}
+
+// readDataSource reads a data source with the given configuration
+func (p *testProvider) readDataSource(typeName string, config map[string]any) (tftypes.Value, []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+	ctx := context.Background()
+	schema, ok := p.schemas.DataSourceSchemas[typeName]
+	if !ok {
+		p.t.Fatalf("no data source type %s", typeName)
+	}
+	typ := schema.ValueType()
+	configValue := objectValue(p.t, typ, config)
+
+	validated, err := p.proto.ValidateDataResourceConfig(ctx, &tfprotov6.ValidateDataResourceConfigRequest{
+		TypeName: typeName,
+		Config:   dynamicValue(p.t, configValue),
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	if hasErrors(validated.Diagnostics) {
+		return tftypes.Value{}, validated.Diagnostics
+	}
+
+	resp, err := p.proto.ReadDataSource(ctx, &tfprotov6.ReadDataSourceRequest{
+		TypeName: typeName,
+		Config:   dynamicValue(p.t, configValue),
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	if hasErrors(resp.Diagnostics) {
+		return tftypes.Value{}, resp.Diagnostics
+	}
+	return unmarshal(p.t, resp.State, typ), resp.Diagnostics
+}
+
+// callFunction calls the provider function name with args, given as for toValue, and returns its
+// result or error. Arguments beyond the parameters go to the variadic parameter.
+func (p *testProvider) callFunction(name string, args ...any) (tftypes.Value, *tfprotov6.FunctionError) {
+	p.t.Helper()
+	definition, ok := p.schemas.Functions[name]
+	if !ok {
+		p.t.Fatalf("no function %s", name)
+	}
+	if len(args) < len(definition.Parameters) || len(args) > len(definition.Parameters) && definition.VariadicParameter == nil {
+		p.t.Fatalf("function %s takes %d arguments, got %d", name, len(definition.Parameters), len(args))
+	}
+
+	arguments := make([]*tfprotov6.DynamicValue, len(args))
+	for i, arg := range args {
+		parameter := definition.VariadicParameter
+		if i < len(definition.Parameters) {
+			parameter = definition.Parameters[i]
+		}
+		arguments[i] = dynamicValue(p.t, toValue(p.t, parameter.Type, arg))
+	}
+	resp, err := p.proto.CallFunction(context.Background(), &tfprotov6.CallFunctionRequest{
+		Name:      name,
+		Arguments: arguments,
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	if resp.Error != nil {
+		return tftypes.Value{}, resp.Error
+	}
+	return unmarshal(p.t, resp.Result, definition.Return.Type), nil
+}
+
+// proposedNewState merges config into prior as Terraform does: configured values win, and
+// computed attributes that are not configured keep their prior value
+func proposedNewState(t *testing.T, schema *tfprotov6.Schema, prior, config tftypes.Value) tftypes.Value {
+	t.Helper()
+	if prior.IsNull() {
+		return config
+	}
+	priorAttrs, configAttrs := attributes(t, prior), attributes(t, config)
+	proposed := make(map[string]tftypes.Value, len(configAttrs))
+	for _, a := range schema.Block.Attributes {
+		value := configAttrs[a.Name]
+		if value.IsNull() && a.Computed {
+			value = priorAttrs[a.Name]
+		}
+		proposed[a.Name] = value
+	}
+	return tftypes.NewValue(config.Type(), proposed)
+}
+
+// checkConsistent fails the test when the applied state changes a value the plan knew, which
+// Terraform reports as the provider producing an inconsistent result
+func checkConsistent(t *testing.T, planned, applied tftypes.Value) {
+	t.Helper()
+	plannedAttrs, appliedAttrs := attributes(t, planned), attributes(t, applied)
+	for name, value := range plannedAttrs {
+		if value.IsFullyKnown() && !value.Equal(appliedAttrs[name]) {
+			t.Errorf("provider produced inconsistent result: %s planned as %s, applied as %s", name, value, appliedAttrs[name])
+		}
+	}
+}
+
+// changedAttributes returns the sorted names of the attributes that differ between a and b
+func changedAttributes(t *testing.T, a, b tftypes.Value) []string {
+	t.Helper()
+	aAttrs, bAttrs := attributes(t, a), attributes(t, b)
+	var changed []string
+	for name, value := range aAttrs {
+		if !value.Equal(bAttrs[name]) {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// attributes returns the attribute values of an object value
+func attributes(t *testing.T, value tftypes.Value) map[string]tftypes.Value {
+	t.Helper()
+	var attrs map[string]tftypes.Value
+	if err := value.As(&attrs); err != nil {
+		t.Fatal(err)
+	}
+	return attrs
+}
+
+// attrValue returns one attribute of an object value
+func attrValue(t *testing.T, value tftypes.Value, name string) tftypes.Value {
+	t.Helper()
+	attrs := attributes(t, value)
+	v, ok := attrs[name]
+	if !ok {
+		t.Fatalf("no attribute %s", name)
+	}
+	return v
+}
+
+// stringAttr returns a string attribute of an object value, "" when null
+func stringAttr(t *testing.T, value tftypes.Value, name string) string {
+	t.Helper()
+	v := attrValue(t, value, name)
+	if v.IsNull() {
+		return ""
+	}
+	var s string
+	if err := v.As(&s); err != nil {
+		t.Fatalf("%s: %s", name, err)
+	}
+	return s
+}
+
+// stringMapAttr returns a map of strings attribute of an object value, nil when null
+func stringMapAttr(t *testing.T, value tftypes.Value, name string) map[string]string {
+	t.Helper()
+	v := attrValue(t, value, name)
+	if v.IsNull() {
+		return nil
+	}
+	var values map[string]tftypes.Value
+	if err := v.As(&values); err != nil {
+		t.Fatalf("%s: %s", name, err)
+	}
+	m := make(map[string]string, len(values))
+	for key, element := range values {
+		var s string
+		if err := element.As(&s); err != nil {
+			t.Fatalf("%s[%s]: %s", name, key, err)
+		}
+		m[key] = s
+	}
+	return m
+}
+
+// withAttributes returns value with top-level attributes replaced, given as for toValue
+func withAttributes(t *testing.T, value tftypes.Value, attrs map[string]any) tftypes.Value {
+	t.Helper()
+	attributeTypes := value.Type().(tftypes.Object).AttributeTypes
+	replaced, err := tftypes.Transform(value, func(at *tftypes.AttributePath, v tftypes.Value) (tftypes.Value, error) {
+		if steps := at.Steps(); len(steps) == 1 {
+			name := string(steps[0].(tftypes.AttributeName))
+			if replacement, ok := attrs[name]; ok {
+				return toValue(t, attributeTypes[name], replacement), nil
+			}
+		}
+		return v, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return replaced
+}
+
+// objectValue builds a value of an object type from attrs, leaving the other attributes null
+func objectValue(t *testing.T, typ tftypes.Type, attrs map[string]any) tftypes.Value {
+	t.Helper()
+	object, ok := typ.(tftypes.Object)
+	if !ok {
+		t.Fatalf("expected an object type, got %s", typ)
+	}
+	for name := range attrs {
+		if _, ok := object.AttributeTypes[name]; !ok {
+			t.Fatalf("no attribute %s", name)
+		}
+	}
+	values := make(map[string]tftypes.Value, len(object.AttributeTypes))
+	for name, attrType := range object.AttributeTypes {
+		values[name] = toValue(t, attrType, attrs[name])
+	}
+	return tftypes.NewValue(typ, values)
+}
+
+// toValue converts v to a value of typ: nil is null, tftypes.UnknownValue is unknown, and Go
+// strings, bools, ints, slices and maps become the matching Terraform values
+func toValue(t *testing.T, typ tftypes.Type, v any) tftypes.Value {
+	t.Helper()
+	switch v := v.(type) {
+	case nil:
+		return tftypes.NewValue(typ, nil)
+	case tftypes.Value:
+		return v
+	case string, bool:
+		return tftypes.NewValue(typ, v)
+	case int:
+		return tftypes.NewValue(typ, big.NewFloat(float64(v)))
+	case map[string]any:
+		if typ.Is(tftypes.Object{}) {
+			return objectValue(t, typ, v)
+		}
+		element := typ.(tftypes.Map).ElementType
+		values := make(map[string]tftypes.Value, len(v))
+		for key, value := range v {
+			values[key] = toValue(t, element, value)
+		}
+		return tftypes.NewValue(typ, values)
+	case map[string]string:
+		values := make(map[string]tftypes.Value, len(v))
+		for key, value := range v {
+			values[key] = tftypes.NewValue(tftypes.String, value)
+		}
+		return tftypes.NewValue(typ, values)
+	case []string:
+		var element tftypes.Type
+		switch typ := typ.(type) {
+		case tftypes.List:
+			element = typ.ElementType
+		case tftypes.Set:
+			element = typ.ElementType
+		default:
+			t.Fatalf("cannot use a list for %s", typ)
+		}
+		values := make([]tftypes.Value, len(v))
+		for i, value := range v {
+			values[i] = tftypes.NewValue(element, value)
+		}
+		return tftypes.NewValue(typ, values)
+	}
+	t.Fatalf("cannot convert %T to %s", v, typ)
+	return tftypes.Value{}
+}
+
+func dynamicValue(t *testing.T, value tftypes.Value) *tfprotov6.DynamicValue {
+	t.Helper()
+	dv, err := tfprotov6.NewDynamicValue(value.Type(), value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &dv
+}
+
+func unmarshal(t *testing.T, dv *tfprotov6.DynamicValue, typ tftypes.Type) tftypes.Value {
+	t.Helper()
+	value, err := dv.Unmarshal(typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func hasErrors(diags []*tfprotov6.Diagnostic) bool {
+	for _, diag := range diags {
+		if diag.Severity == tfprotov6.DiagnosticSeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// formatDiagnostics renders diagnostics for a test failure message
+func formatDiagnostics(diags []*tfprotov6.Diagnostic) string {
+	var b strings.Builder
+	for _, diag := range diags {
+		fmt.Fprintf(&b, "\n  %s: %s: %s", diag.Severity, diag.Summary, diag.Detail)
+	}
+	return b.String()
+}
+
+func requireNoErrors(t *testing.T, operation string, diags []*tfprotov6.Diagnostic) {
+	t.Helper()
+	if hasErrors(diags) {
+		t.Fatalf("%s failed:%s", operation, formatDiagnostics(diags))
+	}
+}
+
+// requireError fails the test unless diags has an error whose summary or detail contains text
+func requireError(t *testing.T, diags []*tfprotov6.Diagnostic, text string) {
+	t.Helper()
+	for _, diag := range diags {
+		if diag.Severity == tfprotov6.DiagnosticSeverityError && (strings.Contains(diag.Summary, text) || strings.Contains(diag.Detail, text)) {
+			return
+		}
+	}
+	t.Fatalf("expected an error containing %q, got:%s", text, formatDiagnostics(diags))
+}
+
+// findWarning returns the detail of the warning of diags with summary, and whether there is one
+func findWarning(diags []*tfprotov6.Diagnostic, summary string) (string, bool) {
+	for _, diag := range diags {
+		if diag.Severity == tfprotov6.DiagnosticSeverityWarning && diag.Summary == summary {
+			return diag.Detail, true
+		}
+	}
+	return "", false
+}
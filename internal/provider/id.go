@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// blobNameSuffixLength is the length of the suffix generateBlobName appends to a prefix: a UTC
+// timestamp, a hyphen and 8 random hex digits
+const blobNameSuffixLength = len("20060102T150405Z") + 1 + 8
+
+// blobLeaseID returns the ID of a blob lease: storage_account/container_name/blob_name. Storage
+// account and container names cannot contain slashes, so the blob name is everything after the
+// second slash and may contain slashes itself.
+func blobLeaseID(storageAccount, containerName, blobName string) string {
+	return fmt.Sprintf("%s/%s/%s", storageAccount, containerName, blobName)
+}
+
+// parseBlobLeaseID splits an ID built by blobLeaseID on its first two slashes
+func parseBlobLeaseID(id string) (storageAccount, containerName, blobName string, err error) {
+	parts := strings.SplitN(id, "/", 3)
+	switch {
+	case len(parts) != 3:
+		return "", "", "", fmt.Errorf("expected storage_account/container_name/blob_name, got %d slash-separated parts: %q", len(parts), id)
+	case parts[0] == "":
+		return "", "", "", fmt.Errorf("expected storage_account/container_name/blob_name, got an empty storage account: %q", id)
+	case parts[1] == "":
+		return "", "", "", fmt.Errorf("expected storage_account/container_name/blob_name, got an empty container name: %q", id)
+	case parts[2] == "":
+		return "", "", "", fmt.Errorf("expected storage_account/container_name/blob_name, got an empty blob name: %q", id)
+	}
+	return parts[0], parts[1], parts[2], nil
+}
+
+// parseImportID parses the import ID of a blob, storage_account/container_name/blob_name
+// optionally followed by ;blob_endpoint, and checks the names against the naming rules, so that
+// e.g. a blob name with a trailing slash fails here rather than as a missing blob. Empty segments
+// inside the blob name are kept as they are. The resources and the parse_blob_id function share
+// it, so an ID means the same everywhere.
+func parseImportID(importID string) (storageAccount, containerName, blobName, endpoint string, err error) {
+	id, endpoint := splitImportEndpoint(importID)
+	storageAccount, containerName, blobName, err = parseBlobLeaseID(id)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	if err := validators.CheckStorageAccountName(storageAccount); err != nil {
+		return "", "", "", "", fmt.Errorf("storage account %w", err)
+	}
+	if err := validators.CheckContainerName(containerName); err != nil {
+		return "", "", "", "", fmt.Errorf("container name %w", err)
+	}
+	if err := validators.CheckBlobName(blobName); err != nil {
+		return "", "", "", "", fmt.Errorf("blob name %w", err)
+	}
+	return storageAccount, containerName, blobName, endpoint, nil
+}
+
+// splitImportEndpoint splits an import ID of the form id;blob_endpoint into the ID and the blob
+// endpoint. An ID without a trailing http or https URL is returned as is, with no endpoint.
+func splitImportEndpoint(importID string) (id, endpoint string) {
+	if i := strings.LastIndex(importID, ";"); i >= 0 {
+		if u, err := url.Parse(importID[i+1:]); err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != "" {
+			return importID[:i], importID[i+1:]
+		}
+	}
+	return importID, ""
+}
+
+// generateBlobName appends a unique suffix to prefix: the UTC time of now and a random part, so
+// that names generated in the same second still differ and generated names sort by creation
+func generateBlobName(prefix string, now time.Time) (string, error) {
+	random := make([]byte, 4)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate blob name: %w", err)
+	}
+	return prefix + now.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(random), nil
+}
@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBlobLeaseID(t *testing.T) {
+	for name, tc := range map[string]struct {
+		id                                      string
+		storageAccount, containerName, blobName string
+		wantErr                                 string
+	}{
+		"simple":                {id: "acct/locks/app.lock", storageAccount: "acct", containerName: "locks", blobName: "app.lock"},
+		"slashes in blob name":  {id: "acct/container/locks/team-a/prod.lock", storageAccount: "acct", containerName: "container", blobName: "locks/team-a/prod.lock"},
+		"many slashes":          {id: "acct/locks/env/prod/eu/west/app.lock", storageAccount: "acct", containerName: "locks", blobName: "env/prod/eu/west/app.lock"},
+		"empty segment":         {id: "acct/locks/env//app.lock", storageAccount: "acct", containerName: "locks", blobName: "env//app.lock"},
+		"leading slash in blob": {id: "acct/locks//app.lock", storageAccount: "acct", containerName: "locks", blobName: "/app.lock"},
+		"trailing slash":        {id: "acct/locks/env/", storageAccount: "acct", containerName: "locks", blobName: "env/"},
+		"dots in blob name":     {id: "acct/locks/../.state.v1.lock", storageAccount: "acct", containerName: "locks", blobName: "../.state.v1.lock"},
+		"unicode blob name":     {id: "acct/locks/équipe/état 1.lock", storageAccount: "acct", containerName: "locks", blobName: "équipe/état 1.lock"},
+		"semicolon in blob":     {id: "acct/locks/a;b.lock", storageAccount: "acct", containerName: "locks", blobName: "a;b.lock"},
+		"two parts":             {id: "acct/locks", wantErr: "got 2 slash-separated parts"},
+		"one part":              {id: "app.lock", wantErr: "got 1 slash-separated parts"},
+		"empty storage account": {id: "/locks/app.lock", wantErr: "empty storage account"},
+		"empty container name":  {id: "acct//app.lock", wantErr: "empty container name"},
+		"empty blob name":       {id: "acct/locks/", wantErr: "empty blob name"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			storageAccount, containerName, blobName, err := parseBlobLeaseID(tc.id)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected an error containing %q, got: %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if storageAccount != tc.storageAccount || containerName != tc.containerName || blobName != tc.blobName {
+				t.Errorf("expected %s, %s, %s, got %s, %s, %s", tc.storageAccount, tc.containerName, tc.blobName, storageAccount, containerName, blobName)
+			}
+			if id := blobLeaseID(storageAccount, containerName, blobName); id != tc.id {
+				t.Errorf("expected the parts to build the ID %q again, got %q", tc.id, id)
+			}
+		})
+	}
+}
+
+func TestParseImportID(t *testing.T) {
+	for name, tc := range map[string]struct {
+		importID                                          string
+		storageAccount, containerName, blobName, endpoint string
+		wantErr                                           string
+	}{
+		"without endpoint": {
+			importID:       "acct/container/locks/team-a/prod.lock",
+			storageAccount: "acct", containerName: "container", blobName: "locks/team-a/prod.lock",
+		},
+		"with endpoint": {
+			importID:       "acct/locks/app.lock;https://acct.blob.core.usgovcloudapi.net/",
+			storageAccount: "acct", containerName: "locks", blobName: "app.lock", endpoint: "https://acct.blob.core.usgovcloudapi.net/",
+		},
+		"with endpoint and port": {
+			importID:       "devstoreaccount1/locks/app.lock;http://127.0.0.1:10000/devstoreaccount1",
+			storageAccount: "devstoreaccount1", containerName: "locks", blobName: "app.lock", endpoint: "http://127.0.0.1:10000/devstoreaccount1",
+		},
+		"unicode blob name": {
+			importID:       "acct/locks/équipe/état.lock",
+			storageAccount: "acct", containerName: "locks", blobName: "équipe/état.lock",
+		},
+		"many slashes": {
+			importID:       "acct/locks/env/prod/eu/west/app.lock",
+			storageAccount: "acct", containerName: "locks", blobName: "env/prod/eu/west/app.lock",
+		},
+		"empty segment": {
+			importID:       "acct/locks/env//app.lock",
+			storageAccount: "acct", containerName: "locks", blobName: "env//app.lock",
+		},
+		"empty segment with endpoint": {
+			importID:       "acct/locks/env//app.lock;https://acct.blob.core.windows.net/",
+			storageAccount: "acct", containerName: "locks", blobName: "env//app.lock", endpoint: "https://acct.blob.core.windows.net/",
+		},
+		"trailing slash":          {importID: "acct/locks/env/prod/", wantErr: "blob name must"},
+		"empty container segment": {importID: "acct//env/app.lock", wantErr: "container name"},
+		"empty account segment":   {importID: "/locks/env/app.lock", wantErr: "storage account"},
+		"semicolon without endpoint": {
+			importID:       "acct/locks/a;b.lock",
+			storageAccount: "acct", containerName: "locks", blobName: "a;b.lock",
+		},
+		"uppercase storage account": {importID: "Acct/locks/app.lock", wantErr: "storage account must be"},
+		"underscore in container":   {importID: "acct/my_locks/app.lock", wantErr: "container name must be"},
+		"blob name ending in a dot": {importID: "acct/locks/app.", wantErr: "blob name must"},
+		"endpoint without a blob":   {importID: "acct/locks;https://acct.blob.core.windows.net", wantErr: "slash-separated parts"},
+		"trailing semicolon": {
+			importID:       "acct/locks/app.lock;",
+			storageAccount: "acct", containerName: "locks", blobName: "app.lock;",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			storageAccount, containerName, blobName, endpoint, err := parseImportID(tc.importID)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected an error containing %q, got: %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if storageAccount != tc.storageAccount || containerName != tc.containerName || blobName != tc.blobName || endpoint != tc.endpoint {
+				t.Errorf("expected %s, %s, %s, %q, got %s, %s, %s, %q",
+					tc.storageAccount, tc.containerName, tc.blobName, tc.endpoint, storageAccount, containerName, blobName, endpoint)
+			}
+		})
+	}
+}
+
+func TestSplitImportEndpoint(t *testing.T) {
+	for name, tc := range map[string]struct {
+		importID, id, endpoint string
+	}{
+		"no endpoint":        {importID: "acct/locks/app.lock", id: "acct/locks/app.lock"},
+		"https endpoint":     {importID: "acct/locks/app.lock;https://acct.blob.core.windows.net", id: "acct/locks/app.lock", endpoint: "https://acct.blob.core.windows.net"},
+		"http endpoint":      {importID: "acct/locks/app.lock;http://localhost:10000/acct", id: "acct/locks/app.lock", endpoint: "http://localhost:10000/acct"},
+		"last semicolon":     {importID: "acct/locks/a;b.lock;https://acct.blob.core.windows.net", id: "acct/locks/a;b.lock", endpoint: "https://acct.blob.core.windows.net"},
+		"not a URL":          {importID: "acct/locks/a;b.lock", id: "acct/locks/a;b.lock"},
+		"other scheme":       {importID: "acct/locks/app.lock;ftp://acct.example.com", id: "acct/locks/app.lock;ftp://acct.example.com"},
+		"no host":            {importID: "acct/locks/app.lock;https://", id: "acct/locks/app.lock;https://"},
+		"trailing semicolon": {importID: "acct/locks/app.lock;", id: "acct/locks/app.lock;"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			id, endpoint := splitImportEndpoint(tc.importID)
+			if id != tc.id || endpoint != tc.endpoint {
+				t.Errorf("expected %q and %q, got %q and %q", tc.id, tc.endpoint, id, endpoint)
+			}
+		})
+	}
+}
+
+func TestGenerateBlobName(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 30, 45, 0, time.FixedZone("CET", 3600))
+	name, err := generateBlobName("locks/run-", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(name, "locks/run-20260301T113045Z-") {
+		t.Errorf("expected the prefix and the UTC time, got %q", name)
+	}
+	if len(name) != len("locks/run-")+blobNameSuffixLength {
+		t.Errorf("expected a suffix of %d characters, got %q", blobNameSuffixLength, name)
+	}
+	if other, _ := generateBlobName("locks/run-", now); other == name {
+		t.Errorf("expected names generated at the same time to differ, got %q twice", name)
+	}
+}
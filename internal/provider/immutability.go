@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+)
+
+// immutabilityPolicyAttrTypes are the attribute types of the immutability_policy object
+var immutabilityPolicyAttrTypes = map[string]attr.Type{
+	"expiry_time": types.StringType,
+	"mode":        types.StringType,
+}
+
+// immutabilityPolicyAttribute returns the schema of the immutability_policy attribute. When it is
+// not configured, the policy of the blob is reported but not managed.
+func immutabilityPolicyAttribute() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "A time-based retention policy that keeps the blob from being modified or deleted until it expires. The container must have version-level immutability support enabled",
+		Optional:            true,
+		Computed:            true,
+		PlanModifiers: []planmodifier.Object{
+			objectplanmodifier.UseStateForUnknown(),
+		},
+		Attributes: map[string]schema.Attribute{
+			"expiry_time": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time until which the blob is protected. Extending it is applied in place",
+				Required:            true,
+				Validators: []validator.String{
+					rfc3339Validator{},
+				},
+			},
+			"mode": schema.StringAttribute{
+				MarkdownDescription: "`Unlocked`, which still allows shortening or deleting the policy, or `Locked`, which only allows extending it. Defaults to `Unlocked`",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(blobclient.ImmutabilityModeUnlocked),
+				Validators: []validator.String{
+					stringOneOfValidator{values: []string{blobclient.ImmutabilityModeUnlocked, blobclient.ImmutabilityModeLocked}},
+				},
+			},
+		},
+	}
+}
+
+// immutabilityPolicy converts the immutability_policy attribute into the client policy, nil when
+// it is null or unknown
+func immutabilityPolicy(obj types.Object) *blobclient.ImmutabilityPolicy {
+	if obj.IsNull() || obj.IsUnknown() {
+		return nil
+	}
+
+	expiry, _ := obj.Attributes()["expiry_time"].(types.String)
+	mode, _ := obj.Attributes()["mode"].(types.String)
+
+	// The value was validated as a timestamp at plan time
+	expiresOn, err := time.Parse(time.RFC3339, expiry.ValueString())
+	if err != nil {
+		return nil
+	}
+
+	policy := &blobclient.ImmutabilityPolicy{ExpiresOn: expiresOn, Mode: blobclient.ImmutabilityModeUnlocked}
+	if !mode.IsNull() && !mode.IsUnknown() {
+		policy.Mode = mode.ValueString()
+	}
+	return policy
+}
+
+// immutabilityPolicyValue converts the policy of a blob into the attribute value. The expiry_time
+// of prior is kept when it denotes the same time, so a configured time zone does not show as drift.
+func immutabilityPolicyValue(policy *blobclient.ImmutabilityPolicy, prior types.Object) types.Object {
+	if policy == nil {
+		return types.ObjectNull(immutabilityPolicyAttrTypes)
+	}
+
+	expiry := timestampValue(&policy.ExpiresOn)
+	if current := immutabilityPolicy(prior); current != nil && current.ExpiresOn.Equal(policy.ExpiresOn) {
+		expiry = prior.Attributes()["expiry_time"].(types.String)
+	}
+
+	return types.ObjectValueMust(immutabilityPolicyAttrTypes, map[string]attr.Value{
+		"expiry_time": expiry,
+		"mode":        types.StringValue(policy.Mode),
+	})
+}
+
+// samePolicy reports whether two policies have the same expiry and mode
+func samePolicy(a, b *blobclient.ImmutabilityPolicy) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.ExpiresOn.Equal(b.ExpiresOn) && a.Mode == b.Mode
+}
+
+// applyImmutability applies a configured legal_hold and immutability_policy that differ from the
+// current ones of the blob. Unconfigured values are recorded as found, and so are the values a
+// failed change leaves on the blob, so that a partially applied state matches the blob.
+func (r *BlobLeaseResource) applyImmutability(ctx context.Context, data *BlobLeaseResourceModel, config blobclient.BlobLeaseConfig, legalHold bool, policy *blobclient.ImmutabilityPolicy) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if data.LegalHold.IsNull() || data.LegalHold.IsUnknown() {
+		data.LegalHold = types.BoolValue(legalHold)
+	} else if data.LegalHold.ValueBool() != legalHold {
+		if err := r.client.SetBlobLegalHold(ctx, config, data.LegalHold.ValueBool()); err != nil {
+			diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to set legal hold, got error: %s", err))
+			data.LegalHold = types.BoolValue(legalHold)
+			data.ImmutabilityPolicy = immutabilityPolicyValue(policy, types.ObjectNull(immutabilityPolicyAttrTypes))
+			return diags
+		}
+	}
+
+	desired := immutabilityPolicy(data.ImmutabilityPolicy)
+	if desired == nil {
+		data.ImmutabilityPolicy = immutabilityPolicyValue(policy, types.ObjectNull(immutabilityPolicyAttrTypes))
+		return diags
+	}
+	if samePolicy(desired, policy) {
+		return diags
+	}
+
+	err := r.client.SetBlobImmutabilityPolicy(ctx, config, desired)
+	if err != nil {
+		data.ImmutabilityPolicy = immutabilityPolicyValue(policy, types.ObjectNull(immutabilityPolicyAttrTypes))
+	}
+	if errors.Is(err, blobclient.ErrImmutabilityPolicyLocked) {
+		diags.AddAttributeError(path.Root("immutability_policy"), "Immutability Policy Locked", err.Error())
+		return diags
+	}
+	if err != nil {
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to set immutability policy, got error: %s", err))
+	}
+	return diags
+}
+
+// immutableBlobError explains why a blob with a legal hold or an unexpired immutability policy
+// cannot be destroyed, and what to change first. Only call it when isImmutable(data) is true.
+func immutableBlobError(data BlobLeaseResourceModel) diag.Diagnostic {
+	blobName := data.BlobName.ValueString()
+	if data.LegalHold.ValueBool() {
+		return diag.NewAttributeErrorDiagnostic(
+			path.Root("legal_hold"),
+			"Blob Is Immutable",
+			fmt.Sprintf("Blob %s has a legal hold and cannot be deleted. Set legal_hold to false and apply before destroying it.", blobName),
+		)
+	}
+
+	policy := immutabilityPolicy(data.ImmutabilityPolicy)
+	expiresOn := policy.ExpiresOn.UTC().Format(time.RFC3339)
+	if policy.Mode == blobclient.ImmutabilityModeLocked {
+		return diag.NewAttributeErrorDiagnostic(
+			path.Root("immutability_policy"),
+			"Blob Is Immutable",
+			fmt.Sprintf("Blob %s is protected by a locked immutability policy until %s and cannot be deleted before then.", blobName, expiresOn),
+		)
+	}
+	return diag.NewAttributeErrorDiagnostic(
+		path.Root("immutability_policy"),
+		"Blob Is Immutable",
+		fmt.Sprintf("Blob %s is protected by an unlocked immutability policy until %s. Wait until it expires, or delete the policy outside Terraform, before destroying it.", blobName, expiresOn),
+	)
+}
+
+// isImmutable reports whether the state shows a legal hold or an unexpired immutability policy
+func isImmutable(data BlobLeaseResourceModel) bool {
+	return data.LegalHold.ValueBool() || immutabilityPolicy(data.ImmutabilityPolicy).Active(time.Now())
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+)
+
+// keepaliveJitter spreads the renewals of many resources started in the same apply
+const keepaliveJitter = 0.1
+
+// keepalives holds the background renewals started with renew_during_apply, by blob path. They
+// belong to the provider process rather than to a request, since they must outlive the Create
+// or Update that started them, and are stopped by StopKeepalives when the provider server shuts
+// down.
+var keepalives = &keepaliveRegistry{renewers: map[string]*blobclient.LeaseRenewer{}}
+
+// keepaliveRegistry tracks running background renewals. It is safe for concurrent use.
+type keepaliveRegistry struct {
+	mu       sync.Mutex
+	renewers map[string]*blobclient.LeaseRenewer
+}
+
+// replace registers renewer for path, or removes the renewal of path when renewer is nil, and
+// stops the renewal it replaces
+func (k *keepaliveRegistry) replace(path string, renewer *blobclient.LeaseRenewer) {
+	k.mu.Lock()
+	previous := k.renewers[path]
+	if renewer != nil {
+		k.renewers[path] = renewer
+	} else {
+		delete(k.renewers, path)
+	}
+	k.mu.Unlock()
+
+	// Stopping waits for a final renewal, so it is done without holding the lock
+	if previous != nil {
+		previous.Stop()
+	}
+}
+
+// stopAll stops every registered renewal and waits for them to exit
+func (k *keepaliveRegistry) stopAll() {
+	k.mu.Lock()
+	renewers := k.renewers
+	k.renewers = map[string]*blobclient.LeaseRenewer{}
+	k.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, renewer := range renewers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			renewer.Stop()
+		}()
+	}
+	wg.Wait()
+}
+
+// StopKeepalives stops the background renewals started with renew_during_apply. It is called
+// when the provider server shuts down.
+func StopKeepalives() {
+	keepalives.stopAll()
+}
+
+// keepLeaseRenewed starts renewing the lease of data in the background at half its duration when
+// renew_during_apply is set and the lease is time-limited, replacing any renewal of the same
+// blob. Otherwise a renewal of the blob is stopped. Failed renewals are logged as warnings, and a
+// lost lease ends the renewal.
+func (r *BlobLeaseResource) keepLeaseRenewed(ctx context.Context, data BlobLeaseResourceModel, leaseID string) {
+	path := blobclient.BlobTargetPath(data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString())
+	duration := data.LeaseDuration.ValueInt32()
+	if !data.RenewDuringApply.ValueBool() || duration <= 0 || leaseID == "" {
+		keepalives.replace(path, nil)
+		return
+	}
+
+	config := blobclient.BlobLeaseConfig{
+		StorageAccount: data.StorageAccount.ValueString(),
+		ContainerName:  data.ContainerName.ValueString(),
+		BlobName:       data.BlobName.ValueString(),
+		LeaseID:        leaseID,
+		LeaseDuration:  duration,
+	}
+
+	// The renewal outlives the request, but keeps its logger and blob endpoint
+	renewCtx := context.WithoutCancel(ctx)
+	renewer := r.client.StartLeaseRenewal(renewCtx, config, blobclient.LeaseRenewalOptions{
+		Interval: time.Duration(duration) * time.Second / 2,
+		Jitter:   keepaliveJitter,
+		OnError: func(err error) {
+			tflog.Warn(renewCtx, "Unable to renew blob lease during apply", map[string]interface{}{
+				"blob":  path,
+				"error": err.Error(),
+			})
+		},
+	})
+	keepalives.replace(path, renewer)
+}
@@ -0,0 +1,181 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient/blobclienttest"
+)
+
+// leasedTestBlob returns a client of a fake service holding env/app.lock leased for 15 seconds,
+// and the configuration of its lease
+func leasedTestBlob(t *testing.T) (*blobclienttest.Server, *blobclient.AzureBlobLeaseClient, blobclient.BlobLeaseConfig) {
+	t.Helper()
+	server := blobclienttest.NewServer()
+	server.PutBlob(testAccount, testContainer, "env/app.lock", []byte("locked"))
+	client, err := server.NewClient(blobclient.ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := blobclient.BlobLeaseConfig{
+		StorageAccount: testAccount,
+		ContainerName:  testContainer,
+		BlobName:       "env/app.lock",
+		LeaseID:        "3f2504e0-4f89-11d3-9a0c-0305e82c3301",
+		LeaseDuration:  15,
+	}
+	if _, err := client.AcquireBlobLease(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+	return server, client, config
+}
+
+// requireStopped fails the test unless renewer has exited
+func requireStopped(t *testing.T, name string, renewer *blobclient.LeaseRenewer) {
+	t.Helper()
+	select {
+	case <-renewer.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the renewal of %s to be stopped", name)
+	}
+}
+
+// requireNoLeakedGoroutines waits for the number of goroutines to drop back to before
+func requireNoLeakedGoroutines(t *testing.T, before int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d goroutines, got %d", before, runtime.NumGoroutine())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestKeepaliveRegistry(t *testing.T) {
+	_, client, config := leasedTestBlob(t)
+	before := runtime.NumGoroutine()
+	start := func() *blobclient.LeaseRenewer {
+		return client.StartLeaseRenewal(context.Background(), config, blobclient.LeaseRenewalOptions{Interval: time.Hour})
+	}
+
+	registry := &keepaliveRegistry{renewers: map[string]*blobclient.LeaseRenewer{}}
+	first, second, other := start(), start(), start()
+
+	// A new renewal of a blob stops the one it replaces
+	registry.replace("acct/locks/a", first)
+	registry.replace("acct/locks/a", second)
+	requireStopped(t, "the replaced renewal", first)
+	registry.replace("acct/locks/b", other)
+
+	// Removing a renewal stops it, and leaves the others running
+	registry.replace("acct/locks/a", nil)
+	requireStopped(t, "the removed renewal", second)
+	select {
+	case <-other.Done():
+		t.Fatal("expected the renewal of another blob to keep running")
+	default:
+	}
+
+	registry.stopAll()
+	requireStopped(t, "the remaining renewal", other)
+	if len(registry.renewers) != 0 {
+		t.Errorf("expected stopAll to empty the registry, got %d renewals", len(registry.renewers))
+	}
+	requireNoLeakedGoroutines(t, before)
+}
+
+func TestLeaseRenewalKeepsLeaseUntilLost(t *testing.T) {
+	server, client, config := leasedTestBlob(t)
+	renewals := func(req *http.Request) bool { return req.Header.Get("X-Ms-Lease-Action") == "renew" }
+	acquires := func(req *http.Request) bool { return req.Header.Get("X-Ms-Lease-Action") == "acquire" }
+	acquired := server.Count(acquires)
+
+	var mu sync.Mutex
+	var errs []error
+	renewer := client.StartLeaseRenewal(context.Background(), config, blobclient.LeaseRenewalOptions{
+		Interval:     10 * time.Millisecond,
+		RetryBackoff: time.Millisecond,
+		OnError: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		},
+	})
+	defer renewer.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for server.Count(renewals) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the lease to be renewed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Someone breaks the lease; the renewal reports it and ends without taking it back
+	server.SetLease(testAccount, testContainer, "env/app.lock", "")
+	requireStopped(t, "the renewal of a lost lease", renewer)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) != 1 || !errors.Is(errs[0], blobclient.ErrLeaseLost) {
+		t.Errorf("expected ErrLeaseLost to be reported once, got %v", errs)
+	}
+	if n := server.Count(acquires) - acquired; n != 0 {
+		t.Errorf("expected the lost lease not to be acquired again, got %d acquisitions", n)
+	}
+	if blob, _ := server.Blob(testAccount, testContainer, "env/app.lock"); blob.LeaseID != "" {
+		t.Errorf("expected the blob to stay unleased, got lease %s", blob.LeaseID)
+	}
+}
+
+func TestBlobLeaseRenewDuringApply(t *testing.T) {
+	t.Cleanup(StopKeepalives)
+	before := runtime.NumGoroutine()
+	p := newTestProvider(t, nil)
+	config := blobLeaseConfig(map[string]any{"renew_during_apply": true, "lease_duration": 15})
+	running := func() *blobclient.LeaseRenewer {
+		keepalives.mu.Lock()
+		defer keepalives.mu.Unlock()
+		return keepalives.renewers[blobclient.BlobTargetPath(testAccount, testContainer, "env/app.lock")]
+	}
+
+	state := p.mustApply(blobLeaseType, nil, config)
+	renewer := running()
+	if renewer == nil {
+		t.Fatal("expected create to start renewing the lease")
+	}
+
+	// Turning renew_during_apply off stops the renewal
+	config["renew_during_apply"] = false
+	state = p.mustApply(blobLeaseType, state, config)
+	requireStopped(t, "the renewal turned off", renewer)
+	if running() != nil {
+		t.Error("expected update to remove the renewal")
+	}
+
+	// Shutting the provider down stops the renewal after a final renewal
+	config["renew_during_apply"] = true
+	p.mustApply(blobLeaseType, state, config)
+	renewer = running()
+	if renewer == nil {
+		t.Fatal("expected update to start renewing the lease")
+	}
+	renewals := func(req *http.Request) bool { return req.Header.Get("X-Ms-Lease-Action") == "renew" }
+	renewed := p.server.Count(renewals)
+	StopKeepalives()
+	requireStopped(t, "the renewal at shutdown", renewer)
+	if p.server.Count(renewals) == renewed {
+		t.Error("expected a final renewal when the provider shuts down")
+	}
+	if running() != nil {
+		t.Error("expected StopKeepalives to empty the registry")
+	}
+	requireNoLeakedGoroutines(t, before)
+}
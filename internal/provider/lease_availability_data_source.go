@@ -0,0 +1,210 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &LeaseAvailabilityDataSource{}
+
+// Defaults of how long and how often blobleas_lease_availability checks the lease
+const (
+	defaultAvailabilityTimeout      = 5 * time.Minute
+	defaultAvailabilityPollInterval = 10 * time.Second
+)
+
+func NewLeaseAvailabilityDataSource() datasource.DataSource {
+	return &LeaseAvailabilityDataSource{}
+}
+
+// LeaseAvailabilityDataSource waits for the lease on a blob to be given up, so that a plan
+// serializes behind whoever holds it. It never leases the blob itself.
+type LeaseAvailabilityDataSource struct {
+	client *blobclient.AzureBlobLeaseClient
+}
+
+// LeaseAvailabilityDataSourceModel describes the data source data model.
+type LeaseAvailabilityDataSourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	StorageAccount types.String `tfsdk:"storage_account_name"`
+	BlobEndpoint   types.String `tfsdk:"blob_endpoint"`
+	ContainerName  types.String `tfsdk:"storage_container_name"`
+	BlobName       types.String `tfsdk:"name"`
+	Timeout        types.String `tfsdk:"timeout"`
+	PollInterval   types.String `tfsdk:"poll_interval"`
+	Available      types.Bool   `tfsdk:"available"`
+	WaitedSeconds  types.Int64  `tfsdk:"waited_seconds"`
+	LeaseState     types.String `tfsdk:"lease_state"`
+}
+
+func (d *LeaseAvailabilityDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_lease_availability"
+}
+
+func (d *LeaseAvailabilityDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Waits until the lease on an Azure Storage blob can be acquired, without acquiring it",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Data source identifier, `storage_account_name/storage_container_name/name`",
+			},
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name",
+				Required:            true,
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, e.g. a private endpoint or sovereign cloud URL, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`",
+				Optional:            true,
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"storage_container_name": schema.StringAttribute{
+				MarkdownDescription: "The container of the blob",
+				Required:            true,
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The name of the blob",
+				Required:            true,
+				Validators: []validator.String{
+					validators.BlobName(),
+				},
+			},
+			"timeout": schema.StringAttribute{
+				MarkdownDescription: "How long to wait for the lease to become available before failing, e.g. `10m`. Defaults to `5m`",
+				Optional:            true,
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"poll_interval": schema.StringAttribute{
+				MarkdownDescription: "How often the lease state is checked while waiting, e.g. `30s`, at least `1s`. Defaults to `10s`",
+				Optional:            true,
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"available": schema.BoolAttribute{
+				MarkdownDescription: "Whether the lease became available. Always true, since a lease still held after `timeout` fails the read",
+				Computed:            true,
+			},
+			"waited_seconds": schema.Int64Attribute{
+				MarkdownDescription: "How many seconds the read waited for the lease",
+				Computed:            true,
+			},
+			"lease_state": schema.StringAttribute{
+				MarkdownDescription: "The lease state that ended the wait: `available`, `expired` or `broken`",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *LeaseAvailabilityDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *LeaseAvailabilityDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data LeaseAvailabilityDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The values were validated as durations at plan time
+	timeout, pollInterval := defaultAvailabilityTimeout, defaultAvailabilityPollInterval
+	if !data.Timeout.IsNull() {
+		timeout, _ = time.ParseDuration(data.Timeout.ValueString())
+	}
+	if !data.PollInterval.IsNull() {
+		pollInterval, _ = time.ParseDuration(data.PollInterval.ValueString())
+	}
+	if pollInterval < time.Second {
+		resp.Diagnostics.AddAttributeError(path.Root("poll_interval"), "Invalid Poll Interval", fmt.Sprintf("poll_interval must be at least 1s, got: %s", pollInterval))
+		return
+	}
+
+	endpoint := data.BlobEndpoint.ValueString()
+	if endpoint != "" {
+		if err := d.client.CheckBlobEndpoint(endpoint); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+			return
+		}
+	}
+	ctx = blobclient.WithBlobEndpoint(ctx, endpoint)
+
+	storageAccount, containerName, blobName := data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString()
+	exists, err := d.client.BlobExists(ctx, storageAccount, containerName, blobName)
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence, got error: %s", err))
+		return
+	}
+	if !exists {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Blob Not Found",
+			fmt.Sprintf("Blob %s does not exist in container %s of storage account %s", blobName, containerName, storageAccount),
+		)
+		return
+	}
+
+	// The wait ends with the context, so cancelling the plan stops it
+	start := time.Now()
+	state, err := d.client.WaitForLeaseAvailable(ctx, storageAccount, containerName, blobName, timeout, pollInterval)
+	if errors.Is(err, blobclient.ErrLeaseWaitTimeout) {
+		resp.Diagnostics.AddAttributeError(path.Root("timeout"), "Lease Still Held", err.Error())
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to wait for blob lease, got error: %s", err))
+		return
+	}
+
+	data.ID = types.StringValue(blobLeaseID(storageAccount, containerName, blobName))
+	data.Available = types.BoolValue(true)
+	data.WaitedSeconds = types.Int64Value(int64(time.Since(start).Seconds()))
+	data.LeaseState = types.StringValue(state.LeaseState)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,409 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/lease"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &LeaseBreakResource{}
+var _ resource.ResourceWithModifyPlan = &LeaseBreakResource{}
+
+// maxBreakPeriod is the longest break period the service accepts, in seconds
+const maxBreakPeriod = 60
+
+// breakPeriodValidator ensures break_period is between 0 and 60 seconds
+type breakPeriodValidator struct{}
+
+func (v breakPeriodValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be between 0 and %d seconds", maxBreakPeriod)
+}
+
+func (v breakPeriodValidator) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprintf("value must be between `0` and `%d` seconds", maxBreakPeriod)
+}
+
+func (v breakPeriodValidator) ValidateInt32(ctx context.Context, req validator.Int32Request, resp *validator.Int32Response) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if period := req.ConfigValue.ValueInt32(); period < 0 || period > maxBreakPeriod {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Break Period",
+			fmt.Sprintf("break_period %s, got: %d", v.Description(ctx), period),
+		)
+	}
+}
+
+func NewLeaseBreakResource() resource.Resource {
+	return &LeaseBreakResource{now: time.Now}
+}
+
+// LeaseBreakResource breaks the lease on a blob, whoever holds it, when it is created. It exists
+// to take over stuck leases from Terraform, for example in a recovery runbook.
+type LeaseBreakResource struct {
+	client *blobclient.AzureBlobLeaseClient
+	// now is the clock last_modified_older_than is measured with
+	now func() time.Time
+}
+
+// LeaseBreakResourceModel describes the resource data model.
+type LeaseBreakResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	StorageAccount types.String `tfsdk:"storage_account_name"`
+	BlobEndpoint   types.String `tfsdk:"blob_endpoint"`
+	ContainerName  types.String `tfsdk:"storage_container_name"`
+	BlobName       types.String `tfsdk:"name"`
+	BreakPeriod    types.Int32  `tfsdk:"break_period"`
+	OnlyIfState    types.Object `tfsdk:"only_if_state"`
+	Broken         types.Bool   `tfsdk:"broken"`
+	BrokenAt       types.String `tfsdk:"broken_at"`
+	LeaseState     types.String `tfsdk:"lease_state"`
+	Timeouts       types.Object `tfsdk:"timeouts"`
+}
+
+func (r *LeaseBreakResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_lease_break"
+}
+
+func (r *LeaseBreakResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Breaks the lease on an Azure Storage blob, whoever holds it, when created",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name. Changing it forces a new resource",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`. Changing it forces a new resource",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"storage_container_name": schema.StringAttribute{
+				MarkdownDescription: "The container of the blob. Changing it forces a new resource",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The name of the blob whose lease is broken. Changing it forces a new resource",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.BlobName(),
+				},
+			},
+			"break_period": schema.Int32Attribute{
+				MarkdownDescription: "How many seconds, 0-60, the lease may stay in effect after the break before it ends. Create waits until it has ended. Defaults to `0`, which ends it immediately. Changing it forces a new resource",
+				Optional:            true,
+				Computed:            true,
+				Default:             int32default.StaticInt32(0),
+				PlanModifiers: []planmodifier.Int32{
+					int32planmodifier.RequiresReplace(),
+				},
+				Validators: []validator.Int32{
+					breakPeriodValidator{},
+				},
+			},
+			"only_if_state": schema.SingleNestedAttribute{
+				MarkdownDescription: "Only break the lease when the blob matches all of these conditions, so a healthy lease is not broken by accident. Otherwise create breaks nothing and warns why. Changing it forces a new resource",
+				Optional:            true,
+				PlanModifiers: []planmodifier.Object{
+					objectplanmodifier.RequiresReplace(),
+				},
+				Attributes: map[string]schema.Attribute{
+					"lease_state": schema.StringAttribute{
+						MarkdownDescription: "The lease state the blob must be in: `leased` or `breaking`",
+						Optional:            true,
+						Validators: []validator.String{
+							stringOneOfValidator{values: []string{string(lease.StateTypeLeased), string(lease.StateTypeBreaking)}},
+						},
+					},
+					"last_modified_older_than": schema.StringAttribute{
+						MarkdownDescription: "How long ago the blob must have been last written at least, e.g. `2h`",
+						Optional:            true,
+						Validators: []validator.String{
+							durationValidator{},
+						},
+					},
+				},
+			},
+			"timeouts": timeoutsAttribute(),
+			"broken": schema.BoolAttribute{
+				MarkdownDescription: "Whether create broke the lease",
+				Computed:            true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"broken_at": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time at which the broken lease ended, null when nothing was broken",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"lease_state": schema.StringAttribute{
+				MarkdownDescription: "The current lease state of the blob, null when the blob no longer exists",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *LeaseBreakResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// ModifyPlan rejects an http blob_endpoint the provider does not allow
+func (r *LeaseBreakResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var endpoint types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("blob_endpoint"), &endpoint)...)
+	if !endpoint.IsNull() && !endpoint.IsUnknown() {
+		if err := r.client.CheckBlobEndpoint(endpoint.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+		}
+	}
+}
+
+// breakSkipReason returns why the lease of a blob with the properties of existing is not broken,
+// or "" when it is: it must be leased or breaking, and match only_if_state
+func breakSkipReason(data LeaseBreakResourceModel, existing *blobclient.BlobLeaseResult, now time.Time) string {
+	if existing.LeaseState != string(lease.StateTypeLeased) && existing.LeaseState != string(lease.StateTypeBreaking) {
+		return fmt.Sprintf("it has no lease to break (lease state: %s)", existing.LeaseState)
+	}
+	if data.OnlyIfState.IsNull() || data.OnlyIfState.IsUnknown() {
+		return ""
+	}
+
+	conditions := data.OnlyIfState.Attributes()
+	if state, ok := conditions["lease_state"].(types.String); ok && !state.IsNull() && existing.LeaseState != state.ValueString() {
+		return fmt.Sprintf("its lease state is %s, not %s as only_if_state requires", existing.LeaseState, state.ValueString())
+	}
+	if age, ok := conditions["last_modified_older_than"].(types.String); ok && !age.IsNull() && existing.LastModified != nil {
+		// The value was validated as a duration at plan time
+		minAge, _ := time.ParseDuration(age.ValueString())
+		if now.Sub(*existing.LastModified) < minAge {
+			return fmt.Sprintf("it was last modified at %s, less than %s ago as only_if_state requires",
+				existing.LastModified.UTC().Format(time.RFC3339), age.ValueString())
+		}
+	}
+	return ""
+}
+
+// brokenLeaseDescription describes the lease of existing that was broken, for the warning that
+// tells the operator what create did
+func brokenLeaseDescription(existing *blobclient.BlobLeaseResult) string {
+	parts := []string{"lease state: " + existing.LeaseState}
+	if existing.LeaseDuration != "" {
+		parts = append(parts, "lease duration: "+existing.LeaseDuration)
+	}
+	if existing.LastModified != nil {
+		parts = append(parts, "last modified: "+existing.LastModified.UTC().Format(time.RFC3339))
+	}
+	if key, ok := lookupFold(existing.Metadata, blobclient.LeaseOwnerMetadataKey); ok {
+		parts = append(parts, fmt.Sprintf("metadata %s=%s", blobclient.LeaseOwnerMetadataKey, existing.Metadata[key]))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (r *LeaseBreakResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data LeaseBreakResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := operationTimeout(data.Timeouts, "create", defaultCreateTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "create", data.BlobName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
+	config := blobclient.BlobLeaseConfig{
+		StorageAccount: data.StorageAccount.ValueString(),
+		ContainerName:  data.ContainerName.ValueString(),
+		BlobName:       data.BlobName.ValueString(),
+	}
+	blobPath := blobLeaseID(config.StorageAccount, config.ContainerName, config.BlobName)
+
+	exists, err := r.client.BlobExists(ctx, config.StorageAccount, config.ContainerName, config.BlobName)
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence, got error: %s", err))
+		return
+	}
+	if !exists {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Blob Not Found",
+			fmt.Sprintf("Blob %s does not exist in container %s of storage account %s, so there is no lease to break.", config.BlobName, config.ContainerName, config.StorageAccount),
+		)
+		return
+	}
+
+	existing, err := r.client.GetBlobLeaseState(ctx, config.StorageAccount, config.ContainerName, config.BlobName)
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read blob lease state, got error: %s", err))
+		return
+	}
+
+	data.ID = types.StringValue(blobPath)
+	data.Broken = types.BoolValue(false)
+	data.BrokenAt = types.StringNull()
+	data.LeaseState = types.StringValue(existing.LeaseState)
+
+	if reason := breakSkipReason(data, existing, r.now()); reason != "" {
+		resp.Diagnostics.AddWarning(
+			"Lease Not Broken",
+			fmt.Sprintf("The lease on blob %s was not broken because %s. Replace this resource to check again.", blobPath, reason),
+		)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	breakPeriod := data.BreakPeriod.ValueInt32()
+	if err := r.client.BreakBlobLeaseAndWait(ctx, config, breakPeriod); err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to break lease, got error: %s", err))
+		return
+	}
+	brokenAt := r.now()
+
+	data.Broken = types.BoolValue(true)
+	data.BrokenAt = timestampValue(&brokenAt)
+	data.LeaseState = types.StringValue(string(lease.StateTypeBroken))
+	resp.Diagnostics.AddWarning(
+		"Lease Broken",
+		fmt.Sprintf("Broke the lease on blob %s (%s) with a break period of %d seconds. The lease ended at %s; its holder can no longer write or delete the blob with it, and the blob can be leased again.",
+			blobPath, brokenLeaseDescription(existing), breakPeriod, data.BrokenAt.ValueString()),
+	)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *LeaseBreakResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data LeaseBreakResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := operationTimeout(data.Timeouts, "read", defaultReadTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "read", data.BlobName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
+	// The break already happened, so a blob deleted since is not a reason to break again
+	exists, err := r.client.BlobExists(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence, got error: %s", err))
+		return
+	}
+	data.LeaseState = types.StringNull()
+	if exists {
+		leaseResult, err := r.client.GetBlobLeaseState(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read blob lease state, got error: %s", err))
+			return
+		}
+		data.LeaseState = types.StringValue(leaseResult.LeaseState)
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update only applies changed timeouts; every other argument forces a new resource
+func (r *LeaseBreakResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data LeaseBreakResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete only removes the resource from state; a broken lease cannot be restored
+func (r *LeaseBreakResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
@@ -0,0 +1,382 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ ephemeral.EphemeralResource = &LeaseEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithConfigure = &LeaseEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithRenew = &LeaseEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithClose = &LeaseEphemeralResource{}
+
+// defaultEphemeralLeaseDuration is the lease duration of the ephemeral lease when none is
+// configured. A finite lease lapses on its own when Terraform exits without closing it.
+const defaultEphemeralLeaseDuration = 60
+
+// ephemeralLeasePrivateKey is the private data key of the lease held by an ephemeral lease,
+// which Renew and Close are given instead of the configuration
+const ephemeralLeasePrivateKey = "lease"
+
+func NewLeaseEphemeralResource() ephemeral.EphemeralResource {
+	return &LeaseEphemeralResource{}
+}
+
+// LeaseEphemeralResource holds a lease on a blob for the duration of a Terraform operation. The
+// lease is acquired when Terraform opens the resource, renewed while the operation runs and
+// released when it is closed; nothing is written to state.
+type LeaseEphemeralResource struct {
+	client *blobclient.AzureBlobLeaseClient
+}
+
+// LeaseEphemeralResourceModel describes the ephemeral resource data model.
+type LeaseEphemeralResourceModel struct {
+	StorageAccount types.String `tfsdk:"storage_account_name"`
+	BlobEndpoint   types.String `tfsdk:"blob_endpoint"`
+	ContainerName  types.String `tfsdk:"storage_container_name"`
+	BlobName       types.String `tfsdk:"name"`
+	CreateBlob     types.Bool   `tfsdk:"create_blob"`
+	LeaseDuration  types.Int32  `tfsdk:"lease_duration"`
+	LeaseID        types.String `tfsdk:"lease_id"`
+	AcquireTimeout types.String `tfsdk:"acquire_timeout"`
+	BlobURL        types.String `tfsdk:"blob_url"`
+	LeaseExpiresAt types.String `tfsdk:"lease_expires_at"`
+}
+
+// ephemeralLease is the lease held by an ephemeral lease, kept in its private data
+type ephemeralLease struct {
+	StorageAccount string `json:"storage_account"`
+	BlobEndpoint   string `json:"blob_endpoint,omitempty"`
+	ContainerName  string `json:"container_name"`
+	BlobName       string `json:"blob_name"`
+	LeaseID        string `json:"lease_id"`
+	LeaseDuration  int32  `json:"lease_duration"`
+}
+
+// config returns the client lease config of the held lease
+func (l ephemeralLease) config() blobclient.BlobLeaseConfig {
+	return blobclient.BlobLeaseConfig{
+		StorageAccount: l.StorageAccount,
+		ContainerName:  l.ContainerName,
+		BlobName:       l.BlobName,
+		LeaseID:        l.LeaseID,
+		LeaseDuration:  l.LeaseDuration,
+	}
+}
+
+// renewAt returns when Terraform should renew a lease acquired or renewed at start: halfway
+// through a finite lease, and never for an infinite one
+func (l ephemeralLease) renewAt(start time.Time) time.Time {
+	if l.LeaseDuration <= 0 {
+		return time.Time{}
+	}
+	return start.Add(time.Duration(l.LeaseDuration) * time.Second / 2)
+}
+
+func (r *LeaseEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_lease"
+}
+
+func (r *LeaseEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Lease on an Azure Storage blob held only while a Terraform operation runs. It is acquired when Terraform opens the resource, renewed during the operation and released when the operation finishes, and is never written to state",
+
+		Attributes: map[string]schema.Attribute{
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name",
+				Required:            true,
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, e.g. a private endpoint or sovereign cloud URL, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`",
+				Optional:            true,
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"storage_container_name": schema.StringAttribute{
+				MarkdownDescription: "The container of the blob, which must exist",
+				Required:            true,
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The name of the blob to lease",
+				Required:            true,
+				Validators: []validator.String{
+					validators.BlobName(),
+				},
+			},
+			"create_blob": schema.BoolAttribute{
+				MarkdownDescription: "Whether to create the blob as an empty marker blob when it does not exist. The marker is left in place when the lease is released. Otherwise a missing blob fails. Defaults to `false`",
+				Optional:            true,
+			},
+			"lease_duration": schema.Int32Attribute{
+				MarkdownDescription: fmt.Sprintf("The lease duration in seconds, 15-60, or -1 for an infinite lease. A finite lease is renewed while the operation runs and lapses on its own if Terraform exits without releasing it; an infinite lease is then held until it is broken. Defaults to `%d`", defaultEphemeralLeaseDuration),
+				Optional:            true,
+				Validators: []validator.Int32{
+					leaseDurationValidator{},
+				},
+			},
+			"lease_id": schema.StringAttribute{
+				MarkdownDescription: "The proposed lease ID, a UUID; otherwise one is generated. Holds the ID of the acquired lease, for write-only arguments of other resources",
+				Optional:            true,
+				Computed:            true,
+				Sensitive:           true,
+				Validators: []validator.String{
+					uuidValidator{},
+				},
+			},
+			"acquire_timeout": schema.StringAttribute{
+				MarkdownDescription: "How long to wait for a lease held by someone else to be released before failing, e.g. `5m`. Unset or `0s` fails immediately",
+				Optional:            true,
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"blob_url": schema.StringAttribute{
+				MarkdownDescription: "The URL of the blob",
+				Computed:            true,
+			},
+			"lease_expires_at": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time at which a finite lease lapses unless renewed, as of acquisition. Null for infinite leases",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *LeaseEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+func (r *LeaseEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data LeaseEphemeralResourceModel
+
+	// Read Terraform config data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.BlobEndpoint.IsNull() {
+		if err := r.client.CheckBlobEndpoint(data.BlobEndpoint.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+			return
+		}
+	}
+
+	held := ephemeralLease{
+		StorageAccount: data.StorageAccount.ValueString(),
+		BlobEndpoint:   data.BlobEndpoint.ValueString(),
+		ContainerName:  data.ContainerName.ValueString(),
+		BlobName:       data.BlobName.ValueString(),
+		LeaseID:        data.LeaseID.ValueString(),
+		LeaseDuration:  defaultEphemeralLeaseDuration,
+	}
+	if !data.LeaseDuration.IsNull() {
+		held.LeaseDuration = data.LeaseDuration.ValueInt32()
+	}
+	if held.LeaseID == "" {
+		held.LeaseID = uuid.New().String()
+	}
+
+	// The value was validated as a duration at validation time
+	acquireTimeout, _ := time.ParseDuration(data.AcquireTimeout.ValueString())
+	ctx, cancel := context.WithTimeout(ctx, defaultCreateTimeout+acquireTimeout)
+	defer cancel()
+	ctx = blobclient.WithBlobEndpoint(ctx, held.BlobEndpoint)
+
+	config := held.config()
+	config.AcquireTimeout = acquireTimeout
+	result, diags := r.acquire(ctx, config, data.CreateBlob.ValueBool())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	held.LeaseID = result.LeaseID
+
+	private, err := json.Marshal(held)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Private State", fmt.Sprintf("Unable to record the lease in private state, got error: %s", err))
+		r.release(ctx, held, &resp.Diagnostics)
+		return
+	}
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, ephemeralLeasePrivateKey, private)...)
+	resp.RenewAt = held.renewAt(time.Now())
+
+	data.LeaseID = types.StringValue(held.LeaseID)
+	data.BlobURL = types.StringValue(result.BlobURL)
+	data.LeaseExpiresAt = timestampValue(result.LeaseExpiresOn)
+
+	// Save data into the ephemeral result
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
+
+// acquire acquires the lease of config on the blob, creating it as an empty marker blob first
+// when it is missing and createBlob is set. A wait for a lease held by someone else that timed
+// out is reported on acquire_timeout.
+func (r *LeaseEphemeralResource) acquire(ctx context.Context, config blobclient.BlobLeaseConfig, createBlob bool) (*blobclient.BlobLeaseResult, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	exists, err := r.client.BlobExists(ctx, config.StorageAccount, config.ContainerName, config.BlobName)
+	if err != nil {
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence, got error: %s", err))
+		return nil, diags
+	}
+
+	var result *blobclient.BlobLeaseResult
+	switch {
+	case exists:
+		result, err = r.client.AcquireBlobLease(ctx, config)
+	case createBlob:
+		config.SkipContainerCreate = true
+		result, err = r.client.CreateBlobWithLease(ctx, config)
+		// Someone else created the blob in the meantime
+		var existsErr *blobclient.BlobExistsError
+		if errors.As(err, &existsErr) {
+			result, err = r.client.AcquireBlobLease(ctx, config)
+		}
+	default:
+		diags.AddAttributeError(
+			path.Root("name"),
+			"Blob Not Found",
+			fmt.Sprintf("Blob %s does not exist in container %s of storage account %s. Set create_blob to create it as an empty marker blob.",
+				config.BlobName, config.ContainerName, config.StorageAccount),
+		)
+		return nil, diags
+	}
+
+	switch {
+	case errors.Is(err, blobclient.ErrLeaseWaitTimeout):
+		diags.AddAttributeError(path.Root("acquire_timeout"), "Lease Wait Timed Out", err.Error())
+	case errors.Is(err, blobclient.ErrContainerNotFound):
+		diags.AddAttributeError(
+			path.Root("storage_container_name"),
+			"Container Not Found",
+			fmt.Sprintf("Container %s does not exist in storage account %s. The ephemeral lease never creates containers.", config.ContainerName, config.StorageAccount),
+		)
+	case err != nil:
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to acquire lease on blob, got error: %s", err))
+	}
+	return result, diags
+}
+
+func (r *LeaseEphemeralResource) Renew(ctx context.Context, req ephemeral.RenewRequest, resp *ephemeral.RenewResponse) {
+	held, diags := r.heldLease(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultUpdateTimeout)
+	defer cancel()
+	ctx = blobclient.WithBlobEndpoint(ctx, held.BlobEndpoint)
+
+	// A lease that lapsed may have been taken by someone else since, so it is not acquired again
+	renewedAt := time.Now()
+	ok, err := r.client.ProbeBlobLease(ctx, held.config())
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to renew lease on blob %s, got error: %s", held.BlobName, err))
+		return
+	}
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Lease Lost",
+			fmt.Sprintf("The lease on blob %s is no longer held: it lapsed or was broken while the operation ran, and someone else may hold the blob now.",
+				blobLeaseID(held.StorageAccount, held.ContainerName, held.BlobName)),
+		)
+		return
+	}
+	resp.RenewAt = held.renewAt(renewedAt)
+}
+
+func (r *LeaseEphemeralResource) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	held, diags := r.heldLease(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultDeleteTimeout)
+	defer cancel()
+	ctx = blobclient.WithBlobEndpoint(ctx, held.BlobEndpoint)
+
+	r.release(ctx, held, &resp.Diagnostics)
+}
+
+// privateData is the private data of the Renew and Close requests of an ephemeral resource
+type privateData interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+// heldLease returns the lease recorded in the private data of an opened ephemeral lease
+func (r *LeaseEphemeralResource) heldLease(ctx context.Context, private privateData) (ephemeralLease, diag.Diagnostics) {
+	var held ephemeralLease
+
+	data, diags := private.GetKey(ctx, ephemeralLeasePrivateKey)
+	if diags.HasError() {
+		return held, diags
+	}
+	if err := json.Unmarshal(data, &held); err != nil {
+		diags.AddError("Invalid Private State", fmt.Sprintf("Unable to read the held lease from private state, got error: %s", err))
+	}
+	return held, diags
+}
+
+// release releases the held lease, leaving the blob in place. A lease that someone else holds
+// by now is theirs to release, and a deleted blob has no lease left.
+func (r *LeaseEphemeralResource) release(ctx context.Context, held ephemeralLease, diags *diag.Diagnostics) {
+	exists, err := r.client.BlobExists(ctx, held.StorageAccount, held.ContainerName, held.BlobName)
+	if err != nil {
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence, got error: %s", err))
+		return
+	}
+	if !exists {
+		return
+	}
+
+	config := held.config()
+	if err := r.client.ReleaseBlobLease(ctx, config, false); err != nil {
+		if ok, probeErr := r.client.ProbeBlobLease(ctx, config); probeErr == nil && !ok {
+			return
+		}
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to release lease on blob %s, got error: %s", held.BlobName, err))
+	}
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// leaseIDPrivateKey is the private state key of a generated lease ID that is not exposed as the
+// lease_id attribute
+const leaseIDPrivateKey = "lease_id"
+
+// privateState is the private state of requests and responses, which the framework only offers
+// as an internal type
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// leaseIDHidden reports whether the lease ID of data is kept in private state instead of the
+// lease_id attribute. A configured lease ID is already in the configuration, so it stays in state
+// like before; otherwise it is hidden unless expose_lease_id is set.
+func leaseIDHidden(ctx context.Context, config tfsdk.Config, data BlobLeaseResourceModel) (bool, diag.Diagnostics) {
+	var configured types.String
+	diags := config.GetAttribute(ctx, path.Root("lease_id"), &configured)
+	return configured.IsNull() && !data.ExposeLeaseID.ValueBool(), diags
+}
+
+// privateLeaseID returns the lease ID kept in private state, or "" when there is none
+func privateLeaseID(ctx context.Context, private privateState) (string, diag.Diagnostics) {
+	value, diags := private.GetKey(ctx, leaseIDPrivateKey)
+	if diags.HasError() || len(value) == 0 {
+		return "", diags
+	}
+
+	var leaseID string
+	if err := json.Unmarshal(value, &leaseID); err != nil {
+		diags.AddError("Invalid Private State", fmt.Sprintf("Unable to read the lease ID from private state, got error: %s", err))
+		return "", diags
+	}
+	return leaseID, diags
+}
+
+// heldLeaseID returns the lease ID of data, taken from private state when lease_id is hidden
+func heldLeaseID(ctx context.Context, data BlobLeaseResourceModel, private privateState) (string, diag.Diagnostics) {
+	if !data.LeaseID.IsNull() {
+		return data.LeaseID.ValueString(), nil
+	}
+	return privateLeaseID(ctx, private)
+}
+
+// resolveLeaseID sets a lease_id that is null in data to the lease ID kept in private state, so
+// the lease is handled the same whether it is hidden or not
+func resolveLeaseID(ctx context.Context, data *BlobLeaseResourceModel, private privateState) diag.Diagnostics {
+	leaseID, diags := heldLeaseID(ctx, *data, private)
+	if data.LeaseID.IsNull() && leaseID != "" {
+		data.LeaseID = types.StringValue(leaseID)
+	}
+	return diags
+}
+
+// storeLeaseID moves the lease ID of data into private state and sets lease_id to null when
+// hidden is set. Otherwise lease_id is kept and any lease ID in private state is removed.
+func storeLeaseID(ctx context.Context, data *BlobLeaseResourceModel, hidden bool, private privateState) diag.Diagnostics {
+	if !hidden || data.LeaseID.IsNull() || data.LeaseID.IsUnknown() || data.LeaseID.ValueString() == "" {
+		if hidden {
+			data.LeaseID = types.StringNull()
+		}
+		return private.SetKey(ctx, leaseIDPrivateKey, nil)
+	}
+
+	value, err := json.Marshal(data.LeaseID.ValueString())
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Invalid Private State", fmt.Sprintf("Unable to store the lease ID in private state, got error: %s", err))
+		return diags
+	}
+	data.LeaseID = types.StringNull()
+	return private.SetKey(ctx, leaseIDPrivateKey, value)
+}
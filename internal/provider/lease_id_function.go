@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &LeaseIDFunction{}
+
+// leaseIDNamespace is the UUIDv5 namespace of lease_id, itself the UUIDv5 of the provider registry
+// URL https://registry.terraform.io/providers/360build/blobleas in the URL namespace. It must
+// never change, since that would change every lease ID derived from a seed.
+var leaseIDNamespace = uuid.MustParse("970bcb3c-aa80-5c05-bf98-a8a29a0c81b9")
+
+func NewLeaseIDFunction() function.Function {
+	return &LeaseIDFunction{}
+}
+
+// LeaseIDFunction derives a lease ID from a seed, so that the same seed always gives the same ID
+type LeaseIDFunction struct{}
+
+func (f *LeaseIDFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "lease_id"
+}
+
+func (f *LeaseIDFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Derives a deterministic lease ID from a seed",
+		MarkdownDescription: "Returns the UUIDv5 of the seed in a namespace of the provider, a lease ID for the `lease_id` argument that is the same on every run for the same seed. Anyone who knows the seed can compute the lease ID and so act on the lease, so the seed must be treated as a secret",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "seed",
+				MarkdownDescription: "The seed of the lease ID. Must not be empty",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *LeaseIDFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var seed string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &seed))
+	if resp.Error != nil {
+		return
+	}
+
+	if seed == "" {
+		resp.Error = function.NewArgumentFuncError(0, "seed must not be empty; an empty seed would give every caller the same lease ID")
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, uuid.NewSHA1(leaseIDNamespace, []byte(seed)).String()))
+}
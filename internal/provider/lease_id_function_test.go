@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestLeaseIDNamespace(t *testing.T) {
+	want := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://registry.terraform.io/providers/360build/blobleas"))
+	if leaseIDNamespace != want {
+		t.Errorf("expected the namespace %s, got %s", want, leaseIDNamespace)
+	}
+}
+
+func TestLeaseIDFunction(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	for name, tc := range map[string]struct {
+		seed    string
+		want    string
+		wantErr string
+	}{
+		// The documented example, which must never change
+		"documented seed": {seed: "blue", want: "6f19fd39-0c8e-5dde-b640-65f641d9d5dc"},
+		"other seed":      {seed: "green", want: uuid.NewSHA1(leaseIDNamespace, []byte("green")).String()},
+		"empty seed":      {seed: "", wantErr: "seed must not be empty"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, funcErr := p.callFunction("lease_id", tc.seed)
+			if tc.wantErr != "" {
+				if funcErr == nil || !strings.Contains(funcErr.Text, tc.wantErr) {
+					t.Fatalf("expected an error containing %q, got %v", tc.wantErr, funcErr)
+				}
+				if funcErr.FunctionArgument == nil || *funcErr.FunctionArgument != 0 {
+					t.Errorf("expected the error to point at the seed argument, got %v", funcErr.FunctionArgument)
+				}
+				return
+			}
+			if funcErr != nil {
+				t.Fatalf("unexpected error: %s", funcErr.Text)
+			}
+			var got string
+			if err := result.As(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+			if id, err := uuid.Parse(got); err != nil || id.Version() != 5 {
+				t.Errorf("expected a version 5 UUID, got %s", got)
+			}
+		})
+	}
+}
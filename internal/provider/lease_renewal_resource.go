@@ -0,0 +1,374 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/lease"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &LeaseRenewalResource{}
+var _ resource.ResourceWithModifyPlan = &LeaseRenewalResource{}
+
+func NewLeaseRenewalResource() resource.Resource {
+	return &LeaseRenewalResource{now: time.Now}
+}
+
+// LeaseRenewalResource renews a lease held by another resource whenever it is created or its
+// triggers change. It never acquires a lease that is no longer held, since the lease belongs to
+// the resource that acquired it.
+type LeaseRenewalResource struct {
+	client *blobclient.AzureBlobLeaseClient
+	// now is the clock the remaining lease validity is measured with
+	now func() time.Time
+}
+
+// LeaseRenewalResourceModel describes the resource data model.
+type LeaseRenewalResourceModel struct {
+	ID               types.String `tfsdk:"id"`
+	StorageAccount   types.String `tfsdk:"storage_account_name"`
+	BlobEndpoint     types.String `tfsdk:"blob_endpoint"`
+	ContainerName    types.String `tfsdk:"storage_container_name"`
+	BlobName         types.String `tfsdk:"name"`
+	LeaseID          types.String `tfsdk:"lease_id"`
+	LeaseDuration    types.Int32  `tfsdk:"lease_duration"`
+	Triggers         types.Map    `tfsdk:"triggers"`
+	RenewedAt        types.String `tfsdk:"renewed_at"`
+	LeaseExpiresAt   types.String `tfsdk:"lease_expires_at"`
+	RemainingSeconds types.Int64  `tfsdk:"lease_remaining_seconds"`
+	LeaseState       types.String `tfsdk:"lease_state"`
+	Timeouts         types.Object `tfsdk:"timeouts"`
+}
+
+func (r *LeaseRenewalResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_lease_renewal"
+}
+
+func (r *LeaseRenewalResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Renews a blob lease held by another resource whenever its triggers change",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name. Changing it forces a new resource",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`. Changing it forces a new resource",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"storage_container_name": schema.StringAttribute{
+				MarkdownDescription: "The container of the blob. Changing it forces a new resource",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The name of the leased blob. Changing it forces a new resource",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.BlobName(),
+				},
+			},
+			"lease_id": schema.StringAttribute{
+				MarkdownDescription: "The ID of the lease to renew, typically the `lease_id` of a `blobleas_blob_lease`. Changing it renews the lease with the new ID",
+				Required:            true,
+				Sensitive:           true,
+				Validators: []validator.String{
+					uuidValidator{},
+				},
+			},
+			"lease_duration": schema.Int32Attribute{
+				MarkdownDescription: "The duration in seconds the lease was acquired with, typically the `lease_duration` of the owning resource, from which `lease_expires_at` is tracked. Unset or -1 for an infinite lease, which has no expiry",
+				Optional:            true,
+				Validators: []validator.Int32{
+					leaseDurationValidator{},
+				},
+			},
+			"triggers": schema.MapAttribute{
+				MarkdownDescription: "Arbitrary values that renew the lease when they change",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"renewed_at": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time of the last renewal by this resource",
+				Computed:            true,
+			},
+			"lease_expires_at": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time at which a time-limited lease lapses unless renewed again, null for infinite leases",
+				Computed:            true,
+			},
+			"lease_remaining_seconds": schema.Int64Attribute{
+				MarkdownDescription: "How many seconds of `lease_expires_at` were left at the last refresh, `0` when the lease is no longer held and null for infinite leases",
+				Computed:            true,
+			},
+			"lease_state": schema.StringAttribute{
+				MarkdownDescription: "The current lease state of the blob",
+				Computed:            true,
+			},
+			"timeouts": timeoutsAttribute(),
+		},
+	}
+}
+
+func (r *LeaseRenewalResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// ModifyPlan rejects an http blob_endpoint the provider does not allow
+func (r *LeaseRenewalResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var endpoint types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("blob_endpoint"), &endpoint)...)
+	if !endpoint.IsNull() && !endpoint.IsUnknown() {
+		if err := r.client.CheckBlobEndpoint(endpoint.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+		}
+	}
+}
+
+// remainingSeconds returns how many seconds are left until expiresAt at now, 0 once it has passed
+// or when the lease is not held, and null without an expiry
+func remainingSeconds(expiresAt, leaseState types.String, now time.Time) types.Int64 {
+	if leaseState.ValueString() != string(lease.StateTypeLeased) {
+		return types.Int64Value(0)
+	}
+	if expiresAt.IsNull() || expiresAt.IsUnknown() {
+		return types.Int64Null()
+	}
+
+	expiry, err := time.Parse(time.RFC3339, expiresAt.ValueString())
+	if err != nil {
+		return types.Int64Null()
+	}
+	return types.Int64Value(max(int64(expiry.Sub(now)/time.Second), 0))
+}
+
+// lostLeaseRenewalError explains that the lease of data cannot be renewed because it is no longer
+// held, which only the resource that acquired it can fix
+func lostLeaseRenewalError(data LeaseRenewalResourceModel, leaseState string, err error) diag.Diagnostic {
+	blobPath := blobLeaseID(data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString())
+	summary := "Lease No Longer Held"
+	if leaseState == string(lease.StateTypeExpired) {
+		summary = "Lease Expired"
+	}
+
+	detail := fmt.Sprintf("The lease on blob %s cannot be renewed with lease_id because it is no longer held (observed lease state: %s). "+
+		"A renewal never acquires the lease again, since it belongs to the resource that acquired it. "+
+		"Replace or reapply that resource, e.g. terraform apply -replace=blobleas_blob_lease.<name>, to acquire a new lease, then apply this resource again. "+
+		"Renew more often than lease_duration if the lease keeps expiring.", blobPath, leaseState)
+	if err != nil {
+		detail += fmt.Sprintf(" Renewal error: %s", err)
+	}
+	return diag.NewAttributeErrorDiagnostic(path.Root("lease_id"), summary, detail)
+}
+
+// renew renews the lease of data and records when, failing with a pointed error when the lease
+// is no longer held
+func (r *LeaseRenewalResource) renew(ctx context.Context, data *LeaseRenewalResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	config := blobclient.BlobLeaseConfig{
+		StorageAccount: data.StorageAccount.ValueString(),
+		ContainerName:  data.ContainerName.ValueString(),
+		BlobName:       data.BlobName.ValueString(),
+		LeaseID:        data.LeaseID.ValueString(),
+		LeaseDuration:  data.LeaseDuration.ValueInt32(),
+	}
+	if data.LeaseDuration.IsNull() {
+		config.LeaseDuration = -1
+	}
+
+	// A lease that is not leased is reported by its state; one lost after this check fails the
+	// renewal, which never acquires it, as that is not this resource's to do
+	existing, err := r.client.GetBlobLeaseState(ctx, config.StorageAccount, config.ContainerName, config.BlobName)
+	if err != nil {
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read blob lease state, got error: %s", err))
+		return diags
+	}
+	if existing.LeaseState != string(lease.StateTypeLeased) {
+		diags.Append(lostLeaseRenewalError(*data, existing.LeaseState, nil))
+		return diags
+	}
+
+	renewedAt := r.now()
+	result, err := r.client.RenewHeldBlobLease(ctx, config)
+	if err != nil {
+		if errors.Is(err, blobclient.ErrLeaseLost) {
+			// The lease was lost since the check; the error names the state it was lost to
+			leaseState := leaseStateLeasedByOther
+			if current, stateErr := r.client.GetBlobLeaseState(ctx, config.StorageAccount, config.ContainerName, config.BlobName); stateErr == nil && current.LeaseState != string(lease.StateTypeLeased) {
+				leaseState = current.LeaseState
+			}
+			diags.Append(lostLeaseRenewalError(*data, leaseState, err))
+			return diags
+		}
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to renew lease, got error: %s", err))
+		return diags
+	}
+
+	data.RenewedAt = timestampValue(&renewedAt)
+	data.LeaseExpiresAt = timestampValue(result.LeaseExpiresOn)
+	data.LeaseState = types.StringValue(result.LeaseState)
+	data.RemainingSeconds = remainingSeconds(data.LeaseExpiresAt, data.LeaseState, renewedAt)
+	return diags
+}
+
+func (r *LeaseRenewalResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data LeaseRenewalResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := operationTimeout(data.Timeouts, "create", defaultCreateTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "create", data.BlobName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
+	resp.Diagnostics.Append(r.renew(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.ID = types.StringValue(blobLeaseID(data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString()))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *LeaseRenewalResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data LeaseRenewalResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := operationTimeout(data.Timeouts, "read", defaultReadTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "read", data.BlobName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
+	exists, err := r.client.BlobExists(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence, got error: %s", err))
+		return
+	}
+	if !exists {
+		// Blob was deleted outside of Terraform, which also ended the lease
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// The expiry is tracked from the last renewal; refresh only reports what is left of it
+	leaseResult, err := r.client.GetBlobLeaseState(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read blob lease state, got error: %s", err))
+		return
+	}
+	if !leaseResult.StaleRead {
+		data.LeaseState = types.StringValue(leaseResult.LeaseState)
+	}
+	data.RemainingSeconds = remainingSeconds(data.LeaseExpiresAt, data.LeaseState, r.now())
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update renews the lease whenever an argument that does not force a new resource changes,
+// triggers in particular
+func (r *LeaseRenewalResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data LeaseRenewalResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := operationTimeout(data.Timeouts, "update", defaultUpdateTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "update", data.BlobName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
+	resp.Diagnostics.Append(r.renew(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete only removes the resource from state; the lease stays with the resource that holds it
+func (r *LeaseRenewalResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+const leaseRenewalType = "blobleas_lease_renewal"
+
+func TestLeaseRenewalLeaseLostAfterCheck(t *testing.T) {
+	// Each case changes the lease after the renewal has found it leased, before it is renewed
+	for name, tc := range map[string]struct {
+		lose      func(p *testProvider, now *time.Time)
+		wantErr   string
+		wantLease string // the lease ID the blob is left with after a failed renewal, empty when none
+	}{
+		"released": {
+			lose: func(p *testProvider, now *time.Time) {
+				p.server.SetLease(testAccount, testContainer, "env/app.lock", "")
+			},
+			wantErr: "observed lease state: available",
+		},
+		"expired and leased again": {
+			lose: func(p *testProvider, now *time.Time) {
+				*now = now.Add(20 * time.Second)
+				p.server.SetLease(testAccount, testContainer, "env/app.lock", otherLeaseID)
+			},
+			wantErr:   "observed lease state: leased-by-other",
+			wantLease: otherLeaseID,
+		},
+		"expired": {
+			// The service renews an expired lease that nobody leased since under the same ID
+			lose: func(p *testProvider, now *time.Time) {
+				*now = now.Add(20 * time.Second)
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := newTestProvider(t, nil)
+			now := time.Now().UTC().Truncate(time.Second)
+			p.server.SetNow(func() time.Time { return now })
+			state := p.mustApply(blobLeaseType, nil, blobLeaseConfig(map[string]any{"lease_duration": 15}))
+			leaseID := leaseIDOf(t, state)
+
+			// The lease state is checked with the first request; the lease changes before the next
+			checked, lost := false, false
+			p.server.Intercept(func(req *http.Request) *http.Response {
+				switch {
+				case lost:
+				case checked:
+					tc.lose(p, &now)
+					lost = true
+				case req.Method == http.MethodHead:
+					checked = true
+				}
+				return nil
+			})
+			_, diags := p.apply(leaseRenewalType, nil, map[string]any{
+				"storage_account_name":   testAccount,
+				"storage_container_name": testContainer,
+				"name":                   "env/app.lock",
+				"lease_id":               leaseID,
+				"lease_duration":         15,
+			})
+			if !lost {
+				t.Fatal("expected the lease to change during the renewal")
+			}
+
+			blob, _ := p.server.Blob(testAccount, testContainer, "env/app.lock")
+			if tc.wantErr == "" {
+				requireNoErrors(t, "apply", diags)
+				if blob.LeaseID != leaseID || blob.LeaseState != "leased" {
+					t.Errorf("expected the lease %s to be renewed, got %s (%s)", leaseID, blob.LeaseID, blob.LeaseState)
+				}
+				return
+			}
+			requireError(t, diags, tc.wantErr)
+			// The renewal never leases the blob again
+			got := blob.LeaseID
+			if blob.LeaseState == "available" {
+				got = ""
+			}
+			if got != tc.wantLease {
+				t.Errorf("expected the blob to be left with lease %q, got %q (%s)", tc.wantLease, got, blob.LeaseState)
+			}
+		})
+	}
+}
@@ -0,0 +1,564 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &LeaseResource{}
+var _ resource.ResourceWithImportState = &LeaseResource{}
+var _ resource.ResourceWithModifyPlan = &LeaseResource{}
+
+func NewLeaseResource() resource.Resource {
+	return &LeaseResource{}
+}
+
+// LeaseResource manages a lease on a blob that exists independently of Terraform. It only ever
+// acquires, renews, changes and releases the lease; it never writes or deletes the blob.
+type LeaseResource struct {
+	client *blobclient.AzureBlobLeaseClient
+}
+
+// LeaseResourceModel describes the resource data model.
+type LeaseResourceModel struct {
+	ID                types.String `tfsdk:"id"`
+	StorageAccount    types.String `tfsdk:"storage_account_name"`
+	BlobEndpoint      types.String `tfsdk:"blob_endpoint"`
+	ContainerName     types.String `tfsdk:"storage_container_name"`
+	BlobName          types.String `tfsdk:"name"`
+	LeaseDuration     types.Int32  `tfsdk:"lease_duration"`
+	LeaseID           types.String `tfsdk:"lease_id"`
+	AcquireTimeout    types.String `tfsdk:"acquire_timeout"`
+	BlobURL           types.String `tfsdk:"blob_url"`
+	LeaseExpiresAt    types.String `tfsdk:"lease_expires_at"`
+	LeaseState        types.String `tfsdk:"lease_state"`
+	LeaseStatus       types.String `tfsdk:"lease_status"`
+	LeaseDurationKind types.String `tfsdk:"lease_duration_kind"`
+	Timeouts          types.Object `tfsdk:"timeouts"`
+}
+
+func (r *LeaseResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_lease"
+}
+
+func (r *LeaseResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Lease on an existing Azure Storage blob. The blob is never written or deleted",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name. Changing it forces a new resource",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, e.g. a private endpoint or sovereign cloud URL, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`. Changing it forces a new resource",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"storage_container_name": schema.StringAttribute{
+				MarkdownDescription: "The container of the blob. Changing it forces a new resource",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The name of the existing blob to lease. Changing it forces a new resource",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.BlobName(),
+				},
+			},
+			"lease_duration": schema.Int32Attribute{
+				MarkdownDescription: "The lease duration in seconds. Use -1 for infinite lease (default), or 15-60 for time-limited lease. Changing it re-acquires the held lease with the new duration",
+				Optional:            true,
+				Computed:            true,
+				Default:             int32default.StaticInt32(-1),
+				Validators: []validator.Int32{
+					leaseDurationValidator{},
+				},
+			},
+			"lease_id": schema.StringAttribute{
+				MarkdownDescription: "The proposed lease ID, a UUID; otherwise one is generated. Changing it changes the ID of the held lease in place",
+				Optional:            true,
+				Computed:            true,
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+				Validators: []validator.String{
+					uuidValidator{},
+				},
+			},
+			"acquire_timeout": schema.StringAttribute{
+				MarkdownDescription: "How long to wait for a lease held by someone else to be released before failing, e.g. `5m`. Unset or `0s` fails immediately",
+				Optional:            true,
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"timeouts": timeoutsAttribute(),
+			"blob_url": schema.StringAttribute{
+				MarkdownDescription: "The URL of the blob",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"lease_expires_at": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time at which a time-limited lease lapses unless renewed, tracked from the last acquisition or renewal by the provider. Null for infinite leases",
+				Computed:            true,
+			},
+			"lease_state": schema.StringAttribute{
+				MarkdownDescription: "The current lease state of the blob, or `leased-by-other` when it is leased with a different lease ID",
+				Computed:            true,
+			},
+			"lease_status": schema.StringAttribute{
+				MarkdownDescription: "The current lease status of the blob, `locked` or `unlocked`",
+				Computed:            true,
+			},
+			"lease_duration_kind": schema.StringAttribute{
+				MarkdownDescription: "Whether the current lease on the blob is `fixed` or `infinite`, empty when the blob is not leased",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *LeaseResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// ModifyPlan plans an update that acquires the lease again when refresh shows it is no longer
+// held, so a lost lease is taken back by the next apply
+func (r *LeaseResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	// Whether an http endpoint is allowed is only known once the provider is configured
+	var endpoint types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("blob_endpoint"), &endpoint)...)
+	if r.client != nil && !endpoint.IsNull() && !endpoint.IsUnknown() {
+		if err := r.client.CheckBlobEndpoint(endpoint.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Two resources managing the same blob would take the lease from each other
+	claimBlobTarget(ctx, r.client, req, resp)
+	if resp.Diagnostics.HasError() || req.State.Raw.IsNull() {
+		return
+	}
+
+	var plan, state LeaseResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// An imported lease has no known lease ID, so it cannot be held by this resource
+	if state.LeaseState.ValueString() == "leased" && !state.LeaseID.IsNull() {
+		if !plan.LeaseDuration.Equal(state.LeaseDuration) {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("lease_expires_at"), types.StringUnknown())...)
+		}
+		return
+	}
+
+	// The apply acquires the lease, which changes these attributes
+	for _, name := range []string{"lease_state", "lease_status", "lease_duration_kind", "lease_expires_at"} {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(name), types.StringUnknown())...)
+	}
+	if plan.LeaseID.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("lease_id"), types.StringUnknown())...)
+	}
+
+	blobPath := blobLeaseID(state.StorageAccount.ValueString(), state.ContainerName.ValueString(), state.BlobName.ValueString())
+	resp.Diagnostics.AddWarning(
+		"Lease Not Held",
+		fmt.Sprintf("The lease on blob %s is not held by this resource (observed lease state: %s). The apply will try to acquire it, without touching the blob.", blobPath, state.LeaseState.ValueString()),
+	)
+}
+
+// leaseConfig builds the client lease config of data, with the lease held or proposed as leaseID
+func leaseConfig(data LeaseResourceModel, leaseID string) blobclient.BlobLeaseConfig {
+	// The value was validated as a duration at plan time
+	timeout, _ := time.ParseDuration(data.AcquireTimeout.ValueString())
+	return blobclient.BlobLeaseConfig{
+		StorageAccount: data.StorageAccount.ValueString(),
+		ContainerName:  data.ContainerName.ValueString(),
+		BlobName:       data.BlobName.ValueString(),
+		LeaseID:        leaseID,
+		LeaseDuration:  data.LeaseDuration.ValueInt32(),
+		AcquireTimeout: timeout,
+	}
+}
+
+// refreshLease records the lease of result in data
+func refreshLease(data *LeaseResourceModel, result *blobclient.BlobLeaseResult) {
+	data.LeaseID = types.StringValue(result.LeaseID)
+	data.BlobURL = types.StringValue(result.BlobURL)
+	data.LeaseState = types.StringValue(result.LeaseState)
+	data.LeaseStatus = leaseStatusValue(result)
+	data.LeaseDurationKind = leaseDurationKindValue(result, data.LeaseDuration)
+	data.LeaseExpiresAt = timestampValue(result.LeaseExpiresOn)
+}
+
+// acquireLease acquires the lease of config on the blob and records it in data. A wait for a lease
+// held by someone else that timed out is reported on acquire_timeout.
+func (r *LeaseResource) acquireLease(ctx context.Context, data *LeaseResourceModel, config blobclient.BlobLeaseConfig) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	result, err := r.client.AcquireBlobLease(ctx, config)
+	if errors.Is(err, blobclient.ErrLeaseWaitTimeout) {
+		diags.AddAttributeError(path.Root("acquire_timeout"), "Lease Wait Timed Out", err.Error())
+		return diags
+	}
+	if err != nil {
+		diags.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to acquire lease on blob, got error: %s", err))
+		return diags
+	}
+	refreshLease(data, result)
+	return diags
+}
+
+func (r *LeaseResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data LeaseResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := operationTimeout(data.Timeouts, "create", defaultCreateTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "create", data.BlobName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
+	// The blob belongs to someone else, so it is never created here
+	exists, err := r.client.BlobExists(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence, got error: %s", err))
+		return
+	}
+	if !exists {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Blob Not Found",
+			fmt.Sprintf("Blob %s does not exist in container %s of storage account %s. blobleas_lease only leases existing blobs; use blobleas_blob_lease to create the blob.",
+				data.BlobName.ValueString(), data.ContainerName.ValueString(), data.StorageAccount.ValueString()),
+		)
+		return
+	}
+
+	leaseID := data.LeaseID.ValueString()
+	if data.LeaseID.IsNull() || data.LeaseID.IsUnknown() {
+		leaseID = uuid.New().String()
+	}
+
+	resp.Diagnostics.Append(r.acquireLease(ctx, &data, leaseConfig(data, leaseID))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.ID = types.StringValue(blobLeaseID(data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString()))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *LeaseResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data LeaseResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := operationTimeout(data.Timeouts, "read", defaultReadTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "read", data.BlobName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
+	exists, err := r.client.BlobExists(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence, got error: %s", err))
+		return
+	}
+	if !exists {
+		// Blob was deleted outside of Terraform, which also ended the lease
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	leaseResult, err := r.client.GetBlobLeaseState(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read blob lease state, got error: %s", err))
+		return
+	}
+
+	// A read served by the secondary endpoint may lag the primary, so keep the prior state
+	if leaseResult.StaleRead {
+		resp.Diagnostics.AddWarning(
+			"Stale Read From Secondary Endpoint",
+			fmt.Sprintf("The primary endpoint of storage account %s is unavailable; lease state for blob %s was read from the secondary endpoint and may be out of date. Keeping the previously known state.",
+				data.StorageAccount.ValueString(), data.BlobName.ValueString()),
+		)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	data.BlobURL = types.StringValue(leaseResult.BlobURL)
+	data.LeaseState = types.StringValue(leaseResult.LeaseState)
+	data.LeaseStatus = leaseStatusValue(leaseResult)
+	data.LeaseDurationKind = leaseDurationKindValue(leaseResult, data.LeaseDuration)
+
+	// A leased blob may be leased by someone else, which only a renewal with the lease ID tells
+	if leaseResult.LeaseState == "leased" && !data.LeaseID.IsNull() {
+		held, err := r.client.ProbeBlobLease(ctx, leaseConfig(data, data.LeaseID.ValueString()))
+		if err != nil {
+			resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob lease, got error: %s", err))
+			return
+		}
+		if !held {
+			data.LeaseState = types.StringValue(leaseStateLeasedByOther)
+		} else if data.LeaseDuration.ValueInt32() > 0 {
+			// The probe renewed the lease
+			expiry := time.Now().Add(time.Duration(data.LeaseDuration.ValueInt32()) * time.Second)
+			data.LeaseExpiresAt = timestampValue(&expiry)
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *LeaseResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state LeaseResourceModel
+
+	// Read Terraform plan and prior state data into the models
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := operationTimeout(plan.Timeouts, "update", defaultUpdateTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "update", plan.BlobName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, plan.BlobEndpoint.ValueString())
+
+	// The lease ID to hold after the update: the configured one, or else the one in state
+	leaseID := state.LeaseID.ValueString()
+	target := leaseID
+	if !plan.LeaseID.IsNull() && !plan.LeaseID.IsUnknown() {
+		target = plan.LeaseID.ValueString()
+	}
+	if target == "" {
+		target = uuid.New().String()
+	}
+	held := state.LeaseState.ValueString() == "leased" && leaseID != ""
+
+	// A held lease is changed to a new ID in place, or renewed
+	if held && target != leaseID {
+		result, err := r.client.ChangeBlobLease(ctx, leaseConfig(plan, leaseID), target)
+		if err == nil {
+			leaseID = result.LeaseID
+		}
+		held = err == nil
+	}
+	if held && plan.LeaseDuration.Equal(state.LeaseDuration) {
+		result, err := r.client.RenewBlobLease(ctx, leaseConfig(plan, leaseID))
+		if err == nil {
+			refreshLease(&plan, result)
+		}
+		held = err == nil
+	} else {
+		held = false
+	}
+
+	// Otherwise the lease is acquired, which re-acquires a lease held with the same ID in place
+	// with the new duration
+	if !held {
+		resp.Diagnostics.Append(r.acquireLease(ctx, &plan, leaseConfig(plan, target))...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *LeaseResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data LeaseResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// An imported lease whose ID was never known is not released
+	if data.LeaseID.IsNull() {
+		return
+	}
+
+	timeout := operationTimeout(data.Timeouts, "delete", defaultDeleteTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer addTimeoutError(ctx, &resp.Diagnostics, "delete", data.BlobName.ValueString(), timeout)
+	ctx = blobclient.WithBlobEndpoint(ctx, data.BlobEndpoint.ValueString())
+
+	// Release the lease only; the blob is left in place
+	exists, err := r.client.BlobExists(ctx, data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence, got error: %s", err))
+		return
+	}
+	if !exists {
+		return
+	}
+	config := leaseConfig(data, data.LeaseID.ValueString())
+	if err := r.client.ReleaseBlobLease(ctx, config, false); err != nil {
+		// A lease someone else took over is theirs to release
+		if held, probeErr := r.client.ProbeBlobLease(ctx, config); probeErr == nil && !held {
+			return
+		}
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to release lease, got error: %s", err))
+	}
+}
+
+func (r *LeaseResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+
+	// Import format: storage_account/container_name/blob_name, optionally followed by
+	// ;blob_endpoint, as for blobleas_blob_lease
+	storageAccount, containerName, blobName, endpoint, err := parseImportID(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: storage_account/container_name/blob_name or storage_account/container_name/blob_name;blob_endpoint. Got: %s (%s)", req.ID, err),
+		)
+		return
+	}
+	if endpoint != "" {
+		if err := r.client.CheckBlobEndpoint(endpoint); err != nil {
+			resp.Diagnostics.AddError("Invalid Blob Endpoint", err.Error())
+			return
+		}
+	}
+	ctx = blobclient.WithBlobEndpoint(ctx, endpoint)
+	id := blobLeaseID(storageAccount, containerName, blobName)
+
+	exists, err := r.client.BlobExists(ctx, storageAccount, containerName, blobName)
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to check blob existence during import, got error: %s", err))
+		return
+	}
+	if !exists {
+		resp.Diagnostics.AddError(
+			"Resource Not Found",
+			fmt.Sprintf("Blob %s does not exist in container %s of storage account %s", blobName, containerName, storageAccount),
+		)
+		return
+	}
+
+	leaseResult, err := r.client.GetBlobLeaseState(ctx, storageAccount, containerName, blobName)
+	if err != nil {
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to read blob lease state during import, got error: %s", err))
+		return
+	}
+
+	// The lease is adopted as found; its ID is not visible on the blob, so the next apply
+	// acquires it unless lease_id is configured with the ID it is held with
+	var data LeaseResourceModel
+	data.ID = types.StringValue(id)
+	data.StorageAccount = types.StringValue(storageAccount)
+	data.BlobEndpoint = stringOrNull(endpoint)
+	data.ContainerName = types.StringValue(containerName)
+	data.BlobName = types.StringValue(blobName)
+	data.LeaseDuration = types.Int32Value(-1)
+	data.LeaseID = types.StringNull()
+	data.AcquireTimeout = types.StringNull()
+	data.BlobURL = types.StringValue(leaseResult.BlobURL)
+	data.LeaseExpiresAt = types.StringNull()
+	data.LeaseState = types.StringValue(leaseResult.LeaseState)
+	data.LeaseStatus = leaseStatusValue(leaseResult)
+	data.LeaseDurationKind = leaseDurationKindValue(leaseResult, data.LeaseDuration)
+	data.Timeouts = types.ObjectNull(timeoutsAttrTypes)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
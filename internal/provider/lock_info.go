@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// lockInfoDocument is the blob content written with write_lock_info, modelled on the lock info
+// Terraform writes for its own state locks, so the blob describes who holds the lease
+type lockInfoDocument struct {
+	ID        string            `json:"ID"`
+	Operation string            `json:"Operation"`
+	Who       string            `json:"Who"`
+	Hostname  string            `json:"Hostname"`
+	Username  string            `json:"Username"`
+	Created   string            `json:"Created"`
+	Info      map[string]string `json:"Info,omitempty"`
+}
+
+// newLockInfo generates the lock document for an acquisition by operation, with the lock_info
+// entries of data. Every call returns a new ID.
+func newLockInfo(ctx context.Context, data BlobLeaseResourceModel, operation string, now time.Time) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	doc := lockInfoDocument{
+		ID:        uuid.New().String(),
+		Operation: operation,
+		Hostname:  "unknown",
+		Username:  "unknown",
+		Created:   now.UTC().Format(time.RFC3339),
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		doc.Hostname = hostname
+	}
+	if current, err := user.Current(); err == nil {
+		doc.Username = current.Username
+	} else if name := os.Getenv("USER"); name != "" {
+		doc.Username = name
+	}
+	doc.Who = doc.Username + "@" + doc.Hostname
+
+	if !data.LockInfo.IsNull() && !data.LockInfo.IsUnknown() {
+		diags.Append(data.LockInfo.ElementsAs(ctx, &doc.Info, false)...)
+		if diags.HasError() {
+			return "", diags
+		}
+	}
+
+	content, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		diags.AddError("Lock Info Error", fmt.Sprintf("Unable to encode the lock document, got error: %s", err))
+		return "", diags
+	}
+	return string(content), diags
+}
+
+// lockInfoDue reports whether an update planned from state to plan writes a new lock document:
+// when write_lock_info is newly enabled or the document is gone, when the lease is acquired again
+// or changes its ID, and when the lock_info entries change. Only call it when plan has
+// write_lock_info set.
+func lockInfoDue(plan, state BlobLeaseResourceModel, lost bool) bool {
+	return lost || state.LockInfoDocument.IsNull() || plan.LeaseID.IsUnknown() || !plan.LeaseID.Equal(state.LeaseID) || !plan.LockInfo.Equal(state.LockInfo)
+}
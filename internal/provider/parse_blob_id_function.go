@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ParseBlobIDFunction{}
+
+func NewParseBlobIDFunction() function.Function {
+	return &ParseBlobIDFunction{}
+}
+
+// ParseBlobIDFunction splits the ID of a blob resource into its components
+type ParseBlobIDFunction struct{}
+
+// parsedBlobID is the object returned by parse_blob_id
+type parsedBlobID struct {
+	StorageAccount types.String `tfsdk:"storage_account"`
+	ContainerName  types.String `tfsdk:"container_name"`
+	BlobName       types.String `tfsdk:"blob_name"`
+	BlobEndpoint   types.String `tfsdk:"blob_endpoint"`
+}
+
+func (f *ParseBlobIDFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_blob_id"
+}
+
+func (f *ParseBlobIDFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Splits a blob ID into its storage account, container and blob name",
+		MarkdownDescription: "Splits an ID of the form `storage_account/container_name/blob_name`, optionally followed by `;blob_endpoint`, with the same rules as resource import. The blob name is everything after the second slash and may contain slashes",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "id",
+				MarkdownDescription: "The ID of a `blobleas_blob_lease` or `blobleas_lease`, or an import ID",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"storage_account": types.StringType,
+				"container_name":  types.StringType,
+				"blob_name":       types.StringType,
+				"blob_endpoint":   types.StringType,
+			},
+		},
+	}
+}
+
+func (f *ParseBlobIDFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var id string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &id))
+	if resp.Error != nil {
+		return
+	}
+
+	storageAccount, containerName, blobName, endpoint, err := parseImportID(id)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, parsedBlobID{
+		StorageAccount: types.StringValue(storageAccount),
+		ContainerName:  types.StringValue(containerName),
+		BlobName:       types.StringValue(blobName),
+		BlobEndpoint:   stringOrNull(endpoint),
+	}))
+}
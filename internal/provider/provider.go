@@ -2,18 +2,28 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 
 	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ provider.Provider = &blobLeaseProvider{}
+	_ provider.Provider                       = &blobLeaseProvider{}
+	_ provider.ProviderWithFunctions          = &blobLeaseProvider{}
+	_ provider.ProviderWithEphemeralResources = &blobLeaseProvider{}
 )
 
 // New is a helper function to simplify provider server and testing implementation.
@@ -31,6 +41,25 @@ type blobLeaseProvider struct {
 	// provider is built and ran locally, and "test" when running acceptance
 	// testing.
 	version string
+
+	// configureClient, when set, adjusts the client options after they are read from the
+	// provider configuration, e.g. so tests can send the requests to a fake storage service
+	configureClient func(*blobclient.ClientOptions)
+}
+
+// blobLeaseProviderModel maps provider schema data to a Go type.
+type blobLeaseProviderModel struct {
+	Timeout               types.String `tfsdk:"timeout"`
+	AllowAnonymousReads   types.Bool   `tfsdk:"allow_anonymous_reads"`
+	UseAccountKeyLookup   types.Bool   `tfsdk:"use_account_key_lookup"`
+	SubscriptionID        types.String `tfsdk:"subscription_id"`
+	AccountResourceGroups types.Map    `tfsdk:"storage_account_resource_groups"`
+	MaxConcurrentOps      types.Int64  `tfsdk:"max_concurrent_operations"`
+	DisableAuthBreaker    types.Bool   `tfsdk:"disable_auth_circuit_breaker"`
+	ReadFromSecondary     types.Bool   `tfsdk:"read_from_secondary_on_failure"`
+	DefaultMetadata       types.Map    `tfsdk:"default_metadata"`
+	AllowHTTPEndpoints    types.Bool   `tfsdk:"allow_http_endpoints"`
+	AllowDuplicateTargets types.Bool   `tfsdk:"allow_duplicate_blob_targets"`
 }
 
 // Metadata returns the provider type name.
@@ -43,15 +72,132 @@ func (p *blobLeaseProvider) Metadata(_ context.Context, _ provider.MetadataReque
 func (p *blobLeaseProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Azure Blob Storage Lease provider for managing blob leases across Azure Storage accounts",
-		// No configuration attributes needed - uses DefaultAzureCredential
-		Attributes: map[string]schema.Attribute{},
+		// Authentication uses ARM_* environment variables or DefaultAzureCredential
+		Attributes: map[string]schema.Attribute{
+			"timeout": schema.StringAttribute{
+				Description: "Maximum duration for provider health checks against a storage account, e.g. \"30s\". Defaults to 30s.",
+				Optional:    true,
+			},
+			"allow_anonymous_reads": schema.BoolAttribute{
+				Description: "Retry read-only operations anonymously when authenticated access is rejected, for blobs in public containers. Lease and write operations still require a credential. Defaults to false.",
+				Optional:    true,
+			},
+			"use_account_key_lookup": schema.BoolAttribute{
+				Description: "Fetch storage account keys through the ARM ListKeys API and use shared key authentication for blob operations. Useful when the principal has management-plane access (e.g. Reader and Storage Account Key Operator) but no data-plane role. Defaults to false.",
+				Optional:    true,
+			},
+			"subscription_id": schema.StringAttribute{
+				Description: "Subscription containing the storage accounts, used by use_account_key_lookup. Defaults to the ARM_SUBSCRIPTION_ID environment variable.",
+				Optional:    true,
+			},
+			"storage_account_resource_groups": schema.MapAttribute{
+				Description: "Map of storage account name to resource group name (or full storage account resource ID), used by use_account_key_lookup. Accounts not listed are searched for in the subscription.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"max_concurrent_operations": schema.Int64Attribute{
+				Description: "Maximum number of concurrent requests per storage account. Requests throttled with 503 hold their slot until the server's Retry-After has elapsed. Defaults to unlimited.",
+				Optional:    true,
+			},
+			"read_from_secondary_on_failure": schema.BoolAttribute{
+				Description: "Retry read-only operations against the RA-GRS secondary endpoint (<account>-secondary) when the primary returns 5xx or is unreachable. Results read from the secondary may be stale and are reported as warnings. Writes and lease operations never use the secondary. Defaults to false.",
+				Optional:    true,
+			},
+			"disable_auth_circuit_breaker": schema.BoolAttribute{
+				Description: "Disable failing fast after repeated authentication or authorization failures against a storage account. Useful when debugging credentials. Defaults to false.",
+				Optional:    true,
+			},
+			"default_metadata": schema.MapAttribute{
+				Description: "Metadata written to every blob managed by the provider. A resource's metadata wins per key.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators: []validator.Map{
+					metadataValidator{},
+				},
+			},
+			"allow_http_endpoints": schema.BoolAttribute{
+				Description: "Accept http URLs in blob_endpoint, for storage emulators such as Azurite. Credentials are sent unencrypted to such endpoints. Defaults to false.",
+				Optional:    true,
+			},
+			"allow_duplicate_blob_targets": schema.BoolAttribute{
+				Description: "Report two blob lease resources that manage the same blob in one plan or apply with a warning instead of an error. Defaults to false.",
+				Optional:    true,
+			},
+		},
 	}
 }
 
 // Configure prepares an Azure Blob Storage lease client for data sources and resources.
 func (p *blobLeaseProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config blobLeaseProviderModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	options := &blobclient.ClientOptions{
+		AllowAnonymousReads:        config.AllowAnonymousReads.ValueBool(),
+		UseAccountKeyLookup:        config.UseAccountKeyLookup.ValueBool(),
+		DisableAuthCircuitBreaker:  config.DisableAuthBreaker.ValueBool(),
+		ReadFromSecondaryOnFailure: config.ReadFromSecondary.ValueBool(),
+		AllowHTTPEndpoints:         config.AllowHTTPEndpoints.ValueBool(),
+		AllowDuplicateBlobTargets:  config.AllowDuplicateTargets.ValueBool(),
+		ProviderVersion:            p.version,
+		SubscriptionID:             os.Getenv("ARM_SUBSCRIPTION_ID"),
+	}
+	if !config.SubscriptionID.IsNull() && !config.SubscriptionID.IsUnknown() {
+		options.SubscriptionID = config.SubscriptionID.ValueString()
+	}
+	if !config.AccountResourceGroups.IsNull() && !config.AccountResourceGroups.IsUnknown() {
+		resp.Diagnostics.Append(config.AccountResourceGroups.ElementsAs(ctx, &options.AccountResourceGroups, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if !config.DefaultMetadata.IsNull() && !config.DefaultMetadata.IsUnknown() {
+		resp.Diagnostics.Append(config.DefaultMetadata.ElementsAs(ctx, &options.DefaultMetadata, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if !config.MaxConcurrentOps.IsNull() && !config.MaxConcurrentOps.IsUnknown() {
+		if config.MaxConcurrentOps.ValueInt64() < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("max_concurrent_operations"),
+				"Invalid Concurrency Limit",
+				fmt.Sprintf("max_concurrent_operations must be at least 1, got: %d", config.MaxConcurrentOps.ValueInt64()),
+			)
+			return
+		}
+		options.MaxConcurrentOperations = int(config.MaxConcurrentOps.ValueInt64())
+	}
+	if options.UseAccountKeyLookup && options.SubscriptionID == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("subscription_id"),
+			"Missing Subscription ID",
+			"use_account_key_lookup requires subscription_id to be set or the ARM_SUBSCRIPTION_ID environment variable to be present.",
+		)
+		return
+	}
+	if !config.Timeout.IsNull() && !config.Timeout.IsUnknown() {
+		timeout, err := time.ParseDuration(config.Timeout.ValueString())
+		if err != nil || timeout <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("timeout"),
+				"Invalid Provider Timeout",
+				fmt.Sprintf("Expected a positive duration such as \"30s\" or \"2m\", got: %s", config.Timeout.ValueString()),
+			)
+			return
+		}
+		options.Timeout = timeout
+	}
+
+	if p.configureClient != nil {
+		p.configureClient(options)
+	}
+
 	// Create the Azure Blob Storage lease client
-	client, err := blobclient.NewAzureBlobLeaseClient()
+	client, err := blobclient.NewAzureBlobLeaseClient(options)
 	if err != nil {
 		resp.Diagnostics.AddError("Failed to initialize Azure Blob Storage lease client", err.Error())
 		return
@@ -60,16 +206,55 @@ func (p *blobLeaseProvider) Configure(ctx context.Context, req provider.Configur
 	// Store the client in the context
 	resp.DataSourceData = client
 	resp.ResourceData = client
+	resp.EphemeralResourceData = client
 }
 
 // DataSources defines the data sources implemented in the provider.
 func (p *blobLeaseProvider) DataSources(_ context.Context) []func() datasource.DataSource {
-	return nil
+	return []func() datasource.DataSource{
+		NewBlobLeaseDataSource,
+		NewBlobDataSource,
+		NewBlobContentDataSource,
+		NewBlobsDataSource,
+		NewLeaseAvailabilityDataSource,
+		NewContainersDataSource,
+		NewBlobSASURLDataSource,
+		NewStorageAccountConnectivityDataSource,
+		NewBlobSnapshotsDataSource,
+		NewBlobExistsDataSource,
+		NewContainerLeaseDataSource,
+	}
+}
+
+// Functions defines the provider-defined functions implemented in the provider.
+func (p *blobLeaseProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewBlobURLFunction,
+		NewContainerURLFunction,
+		NewParseBlobIDFunction,
+		NewBlobNameFromURLFunction,
+		NewLeaseIDFunction,
+		NewValidateBlobNameFunction,
+		NewIsValidBlobNameFunction,
+	}
 }
 
 // Resources defines the resources implemented in the provider.
 func (p *blobLeaseProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewBlobLeaseResource,
+		NewLeaseResource,
+		NewLeaseBreakResource,
+		NewBlobSnapshotResource,
+		NewLeaseRenewalResource,
+		NewBlobLeaseSetResource,
+	}
+}
+
+// EphemeralResources defines the ephemeral resources implemented in the provider.
+func (p *blobLeaseProvider) EphemeralResources(_ context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewLeaseEphemeralResource,
+		NewSASTokenEphemeralResource,
 	}
 }
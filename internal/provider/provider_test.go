@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+func TestSensitiveAttributes(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	// The blob content may hold connection details, a copy source URL may carry a SAS token,
+	// and a lease ID lets its holder change the blob
+	for typeName, names := range map[string][]string{
+		"blobleas_blob_lease":     {"content", "append_content", "copy_source", "lease_id"},
+		"blobleas_blob_lease_set": {"lease_ids"},
+	} {
+		schema := p.resourceSchema(typeName)
+		for _, name := range names {
+			attribute := schemaAttribute(schema, name)
+			if attribute == nil {
+				t.Errorf("%s: no attribute %s", typeName, name)
+				continue
+			}
+			if !attribute.Sensitive {
+				t.Errorf("%s: expected %s to be sensitive", typeName, name)
+			}
+		}
+	}
+
+	// Every lease ID attribute is sensitive, whichever schema has it
+	for kind, schemas := range map[string]map[string]*tfprotov6.Schema{
+		"resource":           p.schemas.ResourceSchemas,
+		"data source":        p.schemas.DataSourceSchemas,
+		"ephemeral resource": p.schemas.EphemeralResourceSchemas,
+	} {
+		for typeName, schema := range schemas {
+			for _, name := range []string{"lease_id", "lease_ids"} {
+				if attribute := schemaAttribute(schema, name); attribute != nil && !attribute.Sensitive {
+					t.Errorf("%s %s: expected %s to be sensitive", kind, typeName, name)
+				}
+			}
+		}
+	}
+}
+
+// schemaAttribute returns the top-level attribute name of schema, or nil if there is none
+func schemaAttribute(schema *tfprotov6.Schema, name string) *tfprotov6.SchemaAttribute {
+	for _, attribute := range schema.Block.Attributes {
+		if attribute.Name == name {
+			return attribute
+		}
+	}
+	return nil
+}
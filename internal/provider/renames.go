@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// renamedAttributes lists the attributes renamed to the names of azurerm_storage_blob, by their
+// deprecated name. The deprecated names are aliases that hold the same value.
+var renamedAttributes = []struct {
+	deprecated, name string
+}{
+	{"storage_account", "storage_account_name"},
+	{"container_name", "storage_container_name"},
+	{"blob_name", "name"},
+}
+
+// deprecationMessage returns the deprecation message of the deprecated alias of name
+func deprecationMessage(deprecated, name string) string {
+	return fmt.Sprintf("Use %s instead, as in azurerm_storage_blob. %s will be removed in a future major version.", name, deprecated)
+}
+
+// aliasPlanModifier plans an attribute that is not configured with the configured value of the
+// attribute it is an alias of, so both names hold the same value in plan and state
+type aliasPlanModifier struct {
+	other string
+}
+
+func (m aliasPlanModifier) Description(ctx context.Context) string {
+	return fmt.Sprintf("Takes the value of %s when unset", m.other)
+}
+
+func (m aliasPlanModifier) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprintf("Takes the value of `%s` when unset", m.other)
+}
+
+func (m aliasPlanModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if !req.ConfigValue.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var other types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(m.other), &other)...)
+	if !other.IsNull() {
+		resp.PlanValue = other
+	}
+}
+
+// setRenamedAttributes sets the renamed attributes of an upgraded state, which are null when it
+// was written before the rename, from their deprecated names
+func setRenamedAttributes(ctx context.Context, resp *resource.UpgradeStateResponse) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, renamed := range renamedAttributes {
+		var value, deprecated types.String
+		diags.Append(resp.State.GetAttribute(ctx, path.Root(renamed.name), &value)...)
+		diags.Append(resp.State.GetAttribute(ctx, path.Root(renamed.deprecated), &deprecated)...)
+		if diags.HasError() {
+			return diags
+		}
+		if value.IsNull() {
+			diags.Append(resp.State.SetAttribute(ctx, path.Root(renamed.name), deprecated)...)
+		}
+	}
+	return diags
+}
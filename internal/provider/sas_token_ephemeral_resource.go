@@ -0,0 +1,246 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ ephemeral.EphemeralResource = &SASTokenEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithConfigure = &SASTokenEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithValidateConfig = &SASTokenEphemeralResource{}
+
+func NewSASTokenEphemeralResource() ephemeral.EphemeralResource {
+	return &SASTokenEphemeralResource{}
+}
+
+// SASTokenEphemeralResource signs a user delegation SAS for a blob or container that is only
+// available during the Terraform operation. It has nothing to close: the SAS simply expires.
+type SASTokenEphemeralResource struct {
+	client *blobclient.AzureBlobLeaseClient
+}
+
+// SASTokenEphemeralResourceModel describes the ephemeral resource data model.
+type SASTokenEphemeralResourceModel struct {
+	StorageAccount types.String `tfsdk:"storage_account_name"`
+	BlobEndpoint   types.String `tfsdk:"blob_endpoint"`
+	ContainerName  types.String `tfsdk:"storage_container_name"`
+	BlobName       types.String `tfsdk:"name"`
+	Permissions    types.String `tfsdk:"permissions"`
+	TTL            types.String `tfsdk:"ttl"`
+	SASURL         types.String `tfsdk:"sas_url"`
+	SASToken       types.String `tfsdk:"sas_token"`
+	StartsAt       types.String `tfsdk:"starts_at"`
+	ExpiresAt      types.String `tfsdk:"expires_at"`
+}
+
+func (r *SASTokenEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_sas_token"
+}
+
+func (r *SASTokenEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Signs a short-lived, HTTPS-only user delegation SAS for an Azure Storage blob or container that is only available during the Terraform operation and never written to state",
+
+		Attributes: map[string]schema.Attribute{
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name",
+				Required:            true,
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, e.g. a private endpoint or sovereign cloud URL, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`",
+				Optional:            true,
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"storage_container_name": schema.StringAttribute{
+				MarkdownDescription: "The container the SAS is for, or the container of the blob",
+				Required:            true,
+				Validators: []validator.String{
+					validators.ContainerName(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The name of the blob the SAS is for, which does not have to exist yet. Unset signs a SAS for the whole container",
+				Optional:            true,
+				Validators: []validator.String{
+					validators.BlobName(),
+				},
+			},
+			"permissions": schema.StringAttribute{
+				MarkdownDescription: "What the SAS allows, any of `r` (read), `w` (write) and `d` (delete), plus `l` (list) for a container SAS, e.g. `rw`. Defaults to `r`",
+				Optional:            true,
+			},
+			"ttl": schema.StringAttribute{
+				MarkdownDescription: "How long the SAS is valid from the time it is signed, e.g. `30m`; at most `168h` (7 days), the limit of a user delegation key. Defaults to `1h`",
+				Optional:            true,
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"sas_url": schema.StringAttribute{
+				MarkdownDescription: "The URL of the blob or container with the SAS token as its query",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"sas_token": schema.StringAttribute{
+				MarkdownDescription: "The SAS token, without a leading `?`",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"starts_at": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time the SAS becomes valid, backdated a few minutes to allow for clock skew",
+				Computed:            true,
+			},
+			"expires_at": schema.StringAttribute{
+				MarkdownDescription: "The RFC3339 time the SAS expires",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *SASTokenEphemeralResource) ValidateConfig(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	var data SASTokenEphemeralResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Which permissions are valid depends on whether the SAS is for a blob or a container
+	if !data.Permissions.IsNull() && !data.Permissions.IsUnknown() && !data.BlobName.IsUnknown() {
+		check, description := blobclient.ValidContainerSASPermissions, "one or more of the permission flags r, w, d and l"
+		if !data.BlobName.IsNull() {
+			check, description = blobclient.ValidBlobSASPermissions, "one or more of the permission flags r, w and d"
+		}
+		if err := check(data.Permissions.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("permissions"),
+				"Invalid SAS Permissions",
+				fmt.Sprintf("permissions must be %s, got: %q (%s)", description, data.Permissions.ValueString(), err),
+			)
+		}
+	}
+
+	if data.TTL.IsNull() || data.TTL.IsUnknown() {
+		return
+	}
+	// The format was checked by the attribute validator
+	ttl, err := time.ParseDuration(data.TTL.ValueString())
+	if err != nil {
+		return
+	}
+	switch {
+	case ttl <= 0:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("ttl"),
+			"Invalid SAS TTL",
+			"ttl must be longer than 0s; a SAS that expires as it is signed cannot be used.",
+		)
+	case ttl > blobclient.MaxUserDelegationExpiry:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("ttl"),
+			"Invalid SAS TTL",
+			fmt.Sprintf("A user delegation SAS expires at most %s after it is signed, got: %s.", blobclient.MaxUserDelegationExpiry, ttl),
+		)
+	}
+}
+
+func (r *SASTokenEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+func (r *SASTokenEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data SASTokenEphemeralResourceModel
+
+	// Read Terraform config data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+
+	endpoint := data.BlobEndpoint.ValueString()
+	if endpoint != "" {
+		if err := r.client.CheckBlobEndpoint(endpoint); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+			return
+		}
+	}
+	ctx = blobclient.WithBlobEndpoint(ctx, endpoint)
+
+	// The values were validated at validation time
+	options := blobclient.BlobSASOptions{
+		Permissions:   defaultSASPermissions,
+		Expiry:        time.Now().Add(defaultSASExpiry),
+		SigningMethod: blobclient.SASSigningUserDelegation,
+	}
+	if !data.Permissions.IsNull() {
+		options.Permissions = data.Permissions.ValueString()
+	}
+	if !data.TTL.IsNull() {
+		ttl, _ := time.ParseDuration(data.TTL.ValueString())
+		options.Expiry = time.Now().Add(ttl)
+	}
+
+	storageAccount, containerName := data.StorageAccount.ValueString(), data.ContainerName.ValueString()
+	var signed *blobclient.BlobSAS
+	var err error
+	if data.BlobName.IsNull() {
+		signed, err = r.client.SignContainerSAS(ctx, storageAccount, containerName, options)
+	} else {
+		signed, err = r.client.SignBlobSAS(ctx, storageAccount, containerName, data.BlobName.ValueString(), options)
+	}
+	switch {
+	case errors.Is(err, blobclient.ErrAuthorizationFailed):
+		resp.Diagnostics.AddError("SAS Signing Not Permitted", err.Error())
+		return
+	case err != nil:
+		resp.Diagnostics.AddError(clientErrorSummary(err), fmt.Sprintf("Unable to sign SAS, got error: %s", err))
+		return
+	}
+
+	data.SASURL = types.StringValue(signed.URL)
+	data.SASToken = types.StringValue(signed.Token)
+	data.StartsAt = types.StringValue(signed.Start.UTC().Format(time.RFC3339))
+	data.ExpiresAt = types.StringValue(options.Expiry.UTC().Format(time.RFC3339))
+
+	// Save data into the ephemeral result
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/defaults"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// blobLeaseSchemaVersion is the current schema version of the blob lease resource
+const blobLeaseSchemaVersion = 3
+
+// UpgradeState upgrades states written with earlier schema versions to the current one
+func (r *BlobLeaseResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// Version 0 is every state written before the schema was versioned
+		0: {StateUpgrader: r.upgradeStateV0},
+		// Version 1 states have every lease ID in lease_id. Upgraders cannot write private
+		// state, so the next apply moves a hidden one there.
+		1: {StateUpgrader: r.upgradeStateV1},
+		// Version 2 states have the blob path only under the names deprecated for those of
+		// azurerm_storage_blob
+		2: {StateUpgrader: r.upgradeStateV2},
+	}
+}
+
+// upgradeStateV0 upgrades a version 0 state. Version 0 schemas only ever gained attributes, so the
+// raw state is decoded with the current schema: attributes added since are null, and those with a
+// schema default get it, as they would have had when written by a current provider. The ID is
+// rebuilt from the blob path.
+func (r *BlobLeaseResource) upgradeStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	r.decodeWithCurrentSchema(ctx, req, resp, 0)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var data BlobLeaseResourceModel
+	resp.Diagnostics.Append(resp.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !data.StorageAccount.IsNull() && !data.ContainerName.IsNull() && !data.BlobName.IsNull() {
+		data.ID = types.StringValue(blobLeaseID(data.StorageAccount.ValueString(), data.ContainerName.ValueString(), data.BlobName.ValueString()))
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// upgradeStateV1 upgrades a version 1 state, which lacks expose_lease_id and the renamed
+// attributes. expose_lease_id is set to its default, so lease_id is planned as hidden like in a
+// new resource.
+func (r *BlobLeaseResource) upgradeStateV1(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	r.decodeWithCurrentSchema(ctx, req, resp, 1)
+}
+
+// upgradeStateV2 upgrades a version 2 state, which only lacks the renamed attributes
+func (r *BlobLeaseResource) upgradeStateV2(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	r.decodeWithCurrentSchema(ctx, req, resp, 2)
+}
+
+// decodeWithCurrentSchema sets the upgraded state to the raw state of the given version decoded
+// with the current schema, with schema defaults for the attributes it lacks and renamed
+// attributes set from their deprecated names
+func (r *BlobLeaseResource) decodeWithCurrentSchema(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse, version int) {
+	if req.RawState == nil {
+		resp.Diagnostics.AddError("Unable to Upgrade State", fmt.Sprintf("No version %d state was provided.", version))
+		return
+	}
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	current := schemaResp.Schema
+
+	raw, err := req.RawState.UnmarshalWithOpts(current.Type().TerraformType(ctx), tfprotov6.UnmarshalOpts{
+		ValueFromJSONOpts: tftypes.ValueFromJSONOpts{
+			// Attributes removed since are dropped
+			IgnoreUndefinedAttributes: true,
+		},
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to Upgrade State", fmt.Sprintf("Unable to read version %d state, got error: %s", version, err))
+		return
+	}
+	resp.State.Raw = raw
+
+	for name, attribute := range current.Attributes {
+		resp.Diagnostics.Append(setMissingDefault(ctx, resp, path.Root(name), attribute)...)
+	}
+	resp.Diagnostics.Append(setRenamedAttributes(ctx, resp)...)
+}
+
+// setMissingDefault sets the schema default of a top-level attribute that is null in the upgraded
+// state. Attributes with a default are never null in a state written by the provider, so a null
+// means the attribute did not exist yet. Only bool, string and int32 attributes of the schema have
+// defaults; a default on an attribute of another type needs a case here.
+func setMissingDefault(ctx context.Context, resp *resource.UpgradeStateResponse, p path.Path, attribute schema.Attribute) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	switch a := attribute.(type) {
+	case schema.BoolAttribute:
+		if a.Default == nil {
+			return diags
+		}
+		var value types.Bool
+		diags.Append(resp.State.GetAttribute(ctx, p, &value)...)
+		if diags.HasError() || !value.IsNull() {
+			return diags
+		}
+		defaultResp := defaults.BoolResponse{}
+		a.Default.DefaultBool(ctx, defaults.BoolRequest{Path: p}, &defaultResp)
+		diags.Append(defaultResp.Diagnostics...)
+		diags.Append(resp.State.SetAttribute(ctx, p, defaultResp.PlanValue)...)
+	case schema.StringAttribute:
+		if a.Default == nil {
+			return diags
+		}
+		var value types.String
+		diags.Append(resp.State.GetAttribute(ctx, p, &value)...)
+		if diags.HasError() || !value.IsNull() {
+			return diags
+		}
+		defaultResp := defaults.StringResponse{}
+		a.Default.DefaultString(ctx, defaults.StringRequest{Path: p}, &defaultResp)
+		diags.Append(defaultResp.Diagnostics...)
+		diags.Append(resp.State.SetAttribute(ctx, p, defaultResp.PlanValue)...)
+	case schema.Int32Attribute:
+		if a.Default == nil {
+			return diags
+		}
+		var value types.Int32
+		diags.Append(resp.State.GetAttribute(ctx, p, &value)...)
+		if diags.HasError() || !value.IsNull() {
+			return diags
+		}
+		defaultResp := defaults.Int32Response{}
+		a.Default.DefaultInt32(ctx, defaults.Int32Request{Path: p}, &defaultResp)
+		diags.Append(defaultResp.Diagnostics...)
+		diags.Append(resp.State.SetAttribute(ctx, p, defaultResp.PlanValue)...)
+	}
+	return diags
+}
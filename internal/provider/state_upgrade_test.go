@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+)
+
+func TestBlobLeaseUpgradeState(t *testing.T) {
+	const leaseID = "3f2504e0-4f89-11d3-9a0c-0305e82c3301"
+
+	for name, tc := range map[string]struct {
+		version int64
+		raw     string
+		// wantExposed is expose_lease_id after the upgrade: the default, unless the state has it
+		wantExposed bool
+	}{
+		// Version 0 states have no ID of the blob path form, and may hold attributes removed since
+		"version 0": {
+			version: 0,
+			raw: `{"id":"env/app.lock","storage_account":"acct","container_name":"locks","blob_name":"env/app.lock",
+				"lease_id":"` + leaseID + `","lease_duration":-1,"lease_state":"leased","content":"locked","removed_since":"x"}`,
+		},
+		"version 1": {
+			version: 1,
+			raw: `{"id":"acct/locks/env/app.lock","storage_account":"acct","container_name":"locks","blob_name":"env/app.lock",
+				"lease_id":"` + leaseID + `","lease_duration":-1,"lease_state":"leased","content":"locked",
+				"lease_action_on_drift":"fail","verify_ownership":false}`,
+		},
+		"version 2": {
+			version: 2,
+			raw: `{"id":"acct/locks/env/app.lock","storage_account":"acct","container_name":"locks","blob_name":"env/app.lock",
+				"lease_id":"` + leaseID + `","lease_duration":-1,"lease_state":"leased","content":"locked",
+				"lease_action_on_drift":"fail","verify_ownership":false,"expose_lease_id":true}`,
+			wantExposed: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := newTestProvider(t, nil)
+			state, diags := p.upgradeState(blobLeaseType, tc.version, tc.raw)
+			requireNoErrors(t, "upgrade", diags)
+
+			want := map[string]string{
+				"id":                     "acct/locks/env/app.lock",
+				"storage_account_name":   "acct",
+				"storage_container_name": "locks",
+				"name":                   "env/app.lock",
+				"storage_account":        "acct",
+				"container_name":         "locks",
+				"blob_name":              "env/app.lock",
+				"lease_id":               leaseID,
+				"content":                "locked",
+			}
+			for attribute, value := range want {
+				if got := stringAttr(t, state.value, attribute); got != value {
+					t.Errorf("expected %s %q, got %q", attribute, value, got)
+				}
+			}
+
+			// Attributes the state predates get their defaults, and those it has are kept
+			wantAction, wantVerify := leaseActionOnDriftReacquire, true
+			if tc.version > 0 {
+				wantAction, wantVerify = leaseActionOnDriftFail, false
+			}
+			if got := stringAttr(t, state.value, "lease_action_on_drift"); got != wantAction {
+				t.Errorf("expected lease_action_on_drift %s, got %s", wantAction, got)
+			}
+			for attribute, value := range map[string]bool{"verify_ownership": wantVerify, "expose_lease_id": tc.wantExposed, "renew_during_apply": false} {
+				var got bool
+				if err := attrValue(t, state.value, attribute).As(&got); err != nil {
+					t.Fatalf("%s: %s", attribute, err)
+				}
+				if got != value {
+					t.Errorf("expected %s %t, got %t", attribute, value, got)
+				}
+			}
+
+			// The upgraded state plans no replacement for the configuration it was written for
+			config := blobLeaseConfig(map[string]any{
+				"content":               "locked",
+				"lease_action_on_drift": wantAction,
+				"verify_ownership":      wantVerify,
+				"expose_lease_id":       tc.wantExposed,
+			})
+			plan, diags := p.plan(blobLeaseType, state, config)
+			requireNoErrors(t, "plan", diags)
+			if len(plan.requiresReplace) > 0 {
+				t.Errorf("expected no replacement, got %v", plan.requiresReplace)
+			}
+			hidden := attrValue(t, plan.planned, "lease_id").IsNull()
+			if hidden == tc.wantExposed {
+				t.Errorf("expected lease_id to be hidden %t in the plan, got %t", !tc.wantExposed, hidden)
+			}
+		})
+	}
+}
+
+func TestBlobLeaseUpgradeStateDefaultTypes(t *testing.T) {
+	// setMissingDefault handles the attribute types that have defaults in the schema; an
+	// attribute of another type with a default would keep a null in upgraded states
+	var schemaResp resource.SchemaResponse
+	(&BlobLeaseResource{}).Schema(context.Background(), resource.SchemaRequest{}, &schemaResp)
+
+	for name, attribute := range schemaResp.Schema.Attributes {
+		switch attribute.(type) {
+		case schema.BoolAttribute, schema.StringAttribute, schema.Int32Attribute:
+			continue
+		}
+		if value := reflect.ValueOf(attribute).FieldByName("Default"); value.IsValid() && !value.IsNil() {
+			t.Errorf("%s has a default of a type setMissingDefault does not set", name)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &StorageAccountConnectivityDataSource{}
+
+func NewStorageAccountConnectivityDataSource() datasource.DataSource {
+	return &StorageAccountConnectivityDataSource{}
+}
+
+// StorageAccountConnectivityDataSource checks that the provider credential can reach a storage
+// account. A failed check is reported in its attributes so that check blocks and preconditions
+// decide what it means; it never fails the read.
+type StorageAccountConnectivityDataSource struct {
+	client *blobclient.AzureBlobLeaseClient
+}
+
+// StorageAccountConnectivityDataSourceModel describes the data source data model.
+type StorageAccountConnectivityDataSourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	StorageAccount types.String `tfsdk:"storage_account_name"`
+	BlobEndpoint   types.String `tfsdk:"blob_endpoint"`
+	Reachable      types.Bool   `tfsdk:"reachable"`
+	AuthOK         types.Bool   `tfsdk:"auth_ok"`
+	ErrorCode      types.String `tfsdk:"error_code"`
+	Detail         types.String `tfsdk:"detail"`
+}
+
+func (d *StorageAccountConnectivityDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_storage_account_connectivity"
+}
+
+func (d *StorageAccountConnectivityDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Checks that the provider credential can reach and is authorized on an Azure Storage Account, without failing the plan when it cannot",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Data source identifier, the storage account name",
+			},
+			"storage_account_name": schema.StringAttribute{
+				MarkdownDescription: "The Azure Storage Account name",
+				Required:            true,
+				Validators: []validator.String{
+					validators.StorageAccountName(),
+				},
+			},
+			"blob_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The blob service URL of the storage account, e.g. a private endpoint or sovereign cloud URL, used instead of `https://<storage_account>.blob.core.windows.net/`. http URLs are only accepted when the provider sets `allow_http_endpoints`",
+				Optional:            true,
+				Validators: []validator.String{
+					blobEndpointValidator{},
+				},
+			},
+			"reachable": schema.BoolAttribute{
+				MarkdownDescription: "Whether the blob endpoint of the storage account answered from this network. False when the account was not contacted because no token could be obtained",
+				Computed:            true,
+			},
+			"auth_ok": schema.BoolAttribute{
+				MarkdownDescription: "Whether the storage account accepted and authorized the credential. False when the account could not be reached to confirm it",
+				Computed:            true,
+			},
+			"error_code": schema.StringAttribute{
+				MarkdownDescription: "Why the check failed: `authentication_failed`, `authorization_failed`, `network_access_denied`, `account_not_found`, `endpoint_unreachable` or `unknown`. Null when it passed",
+				Computed:            true,
+			},
+			"detail": schema.StringAttribute{
+				MarkdownDescription: "A description of the result, with a remediation hint when the check failed",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *StorageAccountConnectivityDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*blobclient.AzureBlobLeaseClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *blobclient.AzureBlobLeaseClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *StorageAccountConnectivityDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data StorageAccountConnectivityDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	endpoint := data.BlobEndpoint.ValueString()
+	if endpoint != "" {
+		if err := d.client.CheckBlobEndpoint(endpoint); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("blob_endpoint"), "Invalid Blob Endpoint", err.Error())
+			return
+		}
+	}
+	ctx = blobclient.WithBlobEndpoint(ctx, endpoint)
+
+	// CanConnect is bounded by the provider timeout, so a black-holed endpoint fails the check
+	// instead of hanging the plan
+	storageAccount := data.StorageAccount.ValueString()
+	err := d.client.CanConnect(ctx, storageAccount)
+
+	data.ID = types.StringValue(storageAccount)
+	data.Reachable, data.AuthOK, data.ErrorCode = connectivityValues(err)
+	if err != nil {
+		data.Detail = types.StringValue(err.Error())
+	} else {
+		data.Detail = types.StringValue(fmt.Sprintf("storage account %s is reachable and authorized the credential", storageAccount))
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// connectivityValues converts the result of CanConnect into the reachable, auth_ok and
+// error_code attributes
+func connectivityValues(err error) (types.Bool, types.Bool, types.String) {
+	if err == nil {
+		return types.BoolValue(true), types.BoolValue(true), types.StringNull()
+	}
+
+	var connErr *blobclient.ConnectivityError
+	if !errors.As(err, &connErr) {
+		return types.BoolValue(false), types.BoolValue(false), types.StringValue("unknown")
+	}
+
+	// A credential rejected by the service was still reached; one that could not produce a
+	// token never contacted the account
+	var respErr *azcore.ResponseError
+	reachable := (connErr.Kind == blobclient.ErrAuthenticationFailed || connErr.Kind == blobclient.ErrAuthorizationFailed) &&
+		errors.As(err, &respErr)
+	return types.BoolValue(reachable), types.BoolValue(false), types.StringValue(connErr.Code())
+}
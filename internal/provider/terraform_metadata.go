@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	blobclient "github.com/360-build/terraform-provider-blobleas/internal/provider/blobclient"
+)
+
+// Metadata keys written with write_terraform_metadata. Metadata names must be C# identifiers,
+// so the keys use underscores rather than hyphens. steal_if_older_than judges the age of a lease
+// by tf_acquired_at.
+const (
+	tfWorkspaceMetadataKey       = "tf_workspace"
+	tfRunIDMetadataKey           = "tf_run_id"
+	tfProviderVersionMetadataKey = "tf_provider_version"
+	tfAcquiredAtMetadataKey      = blobclient.AcquiredAtMetadataKey
+)
+
+// terraformMetadataKeys lists the keys managed by write_terraform_metadata
+var terraformMetadataKeys = []string{
+	tfWorkspaceMetadataKey,
+	tfRunIDMetadataKey,
+	tfProviderVersionMetadataKey,
+	tfAcquiredAtMetadataKey,
+}
+
+// terraformMetadata returns the metadata written with write_terraform_metadata, or nil when it is
+// not set. The workspace and run are taken from the variables HCP Terraform and Terraform
+// Enterprise set for a run, with TF_WORKSPACE as the workspace of a local run; values that are
+// not known are left out.
+func (r *BlobLeaseResource) terraformMetadata(data BlobLeaseResourceModel, acquiredAt time.Time) map[string]string {
+	if !data.TerraformMetadata.ValueBool() {
+		return nil
+	}
+
+	metadata := map[string]string{
+		tfAcquiredAtMetadataKey: acquiredAt.UTC().Format(time.RFC3339),
+	}
+	if version := r.client.ProviderVersion(); version != "" {
+		metadata[tfProviderVersionMetadataKey] = version
+	}
+	for _, name := range []string{"TFC_WORKSPACE_NAME", "TF_WORKSPACE"} {
+		if workspace := os.Getenv(name); workspace != "" {
+			metadata[tfWorkspaceMetadataKey] = workspace
+			break
+		}
+	}
+	if runID := os.Getenv("TFC_RUN_ID"); runID != "" {
+		metadata[tfRunIDMetadataKey] = runID
+	}
+	return metadata
+}
+
+// leaseAcquiredAt returns the time the lease of data was acquired, as recorded in
+// lease_rotated_at, or now when it is not known yet
+func leaseAcquiredAt(data BlobLeaseResourceModel, now time.Time) time.Time {
+	if data.LeaseRotatedAt.IsNull() || data.LeaseRotatedAt.IsUnknown() {
+		return now
+	}
+	at, err := time.Parse(time.RFC3339, data.LeaseRotatedAt.ValueString())
+	if err != nil {
+		return now
+	}
+	return at
+}
+
+// isTerraformMetadataKey reports whether key is written by write_terraform_metadata, ignoring
+// case as Azure does for metadata names
+func isTerraformMetadataKey(key string) bool {
+	for _, managed := range terraformMetadataKeys {
+		if strings.EqualFold(key, managed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Default operation timeouts of the blob lease resource
+const (
+	defaultCreateTimeout = 10 * time.Minute
+	defaultReadTimeout   = 5 * time.Minute
+	defaultUpdateTimeout = 5 * time.Minute
+	defaultDeleteTimeout = 5 * time.Minute
+)
+
+// timeoutsAttrTypes are the attribute types of the timeouts object
+var timeoutsAttrTypes = map[string]attr.Type{
+	"create": types.StringType,
+	"read":   types.StringType,
+	"update": types.StringType,
+	"delete": types.StringType,
+}
+
+// timeoutsAttribute returns the schema of the timeouts attribute, which has one duration per operation
+func timeoutsAttribute() schema.SingleNestedAttribute {
+	attribute := func(operation string, defaultTimeout time.Duration) schema.StringAttribute {
+		return schema.StringAttribute{
+			MarkdownDescription: fmt.Sprintf("How long %s may take, e.g. `30s` or `10m`. Defaults to `%s`", operation, defaultTimeout),
+			Optional:            true,
+			Validators: []validator.String{
+				durationValidator{},
+			},
+		}
+	}
+
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Limits on how long each operation may take",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"create": attribute("create", defaultCreateTimeout),
+			"read":   attribute("refresh", defaultReadTimeout),
+			"update": attribute("update", defaultUpdateTimeout),
+			"delete": attribute("destroy", defaultDeleteTimeout),
+		},
+	}
+}
+
+// operationTimeout returns the configured timeout of an operation, or defaultTimeout when unset
+func operationTimeout(timeouts types.Object, operation string, defaultTimeout time.Duration) time.Duration {
+	if timeouts.IsNull() || timeouts.IsUnknown() {
+		return defaultTimeout
+	}
+
+	value, ok := timeouts.Attributes()[operation].(types.String)
+	if !ok || value.IsNull() || value.IsUnknown() {
+		return defaultTimeout
+	}
+
+	// The value was validated as a duration at plan time
+	timeout, err := time.ParseDuration(value.ValueString())
+	if err != nil || timeout <= 0 {
+		return defaultTimeout
+	}
+	return timeout
+}
+
+// addTimeoutError explains a failed operation whose context ran out of time, so the user knows
+// which timeout to raise. Deferred by each operation after deriving ctx from its timeout.
+func addTimeoutError(ctx context.Context, diags *diag.Diagnostics, operation, blobName string, timeout time.Duration) {
+	if !diags.HasError() || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return
+	}
+
+	diags.AddError(
+		"Operation Timed Out",
+		fmt.Sprintf("The %s of blob %s did not complete within %s. Increase timeouts.%s if the operation needs more time.", operation, blobName, timeout, operation),
+	)
+}
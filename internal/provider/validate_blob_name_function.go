@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+
+	"github.com/360-build/terraform-provider-blobleas/internal/validators"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ function.Function = &ValidateBlobNameFunction{}
+	_ function.Function = &IsValidBlobNameFunction{}
+)
+
+// blobNameParameter is the parameter of the blob name functions
+var blobNameParameter = function.StringParameter{
+	Name:                "name",
+	MarkdownDescription: "The blob name to check",
+}
+
+func NewValidateBlobNameFunction() function.Function {
+	return &ValidateBlobNameFunction{}
+}
+
+// ValidateBlobNameFunction returns a blob name unchanged, or fails with the naming rule it breaks.
+// It applies the check of the name attributes of the resources.
+type ValidateBlobNameFunction struct{}
+
+func (f *ValidateBlobNameFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "validate_blob_name"
+}
+
+func (f *ValidateBlobNameFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Returns a blob name unchanged, failing when it is not a valid Azure blob name",
+		MarkdownDescription: "Returns the blob name unchanged when it is 1-1024 characters in at most 254 `/`-separated path segments, not ending with `/` or `.`, and fails with the rule it breaks otherwise. These are the rules the `name` attributes of the provider are validated with",
+
+		Parameters: []function.Parameter{blobNameParameter},
+		Return:     function.StringReturn{},
+	}
+}
+
+func (f *ValidateBlobNameFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var name string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &name))
+	if resp.Error != nil {
+		return
+	}
+
+	if err := validators.CheckBlobName(name); err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("name %s", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, name))
+}
+
+func NewIsValidBlobNameFunction() function.Function {
+	return &IsValidBlobNameFunction{}
+}
+
+// IsValidBlobNameFunction reports whether a string is a valid blob name, for conditions
+type IsValidBlobNameFunction struct{}
+
+func (f *IsValidBlobNameFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "is_valid_blob_name"
+}
+
+func (f *IsValidBlobNameFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Reports whether a string is a valid Azure blob name",
+		MarkdownDescription: "Returns whether the blob name passes `validate_blob_name`, for the `condition` of variable validation blocks",
+
+		Parameters: []function.Parameter{blobNameParameter},
+		Return:     function.BoolReturn{},
+	}
+}
+
+func (f *IsValidBlobNameFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var name string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &name))
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, validators.CheckBlobName(name) == nil))
+}
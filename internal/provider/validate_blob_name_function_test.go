@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateBlobNameFunction(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	for name, tc := range map[string]struct {
+		blobName string
+		wantErr  string // the rule broken, "" when the name is valid
+	}{
+		"single character":           {blobName: "a"},
+		"path":                       {blobName: "env/prod/app.lock"},
+		"spaces and unicode":         {blobName: "équipe a/état.lock"},
+		"1024 characters":            {blobName: strings.Repeat("a", 1024)},
+		"1024 multi-byte characters": {blobName: strings.Repeat("é", 1024)},
+		"254 path segments":          {blobName: strings.Repeat("a/", 253) + "a"},
+		"dot inside a segment":       {blobName: "env/.hidden/app.lock"},
+		"empty":                      {blobName: "", wantErr: "0 characters long"},
+		"1025 characters":            {blobName: strings.Repeat("a", 1025), wantErr: "1025 characters long"},
+		"1025 multi-byte characters": {blobName: strings.Repeat("é", 1025), wantErr: "1025 characters long"},
+		"trailing slash":             {blobName: "env/app/", wantErr: "ends with '/'"},
+		"trailing dot":               {blobName: "env/app.", wantErr: "ends with '.'"},
+		"trailing dot and slash":     {blobName: "env/app./", wantErr: "ends with '/'"},
+		"only a dot":                 {blobName: ".", wantErr: "ends with '.'"},
+		"255 path segments":          {blobName: strings.Repeat("a/", 254) + "a", wantErr: "255 path segments"},
+		"255 segments within length": {blobName: strings.Repeat("/", 254) + "a", wantErr: "255 path segments"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, funcErr := p.callFunction("validate_blob_name", tc.blobName)
+			if tc.wantErr == "" {
+				if funcErr != nil {
+					t.Fatalf("unexpected error: %s", funcErr.Text)
+				}
+				var got string
+				if err := result.As(&got); err != nil {
+					t.Fatal(err)
+				}
+				if got != tc.blobName {
+					t.Errorf("expected the name to be returned unchanged, got %q", got)
+				}
+			} else {
+				if funcErr == nil {
+					t.Fatalf("expected an error for %q", tc.blobName)
+				}
+				if !strings.Contains(funcErr.Text, tc.wantErr) {
+					t.Errorf("expected the error to name the rule %q, got: %s", tc.wantErr, funcErr.Text)
+				}
+				if funcErr.FunctionArgument == nil || *funcErr.FunctionArgument != 0 {
+					t.Errorf("expected the error to point at the name argument, got %v", funcErr.FunctionArgument)
+				}
+			}
+
+			// is_valid_blob_name agrees without failing
+			result, funcErr = p.callFunction("is_valid_blob_name", tc.blobName)
+			if funcErr != nil {
+				t.Fatalf("unexpected error: %s", funcErr.Text)
+			}
+			var valid bool
+			if err := result.As(&valid); err != nil {
+				t.Fatal(err)
+			}
+			if valid != (tc.wantErr == "") {
+				t.Errorf("expected is_valid_blob_name to return %t, got %t", tc.wantErr == "", valid)
+			}
+		})
+	}
+}
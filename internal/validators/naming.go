@@ -0,0 +1,177 @@
+// Package validators contains schema validators for Azure Storage names, shared by the resources
+// and data sources of the provider, and the same checks for values outside of a schema, such as
+// provider function arguments.
+package validators
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+var (
+	storageAccountNamePattern = regexp.MustCompile(`^[a-z0-9]{3,24}$`)
+	containerNamePattern      = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
+
+// Containers with reserved names that do not follow the container naming rules
+var reservedContainerNames = []string{"$root", "$web", "$logs"}
+
+// maxBlobNameLength is the longest blob name the service accepts, in characters
+const maxBlobNameLength = 1024
+
+// maxBlobPathSegments is how many slash-separated path segments a blob name may have
+const maxBlobPathSegments = 254
+
+// nameValidator validates a string attribute with check, which returns why the value is invalid
+// or an empty string
+type nameValidator struct {
+	title       string
+	description string
+	check       func(value string) string
+}
+
+func (v nameValidator) Description(ctx context.Context) string {
+	return v.description
+}
+
+func (v nameValidator) MarkdownDescription(ctx context.Context) string {
+	return v.description
+}
+
+func (v nameValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if reason := v.check(req.ConfigValue.ValueString()); reason != "" {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			v.title,
+			fmt.Sprintf("%s %s, got: %q (%s)", req.Path, v.description, req.ConfigValue.ValueString(), reason),
+		)
+	}
+}
+
+// err returns why value is invalid, or nil
+func (v nameValidator) err(value string) error {
+	if reason := v.check(value); reason != "" {
+		return fmt.Errorf("%s, got: %q (%s)", v.description, value, reason)
+	}
+	return nil
+}
+
+// CheckStorageAccountName returns why name is not a storage account name, or nil
+func CheckStorageAccountName(name string) error {
+	return storageAccountName.err(name)
+}
+
+// CheckContainerName returns why name is not a container name, or nil
+func CheckContainerName(name string) error {
+	return containerName.err(name)
+}
+
+// CheckBlobName returns why name is not a blob name, or nil
+func CheckBlobName(name string) error {
+	return blobName.err(name)
+}
+
+// StorageAccountName validates that a string is a storage account name: 3-24 lowercase letters
+// and digits
+func StorageAccountName() validator.String {
+	return storageAccountName
+}
+
+var storageAccountName = nameValidator{
+	title:       "Invalid Storage Account Name",
+	description: "must be 3-24 lowercase letters and digits",
+	check:       checkStorageAccountName,
+}
+
+func checkStorageAccountName(name string) string {
+	switch {
+	case len(name) < 3 || len(name) > 24:
+		return fmt.Sprintf("%d characters long", len(name))
+	case !storageAccountNamePattern.MatchString(name):
+		return "contains characters other than lowercase letters and digits"
+	}
+	return ""
+}
+
+// ContainerName validates that a string is a container name: 3-63 lowercase letters, digits and
+// hyphens, starting and ending with a letter or digit, without consecutive hyphens. The
+// reserved $root, $web and $logs containers are accepted as well.
+func ContainerName() validator.String {
+	return containerName
+}
+
+var containerName = nameValidator{
+	title:       "Invalid Container Name",
+	description: "must be 3-63 lowercase letters, digits and single hyphens, starting and ending with a letter or digit",
+	check:       checkContainerName,
+}
+
+func checkContainerName(name string) string {
+	if slices.Contains(reservedContainerNames, name) {
+		return ""
+	}
+
+	switch {
+	case len(name) < 3 || len(name) > 63:
+		return fmt.Sprintf("%d characters long", len(name))
+	case strings.Contains(name, "--"):
+		return "contains consecutive hyphens"
+	case strings.HasPrefix(name, "-") || strings.HasSuffix(name, "-"):
+		return "starts or ends with a hyphen"
+	case !containerNamePattern.MatchString(name):
+		return "contains characters other than lowercase letters, digits and hyphens"
+	}
+	return ""
+}
+
+// BlobName validates that a string is a blob name: 1-1024 characters in at most 254 path
+// segments, not ending with a slash or a dot
+func BlobName() validator.String {
+	return blobName
+}
+
+var blobName = nameValidator{
+	title:       "Invalid Blob Name",
+	description: "must be 1-1024 characters in at most 254 '/'-separated path segments and must not end with '/' or '.'",
+	check:       checkBlobName,
+}
+
+// BlobNamePrefix validates that a string is a prefix that stays a valid blob name with a
+// generated suffix of suffixLength characters appended: 1 to 1024 - suffixLength characters
+func BlobNamePrefix(suffixLength int) validator.String {
+	maxLength := maxBlobNameLength - suffixLength
+	return nameValidator{
+		title:       "Invalid Blob Name Prefix",
+		description: fmt.Sprintf("must be 1-%d characters, so the generated blob name stays within %d characters", maxLength, maxBlobNameLength),
+		check: func(prefix string) string {
+			if length := len([]rune(prefix)); length < 1 || length > maxLength {
+				return fmt.Sprintf("%d characters long", length)
+			}
+			return ""
+		},
+	}
+}
+
+func checkBlobName(name string) string {
+	length := len([]rune(name))
+	switch {
+	case length < 1 || length > maxBlobNameLength:
+		return fmt.Sprintf("%d characters long", length)
+	case strings.HasSuffix(name, "/"):
+		return "ends with '/'"
+	case strings.HasSuffix(name, "."):
+		return "ends with '.'"
+	case strings.Count(name, "/")+1 > maxBlobPathSegments:
+		return fmt.Sprintf("%d path segments", strings.Count(name, "/")+1)
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package validators
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestNameValidators(t *testing.T) {
+	for name, tc := range map[string]struct {
+		validator validator.String
+		value     string
+		wantErr   string // the reason reported, "" when the value is valid
+	}{
+		"storage account 3 characters":     {validator: StorageAccountName(), value: "abc"},
+		"storage account 24 characters":    {validator: StorageAccountName(), value: strings.Repeat("a1", 12)},
+		"storage account 2 characters":     {validator: StorageAccountName(), value: "ab", wantErr: "2 characters long"},
+		"storage account 25 characters":    {validator: StorageAccountName(), value: strings.Repeat("a", 25), wantErr: "25 characters long"},
+		"storage account uppercase":        {validator: StorageAccountName(), value: "MyAccount", wantErr: "other than lowercase letters and digits"},
+		"storage account hyphen":           {validator: StorageAccountName(), value: "my-account", wantErr: "other than lowercase letters and digits"},
+		"container":                        {validator: ContainerName(), value: "tf-locks-1"},
+		"container 63 characters":          {validator: ContainerName(), value: strings.Repeat("a", 63)},
+		"container reserved $web":          {validator: ContainerName(), value: "$web"},
+		"container 2 characters":           {validator: ContainerName(), value: "ab", wantErr: "2 characters long"},
+		"container 64 characters":          {validator: ContainerName(), value: strings.Repeat("a", 64), wantErr: "64 characters long"},
+		"container leading hyphen":         {validator: ContainerName(), value: "-locks", wantErr: "starts or ends with a hyphen"},
+		"container trailing hyphen":        {validator: ContainerName(), value: "locks-", wantErr: "starts or ends with a hyphen"},
+		"container consecutive hyphens":    {validator: ContainerName(), value: "tf--locks", wantErr: "consecutive hyphens"},
+		"container underscore":             {validator: ContainerName(), value: "tf_locks", wantErr: "other than lowercase letters, digits and hyphens"},
+		"container uppercase":              {validator: ContainerName(), value: "Locks", wantErr: "other than lowercase letters, digits and hyphens"},
+		"container other reserved name":    {validator: ContainerName(), value: "$locks", wantErr: "other than lowercase letters, digits and hyphens"},
+		"blob path":                        {validator: BlobName(), value: "env/prod/app.lock"},
+		"blob 1024 characters":             {validator: BlobName(), value: strings.Repeat("a", 1024)},
+		"blob empty":                       {validator: BlobName(), value: "", wantErr: "0 characters long"},
+		"blob 1025 characters":             {validator: BlobName(), value: strings.Repeat("a", 1025), wantErr: "1025 characters long"},
+		"blob trailing slash":              {validator: BlobName(), value: "env/", wantErr: "ends with '/'"},
+		"blob trailing dot":                {validator: BlobName(), value: "app.", wantErr: "ends with '.'"},
+		"blob 255 path segments":           {validator: BlobName(), value: strings.Repeat("a/", 254) + "a", wantErr: "255 path segments"},
+		"blob prefix within suffix length": {validator: BlobNamePrefix(24), value: strings.Repeat("a", 1000)},
+		"blob prefix beyond suffix length": {validator: BlobNamePrefix(24), value: strings.Repeat("a", 1001), wantErr: "1001 characters long"},
+		"blob prefix empty":                {validator: BlobNamePrefix(24), value: "", wantErr: "0 characters long"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			attribute := path.Root("name")
+			var resp validator.StringResponse
+			tc.validator.ValidateString(context.Background(), validator.StringRequest{
+				Path:        attribute,
+				ConfigValue: types.StringValue(tc.value),
+			}, &resp)
+
+			if tc.wantErr == "" {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("unexpected error: %v", resp.Diagnostics)
+				}
+				return
+			}
+			if resp.Diagnostics.ErrorsCount() != 1 {
+				t.Fatalf("expected one error, got: %v", resp.Diagnostics)
+			}
+			diag := resp.Diagnostics.Errors()[0]
+			if !strings.Contains(diag.Detail(), tc.wantErr) {
+				t.Errorf("expected the error to give the reason %q, got: %s", tc.wantErr, diag.Detail())
+			}
+			withPath, ok := diag.(interface{ Path() path.Path })
+			if !ok || !withPath.Path().Equal(attribute) {
+				t.Errorf("expected the error to point at %s", attribute)
+			}
+		})
+	}
+}
+
+func TestNameValidatorsSkipUnknownValues(t *testing.T) {
+	for _, v := range []validator.String{StorageAccountName(), ContainerName(), BlobName()} {
+		for _, value := range []types.String{types.StringNull(), types.StringUnknown()} {
+			var resp validator.StringResponse
+			v.ValidateString(context.Background(), validator.StringRequest{Path: path.Root("name"), ConfigValue: value}, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Errorf("expected %s to be skipped, got: %v", value, resp.Diagnostics)
+			}
+		}
+	}
+}
+
+func TestCheckNames(t *testing.T) {
+	for name, tc := range map[string]struct {
+		check   func(string) error
+		value   string
+		wantErr bool
+	}{
+		"storage account":           {check: CheckStorageAccountName, value: "acct"},
+		"storage account uppercase": {check: CheckStorageAccountName, value: "Acct", wantErr: true},
+		"container":                 {check: CheckContainerName, value: "locks"},
+		"container underscore":      {check: CheckContainerName, value: "my_locks", wantErr: true},
+		"blob":                      {check: CheckBlobName, value: "env/app.lock"},
+		"blob trailing dot":         {check: CheckBlobName, value: "env/app.", wantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.check(tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %t, got: %v", tc.wantErr, err)
+			}
+			if err != nil && !strings.Contains(err.Error(), tc.value) {
+				t.Errorf("expected the error to quote the value, got: %s", err)
+			}
+		})
+	}
+}
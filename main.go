@@ -33,6 +33,7 @@ func main() {
 	}
 
 	err := providerserver.Serve(context.Background(), provider.New(version), opts)
+	provider.StopKeepalives()
 
 	if err != nil {
 		log.Fatal(err.Error())